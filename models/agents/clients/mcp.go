@@ -3,14 +3,21 @@ package clients
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type MCPClient struct {
-	client   *mcp.Client
-	endpoint string
+	client     *mcp.Client
+	endpoint   string
+	httpClient *http.Client
 }
 
 func NewMCPClient() (*MCPClient, error) {
@@ -25,19 +32,40 @@ func NewMCPClient() (*MCPClient, error) {
 			Version: os.Getenv("MCP_CLIENT_VERSION"),
 		}, nil),
 		endpoint: endpoint,
+		// Requests carry the caller's trace context so tool spans on the MCP
+		// server join the run's trace.
+		httpClient: &http.Client{Transport: telemetry.NewTransport(nil)},
 	}, nil
 }
 
-func (m *MCPClient) CallTool(ctx context.Context, toolName string, args map[string]any) (*mcp.CallToolResult, error) {
+func (m *MCPClient) CallTool(ctx context.Context, toolName string, args map[string]any) (result *mcp.CallToolResult, err error) {
+	ctx, span := tracer.Start(ctx, "mcp.call_tool "+toolName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("mcp.tool.name", toolName),
+		attribute.String("server.address", m.endpoint),
+	))
+	defer func() {
+		if err == nil && result != nil && result.IsError {
+			span.SetAttributes(attribute.Bool("mcp.tool.is_error", true))
+		}
+		telemetry.EndSpan(span, err)
+	}()
+
 	session, err := m.client.Connect(ctx, &mcp.StreamableClientTransport{
-		Endpoint: m.endpoint,
+		Endpoint:   m.endpoint,
+		HTTPClient: m.httpClient,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MCP server at %s: %w", m.endpoint, err)
+		return nil, errorcodes.Wrap(
+			errorcodes.ToolUnavailable,
+			fmt.Errorf("failed to connect to MCP server at %s: %w", m.endpoint, err),
+		)
 	}
 	defer session.Close()
 
+	// The run's correlation fields travel in _meta so tool logs on the MCP
+	// server carry the same run ID.
 	result, callErr := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      logging.Meta(ctx),
 		Name:      toolName,
 		Arguments: args,
 	})
@@ -15,3 +15,14 @@ func NewOpenAIClient(modelName string) (*openai.LLM, error) {
 
 	return openai.New(openai.WithModel(modelName))
 }
+
+// NewOpenAIClientWithResponseFormat creates an OpenAI client whose responses are
+// constrained by responseFormat.
+func NewOpenAIClientWithResponseFormat(modelName string, responseFormat *openai.ResponseFormat) (*openai.LLM, error) {
+	openaiAPIKey := os.Getenv("OPENAI_API_KEY")
+	if openaiAPIKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY not set")
+	}
+
+	return openai.New(openai.WithModel(modelName), openai.WithResponseFormat(responseFormat))
+}
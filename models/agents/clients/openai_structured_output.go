@@ -0,0 +1,99 @@
+package clients
+
+import (
+	"slices"
+
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// openAIResponseFormat translates output into an OpenAI json_schema response
+// format. ok is false when the schema uses constructs the response format
+// cannot express, such as type unions. Keywords OpenAI does not accept
+// (minimum, pattern, ...) are dropped; the caller's validator still enforces
+// them. Strict mode is only requested when every object is closed and lists all
+// of its properties as required, which is what OpenAI demands for strict schemas.
+func openAIResponseFormat(output StructuredOutput) (*openai.ResponseFormat, bool) {
+	schema, strict, ok := openAISchemaProperty(output.Schema)
+	if !ok || schema.Type != "object" {
+		return nil, false
+	}
+
+	return &openai.ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &openai.ResponseFormatJSONSchema{
+			Name:   output.Name,
+			Strict: strict,
+			Schema: schema,
+		},
+	}, true
+}
+
+func openAISchemaProperty(schema map[string]any) (*openai.ResponseFormatJSONSchemaProperty, bool, bool) {
+	schemaType, ok := schema["type"].(string)
+	if !ok || schemaType == "" {
+		return nil, false, false
+	}
+
+	property := &openai.ResponseFormatJSONSchemaProperty{Type: schemaType}
+	strict := true
+	if description, ok := schema["description"].(string); ok {
+		property.Description = description
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		property.Enum = enum
+	}
+
+	switch schemaType {
+	case "object":
+		properties, _ := schema["properties"].(map[string]any)
+		required := stringSlice(schema["required"])
+		additionalProperties, hasAdditional := schema["additionalProperties"].(bool)
+		if !hasAdditional || additionalProperties {
+			strict = false
+		}
+		property.Required = required
+		property.Properties = make(map[string]*openai.ResponseFormatJSONSchemaProperty, len(properties))
+		for name, raw := range properties {
+			childSchema, ok := raw.(map[string]any)
+			if !ok {
+				return nil, false, false
+			}
+			child, childStrict, ok := openAISchemaProperty(childSchema)
+			if !ok {
+				return nil, false, false
+			}
+			property.Properties[name] = child
+			if !childStrict || !slices.Contains(required, name) {
+				strict = false
+			}
+		}
+	case "array":
+		itemsSchema, ok := schema["items"].(map[string]any)
+		if !ok {
+			return nil, false, false
+		}
+		items, itemsStrict, ok := openAISchemaProperty(itemsSchema)
+		if !ok {
+			return nil, false, false
+		}
+		property.Items = items
+		strict = strict && itemsStrict
+	}
+
+	return property, strict, true
+}
+
+func stringSlice(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			values = append(values, text)
+		}
+	}
+	return values
+}
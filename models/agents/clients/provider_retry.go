@@ -7,13 +7,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
 	"github.com/tmc/langchaingo/llms"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -21,7 +27,7 @@ const (
 	providerRetryDelay      = 100 * time.Millisecond
 )
 
-type executionIDContextKey struct{}
+var tracer = telemetry.Tracer("github.com/arcnem-ai/arcnem-vision/models/agents/clients")
 
 type retryingModel struct {
 	model     llms.Model
@@ -38,9 +44,37 @@ func (e *providerCallError) Error() string {
 	return fmt.Sprintf("provider request failed (status=%s)", e.status)
 }
 
-// ContextWithExecutionID attaches the workflow run identity used by provider diagnostics.
+// Transient reports whether the whole run is worth retrying later: rate limits,
+// provider 5xx responses, transport failures, and timeouts usually clear.
+func (e *providerCallError) Transient() bool {
+	switch e.status {
+	case "429", "transport_error", "timeout":
+		return true
+	}
+	status, err := strconv.Atoi(e.status)
+	return err == nil && status >= 500 && status <= 599
+}
+
+func (e *providerCallError) ErrorCode() errorcodes.Code {
+	switch {
+	case e.status == "429":
+		return errorcodes.ProviderRateLimited
+	case e.status == "timeout":
+		return errorcodes.Timeout
+	case e.status == "canceled":
+		return errorcodes.Cancelled
+	case e.Transient():
+		return errorcodes.ProviderUnavailable
+	default:
+		return errorcodes.ProviderFailed
+	}
+}
+
+// ContextWithExecutionID attaches the workflow run identity used by provider
+// diagnostics. Logs written with the context and MCP tool calls made with it
+// carry the ID as run_id.
 func ContextWithExecutionID(ctx context.Context, executionID string) context.Context {
-	return context.WithValue(ctx, executionIDContextKey{}, executionID)
+	return logging.WithRunID(ctx, executionID)
 }
 
 // WithProviderRetry adds bounded transient retries and content-safe diagnostics to a model.
@@ -59,7 +93,7 @@ func (m *retryingModel) GenerateContent(
 	options ...llms.CallOption,
 ) (*llms.ContentResponse, error) {
 	requestBytes, requestHash := messageFingerprint(messages)
-	return runProviderCall(ctx, m, requestBytes, requestHash, func() (*llms.ContentResponse, error) {
+	return runProviderCall(ctx, m, requestBytes, requestHash, func(ctx context.Context) (*llms.ContentResponse, error) {
 		return m.model.GenerateContent(ctx, messages, options...)
 	})
 }
@@ -70,7 +104,7 @@ func (m *retryingModel) Call(
 	options ...llms.CallOption,
 ) (string, error) {
 	hash := sha256.Sum256([]byte(prompt))
-	return runProviderCall(ctx, m, len(prompt), fmt.Sprintf("%x", hash), func() (string, error) {
+	return runProviderCall(ctx, m, len(prompt), fmt.Sprintf("%x", hash), func(ctx context.Context) (string, error) {
 		return m.model.Call(ctx, prompt, options...)
 	})
 }
@@ -85,27 +119,45 @@ func runProviderCall[T any](
 	model *retryingModel,
 	requestBytes int,
 	requestHash string,
-	call func() (T, error),
-) (T, error) {
+	call func(context.Context) (T, error),
+) (_ T, callErr error) {
 	var zero T
-	executionID, _ := ctx.Value(executionIDContextKey{}).(string)
+	executionID := logging.RunID(ctx)
+
+	ctx, span := tracer.Start(ctx, "model.call", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("arcnem.run_id", executionID),
+		attribute.String("arcnem.node", model.node),
+		attribute.String("gen_ai.system", model.provider),
+		attribute.String("gen_ai.request.model", model.modelName),
+		attribute.Int("arcnem.model.request_bytes", requestBytes),
+	))
+	defer func() { telemetry.EndSpan(span, callErr) }()
 
 	for attempt := 1; ; attempt++ {
 		startedAt := time.Now()
-		result, err := call()
+		result, err := call(ctx)
+		latency := time.Since(startedAt)
 		status, retryable := classifyProviderError(ctx, err)
-		log.Printf(
-			"provider request execution=%q node=%q provider=%q model=%q attempt=%d max_attempts=%d status=%q latency_ms=%d request_bytes=%d request_sha256=%s",
-			diagnosticValue(executionID),
-			diagnosticValue(model.node),
-			diagnosticValue(model.provider),
-			diagnosticValue(model.modelName),
-			attempt,
-			providerCallMaxAttempts,
-			status,
-			time.Since(startedAt).Milliseconds(),
-			requestBytes,
-			requestHash,
+		metrics.ObserveProviderCall(model.provider, model.modelName, status, latency)
+		span.AddEvent("attempt", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.String("status", status),
+			attribute.Int64("latency_ms", latency.Milliseconds()),
+		))
+		level := slog.LevelInfo
+		if err != nil {
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "provider request",
+			"node_key", diagnosticValue(model.node),
+			"provider", diagnosticValue(model.provider),
+			"model", diagnosticValue(model.modelName),
+			"attempt", attempt,
+			"max_attempts", providerCallMaxAttempts,
+			"status", status,
+			"latency_ms", latency.Milliseconds(),
+			"request_bytes", requestBytes,
+			"request_sha256", requestHash,
 		)
 		if err == nil {
 			return result, nil
@@ -113,6 +165,7 @@ func runProviderCall[T any](
 		if !retryable || attempt == providerCallMaxAttempts {
 			return zero, &providerCallError{status: status}
 		}
+		metrics.CountProviderRetry(model.provider, model.modelName)
 
 		timer := time.NewTimer(time.Duration(attempt) * providerRetryDelay)
 		select {
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"strings"
 	"testing"
 
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -29,17 +32,9 @@ func (m *providerTestModel) Call(context.Context, string, ...llms.CallOption) (s
 
 func TestProviderRetryHandles500ThenSuccessWithSafeDiagnostics(t *testing.T) {
 	var logs bytes.Buffer
-	previousOutput := log.Writer()
-	previousFlags := log.Flags()
-	previousPrefix := log.Prefix()
-	log.SetOutput(&logs)
-	log.SetFlags(0)
-	log.SetPrefix("")
-	t.Cleanup(func() {
-		log.SetOutput(previousOutput)
-		log.SetFlags(previousFlags)
-		log.SetPrefix(previousPrefix)
-	})
+	previousLogger := slog.Default()
+	slog.SetDefault(slog.New(logging.NewHandler(slog.NewJSONHandler(&logs, nil))))
+	t.Cleanup(func() { slog.SetDefault(previousLogger) })
 
 	calls := 0
 	model := WithProviderRetry(&providerTestModel{generate: func() (*llms.ContentResponse, error) {
@@ -72,15 +67,15 @@ func TestProviderRetryHandles500ThenSuccessWithSafeDiagnostics(t *testing.T) {
 
 	output := logs.String()
 	for _, expected := range []string{
-		`execution="execution-123"`,
-		`node="inspect_image"`,
-		`provider="OPENAI"`,
-		`model="gpt-test"`,
-		`attempt=1 max_attempts=3 status="500"`,
-		`attempt=2 max_attempts=3 status="ok"`,
-		"latency_ms=",
-		"request_bytes=",
-		"request_sha256=",
+		`"run_id":"execution-123"`,
+		`"node_key":"inspect_image"`,
+		`"provider":"OPENAI"`,
+		`"model":"gpt-test"`,
+		`"attempt":1,"max_attempts":3,"status":"500"`,
+		`"attempt":2,"max_attempts":3,"status":"ok"`,
+		`"latency_ms":`,
+		`"request_bytes":`,
+		`"request_sha256":`,
 	} {
 		if !strings.Contains(output, expected) {
 			t.Errorf("expected diagnostics to contain %q, got %q", expected, output)
@@ -152,3 +147,23 @@ func TestProviderRetryClassifiesTransportFailureAsRetryable(t *testing.T) {
 		t.Fatalf("expected retryable transport_error, got status=%q retryable=%t", status, retryable)
 	}
 }
+
+func TestProviderCallErrorClassifiesRunRetries(t *testing.T) {
+	for _, test := range []struct {
+		status    string
+		transient bool
+	}{
+		{status: "429", transient: true},
+		{status: "503", transient: true},
+		{status: "transport_error", transient: true},
+		{status: "timeout", transient: true},
+		{status: "400", transient: false},
+		{status: "canceled", transient: false},
+		{status: "error", transient: false},
+	} {
+		err := fmt.Errorf("worker node: %w", &providerCallError{status: test.status})
+		if got := runerrors.IsTransient(err); got != test.transient {
+			t.Fatalf("status %s: expected transient=%t, got %t", test.status, test.transient, got)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package clients
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/enums"
+	"github.com/tmc/langchaingo/llms"
+)
+
+const structuredOutputToolName = "submit_output"
+
+var structuredOutputNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// StructuredOutput is the provider-neutral JSON Schema a model's final answer
+// must satisfy. Schema is a JSON Schema object as decoded by encoding/json.
+type StructuredOutput struct {
+	Name   string
+	Schema map[string]any
+}
+
+// NewStructuredOutput normalizes a schema name into the identifier format
+// providers accept for response formats and tool names.
+func NewStructuredOutput(name string, schema map[string]any) StructuredOutput {
+	normalized := strings.Trim(structuredOutputNamePattern.ReplaceAllString(name, "_"), "_")
+	if normalized == "" {
+		normalized = "output"
+	}
+	if len(normalized) > 64 {
+		normalized = normalized[:64]
+	}
+
+	return StructuredOutput{Name: normalized, Schema: schema}
+}
+
+// NewStructuredModelClient creates a model client that constrains its final
+// answer to output natively. ok is false when the provider, or this particular
+// schema, cannot be expressed natively and callers should fall back.
+func NewStructuredModelClient(provider string, modelName string, output StructuredOutput) (any, bool, error) {
+	switch provider {
+	case enums.ModelProviderOpenAI:
+		responseFormat, ok := openAIResponseFormat(output)
+		if !ok {
+			return nil, false, nil
+		}
+		client, err := NewOpenAIClientWithResponseFormat(modelName, responseFormat)
+		if err != nil {
+			return nil, false, err
+		}
+		return client, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+type toolForcedOutputModel struct {
+	model      llms.Model
+	tool       llms.Tool
+	toolChoice llms.ToolChoice
+}
+
+// WithToolForcedOutput makes a model answer by calling a single forced tool whose
+// parameters are the output schema, and returns the tool arguments as the text
+// content. It is meant for nodes that have no tools of their own.
+func WithToolForcedOutput(model llms.Model, output StructuredOutput) llms.Model {
+	return &toolForcedOutputModel{
+		model: model,
+		tool: llms.Tool{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        structuredOutputToolName,
+				Description: "Submit the final answer as " + output.Name + ".",
+				Parameters:  output.Schema,
+			},
+		},
+		toolChoice: llms.ToolChoice{
+			Type:     "function",
+			Function: &llms.FunctionReference{Name: structuredOutputToolName},
+		},
+	}
+}
+
+func (m *toolForcedOutputModel) GenerateContent(
+	ctx context.Context,
+	messages []llms.MessageContent,
+	options ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	forcedOptions := append(append([]llms.CallOption(nil), options...),
+		llms.WithTools([]llms.Tool{m.tool}),
+		llms.WithToolChoice(m.toolChoice),
+	)
+
+	resp, err := m.model.GenerateContent(ctx, messages, forcedOptions...)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	for _, choice := range resp.Choices {
+		for _, call := range choice.ToolCalls {
+			if call.FunctionCall == nil || call.FunctionCall.Name != structuredOutputToolName {
+				continue
+			}
+			choice.Content = call.FunctionCall.Arguments
+			choice.ToolCalls = nil
+			choice.FuncCall = nil
+			break
+		}
+	}
+
+	return resp, nil
+}
+
+func (m *toolForcedOutputModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
@@ -0,0 +1,127 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+type structuredOutputTestModel struct {
+	generate func(options llms.CallOptions) (*llms.ContentResponse, error)
+}
+
+func (m *structuredOutputTestModel) GenerateContent(
+	_ context.Context,
+	_ []llms.MessageContent,
+	options ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	var callOptions llms.CallOptions
+	for _, option := range options {
+		option(&callOptions)
+	}
+	return m.generate(callOptions)
+}
+
+func (m *structuredOutputTestModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestToolForcedOutputReturnsToolArgumentsAsContent(t *testing.T) {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"label": map[string]any{"type": "string"}},
+	}
+	model := WithToolForcedOutput(&structuredOutputTestModel{generate: func(options llms.CallOptions) (*llms.ContentResponse, error) {
+		if len(options.Tools) != 1 || options.Tools[0].Function.Name != structuredOutputToolName {
+			t.Fatalf("expected only the output tool, got %#v", options.Tools)
+		}
+		choice, ok := options.ToolChoice.(llms.ToolChoice)
+		if !ok || choice.Function == nil || choice.Function.Name != structuredOutputToolName {
+			t.Fatalf("expected output tool to be forced, got %#v", options.ToolChoice)
+		}
+		return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+			ToolCalls: []llms.ToolCall{{
+				ID:   "call-1",
+				Type: "function",
+				FunctionCall: &llms.FunctionCall{
+					Name:      structuredOutputToolName,
+					Arguments: `{"label":"rust"}`,
+				},
+			}},
+		}}}, nil
+	}}, NewStructuredOutput("classify image/output", schema))
+
+	response, err := model.GenerateContent(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GenerateContent returned error: %v", err)
+	}
+	choice := response.Choices[0]
+	if choice.Content != `{"label":"rust"}` {
+		t.Fatalf("expected tool arguments as content, got %q", choice.Content)
+	}
+	if len(choice.ToolCalls) != 0 {
+		t.Fatalf("expected output tool call to be consumed, got %#v", choice.ToolCalls)
+	}
+}
+
+func TestNewStructuredOutputNormalizesName(t *testing.T) {
+	if got := NewStructuredOutput("classify image/output", nil).Name; got != "classify_image_output" {
+		t.Fatalf("expected normalized name, got %q", got)
+	}
+	if got := NewStructuredOutput("!!!", nil).Name; got != "output" {
+		t.Fatalf("expected fallback name, got %q", got)
+	}
+}
+
+func TestOpenAIResponseFormatTranslatesSchema(t *testing.T) {
+	closed := map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []any{"label", "tags"},
+		"properties": map[string]any{
+			"label": map[string]any{"type": "string", "enum": []any{"rust", "clean"}},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+
+	format, ok := openAIResponseFormat(NewStructuredOutput("inspect", closed))
+	if !ok {
+		t.Fatal("expected closed schema to translate")
+	}
+	if format.Type != "json_schema" || format.JSONSchema.Name != "inspect" {
+		t.Fatalf("unexpected response format %#v", format)
+	}
+	if !format.JSONSchema.Strict {
+		t.Fatal("expected closed schema with all fields required to be strict")
+	}
+	if got := format.JSONSchema.Schema.Properties["tags"].Items.Type; got != "string" {
+		t.Fatalf("expected array item type to translate, got %q", got)
+	}
+
+	open := map[string]any{
+		"type":     "object",
+		"required": []any{"label"},
+		"properties": map[string]any{
+			"label": map[string]any{"type": "string"},
+			"note":  map[string]any{"type": "string"},
+		},
+	}
+	format, ok = openAIResponseFormat(NewStructuredOutput("inspect", open))
+	if !ok {
+		t.Fatal("expected open schema to translate")
+	}
+	if format.JSONSchema.Strict {
+		t.Fatal("expected open schema with optional fields to be non-strict")
+	}
+
+	nullable := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"note": map[string]any{"type": []any{"string", "null"}},
+		},
+	}
+	if _, ok := openAIResponseFormat(NewStructuredOutput("inspect", nullable)); ok {
+		t.Fatal("expected type unions to fall back")
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// localImage is an image file served over loopback so graphs can fetch it
+// from temp_url like a presigned S3 URL.
+type localImage struct {
+	URL         string
+	ContentType string
+	SizeBytes   int64
+
+	server *http.Server
+}
+
+func serveImage(path string) (*localImage, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("image %s is a directory", path)
+	}
+	contentType, err := detectContentType(path)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on loopback: %w", err)
+	}
+	// A fixed name keeps the URL valid whatever the file is called.
+	name := "image" + strings.ToLower(filepath.Ext(path))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /"+name, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		http.ServeFile(w, r, path)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "image server stopped: %v\n", err)
+		}
+	}()
+
+	return &localImage{
+		URL:         fmt.Sprintf("http://%s/%s", listener.Addr(), name),
+		ContentType: contentType,
+		SizeBytes:   info.Size(),
+		server:      server,
+	}, nil
+}
+
+func (i *localImage) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return i.server.Shutdown(ctx)
+}
+
+func detectContentType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := file.Read(header)
+	if err != nil && n == 0 {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	return http.DetectContentType(header[:n]), nil
+}
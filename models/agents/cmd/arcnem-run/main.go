@@ -0,0 +1,215 @@
+// Command arcnem-run runs a workflow graph file against a local image without
+// the database, S3, or a job queue. It prints each node's state delta and the
+// final state, and can write the run to a trace file.
+//
+//	arcnem-run -graph graph.yaml -image photo.jpg [-trace run.json]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/env"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/google/uuid"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+type options struct {
+	GraphPath  string
+	ImagePath  string
+	State      string
+	DocumentID string
+	TracePath  string
+	MCPURL     string
+	ModelURL   string
+	Timeout    time.Duration
+	Verbose    bool
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "arcnem-run: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseOptions(args []string) (options, error) {
+	var opts options
+	flags := flag.NewFlagSet("arcnem-run", flag.ContinueOnError)
+	flags.StringVar(&opts.GraphPath, "graph", "", "graph snapshot file (.json, .yaml or .yml)")
+	flags.StringVar(&opts.ImagePath, "image", "", "image served to the graph as temp_url")
+	flags.StringVar(&opts.State, "state", "", "JSON object merged into the initial state")
+	flags.StringVar(&opts.DocumentID, "document-id", "", "document ID given to the graph (default: a new UUID)")
+	flags.StringVar(&opts.TracePath, "trace", "", "write the run and its steps to this JSON file")
+	flags.StringVar(&opts.MCPURL, "mcp-url", "", "MCP server URL (default: $MCP_SERVER_URL)")
+	flags.StringVar(&opts.ModelURL, "model-url", "", "OpenAI-compatible API base URL (default: $OPENAI_BASE_URL)")
+	flags.DurationVar(&opts.Timeout, "timeout", 10*time.Minute, "how long the graph may run")
+	flags.BoolVar(&opts.Verbose, "v", false, "log node and provider activity to stderr")
+	if err := flags.Parse(args); err != nil {
+		return options{}, err
+	}
+	if opts.GraphPath == "" || opts.ImagePath == "" {
+		flags.Usage()
+		return options{}, errors.New("-graph and -image are required")
+	}
+	if opts.Timeout <= 0 {
+		return options{}, fmt.Errorf("invalid -timeout %s: must be positive", opts.Timeout)
+	}
+	return opts, nil
+}
+
+func run(args []string, out io.Writer) error {
+	opts, err := parseOptions(args)
+	if err != nil {
+		return err
+	}
+	// A .env file is optional for local runs.
+	if err := env.LoadEnv(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("load env: %w", err)
+	}
+	setupLogging(opts.Verbose)
+	// Clients read their endpoints from the environment, so the flags
+	// override it for this process.
+	if opts.MCPURL != "" {
+		os.Setenv("MCP_SERVER_URL", opts.MCPURL)
+	}
+	if opts.ModelURL != "" {
+		os.Setenv("OPENAI_BASE_URL", opts.ModelURL)
+	}
+
+	seed, err := parseState(opts.State)
+	if err != nil {
+		return err
+	}
+	snapshot, err := readSnapshotFile(opts.GraphPath)
+	if err != nil {
+		return err
+	}
+	var mcpClient *clients.MCPClient
+	if os.Getenv("MCP_SERVER_URL") != "" {
+		if mcpClient, err = clients.NewMCPClient(); err != nil {
+			return err
+		}
+	}
+	builtGraph, err := graphs.BuildGraph(snapshot, mcpClient)
+	if err != nil {
+		return fmt.Errorf("failed to build graph: %w", err)
+	}
+
+	image, err := serveImage(opts.ImagePath)
+	if err != nil {
+		return err
+	}
+	defer image.Close()
+
+	documentID := opts.DocumentID
+	if documentID == "" {
+		documentID = uuid.NewString()
+	}
+	runID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate run id: %w", err)
+	}
+	initialState := localInitialState(seed, documentID, image)
+
+	recorder := newTraceRecorder(out, graphs.NodeTypes(snapshot))
+	tracer := graph.NewTracer()
+	tracer.AddHook(recorder)
+	builtGraph.SetTracer(tracer)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	fmt.Fprintf(out, "running %s (run %s) with %s\n", snapshot.AgentGraph.Name, runID, image.URL)
+	startedAt := time.Now()
+	finalState, graphErr := builtGraph.Invoke(clients.ContextWithExecutionID(ctx, runID.String()), initialState)
+	trace := &runTrace{
+		RunID:        runID.String(),
+		AgentGraph:   snapshot.AgentGraph.Name,
+		Status:       "completed",
+		InitialState: initialState,
+		FinalState:   finalState,
+		StartedAt:    startedAt,
+		FinishedAt:   time.Now(),
+		Steps:        recorder.trace(),
+	}
+	if graphErr != nil {
+		message := graphErr.Error()
+		code := string(errorcodes.Of(graphErr))
+		trace.Status = "failed"
+		trace.Error = &message
+		trace.ErrorCode = &code
+	}
+
+	if graphErr == nil {
+		fmt.Fprintln(out, "final state:")
+		writeIndentedJSON(out, finalState, "")
+	}
+	if opts.TracePath != "" {
+		if err := writeTraceFile(opts.TracePath, trace); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "trace written to %s\n", opts.TracePath)
+	}
+	if graphErr != nil {
+		return fmt.Errorf("run failed (%s): %w", *trace.ErrorCode, graphErr)
+	}
+	return nil
+}
+
+// setupLogging sends logs to stderr so stdout only carries the run. Only
+// warnings are logged unless verbose is set.
+func setupLogging(verbose bool) {
+	level := slog.LevelWarn
+	if verbose {
+		level = slog.LevelInfo
+	}
+	handler := logging.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	slog.SetDefault(slog.New(handler).With("service", "arcnem-run"))
+}
+
+func parseState(raw string) (map[string]any, error) {
+	seed := map[string]any{}
+	if strings.TrimSpace(raw) == "" {
+		return seed, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &seed); err != nil {
+		return nil, fmt.Errorf("invalid -state: must be a JSON object: %w", err)
+	}
+	return seed, nil
+}
+
+// localInitialState seeds the graph like a single-document execution, with
+// the image standing in for the document.
+func localInitialState(seed map[string]any, documentID string, image *localImage) map[string]any {
+	initialState := make(map[string]any, len(seed)+4)
+	for key, value := range seed {
+		initialState[key] = value
+	}
+	initialState["document_id"] = documentID
+	initialState["document_ids"] = []string{documentID}
+	initialState["documents"] = []map[string]any{{
+		"id":           documentID,
+		"content_type": image.ContentType,
+		"size_bytes":   image.SizeBytes,
+		"temp_url":     image.URL,
+	}}
+	initialState["temp_url"] = image.URL
+	return initialState
+}
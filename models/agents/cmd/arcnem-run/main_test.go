@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const snapshotJSON = `{
+	"agent_graph": {"id": "graph-1", "name": "Describe", "entry_node": "describe"},
+	"nodes": [{
+		"node": {"id": "node-1", "node_key": "describe", "node_type": "worker", "input_key": "temp_url"},
+		"model": {"id": "model-1", "provider": "OPENAI", "name": "gpt-4.1-mini"}
+	}],
+	"edges": [{"id": "edge-1", "from_node": "describe", "to_node": "END"}]
+}`
+
+const snapshotYAML = `
+agent_graph:
+  id: graph-1
+  name: Describe
+  entry_node: describe
+nodes:
+  - node:
+      id: node-1
+      node_key: describe
+      node_type: worker
+      input_key: temp_url
+      config:
+        system_message: Describe the image.
+    model:
+      id: model-1
+      provider: OPENAI
+      name: gpt-4.1-mini
+edges:
+  - id: edge-1
+    from_node: describe
+    to_node: END
+`
+
+func TestDecodeSnapshotReadsJSONAndYAML(t *testing.T) {
+	fromJSON, err := decodeSnapshot([]byte(snapshotJSON), ".json")
+	if err != nil {
+		t.Fatalf("decode JSON: %v", err)
+	}
+	fromYAML, err := decodeSnapshot([]byte(snapshotYAML), ".yaml")
+	if err != nil {
+		t.Fatalf("decode YAML: %v", err)
+	}
+
+	if fromYAML.AgentGraph.EntryNode != "describe" || fromJSON.AgentGraph.EntryNode != "describe" {
+		t.Fatalf("unexpected entry nodes: %q, %q", fromJSON.AgentGraph.EntryNode, fromYAML.AgentGraph.EntryNode)
+	}
+	if len(fromYAML.Nodes) != 1 || fromYAML.Nodes[0].Model == nil || fromYAML.Nodes[0].Model.Name != "gpt-4.1-mini" {
+		t.Fatalf("unexpected YAML nodes: %#v", fromYAML.Nodes)
+	}
+	if fromYAML.Nodes[0].Node.Config != `{"system_message":"Describe the image."}` {
+		t.Fatalf("expected config to be encoded as JSON text, got %q", fromYAML.Nodes[0].Node.Config)
+	}
+	if len(fromYAML.Edges) != 1 || fromYAML.Edges[0].ToNode != "END" {
+		t.Fatalf("unexpected YAML edges: %#v", fromYAML.Edges)
+	}
+}
+
+func TestDecodeSnapshotRequiresAgentGraph(t *testing.T) {
+	if _, err := decodeSnapshot([]byte(`{"nodes": []}`), ".json"); err == nil {
+		t.Fatal("expected an error for a snapshot without agent_graph")
+	}
+}
+
+func TestServeImageServesFileOverLoopback(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	path := filepath.Join(t.TempDir(), "receipt scan.PNG")
+	if err := os.WriteFile(path, png, 0o600); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+
+	image, err := serveImage(path)
+	if err != nil {
+		t.Fatalf("serveImage returned error: %v", err)
+	}
+	defer image.Close()
+
+	if image.ContentType != "image/png" || image.SizeBytes != int64(len(png)) {
+		t.Fatalf("unexpected image: %#v", image)
+	}
+	response, err := http.Get(image.URL)
+	if err != nil {
+		t.Fatalf("fetch %s: %v", image.URL, err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK || !bytes.Equal(body, png) {
+		t.Fatalf("unexpected response %d: %q", response.StatusCode, body)
+	}
+}
+
+func TestLocalInitialStateSeedsOneDocument(t *testing.T) {
+	image := &localImage{URL: "http://127.0.0.1:1234/image.png", ContentType: "image/png", SizeBytes: 42}
+	seed, err := parseState(`{"locale": "ja", "temp_url": "ignored"}`)
+	if err != nil {
+		t.Fatalf("parseState returned error: %v", err)
+	}
+
+	state := localInitialState(seed, "doc-1", image)
+
+	if state["locale"] != "ja" || state["document_id"] != "doc-1" || state["temp_url"] != image.URL {
+		t.Fatalf("unexpected initial state: %#v", state)
+	}
+	documents := state["documents"].([]map[string]any)
+	if len(documents) != 1 || documents[0]["temp_url"] != image.URL || documents[0]["size_bytes"] != int64(42) {
+		t.Fatalf("unexpected documents: %#v", documents)
+	}
+}
+
+func TestParseStateRejectsNonObjects(t *testing.T) {
+	if _, err := parseState(`["a"]`); err == nil {
+		t.Fatal("expected an error for a non-object state")
+	}
+}
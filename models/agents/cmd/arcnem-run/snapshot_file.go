@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"gopkg.in/yaml.v3"
+)
+
+// jsonbFields are snapshot fields that hold JSON text, such as a node's
+// config. Files may write them as objects, which are encoded back to text.
+var jsonbFields = map[string]bool{
+	"config":        true,
+	"state_schema":  true,
+	"input_schema":  true,
+	"output_schema": true,
+}
+
+// readSnapshotFile reads a graph snapshot in the graphs.Snapshot JSON format,
+// such as a run's graph_snapshot. Files ending in .yaml or .yml are read as
+// YAML with the same field names.
+func readSnapshotFile(path string) (*graphs.Snapshot, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graph file: %w", err)
+	}
+	snapshot, err := decodeSnapshot(contents, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode graph file %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+func decodeSnapshot(contents []byte, ext string) (*graphs.Snapshot, error) {
+	var document any
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(contents, &document); err != nil {
+			return nil, err
+		}
+	default:
+		if err := json.Unmarshal(contents, &document); err != nil {
+			return nil, err
+		}
+	}
+	if err := encodeJSONBFields(document); err != nil {
+		return nil, err
+	}
+
+	// The snapshot types only carry JSON tags, so the document is decoded
+	// through JSON whichever format it was written in.
+	converted, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot graphs.Snapshot
+	if err := json.Unmarshal(converted, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.AgentGraph == nil {
+		return nil, fmt.Errorf("agent_graph is missing")
+	}
+	return &snapshot, nil
+}
+
+func encodeJSONBFields(value any) error {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			switch field.(type) {
+			case map[string]any, []any:
+				if jsonbFields[key] {
+					encoded, err := json.Marshal(field)
+					if err != nil {
+						return fmt.Errorf("failed to encode %s: %w", key, err)
+					}
+					value[key] = string(encoded)
+					continue
+				}
+			}
+			if err := encodeJSONBFields(field); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if err := encodeJSONBFields(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
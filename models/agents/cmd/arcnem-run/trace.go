@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// runTrace is the run written by -trace. Its fields mirror agent_graph_runs
+// and agent_graph_run_steps so a local run reads like a recorded one.
+type runTrace struct {
+	RunID        string         `json:"run_id"`
+	AgentGraph   string         `json:"agent_graph"`
+	Status       string         `json:"status"`
+	Error        *string        `json:"error,omitempty"`
+	ErrorCode    *string        `json:"error_code,omitempty"`
+	InitialState map[string]any `json:"initial_state"`
+	FinalState   map[string]any `json:"final_state,omitempty"`
+	StartedAt    time.Time      `json:"started_at"`
+	FinishedAt   time.Time      `json:"finished_at"`
+	Steps        []*traceStep   `json:"steps"`
+}
+
+type traceStep struct {
+	StepOrder  int       `json:"step_order"`
+	NodeKey    string    `json:"node_key"`
+	NodeType   string    `json:"node_type,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMS int64     `json:"duration_ms"`
+	StateDelta any       `json:"state_delta,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	ErrorCode  *string   `json:"error_code,omitempty"`
+}
+
+// traceRecorder prints each node's state delta as the graph runs and keeps
+// the steps for the trace file.
+type traceRecorder struct {
+	out       io.Writer
+	nodeTypes map[string]string
+
+	mu    sync.Mutex
+	steps []*traceStep
+	// open tracks in-flight steps by span ID.
+	open map[string]*traceStep
+}
+
+var _ graph.TraceHook = (*traceRecorder)(nil)
+
+func newTraceRecorder(out io.Writer, nodeTypes map[string]string) *traceRecorder {
+	return &traceRecorder{
+		out:       out,
+		nodeTypes: nodeTypes,
+		open:      make(map[string]*traceStep),
+	}
+}
+
+// OnEvent implements graph.TraceHook.
+func (r *traceRecorder) OnEvent(_ context.Context, span *graph.TraceSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch span.Event {
+	case graph.TraceEventNodeStart:
+		step := &traceStep{
+			StepOrder: len(r.steps) + 1,
+			NodeKey:   span.NodeName,
+			NodeType:  r.nodeTypes[span.NodeName],
+			StartedAt: span.StartTime,
+		}
+		r.steps = append(r.steps, step)
+		r.open[span.ID] = step
+		fmt.Fprintf(r.out, "[%d] %s started\n", step.StepOrder, step.NodeKey)
+
+	case graph.TraceEventNodeEnd, graph.TraceEventNodeError:
+		step, ok := r.open[span.ID]
+		if !ok {
+			return
+		}
+		delete(r.open, span.ID)
+		step.FinishedAt = span.EndTime
+		step.DurationMS = span.Duration.Milliseconds()
+		if span.State != nil {
+			step.StateDelta = span.State
+		}
+		if span.Event == graph.TraceEventNodeError {
+			nodeErr := span.Error
+			if nodeErr == nil {
+				nodeErr = fmt.Errorf("node %s failed", span.NodeName)
+			}
+			message := nodeErr.Error()
+			code := string(errorcodes.Of(nodeErr))
+			step.Error = &message
+			step.ErrorCode = &code
+			fmt.Fprintf(r.out, "[%d] %s failed after %dms: %s (%s)\n", step.StepOrder, step.NodeKey, step.DurationMS, message, code)
+		} else {
+			fmt.Fprintf(r.out, "[%d] %s finished in %dms\n", step.StepOrder, step.NodeKey, step.DurationMS)
+		}
+		if step.StateDelta != nil {
+			writeIndentedJSON(r.out, step.StateDelta, "    ")
+		}
+	}
+}
+
+// trace returns the recorded steps in start order.
+func (r *traceRecorder) trace() []*traceStep {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*traceStep(nil), r.steps...)
+}
+
+func writeTraceFile(path string, trace *runTrace) error {
+	encoded, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trace: %w", err)
+	}
+	if err := os.WriteFile(path, append(encoded, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return nil
+}
+
+func writeIndentedJSON(out io.Writer, value any, prefix string) {
+	encoded, err := json.MarshalIndent(value, prefix, "  ")
+	if err != nil {
+		fmt.Fprintf(out, "%s(unencodable state: %v)\n", prefix, err)
+		return
+	}
+	fmt.Fprintf(out, "%s%s\n", prefix, encoded)
+}
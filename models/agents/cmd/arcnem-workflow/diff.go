@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphdiff"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/workflowfile"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// graphSource is one side of a diff, written as a file path or as
+// workflow:<id>, run:<id>, template:<id>, or template:<id>@<version>.
+type graphSource struct {
+	Path       string
+	WorkflowID uuid.UUID
+	RunID      uuid.UUID
+	TemplateID uuid.UUID
+	Version    int
+}
+
+func parseGraphSource(value string) (graphSource, error) {
+	kind, rest, found := strings.Cut(value, ":")
+	if !found {
+		return graphSource{Path: value}, nil
+	}
+
+	var source graphSource
+	var err error
+	switch kind {
+	case "workflow":
+		source.WorkflowID, err = uuid.Parse(rest)
+	case "run":
+		source.RunID, err = uuid.Parse(rest)
+	case "template":
+		id, version, hasVersion := strings.Cut(rest, "@")
+		if source.TemplateID, err = uuid.Parse(id); err == nil && hasVersion {
+			source.Version, err = strconv.Atoi(version)
+			if err == nil && source.Version <= 0 {
+				err = errors.New("version must be positive")
+			}
+		}
+	default:
+		// A path that happens to contain a colon.
+		return graphSource{Path: value}, nil
+	}
+	if err != nil {
+		return graphSource{}, fmt.Errorf("invalid %q: %w", value, err)
+	}
+	return source, nil
+}
+
+func (s graphSource) needsDatabase() bool {
+	return s.Path == ""
+}
+
+func (s graphSource) load(ctx context.Context, db *gorm.DB) (*workflowfile.File, error) {
+	switch {
+	case s.Path != "":
+		contents, err := os.ReadFile(s.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workflow file: %w", err)
+		}
+		file, err := workflowfile.Decode(contents, workflowfile.FormatForPath(s.Path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Path, err)
+		}
+		return file, nil
+	case s.RunID != uuid.Nil:
+		snapshot, _, err := loadRun(ctx, db, s.RunID)
+		if err != nil {
+			return nil, err
+		}
+		return workflowfile.FromSnapshot(snapshot)
+	case s.TemplateID != uuid.Nil:
+		return workflowfile.ExportTemplateVersion(ctx, db, s.TemplateID, s.Version)
+	default:
+		return workflowfile.ExportWorkflow(ctx, db, s.WorkflowID)
+	}
+}
+
+type diffOptions struct {
+	Before graphSource
+	After  graphSource
+	JSON   bool
+}
+
+func parseDiffOptions(args []string) (diffOptions, error) {
+	var opts diffOptions
+	var format string
+	flags := flag.NewFlagSet("arcnem-workflow diff", flag.ContinueOnError)
+	flags.StringVar(&format, "format", "text", "text or json")
+	if err := flags.Parse(args); err != nil {
+		return diffOptions{}, err
+	}
+	if flags.NArg() != 2 {
+		return diffOptions{}, errors.New("diff needs a before and an after graph")
+	}
+	switch format {
+	case "text":
+	case "json":
+		opts.JSON = true
+	default:
+		return diffOptions{}, fmt.Errorf("invalid -format %q: must be text or json", format)
+	}
+
+	var err error
+	if opts.Before, err = parseGraphSource(flags.Arg(0)); err != nil {
+		return diffOptions{}, err
+	}
+	if opts.After, err = parseGraphSource(flags.Arg(1)); err != nil {
+		return diffOptions{}, err
+	}
+	return opts, nil
+}
+
+func runDiff(ctx context.Context, args []string, out io.Writer) error {
+	opts, err := parseDiffOptions(args)
+	if err != nil {
+		return err
+	}
+	var db *gorm.DB
+	if opts.Before.needsDatabase() || opts.After.needsDatabase() {
+		if db, err = connect(); err != nil {
+			return err
+		}
+	}
+
+	before, err := opts.Before.load(ctx, db)
+	if err != nil {
+		return fmt.Errorf("before: %w", err)
+	}
+	after, err := opts.After.load(ctx, db)
+	if err != nil {
+		return fmt.Errorf("after: %w", err)
+	}
+	diff := graphdiff.CompareFiles(before, after)
+
+	if !opts.JSON {
+		_, err = io.WriteString(out, diff.Text())
+		return err
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(diff)
+}
@@ -0,0 +1,225 @@
+// Command arcnem-workflow exports workflows and template versions to canonical
+// YAML or JSON files and imports them back, so workflows can live in git. It
+// also draws workflows and runs as Mermaid or Graphviz diagrams and shows what
+// changed between two versions of a graph.
+//
+//	arcnem-workflow export -workflow <id> [-o workflow.yaml]
+//	arcnem-workflow export -template <id> [-version N] [-o workflow.yaml]
+//	arcnem-workflow import -organization <id> [-workflow <id>] workflow.yaml
+//	arcnem-workflow render (-workflow <id> | -run <id> | -file workflow.yaml) [-trace run.json]
+//	arcnem-workflow diff [-format json] template:<id>@3 template:<id>@4
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/workflowfile"
+	"github.com/arcnem-ai/arcnem-vision/models/db/client"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/env"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const usage = `usage:
+  arcnem-workflow export (-workflow <id> | -template <id> [-version N]) [-o path] [-format yaml|json]
+  arcnem-workflow import -organization <id> [-workflow <id>] <file>
+  arcnem-workflow render (-workflow <id> | -run <id> | -file <path>) [-trace path] [-o path] [-format mermaid|dot]
+  arcnem-workflow diff [-format text|json] <before> <after>
+      graphs are file paths, workflow:<id>, run:<id>, template:<id>, or template:<id>@<version>`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "arcnem-workflow: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args[0] {
+	case "export":
+		return runExport(ctx, args[1:], out)
+	case "import":
+		return runImport(ctx, args[1:], out)
+	case "render":
+		return runRender(ctx, args[1:], out)
+	case "diff":
+		return runDiff(ctx, args[1:], out)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+type exportOptions struct {
+	WorkflowID uuid.UUID
+	TemplateID uuid.UUID
+	Version    int
+	OutputPath string
+	Format     workflowfile.Format
+}
+
+func parseExportOptions(args []string) (exportOptions, error) {
+	var opts exportOptions
+	var workflowID, templateID, format string
+	flags := flag.NewFlagSet("arcnem-workflow export", flag.ContinueOnError)
+	flags.StringVar(&workflowID, "workflow", "", "workflow to export")
+	flags.StringVar(&templateID, "template", "", "template to export")
+	flags.IntVar(&opts.Version, "version", 0, "template version to export (default: the current version)")
+	flags.StringVar(&opts.OutputPath, "o", "", "write to this file instead of stdout")
+	flags.StringVar(&format, "format", "", "yaml or json (default: from -o, else yaml)")
+	if err := flags.Parse(args); err != nil {
+		return exportOptions{}, err
+	}
+	if (workflowID == "") == (templateID == "") {
+		return exportOptions{}, errors.New("pass exactly one of -workflow and -template")
+	}
+	if opts.Version < 0 {
+		return exportOptions{}, fmt.Errorf("invalid -version %d", opts.Version)
+	}
+	if opts.Version > 0 && templateID == "" {
+		return exportOptions{}, errors.New("-version needs -template")
+	}
+
+	var err error
+	if workflowID != "" {
+		if opts.WorkflowID, err = uuid.Parse(workflowID); err != nil {
+			return exportOptions{}, fmt.Errorf("invalid -workflow: %w", err)
+		}
+	}
+	if templateID != "" {
+		if opts.TemplateID, err = uuid.Parse(templateID); err != nil {
+			return exportOptions{}, fmt.Errorf("invalid -template: %w", err)
+		}
+	}
+	switch format {
+	case "":
+		opts.Format = workflowfile.FormatForPath(opts.OutputPath)
+	case string(workflowfile.FormatYAML), string(workflowfile.FormatJSON):
+		opts.Format = workflowfile.Format(format)
+	default:
+		return exportOptions{}, fmt.Errorf("invalid -format %q: must be yaml or json", format)
+	}
+	return opts, nil
+}
+
+func runExport(ctx context.Context, args []string, out io.Writer) error {
+	opts, err := parseExportOptions(args)
+	if err != nil {
+		return err
+	}
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+
+	var file *workflowfile.File
+	if opts.WorkflowID != uuid.Nil {
+		file, err = workflowfile.ExportWorkflow(ctx, db, opts.WorkflowID)
+	} else {
+		file, err = workflowfile.ExportTemplateVersion(ctx, db, opts.TemplateID, opts.Version)
+	}
+	if err != nil {
+		return err
+	}
+	encoded, err := workflowfile.Encode(file, opts.Format)
+	if err != nil {
+		return err
+	}
+
+	if opts.OutputPath == "" {
+		_, err = out.Write(encoded)
+		return err
+	}
+	if err := os.WriteFile(opts.OutputPath, encoded, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.OutputPath, err)
+	}
+	return nil
+}
+
+type importOptions struct {
+	Path   string
+	Import workflowfile.ImportOptions
+}
+
+func parseImportOptions(args []string) (importOptions, error) {
+	var opts importOptions
+	var organizationID, workflowID string
+	flags := flag.NewFlagSet("arcnem-workflow import", flag.ContinueOnError)
+	flags.StringVar(&organizationID, "organization", "", "organization that owns the workflow")
+	flags.StringVar(&workflowID, "workflow", "", "workflow to overwrite (default: the unarchived workflow with the file's name)")
+	if err := flags.Parse(args); err != nil {
+		return importOptions{}, err
+	}
+	if flags.NArg() != 1 || organizationID == "" {
+		return importOptions{}, errors.New("-organization and one file are required")
+	}
+	opts.Path = flags.Arg(0)
+
+	var err error
+	if opts.Import.OrganizationID, err = uuid.Parse(organizationID); err != nil {
+		return importOptions{}, fmt.Errorf("invalid -organization: %w", err)
+	}
+	if workflowID != "" {
+		if opts.Import.WorkflowID, err = uuid.Parse(workflowID); err != nil {
+			return importOptions{}, fmt.Errorf("invalid -workflow: %w", err)
+		}
+	}
+	return opts, nil
+}
+
+func runImport(ctx context.Context, args []string, out io.Writer) error {
+	opts, err := parseImportOptions(args)
+	if err != nil {
+		return err
+	}
+	contents, err := os.ReadFile(opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow file: %w", err)
+	}
+	file, err := workflowfile.Decode(contents, workflowfile.FormatForPath(opts.Path))
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.Path, err)
+	}
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+
+	result, err := workflowfile.ImportWorkflow(ctx, db, file, opts.Import)
+	if err != nil {
+		return err
+	}
+	switch {
+	case result.Created:
+		fmt.Fprintf(out, "created workflow %s\n", result.WorkflowID)
+	case result.Changed:
+		fmt.Fprintf(out, "updated workflow %s\n", result.WorkflowID)
+	default:
+		fmt.Fprintf(out, "workflow %s is up to date\n", result.WorkflowID)
+	}
+	return nil
+}
+
+func connect() (*gorm.DB, error) {
+	// A .env file is optional; DATABASE_URL may come from the environment.
+	if err := env.LoadEnv(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load env: %w", err)
+	}
+	db, err := client.NewPGClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	return db, nil
+}
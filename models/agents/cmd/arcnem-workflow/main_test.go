@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphrender"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/workflowfile"
+	"github.com/google/uuid"
+)
+
+const testID = "0194f3b2-6d7c-7a3e-8c1d-2b9f4e6a1c00"
+
+func TestParseExportOptionsPicksFormatFromOutputPath(t *testing.T) {
+	opts, err := parseExportOptions([]string{"-template", testID, "-version", "3", "-o", "workflow.json"})
+	if err != nil {
+		t.Fatalf("parseExportOptions returned error: %v", err)
+	}
+	if opts.TemplateID.String() != testID || opts.Version != 3 || opts.Format != workflowfile.FormatJSON {
+		t.Fatalf("unexpected options: %#v", opts)
+	}
+
+	opts, err = parseExportOptions([]string{"-workflow", testID})
+	if err != nil {
+		t.Fatalf("parseExportOptions returned error: %v", err)
+	}
+	if opts.WorkflowID.String() != testID || opts.Format != workflowfile.FormatYAML {
+		t.Fatalf("unexpected options: %#v", opts)
+	}
+}
+
+func TestParseExportOptionsRejectsInvalidCombinations(t *testing.T) {
+	cases := map[string][]string{
+		"no source":            {},
+		"both sources":         {"-workflow", testID, "-template", testID},
+		"version for workflow": {"-workflow", testID, "-version", "2"},
+		"bad format":           {"-workflow", testID, "-format", "toml"},
+		"bad id":               {"-workflow", "not-a-uuid"},
+	}
+	for name, args := range cases {
+		if _, err := parseExportOptions(args); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseImportOptions(t *testing.T) {
+	opts, err := parseImportOptions([]string{"-organization", testID, "workflows/describe.yaml"})
+	if err != nil {
+		t.Fatalf("parseImportOptions returned error: %v", err)
+	}
+	if opts.Path != "workflows/describe.yaml" || opts.Import.OrganizationID.String() != testID {
+		t.Fatalf("unexpected options: %#v", opts)
+	}
+
+	if _, err := parseImportOptions([]string{"workflows/describe.yaml"}); err == nil {
+		t.Fatal("expected an error without -organization")
+	}
+}
+
+func TestParseRenderOptions(t *testing.T) {
+	opts, err := parseRenderOptions([]string{"-file", "describe.yaml", "-trace", "run.json", "-o", "describe.gv"})
+	if err != nil {
+		t.Fatalf("parseRenderOptions returned error: %v", err)
+	}
+	if opts.FilePath != "describe.yaml" || opts.TracePath != "run.json" || opts.Format != graphrender.FormatDOT {
+		t.Fatalf("unexpected options: %#v", opts)
+	}
+
+	cases := map[string][]string{
+		"no source":        {},
+		"two sources":      {"-workflow", testID, "-file", "describe.yaml"},
+		"trace over a run": {"-run", testID, "-trace", "run.json"},
+		"bad format":       {"-file", "describe.yaml", "-format", "svg"},
+	}
+	for name, args := range cases {
+		if _, err := parseRenderOptions(args); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadTraceStepsConvertsArcnemRunTraces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	trace := `{"run_id": "run-1", "steps": [
+		{"step_order": 1, "node_key": "describe", "started_at": "2026-10-01T09:00:00Z", "finished_at": "2026-10-01T09:00:01Z", "state_delta": {"description": "A receipt."}},
+		{"step_order": 2, "node_key": "save", "started_at": "2026-10-01T09:00:01Z", "finished_at": "0001-01-01T00:00:00Z", "error_code": "tool_failed"}
+	]}`
+	if err := os.WriteFile(path, []byte(trace), 0o600); err != nil {
+		t.Fatalf("write trace: %v", err)
+	}
+
+	steps, err := readTraceSteps(path)
+	if err != nil {
+		t.Fatalf("readTraceSteps returned error: %v", err)
+	}
+	if len(steps) != 2 || steps[0].NodeKey != "describe" || steps[0].FinishedAt == nil {
+		t.Fatalf("unexpected steps: %#v", steps)
+	}
+	if steps[1].FinishedAt != nil || steps[1].ErrorCode == nil || *steps[1].ErrorCode != "tool_failed" {
+		t.Fatalf("unexpected unfinished step: %#v", steps[1])
+	}
+}
+
+func TestParseGraphSource(t *testing.T) {
+	cases := map[string]graphSource{
+		"workflows/describe.yaml":    {Path: "workflows/describe.yaml"},
+		"C:/workflows/describe.yaml": {Path: "C:/workflows/describe.yaml"},
+		"workflow:" + testID:         {WorkflowID: uuid.MustParse(testID)},
+		"run:" + testID:              {RunID: uuid.MustParse(testID)},
+		"template:" + testID:         {TemplateID: uuid.MustParse(testID)},
+		"template:" + testID + "@12": {TemplateID: uuid.MustParse(testID), Version: 12},
+	}
+	for value, want := range cases {
+		got, err := parseGraphSource(value)
+		if err != nil {
+			t.Fatalf("%s: parseGraphSource returned error: %v", value, err)
+		}
+		if got != want {
+			t.Fatalf("%s: got %#v, want %#v", value, got, want)
+		}
+	}
+
+	for _, value := range []string{"run:not-a-uuid", "template:" + testID + "@0", "template:" + testID + "@latest"} {
+		if _, err := parseGraphSource(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
+
+func TestParseDiffOptions(t *testing.T) {
+	opts, err := parseDiffOptions([]string{"-format", "json", "before.yaml", "run:" + testID})
+	if err != nil {
+		t.Fatalf("parseDiffOptions returned error: %v", err)
+	}
+	if !opts.JSON || opts.Before.Path != "before.yaml" || opts.After.RunID.String() != testID {
+		t.Fatalf("unexpected options: %#v", opts)
+	}
+	if opts.Before.needsDatabase() || !opts.After.needsDatabase() {
+		t.Fatal("expected only the run to need the database")
+	}
+
+	if _, err := parseDiffOptions([]string{"before.yaml"}); err == nil {
+		t.Fatal("expected an error with one graph")
+	}
+	if _, err := parseDiffOptions([]string{"-format", "html", "a.yaml", "b.yaml"}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
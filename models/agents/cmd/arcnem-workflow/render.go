@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphrender"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/load"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/workflowfile"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type renderOptions struct {
+	WorkflowID uuid.UUID
+	RunID      uuid.UUID
+	FilePath   string
+	TracePath  string
+	OutputPath string
+	Format     graphrender.Format
+}
+
+func parseRenderOptions(args []string) (renderOptions, error) {
+	var opts renderOptions
+	var workflowID, runID, format string
+	flags := flag.NewFlagSet("arcnem-workflow render", flag.ContinueOnError)
+	flags.StringVar(&workflowID, "workflow", "", "workflow to draw")
+	flags.StringVar(&runID, "run", "", "run to draw, over the graph it ran")
+	flags.StringVar(&opts.FilePath, "file", "", "workflow file to draw")
+	flags.StringVar(&opts.TracePath, "trace", "", "arcnem-run trace file to draw over the graph")
+	flags.StringVar(&opts.OutputPath, "o", "", "write to this file instead of stdout")
+	flags.StringVar(&format, "format", "", "mermaid or dot (default: from -o, else mermaid)")
+	if err := flags.Parse(args); err != nil {
+		return renderOptions{}, err
+	}
+
+	sources := 0
+	for _, source := range []string{workflowID, runID, opts.FilePath} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return renderOptions{}, errors.New("pass exactly one of -workflow, -run, and -file")
+	}
+	if runID != "" && opts.TracePath != "" {
+		return renderOptions{}, errors.New("-trace cannot be combined with -run, which draws the run's own steps")
+	}
+
+	var err error
+	if workflowID != "" {
+		if opts.WorkflowID, err = uuid.Parse(workflowID); err != nil {
+			return renderOptions{}, fmt.Errorf("invalid -workflow: %w", err)
+		}
+	}
+	if runID != "" {
+		if opts.RunID, err = uuid.Parse(runID); err != nil {
+			return renderOptions{}, fmt.Errorf("invalid -run: %w", err)
+		}
+	}
+	switch format {
+	case "":
+		opts.Format = renderFormatForPath(opts.OutputPath)
+	case string(graphrender.FormatMermaid), string(graphrender.FormatDOT):
+		opts.Format = graphrender.Format(format)
+	default:
+		return renderOptions{}, fmt.Errorf("invalid -format %q: must be mermaid or dot", format)
+	}
+	return opts, nil
+}
+
+func renderFormatForPath(path string) graphrender.Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return graphrender.FormatDOT
+	default:
+		return graphrender.FormatMermaid
+	}
+}
+
+func runRender(ctx context.Context, args []string, out io.Writer) error {
+	opts, err := parseRenderOptions(args)
+	if err != nil {
+		return err
+	}
+
+	var snapshot *graphs.Snapshot
+	var steps []*dbmodels.AgentGraphRunStep
+	if opts.FilePath != "" {
+		if snapshot, err = readWorkflowFileSnapshot(opts.FilePath); err != nil {
+			return err
+		}
+	} else {
+		db, err := connect()
+		if err != nil {
+			return err
+		}
+		if opts.RunID != uuid.Nil {
+			snapshot, steps, err = loadRun(ctx, db, opts.RunID)
+		} else {
+			snapshot, err = load.LoadAgentGraphSnapshot(ctx, db, opts.WorkflowID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if opts.TracePath != "" {
+		if steps, err = readTraceSteps(opts.TracePath); err != nil {
+			return err
+		}
+	}
+
+	rendered, err := graphrender.Render(snapshot, opts.Format, graphrender.Options{Steps: steps})
+	if err != nil {
+		return err
+	}
+	if opts.OutputPath == "" {
+		_, err = io.WriteString(out, rendered)
+		return err
+	}
+	if err := os.WriteFile(opts.OutputPath, []byte(rendered), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.OutputPath, err)
+	}
+	return nil
+}
+
+func readWorkflowFileSnapshot(path string) (*graphs.Snapshot, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow file: %w", err)
+	}
+	file, err := workflowfile.Decode(contents, workflowfile.FormatForPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file.Snapshot()
+}
+
+// loadRun loads the graph a run was pinned to and its steps. Runs created
+// before graphs were pinned are drawn over their workflow's current graph.
+func loadRun(ctx context.Context, db *gorm.DB, runID uuid.UUID) (*graphs.Snapshot, []*dbmodels.AgentGraphRunStep, error) {
+	var run dbmodels.AgentGraphRun
+	if err := db.WithContext(ctx).Where("id = ?", runID).Take(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("run %s not found", runID)
+		}
+		return nil, nil, fmt.Errorf("failed to load run %s: %w", runID, runerrors.Database(err))
+	}
+
+	var snapshot *graphs.Snapshot
+	if run.GraphSnapshot != nil {
+		if err := json.Unmarshal([]byte(*run.GraphSnapshot), &snapshot); err != nil {
+			return nil, nil, fmt.Errorf("invalid graph snapshot on run %s: %w", runID, err)
+		}
+	} else {
+		agentGraphID, err := uuid.Parse(run.AgentGraphID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid run workflow id %q: %w", run.AgentGraphID, err)
+		}
+		if snapshot, err = load.LoadAgentGraphSnapshot(ctx, db, agentGraphID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var steps []*dbmodels.AgentGraphRunStep
+	if err := db.WithContext(ctx).
+		Where("run_id = ?", runID).
+		Order("step_order").
+		Find(&steps).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load run steps: %w", runerrors.Database(err))
+	}
+	return snapshot, steps, nil
+}
+
+// traceFile is the part of an arcnem-run trace file the overlay needs.
+type traceFile struct {
+	Steps []struct {
+		StepOrder  int32     `json:"step_order"`
+		NodeKey    string    `json:"node_key"`
+		StartedAt  time.Time `json:"started_at"`
+		FinishedAt time.Time `json:"finished_at"`
+		ErrorCode  *string   `json:"error_code"`
+	} `json:"steps"`
+}
+
+func readTraceSteps(path string) ([]*dbmodels.AgentGraphRunStep, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace file: %w", err)
+	}
+	var trace traceFile
+	if err := json.Unmarshal(contents, &trace); err != nil {
+		return nil, fmt.Errorf("failed to decode trace file %s: %w", path, err)
+	}
+	steps := make([]*dbmodels.AgentGraphRunStep, 0, len(trace.Steps))
+	for _, step := range trace.Steps {
+		runStep := &dbmodels.AgentGraphRunStep{
+			NodeKey:   step.NodeKey,
+			StepOrder: step.StepOrder,
+			StartedAt: step.StartedAt,
+			ErrorCode: step.ErrorCode,
+		}
+		if !step.FinishedAt.IsZero() {
+			finishedAt := step.FinishedAt
+			runStep.FinishedAt = &finishedAt
+		}
+		steps = append(steps, runStep)
+	}
+	return steps, nil
+}
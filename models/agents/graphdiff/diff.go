@@ -0,0 +1,257 @@
+// Package graphdiff compares two versions of a workflow graph. Nodes are
+// matched by node key and models and tools by name, so the diff shows what
+// changed in the workflow rather than which rows were rewritten.
+package graphdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/workflowfile"
+)
+
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Diff lists the changes from one graph to another. An empty Diff means the
+// graphs behave the same; node positions in the editor are not compared.
+type Diff struct {
+	// Workflow holds changes to the name, description, and entry node.
+	Workflow    []FieldChange `json:"workflow,omitempty"`
+	StateSchema []FieldChange `json:"state_schema,omitempty"`
+	Nodes       []NodeChange  `json:"nodes,omitempty"`
+	Edges       []EdgeChange  `json:"edges,omitempty"`
+}
+
+// FieldChange is a changed value. Path names it within its parent, with
+// nested config and schema keys joined by dots, such as
+// "config.output_schema.required".
+type FieldChange struct {
+	Path   string     `json:"path"`
+	Kind   ChangeKind `json:"kind"`
+	Before any        `json:"before,omitempty"`
+	After  any        `json:"after,omitempty"`
+	// TextDiff is a line diff of a changed multi-line string, such as a
+	// prompt. Lines start with "  ", "- ", or "+ ".
+	TextDiff []string `json:"text_diff,omitempty"`
+}
+
+type NodeChange struct {
+	Key  string     `json:"key"`
+	Kind ChangeKind `json:"kind"`
+	// Type is the node type, after the change for modified nodes.
+	Type         string        `json:"type"`
+	Fields       []FieldChange `json:"fields,omitempty"`
+	ToolsAdded   []string      `json:"tools_added,omitempty"`
+	ToolsRemoved []string      `json:"tools_removed,omitempty"`
+}
+
+type EdgeChange struct {
+	From string     `json:"from"`
+	To   string     `json:"to"`
+	Kind ChangeKind `json:"kind"`
+}
+
+// Empty reports whether there are no changes.
+func (d *Diff) Empty() bool {
+	return len(d.Workflow) == 0 && len(d.StateSchema) == 0 && len(d.Nodes) == 0 && len(d.Edges) == 0
+}
+
+// Compare diffs two graph snapshots, such as the graphs pinned by two runs.
+func Compare(before, after *graphs.Snapshot) (*Diff, error) {
+	beforeFile, err := workflowfile.FromSnapshot(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %w", err)
+	}
+	afterFile, err := workflowfile.FromSnapshot(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %w", err)
+	}
+	return CompareFiles(beforeFile, afterFile), nil
+}
+
+// CompareFiles diffs two workflow files.
+func CompareFiles(before, after *workflowfile.File) *Diff {
+	diff := &Diff{}
+	diff.Workflow = append(diff.Workflow, compareValue("name", before.Name, after.Name)...)
+	diff.Workflow = append(diff.Workflow, compareValue("description", before.Description, after.Description)...)
+	diff.Workflow = append(diff.Workflow, compareValue("entry_node", before.EntryNode, after.EntryNode)...)
+	diff.StateSchema = compareObjects("", before.StateSchema, after.StateSchema)
+
+	beforeNodes := nodesByKey(before.Nodes)
+	afterNodes := nodesByKey(after.Nodes)
+	for _, key := range unionKeys(beforeNodes, afterNodes, strings.Compare) {
+		beforeNode, inBefore := beforeNodes[key]
+		afterNode, inAfter := afterNodes[key]
+		switch {
+		case !inBefore:
+			diff.Nodes = append(diff.Nodes, NodeChange{Key: key, Kind: Added, Type: afterNode.Type})
+		case !inAfter:
+			diff.Nodes = append(diff.Nodes, NodeChange{Key: key, Kind: Removed, Type: beforeNode.Type})
+		default:
+			if change := compareNodes(beforeNode, afterNode); change != nil {
+				diff.Nodes = append(diff.Nodes, *change)
+			}
+		}
+	}
+
+	beforeEdges := edgeSet(before.Edges)
+	afterEdges := edgeSet(after.Edges)
+	for _, edge := range unionKeys(beforeEdges, afterEdges, compareEdges) {
+		_, inBefore := beforeEdges[edge]
+		_, inAfter := afterEdges[edge]
+		switch {
+		case !inBefore:
+			diff.Edges = append(diff.Edges, EdgeChange{From: edge.From, To: edge.To, Kind: Added})
+		case !inAfter:
+			diff.Edges = append(diff.Edges, EdgeChange{From: edge.From, To: edge.To, Kind: Removed})
+		}
+	}
+	return diff
+}
+
+func compareNodes(before, after workflowfile.Node) *NodeChange {
+	change := &NodeChange{Key: after.Key, Kind: Modified, Type: after.Type}
+	change.Fields = append(change.Fields, compareValue("type", before.Type, after.Type)...)
+	change.Fields = append(change.Fields, compareValue("input_key", before.InputKey, after.InputKey)...)
+	change.Fields = append(change.Fields, compareValue("output_key", before.OutputKey, after.OutputKey)...)
+	change.Fields = append(change.Fields, compareValue("model", modelName(before.Model), modelName(after.Model))...)
+	change.Fields = append(change.Fields, compareObjects("config", before.Config, after.Config)...)
+
+	for _, tool := range after.Tools {
+		if !slices.Contains(before.Tools, tool) {
+			change.ToolsAdded = append(change.ToolsAdded, tool)
+		}
+	}
+	for _, tool := range before.Tools {
+		if !slices.Contains(after.Tools, tool) {
+			change.ToolsRemoved = append(change.ToolsRemoved, tool)
+		}
+	}
+	slices.Sort(change.ToolsAdded)
+	slices.Sort(change.ToolsRemoved)
+
+	if len(change.Fields) == 0 && len(change.ToolsAdded) == 0 && len(change.ToolsRemoved) == 0 {
+		return nil
+	}
+	return change
+}
+
+// compareObjects diffs two JSON objects key by key, descending into nested
+// objects. Arrays and other values are compared whole.
+func compareObjects(prefix string, before, after map[string]any) []FieldChange {
+	var changes []FieldChange
+	for _, key := range unionKeys(before, after, strings.Compare) {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		beforeValue, inBefore := before[key]
+		afterValue, inAfter := after[key]
+		switch {
+		case !inBefore:
+			changes = append(changes, FieldChange{Path: path, Kind: Added, After: afterValue})
+		case !inAfter:
+			changes = append(changes, FieldChange{Path: path, Kind: Removed, Before: beforeValue})
+		default:
+			beforeObject, beforeIsObject := beforeValue.(map[string]any)
+			afterObject, afterIsObject := afterValue.(map[string]any)
+			if beforeIsObject && afterIsObject {
+				changes = append(changes, compareObjects(path, beforeObject, afterObject)...)
+				continue
+			}
+			changes = append(changes, compareValue(path, beforeValue, afterValue)...)
+		}
+	}
+	return changes
+}
+
+// compareValue reports a change between two values, treating empty strings
+// and nil as absent.
+func compareValue(path string, before, after any) []FieldChange {
+	if sameJSON(before, after) {
+		return nil
+	}
+	change := FieldChange{Path: path, Kind: Modified, Before: before, After: after}
+	switch {
+	case isAbsent(before):
+		change = FieldChange{Path: path, Kind: Added, After: after}
+	case isAbsent(after):
+		change = FieldChange{Path: path, Kind: Removed, Before: before}
+	}
+	beforeText, beforeIsText := before.(string)
+	afterText, afterIsText := after.(string)
+	if beforeIsText && afterIsText && (strings.Contains(beforeText, "\n") || strings.Contains(afterText, "\n")) {
+		change.TextDiff = lineDiff(beforeText, afterText)
+	}
+	return []FieldChange{change}
+}
+
+func isAbsent(value any) bool {
+	return value == nil || value == ""
+}
+
+// sameJSON compares values by their JSON encoding, since decoded YAML and
+// JSON hold numbers as different Go types.
+func sameJSON(a, b any) bool {
+	if isAbsent(a) && isAbsent(b) {
+		return true
+	}
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+func modelName(model *workflowfile.ModelRef) string {
+	if model == nil {
+		return ""
+	}
+	return model.String()
+}
+
+func nodesByKey(nodes []workflowfile.Node) map[string]workflowfile.Node {
+	byKey := make(map[string]workflowfile.Node, len(nodes))
+	for _, node := range nodes {
+		byKey[node.Key] = node
+	}
+	return byKey
+}
+
+func edgeSet(edges []workflowfile.Edge) map[workflowfile.Edge]struct{} {
+	set := make(map[workflowfile.Edge]struct{}, len(edges))
+	for _, edge := range edges {
+		set[edge] = struct{}{}
+	}
+	return set
+}
+
+// unionKeys returns the keys of both maps, sorted by compare.
+func unionKeys[K comparable, A, B any](a map[K]A, b map[K]B, compare func(K, K) int) []K {
+	keys := make([]K, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, compare)
+	return keys
+}
+
+func compareEdges(a, b workflowfile.Edge) int {
+	if a.From != b.From {
+		return strings.Compare(a.From, b.From)
+	}
+	return strings.Compare(a.To, b.To)
+}
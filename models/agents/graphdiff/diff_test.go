@@ -0,0 +1,177 @@
+package graphdiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/workflowfile"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func beforeFile() *workflowfile.File {
+	return &workflowfile.File{
+		Version:     workflowfile.FormatVersion,
+		Name:        "Receipts",
+		EntryNode:   "describe",
+		StateSchema: map[string]any{"type": "object", "properties": map[string]any{"total": map[string]any{"type": "number"}}},
+		Nodes: []workflowfile.Node{
+			{
+				Key:      "describe",
+				Type:     "worker",
+				InputKey: "temp_url",
+				Model:    &workflowfile.ModelRef{Provider: "OPENAI", Name: "gpt-4.1-mini"},
+				Config:   map[string]any{"system_message": "Describe the image.\nMention the total.", "temperature": 0.2},
+				Position: &workflowfile.Position{X: 40, Y: 80},
+			},
+			{Key: "save", Type: "tool", Tools: []string{"create_embedding", "save_description"}},
+			{Key: "legacy", Type: "tool", Tools: []string{"save_description"}},
+		},
+		Edges: []workflowfile.Edge{{From: "describe", To: "save"}, {From: "save", To: "END"}, {From: "legacy", To: "END"}},
+	}
+}
+
+func afterFile() *workflowfile.File {
+	return &workflowfile.File{
+		Version:     workflowfile.FormatVersion,
+		Name:        "Receipts",
+		EntryNode:   "describe",
+		StateSchema: map[string]any{"type": "object", "properties": map[string]any{"total": map[string]any{"type": "string"}}},
+		Nodes: []workflowfile.Node{
+			{Key: "save", Type: "tool", Tools: []string{"save_description", "save_ocr_result"}},
+			{
+				Key:      "describe",
+				Type:     "worker",
+				InputKey: "temp_url",
+				Model:    &workflowfile.ModelRef{Provider: "OPENAI", Name: "gpt-4.1"},
+				Config:   map[string]any{"system_message": "Describe the receipt.\nMention the total.", "temperature": 0.2},
+				Position: &workflowfile.Position{X: 400, Y: 80},
+			},
+			{Key: "classify", Type: "condition"},
+		},
+		Edges: []workflowfile.Edge{{From: "describe", To: "save"}, {From: "save", To: "END"}, {From: "classify", To: "END"}},
+	}
+}
+
+func TestCompareFilesKeysChangesByNode(t *testing.T) {
+	diff := CompareFiles(beforeFile(), afterFile())
+
+	if len(diff.Workflow) != 0 {
+		t.Fatalf("expected no workflow changes, got %#v", diff.Workflow)
+	}
+	wantSchema := []FieldChange{{Path: "properties.total.type", Kind: Modified, Before: "number", After: "string"}}
+	if !reflect.DeepEqual(diff.StateSchema, wantSchema) {
+		t.Fatalf("unexpected state schema changes: %#v", diff.StateSchema)
+	}
+
+	if len(diff.Nodes) != 4 {
+		t.Fatalf("expected four node changes, got %#v", diff.Nodes)
+	}
+	classify, describe, legacy, save := diff.Nodes[0], diff.Nodes[1], diff.Nodes[2], diff.Nodes[3]
+	if classify.Key != "classify" || classify.Kind != Added || classify.Type != "condition" {
+		t.Fatalf("unexpected added node: %#v", classify)
+	}
+	if legacy.Key != "legacy" || legacy.Kind != Removed {
+		t.Fatalf("unexpected removed node: %#v", legacy)
+	}
+
+	if describe.Kind != Modified || len(describe.Fields) != 2 {
+		t.Fatalf("expected model and prompt changes only, got %#v", describe.Fields)
+	}
+	if model := describe.Fields[0]; model.Path != "model" || model.Before != "OPENAI/gpt-4.1-mini" || model.After != "OPENAI/gpt-4.1" {
+		t.Fatalf("unexpected model change: %#v", model)
+	}
+	prompt := describe.Fields[1]
+	wantText := []string{"- Describe the image.", "+ Describe the receipt.", "  Mention the total."}
+	if prompt.Path != "config.system_message" || !reflect.DeepEqual(prompt.TextDiff, wantText) {
+		t.Fatalf("unexpected prompt change: %#v", prompt)
+	}
+
+	if !reflect.DeepEqual(save.ToolsAdded, []string{"save_ocr_result"}) || !reflect.DeepEqual(save.ToolsRemoved, []string{"create_embedding"}) {
+		t.Fatalf("unexpected tool changes: %#v", save)
+	}
+
+	wantEdges := []EdgeChange{{From: "classify", To: "END", Kind: Added}, {From: "legacy", To: "END", Kind: Removed}}
+	if !reflect.DeepEqual(diff.Edges, wantEdges) {
+		t.Fatalf("unexpected edge changes: %#v", diff.Edges)
+	}
+}
+
+func TestCompareFilesIgnoresOrderAndNumberTypes(t *testing.T) {
+	before := beforeFile()
+	after := beforeFile()
+	after.Nodes[0], after.Nodes[2] = after.Nodes[2], after.Nodes[0]
+	after.Nodes[2].Config["temperature"] = float32(0.2)
+	after.Nodes[2].Position = nil
+	after.StateSchema["properties"] = map[string]any{"total": map[string]any{"type": "number"}}
+
+	if diff := CompareFiles(before, after); !diff.Empty() {
+		t.Fatalf("expected no changes, got %s", diff.Text())
+	}
+}
+
+func TestCompareReadsSnapshotsWithoutIDs(t *testing.T) {
+	snapshot := func(nodeID, modelID string) *graphs.Snapshot {
+		return &graphs.Snapshot{
+			AgentGraph: &dbmodels.AgentGraph{ID: "graph-" + nodeID, Name: "Describe", EntryNode: "describe"},
+			Nodes: []*graphs.SnapshotNode{{
+				Node:  &dbmodels.AgentGraphNode{ID: nodeID, NodeKey: "describe", NodeType: "worker", Config: `{"system_message":"Describe."}`, ModelID: stringPtr(modelID)},
+				Model: &dbmodels.Model{ID: modelID, Provider: "OPENAI", Name: "gpt-4.1-mini"},
+			}},
+			Edges: []*dbmodels.AgentGraphEdge{{ID: "edge-" + nodeID, FromNode: "describe", ToNode: "END"}},
+		}
+	}
+
+	diff, err := Compare(snapshot("node-1", "model-1"), snapshot("node-2", "model-2"))
+	if err != nil {
+		t.Fatalf("Compare returned error: %v", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected snapshots that differ only in IDs to match, got %s", diff.Text())
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	text := CompareFiles(beforeFile(), afterFile()).Text()
+
+	for _, fragment := range []string{
+		"state_schema:\n  ~ properties.total.type: number → string\n",
+		"  + classify (condition)\n",
+		"  ~ describe (worker)\n      ~ model: OPENAI/gpt-4.1-mini → OPENAI/gpt-4.1\n      ~ config.system_message:\n          - Describe the image.\n          + Describe the receipt.\n            Mention the total.\n",
+		"  - legacy (tool)\n",
+		"      + tool save_ocr_result\n      - tool create_embedding\n",
+		"edges:\n  + classify → END\n  - legacy → END\n",
+	} {
+		if !strings.Contains(text, fragment) {
+			t.Errorf("expected text to contain %q:\n%s", fragment, text)
+		}
+	}
+	if (&Diff{}).Text() != "no changes\n" {
+		t.Fatal("expected an empty diff to say so")
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	encoded, err := json.Marshal(CompareFiles(beforeFile(), afterFile()))
+	if err != nil {
+		t.Fatalf("marshal diff: %v", err)
+	}
+	for _, fragment := range []string{
+		`"nodes":[{"key":"classify","kind":"added","type":"condition"}`,
+		`{"path":"model","kind":"modified","before":"OPENAI/gpt-4.1-mini","after":"OPENAI/gpt-4.1"}`,
+		`"tools_added":["save_ocr_result"],"tools_removed":["create_embedding"]`,
+	} {
+		if !strings.Contains(string(encoded), fragment) {
+			t.Errorf("expected JSON to contain %s:\n%s", fragment, encoded)
+		}
+	}
+	if empty, _ := json.Marshal(&Diff{}); string(empty) != "{}" {
+		t.Fatalf("expected an empty diff to encode as {}, got %s", empty)
+	}
+}
@@ -0,0 +1,140 @@
+package graphdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Text formats the diff for people, one change per line:
+//
+//	nodes:
+//	  ~ describe (worker)
+//	      ~ model: OPENAI/gpt-4.1-mini → OPENAI/gpt-4.1
+//	      + tool save_description
+//	edges:
+//	  - describe → END
+func (d *Diff) Text() string {
+	if d.Empty() {
+		return "no changes\n"
+	}
+	var b strings.Builder
+	if len(d.Workflow) > 0 {
+		b.WriteString("workflow:\n")
+		writeFields(&b, "  ", d.Workflow)
+	}
+	if len(d.StateSchema) > 0 {
+		b.WriteString("state_schema:\n")
+		writeFields(&b, "  ", d.StateSchema)
+	}
+	if len(d.Nodes) > 0 {
+		b.WriteString("nodes:\n")
+		for _, node := range d.Nodes {
+			fmt.Fprintf(&b, "  %s %s (%s)\n", kindSymbol(node.Kind), node.Key, node.Type)
+			writeFields(&b, "      ", node.Fields)
+			for _, tool := range node.ToolsAdded {
+				fmt.Fprintf(&b, "      + tool %s\n", tool)
+			}
+			for _, tool := range node.ToolsRemoved {
+				fmt.Fprintf(&b, "      - tool %s\n", tool)
+			}
+		}
+	}
+	if len(d.Edges) > 0 {
+		b.WriteString("edges:\n")
+		for _, edge := range d.Edges {
+			fmt.Fprintf(&b, "  %s %s → %s\n", kindSymbol(edge.Kind), edge.From, edge.To)
+		}
+	}
+	return b.String()
+}
+
+func writeFields(b *strings.Builder, indent string, fields []FieldChange) {
+	for _, field := range fields {
+		switch {
+		case len(field.TextDiff) > 0:
+			fmt.Fprintf(b, "%s%s %s:\n", indent, kindSymbol(field.Kind), field.Path)
+			for _, line := range field.TextDiff {
+				fmt.Fprintf(b, "%s    %s\n", indent, line)
+			}
+		case field.Kind == Added:
+			fmt.Fprintf(b, "%s+ %s: %s\n", indent, field.Path, formatValue(field.After))
+		case field.Kind == Removed:
+			fmt.Fprintf(b, "%s- %s: %s\n", indent, field.Path, formatValue(field.Before))
+		default:
+			fmt.Fprintf(b, "%s~ %s: %s → %s\n", indent, field.Path, formatValue(field.Before), formatValue(field.After))
+		}
+	}
+}
+
+func kindSymbol(kind ChangeKind) string {
+	switch kind {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// formatValue writes strings as they are and other values as JSON.
+func formatValue(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// lineDiff returns a line diff of two texts from their longest common
+// subsequence of lines.
+func lineDiff(before, after string) []string {
+	// Prompts written as YAML block scalars end in a newline, which would
+	// otherwise show up as an empty line.
+	a := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+
+	// common[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+ "+b[j])
+	}
+	return lines
+}
@@ -0,0 +1,92 @@
+package graphrender
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+)
+
+const (
+	executedColor = "#2e7d32"
+	failedColor   = "#c62828"
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// DOT draws snapshot as a Graphviz digraph.
+func DOT(snapshot *graphs.Snapshot, options Options) (string, error) {
+	d, err := newDiagram(snapshot, options)
+	if err != nil {
+		return "", err
+	}
+	ids := d.ids()
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph \"%s\" {\n", dotEscaper.Replace(d.title))
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\"];\n")
+
+	fmt.Fprintf(&b, "  %s [label=\"%s\", shape=circle];\n", ids[startNode], startNode)
+	for _, node := range d.nodes {
+		attributes := []string{fmt.Sprintf("label=\"%s\"", dotLabel(node.lines()))}
+		if shape := dotShape(node.nodeType); shape != "" {
+			attributes = append(attributes, "shape="+shape)
+		}
+		switch {
+		case node.failed != "":
+			attributes = append(attributes, fmt.Sprintf("color=\"%s\"", failedColor), "penwidth=3")
+		case node.executed():
+			attributes = append(attributes, fmt.Sprintf("color=\"%s\"", executedColor), "penwidth=3")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", ids[node.key], strings.Join(attributes, ", "))
+	}
+	for _, key := range d.extraTargets() {
+		shape := "box"
+		if key == endNode {
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(&b, "  %s [label=\"%s\", shape=%s];\n", ids[key], dotLabel([]string{key}), shape)
+	}
+
+	for _, route := range d.routes {
+		var attributes []string
+		if label := route.label(); label != "" {
+			attributes = append(attributes, fmt.Sprintf("label=\"%s\"", label))
+		}
+		if route.dashed() {
+			attributes = append(attributes, "style=dashed")
+		}
+		if route.executed {
+			attributes = append(attributes, fmt.Sprintf("color=\"%s\"", executedColor), "penwidth=3")
+		}
+		if len(attributes) == 0 {
+			fmt.Fprintf(&b, "  %s -> %s;\n", ids[route.from], ids[route.to])
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", ids[route.from], ids[route.to], strings.Join(attributes, ", "))
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+func dotShape(nodeType string) string {
+	switch nodeType {
+	case "tool":
+		return "component"
+	case "supervisor":
+		return "hexagon"
+	case "condition":
+		return "diamond"
+	default:
+		return ""
+	}
+}
+
+func dotLabel(lines []string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = dotEscaper.Replace(line)
+	}
+	return strings.Join(escaped, `\n`)
+}
@@ -0,0 +1,98 @@
+package graphrender
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+)
+
+const (
+	executedStyle = "stroke:#2e7d32,stroke-width:3px"
+	failedStyle   = "stroke:#c62828,stroke-width:3px"
+)
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+// Mermaid draws snapshot as a Mermaid flowchart.
+func Mermaid(snapshot *graphs.Snapshot, options Options) (string, error) {
+	d, err := newDiagram(snapshot, options)
+	if err != nil {
+		return "", err
+	}
+	ids := d.ids()
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	fmt.Fprintf(&b, "  %%%% %s\n", d.title)
+
+	fmt.Fprintf(&b, "  %s((\"%s\"))\n", ids[startNode], startNode)
+	var executed, failed []string
+	for _, node := range d.nodes {
+		openShape, closeShape := mermaidShape(node.nodeType)
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", ids[node.key], openShape, mermaidLabel(node.lines()), closeShape)
+		switch {
+		case node.failed != "":
+			failed = append(failed, ids[node.key])
+		case node.executed():
+			executed = append(executed, ids[node.key])
+		}
+	}
+	for _, key := range d.extraTargets() {
+		shape := "[\"%s\"]"
+		if key == endNode {
+			shape = "((\"%s\"))"
+		}
+		fmt.Fprintf(&b, "  %s"+shape+"\n", ids[key], mermaidLabel([]string{key}))
+	}
+
+	var executedLinks []string
+	for index, route := range d.routes {
+		arrow := "-->"
+		if route.dashed() {
+			arrow = "-.->"
+		}
+		if label := route.label(); label != "" {
+			arrow += "|" + label + "|"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[route.from], arrow, ids[route.to])
+		if route.executed {
+			executedLinks = append(executedLinks, fmt.Sprint(index))
+		}
+	}
+
+	if len(executed) > 0 || len(failed) > 0 {
+		fmt.Fprintf(&b, "  classDef executed %s\n", executedStyle)
+		fmt.Fprintf(&b, "  classDef failed %s\n", failedStyle)
+	}
+	if len(executed) > 0 {
+		fmt.Fprintf(&b, "  class %s executed\n", strings.Join(executed, ","))
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(&b, "  class %s failed\n", strings.Join(failed, ","))
+	}
+	if len(executedLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s %s\n", strings.Join(executedLinks, ","), executedStyle)
+	}
+	return b.String(), nil
+}
+
+func mermaidShape(nodeType string) (string, string) {
+	switch nodeType {
+	case "tool":
+		return "[[", "]]"
+	case "supervisor":
+		return "{{", "}}"
+	case "condition":
+		return "{", "}"
+	default:
+		return "[", "]"
+	}
+}
+
+func mermaidLabel(lines []string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = mermaidEscaper.Replace(line)
+	}
+	return strings.Join(escaped, "<br/>")
+}
@@ -0,0 +1,267 @@
+// Package graphrender draws graph snapshots as Mermaid or Graphviz DOT, so a
+// workflow can be shown in a pull request or incident review. A run's steps
+// can be laid over the graph to show the path it took.
+package graphrender
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+type Format string
+
+const (
+	FormatMermaid Format = "mermaid"
+	FormatDOT     Format = "dot"
+)
+
+const (
+	startNode = "START"
+	endNode   = "END"
+)
+
+type Options struct {
+	// Steps are a run's agent_graph_run_steps. When set, the nodes and routes
+	// the run took are highlighted and nodes are annotated with their step
+	// numbers and durations.
+	Steps []*dbmodels.AgentGraphRunStep
+}
+
+// Render draws snapshot in format.
+func Render(snapshot *graphs.Snapshot, format Format, options Options) (string, error) {
+	switch format {
+	case FormatMermaid:
+		return Mermaid(snapshot, options)
+	case FormatDOT:
+		return DOT(snapshot, options)
+	default:
+		return "", fmt.Errorf("unknown graph format %q", format)
+	}
+}
+
+// diagram is a snapshot laid out for drawing, independent of the output
+// format.
+type diagram struct {
+	title  string
+	nodes  []*diagramNode
+	routes []*diagramRoute
+}
+
+type diagramNode struct {
+	key      string
+	nodeType string
+	details  []string
+	// Overlay fields, set from the run's steps.
+	steps    []int32
+	duration time.Duration
+	failed   string
+}
+
+func (n *diagramNode) executed() bool {
+	return len(n.steps) > 0
+}
+
+type diagramRoute struct {
+	from     string
+	to       string
+	kind     graphs.RouteKind
+	executed bool
+}
+
+func (r *diagramRoute) label() string {
+	switch r.kind {
+	case graphs.RouteConditionTrue, graphs.RouteConditionFalse, graphs.RouteSupervisorFinish, graphs.RouteSupervisorLoop:
+		return string(r.kind)
+	}
+	return ""
+}
+
+// dashed reports whether the route is taken by a supervisor's choice rather
+// than always.
+func (r *diagramRoute) dashed() bool {
+	switch r.kind {
+	case graphs.RouteSupervisorMember, graphs.RouteSupervisorReturn, graphs.RouteSupervisorLoop:
+		return true
+	}
+	return false
+}
+
+func newDiagram(snapshot *graphs.Snapshot, options Options) (*diagram, error) {
+	if snapshot == nil || snapshot.AgentGraph == nil {
+		return nil, fmt.Errorf("graph snapshot is missing its agent graph")
+	}
+	d := &diagram{title: snapshot.AgentGraph.Name}
+	byKey := make(map[string]*diagramNode, len(snapshot.Nodes))
+	for _, snapshotNode := range snapshot.Nodes {
+		if snapshotNode == nil || snapshotNode.Node == nil {
+			continue
+		}
+		node := &diagramNode{
+			key:      snapshotNode.Node.NodeKey,
+			nodeType: strings.ToLower(strings.TrimSpace(snapshotNode.Node.NodeType)),
+			details:  nodeDetails(snapshotNode),
+		}
+		d.nodes = append(d.nodes, node)
+		byKey[node.key] = node
+	}
+
+	d.routes = append(d.routes, &diagramRoute{from: startNode, to: snapshot.AgentGraph.EntryNode, kind: graphs.RouteEdge})
+	for _, route := range graphs.Routes(snapshot) {
+		d.routes = append(d.routes, &diagramRoute{from: route.From, to: route.To, kind: route.Kind})
+	}
+
+	if len(options.Steps) > 0 {
+		d.overlay(options.Steps, byKey)
+	}
+	return d, nil
+}
+
+// ids gives every node and route endpoint an identifier that is safe in
+// any output format, since node keys may hold any text.
+func (d *diagram) ids() map[string]string {
+	ids := map[string]string{startNode: "graph_start", endNode: "graph_end"}
+	for index, node := range d.nodes {
+		ids[node.key] = fmt.Sprintf("n%d", index)
+	}
+	for index, key := range d.extraTargets() {
+		if _, ok := ids[key]; !ok {
+			ids[key] = fmt.Sprintf("x%d", index)
+		}
+	}
+	return ids
+}
+
+// extraTargets are route endpoints that are not nodes: END, and any target
+// missing from the snapshot.
+func (d *diagram) extraTargets() []string {
+	known := make(map[string]struct{}, len(d.nodes)+1)
+	known[startNode] = struct{}{}
+	for _, node := range d.nodes {
+		known[node.key] = struct{}{}
+	}
+	var targets []string
+	for _, route := range d.routes {
+		for _, key := range []string{route.from, route.to} {
+			if _, ok := known[key]; !ok {
+				known[key] = struct{}{}
+				targets = append(targets, key)
+			}
+		}
+	}
+	slices.Sort(targets)
+	return targets
+}
+
+// nodeDetails are the lines drawn under a node's key.
+func nodeDetails(snapshotNode *graphs.SnapshotNode) []string {
+	node := snapshotNode.Node
+	var details []string
+	kind := node.NodeType
+	if model := snapshotNode.Model; model != nil {
+		modelName := model.Provider + "/" + model.Name
+		if model.Version != "" {
+			modelName += "@" + model.Version
+		}
+		kind += " · " + modelName
+	}
+	details = append(details, kind)
+
+	if node.InputKey != nil || node.OutputKey != nil {
+		details = append(details, derefOr(node.InputKey, "-")+" → "+derefOr(node.OutputKey, "-"))
+	}
+	if len(snapshotNode.Tools) > 0 {
+		names := make([]string, 0, len(snapshotNode.Tools))
+		for _, tool := range snapshotNode.Tools {
+			if tool != nil {
+				names = append(names, tool.Name)
+			}
+		}
+		details = append(details, "tools: "+strings.Join(names, ", "))
+	}
+	return details
+}
+
+// overlay marks the nodes the run's steps visited and the routes between
+// consecutive steps.
+func (d *diagram) overlay(steps []*dbmodels.AgentGraphRunStep, byKey map[string]*diagramNode) {
+	ordered := slices.DeleteFunc(slices.Clone(steps), func(step *dbmodels.AgentGraphRunStep) bool {
+		return step == nil
+	})
+	slices.SortStableFunc(ordered, func(a, b *dbmodels.AgentGraphRunStep) int {
+		return int(a.StepOrder - b.StepOrder)
+	})
+
+	path := []string{startNode}
+	for _, step := range ordered {
+		path = append(path, step.NodeKey)
+		node := byKey[step.NodeKey]
+		if node == nil {
+			continue
+		}
+		node.steps = append(node.steps, step.StepOrder)
+		if step.FinishedAt != nil {
+			node.duration += step.FinishedAt.Sub(step.StartedAt)
+		}
+		if step.ErrorCode != nil && *step.ErrorCode != "" {
+			node.failed = *step.ErrorCode
+		}
+	}
+	if last := ordered[len(ordered)-1]; last != nil && last.FinishedAt != nil && (last.ErrorCode == nil || *last.ErrorCode == "") {
+		path = append(path, endNode)
+	}
+
+	for i := 1; i < len(path); i++ {
+		for _, route := range d.routes {
+			if route.from == path[i-1] && route.to == path[i] {
+				route.executed = true
+			}
+		}
+	}
+}
+
+// overlayLine summarizes a node's steps, such as "steps 2, 4 · 1.2s".
+func (n *diagramNode) overlayLine() string {
+	if !n.executed() {
+		return ""
+	}
+	numbers := make([]string, len(n.steps))
+	for i, step := range n.steps {
+		numbers[i] = fmt.Sprint(step)
+	}
+	label := "step "
+	if len(n.steps) > 1 {
+		label = "steps "
+	}
+	line := label + strings.Join(numbers, ", ") + " · " + formatDuration(n.duration)
+	if n.failed != "" {
+		line += " · failed: " + n.failed
+	}
+	return line
+}
+
+func (n *diagramNode) lines() []string {
+	lines := append([]string{n.key}, n.details...)
+	if overlay := n.overlayLine(); overlay != "" {
+		lines = append(lines, overlay)
+	}
+	return lines
+}
+
+func formatDuration(duration time.Duration) string {
+	if duration < time.Second {
+		return fmt.Sprintf("%dms", duration.Milliseconds())
+	}
+	return duration.Round(100 * time.Millisecond).String()
+}
+
+func derefOr(value *string, fallback string) string {
+	if value == nil || *value == "" {
+		return fallback
+	}
+	return *value
+}
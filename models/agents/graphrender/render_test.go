@@ -0,0 +1,156 @@
+package graphrender
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func testSnapshot() *graphs.Snapshot {
+	return &graphs.Snapshot{
+		AgentGraph: &dbmodels.AgentGraph{Name: "Receipts", EntryNode: "check"},
+		Nodes: []*graphs.SnapshotNode{
+			{Node: &dbmodels.AgentGraphNode{
+				NodeKey:  "check",
+				NodeType: "condition",
+				Config:   `{"source_key":"kind","operator":"equals","value":"receipt","true_target":"team","false_target":"END"}`,
+			}},
+			{
+				Node: &dbmodels.AgentGraphNode{
+					NodeKey:   "reader",
+					NodeType:  "worker",
+					InputKey:  stringPtr("temp_url"),
+					OutputKey: stringPtr("text"),
+					Config:    `{"system_message":"Read the \"total\"."}`,
+				},
+				Model: &dbmodels.Model{Provider: "OPENAI", Name: "gpt-4.1-mini"},
+			},
+			{
+				Node:  &dbmodels.AgentGraphNode{NodeKey: "save", NodeType: "tool", Config: `{}`},
+				Tools: []*dbmodels.Tool{{Name: "save_description"}},
+			},
+			{Node: &dbmodels.AgentGraphNode{
+				NodeKey:  "team",
+				NodeType: "supervisor",
+				Config:   `{"members":["reader"],"finish_target":"save"}`,
+			}},
+		},
+		Edges: []*dbmodels.AgentGraphEdge{{FromNode: "save", ToNode: "END"}},
+	}
+}
+
+func testSteps() []*dbmodels.AgentGraphRunStep {
+	started := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	step := func(order int32, key string, duration time.Duration) *dbmodels.AgentGraphRunStep {
+		startedAt := started.Add(time.Duration(order) * time.Second)
+		finishedAt := startedAt.Add(duration)
+		return &dbmodels.AgentGraphRunStep{NodeKey: key, StepOrder: order, StartedAt: startedAt, FinishedAt: &finishedAt}
+	}
+	return []*dbmodels.AgentGraphRunStep{
+		step(3, "reader", 1200*time.Millisecond),
+		step(1, "check", 5*time.Millisecond),
+		step(2, "team", 300*time.Millisecond),
+		step(4, "team", 250*time.Millisecond),
+		step(5, "save", 40*time.Millisecond),
+	}
+}
+
+func assertContains(t *testing.T, rendered string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if !strings.Contains(rendered, fragment) {
+			t.Errorf("expected output to contain %q:\n%s", fragment, rendered)
+		}
+	}
+}
+
+func TestMermaidDrawsNodesAndRoutes(t *testing.T) {
+	rendered, err := Mermaid(testSnapshot(), Options{})
+	if err != nil {
+		t.Fatalf("Mermaid returned error: %v", err)
+	}
+
+	assertContains(t, rendered,
+		"flowchart TD\n",
+		`graph_start(("START"))`,
+		`n0{"check<br/>condition"}`,
+		`n1["reader<br/>worker · OPENAI/gpt-4.1-mini<br/>temp_url → text"]`,
+		`n2[["save<br/>tool<br/>tools: save_description"]]`,
+		`n3{{"team<br/>supervisor"}}`,
+		`graph_end(("END"))`,
+		"graph_start --> n0\n",
+		"n0 -->|true| n3\n",
+		"n0 -->|false| graph_end\n",
+		"n3 -.-> n1\n",
+		"n1 -.-> n3\n",
+		"n3 -->|finish| n2\n",
+		"n2 --> graph_end\n",
+	)
+	if strings.Contains(rendered, "classDef") {
+		t.Fatalf("expected no overlay styles without steps:\n%s", rendered)
+	}
+}
+
+func TestMermaidOverlaysRunPath(t *testing.T) {
+	rendered, err := Mermaid(testSnapshot(), Options{Steps: testSteps()})
+	if err != nil {
+		t.Fatalf("Mermaid returned error: %v", err)
+	}
+
+	assertContains(t, rendered,
+		"step 3 · 1.2s",
+		"steps 2, 4 · 550ms",
+		"class n0,n1,n2,n3 executed\n",
+		// Every route except check's false branch was taken.
+		"linkStyle 0,1,3,4,5,6 "+executedStyle+"\n",
+	)
+}
+
+func TestOverlaySkipsNilSteps(t *testing.T) {
+	steps := append([]*dbmodels.AgentGraphRunStep{nil}, testSteps()...)
+	steps = append(steps, nil)
+	rendered, err := Mermaid(testSnapshot(), Options{Steps: steps})
+	if err != nil {
+		t.Fatalf("Mermaid returned error: %v", err)
+	}
+
+	assertContains(t, rendered, "class n0,n1,n2,n3 executed\n")
+}
+
+func TestDOTDrawsOverlayAndFailures(t *testing.T) {
+	steps := testSteps()[:3]
+	steps[0].ErrorCode = stringPtr("provider_failed")
+
+	rendered, err := DOT(testSnapshot(), Options{Steps: steps})
+	if err != nil {
+		t.Fatalf("DOT returned error: %v", err)
+	}
+
+	assertContains(t, rendered,
+		"digraph \"Receipts\" {\n",
+		`n0 [label="check\ncondition\nstep 1 · 5ms", shape=diamond, color="#2e7d32", penwidth=3];`,
+		`n1 [label="reader\nworker · OPENAI/gpt-4.1-mini\ntemp_url → text\nstep 3 · 1.2s · failed: provider_failed", color="#c62828", penwidth=3];`,
+		`n2 [label="save\ntool\ntools: save_description", shape=component];`,
+		`n0 -> n3 [label="true", color="#2e7d32", penwidth=3];`,
+		`n3 -> n1 [style=dashed, color="#2e7d32", penwidth=3];`,
+		`n1 -> n3 [style=dashed];`,
+		`n2 -> graph_end;`,
+		"}\n",
+	)
+}
+
+func TestRenderRejectsUnknownFormats(t *testing.T) {
+	if _, err := Render(testSnapshot(), Format("svg"), Options{}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+	if _, err := Render(&graphs.Snapshot{}, FormatDOT, Options{}); err == nil {
+		t.Fatal("expected an error for a snapshot without an agent graph")
+	}
+}
@@ -12,18 +12,50 @@ import (
 
 type modelClientFactory func(provider string, modelName string, modelVersion string) (any, error)
 
+// structuredModelClientFactory creates a client that enforces output natively.
+// It returns ok=false when the provider cannot, so the caller falls back.
+type structuredModelClientFactory func(
+	provider string,
+	modelName string,
+	modelVersion string,
+	output clients.StructuredOutput,
+) (any, bool, error)
+
 func defaultModelClientFactory(provider string, modelName string, _ string) (any, error) {
 	return clients.NewModelClient(provider, modelName)
 }
 
+func defaultStructuredModelClientFactory(
+	provider string,
+	modelName string,
+	_ string,
+	output clients.StructuredOutput,
+) (any, bool, error) {
+	return clients.NewStructuredModelClient(provider, modelName, output)
+}
+
 func BuildGraph(agentGraphSnapshot *Snapshot, mcpClient *clients.MCPClient) (*graph.StateRunnable[map[string]any], error) {
-	return buildGraphWithModelFactory(agentGraphSnapshot, mcpClient, defaultModelClientFactory)
+	return buildGraphWithModelFactories(
+		agentGraphSnapshot,
+		mcpClient,
+		defaultModelClientFactory,
+		defaultStructuredModelClientFactory,
+	)
 }
 
 func buildGraphWithModelFactory(
 	agentGraphSnapshot *Snapshot,
 	mcpClient *clients.MCPClient,
 	newModelClient modelClientFactory,
+) (*graph.StateRunnable[map[string]any], error) {
+	return buildGraphWithModelFactories(agentGraphSnapshot, mcpClient, newModelClient, nil)
+}
+
+func buildGraphWithModelFactories(
+	agentGraphSnapshot *Snapshot,
+	mcpClient *clients.MCPClient,
+	newModelClient modelClientFactory,
+	newStructuredModelClient structuredModelClientFactory,
 ) (*graph.StateRunnable[map[string]any], error) {
 	if err := validateSnapshot(agentGraphSnapshot); err != nil {
 		return nil, fmt.Errorf("invalid graph snapshot: %w", err)
@@ -72,8 +104,9 @@ func buildGraphWithModelFactory(
 	}
 	g.SetSchema(schema)
 
-	// Deduplicate model clients by provider:model:version.
-	modelClients, err := buildModelClients(agentGraphSnapshot, newModelClient)
+	// Deduplicate model clients by provider:model:version. Workers with a
+	// natively enforced output_schema get their own client per node.
+	modelClients, err := buildModelClients(agentGraphSnapshot, newModelClient, newStructuredModelClient)
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +114,7 @@ func buildGraphWithModelFactory(
 		agentGraphSnapshot,
 		mcpClient,
 		modelClients,
+		supervisors,
 		supervisorMembers,
 	)
 	if err != nil {
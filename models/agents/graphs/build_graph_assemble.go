@@ -7,6 +7,7 @@ import (
 
 type supervisorInfo struct {
 	snapshotNode *SnapshotNode
+	cfg          supervisorConfig
 	result       *SupervisorRoutingResult
 }
 
@@ -48,7 +49,16 @@ func collectRoutingNodeInfo(
 					)
 				}
 			}
-			supervisors[node.Node.NodeKey] = &supervisorInfo{snapshotNode: node}
+			if cfg.LoopTarget != "" {
+				if _, exists := nodeKeys[cfg.LoopTarget]; !exists {
+					return nil, nil, nil, fmt.Errorf(
+						"supervisor node %q references unknown loop target %q",
+						node.Node.NodeKey,
+						cfg.LoopTarget,
+					)
+				}
+			}
+			supervisors[node.Node.NodeKey] = &supervisorInfo{snapshotNode: node, cfg: cfg}
 			for _, member := range cfg.Members {
 				if existing, exists := supervisorMembers[member]; exists {
 					return nil, nil, nil, fmt.Errorf(
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
 	"github.com/tmc/langchaingo/llms"
 )
@@ -129,6 +130,69 @@ func TestBuildGraphSupervisorRoutesWorkerAndFinishTarget(t *testing.T) {
 	}
 }
 
+func TestBuildGraphSupervisorBreaksRepeatedRoutingLoop(t *testing.T) {
+	snapshot := supervisorRouteSnapshot()
+	snapshot.Nodes[0].Node.Config = `{"members":["billing_worker","operations_worker"],"input_prompt":"Route this OCR text to the best specialist.","max_iterations":6,"loop_repeat_limit":2,"finish_target":"save_review_summary"}`
+
+	routeCalls := 0
+	runnable, err := buildGraphWithModelFactory(snapshot, nil, func(provider string, modelName string, modelVersion string) (any, error) {
+		switch modelName {
+		case "router-model":
+			return &scriptedLLM{
+				generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+					routeCalls++
+					if routeCalls%2 == 1 {
+						return toolCallResponse("billing_worker"), nil
+					}
+					return toolCallResponse("operations_worker"), nil
+				},
+			}, nil
+		case "billing-model":
+			return &scriptedLLM{
+				generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+					return textResponse("Billing specialist summary"), nil
+				},
+			}, nil
+		case "operations-model":
+			return &scriptedLLM{
+				generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+					return textResponse("Operations specialist summary"), nil
+				},
+			}, nil
+		case "save-model":
+			return &scriptedLLM{
+				generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+					return textResponse("Saved: " + lastHumanInput(messages)), nil
+				},
+			}, nil
+		default:
+			return nil, fmt.Errorf("unexpected model request %s/%s@%s", provider, modelName, modelVersion)
+		}
+	})
+	if err != nil {
+		t.Fatalf("buildGraphWithModelFactory returned error: %v", err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{
+		"ocr_text": "INVOICE #1048\nTotal Due: $482.15",
+	})
+	if err != nil {
+		t.Fatalf("expected loop detection to finish before max iterations, got %v", err)
+	}
+
+	// The fourth route completes billing/operations twice, so the supervisor
+	// converts it to FINISH instead of running operations_worker again.
+	if routeCalls != 4 {
+		t.Fatalf("expected 4 routing calls, got %d", routeCalls)
+	}
+	if got := result["ocr_review_summary"]; got != "Billing specialist summary" {
+		t.Fatalf("expected last specialist reply as supervisor output, got %#v", got)
+	}
+	if got := result["saved_summary"]; got != "Saved: Billing specialist summary" {
+		t.Fatalf("expected finish target to run after loop detection, got %#v", got)
+	}
+}
+
 func TestBuildGraphSupervisorMemberRepairsStructuredOutputBeforeFinishing(t *testing.T) {
 	callCount := 0
 	runnable, err := buildGraphWithModelFactory(structuredSupervisorSnapshot(), nil, func(provider string, modelName string, modelVersion string) (any, error) {
@@ -524,3 +588,152 @@ func lastHumanInput(messages []llms.MessageContent) string {
 	}
 	return ""
 }
+
+func TestBuildGraphWorkerUsesNativeStructuredOutputClient(t *testing.T) {
+	var requested clients.StructuredOutput
+	runnable, err := buildGraphWithModelFactories(
+		structuredWorkerSnapshot(),
+		nil,
+		func(provider string, modelName string, modelVersion string) (any, error) {
+			return &scriptedLLM{
+				generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+					t.Fatal("expected the native structured output client to be used")
+					return nil, nil
+				},
+			}, nil
+		},
+		func(provider string, modelName string, modelVersion string, output clients.StructuredOutput) (any, bool, error) {
+			requested = output
+			return &scriptedLLM{
+				generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+					return textResponse(validFindingSummaryJSON), nil
+				},
+			}, true, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("buildGraphWithModelFactories returned error: %v", err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{
+		"finding_draft": "The weld is partially blocked and needs a retake.",
+	})
+	if err != nil {
+		t.Fatalf("runnable.Invoke returned error: %v", err)
+	}
+
+	if requested.Name != "normalize_finding_summary_output" {
+		t.Fatalf("expected schema name from node key, got %q", requested.Name)
+	}
+	if requested.Schema["type"] != "object" || requested.Schema["additionalProperties"] != false {
+		t.Fatalf("expected translated root schema, got %#v", requested.Schema)
+	}
+	if _, ok := result["finding_summary"].(string); !ok {
+		t.Fatalf("expected finding summary, got %#v", result["finding_summary"])
+	}
+}
+
+func TestBuildGraphWorkerForcesOutputToolWithoutNativeSupport(t *testing.T) {
+	callCount := 0
+	runnable, err := buildGraphWithModelFactories(
+		structuredWorkerSnapshot(),
+		nil,
+		func(provider string, modelName string, modelVersion string) (any, error) {
+			return &scriptedLLM{
+				generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+					callCount++
+					var callOptions llms.CallOptions
+					for _, option := range options {
+						option(&callOptions)
+					}
+					if len(callOptions.Tools) != 1 {
+						t.Fatalf("expected a single forced output tool, got %#v", callOptions.Tools)
+					}
+
+					response := toolCallResponse("")
+					response.Choices[0].ToolCalls[0].FunctionCall = &llms.FunctionCall{
+						Name:      callOptions.Tools[0].Function.Name,
+						Arguments: validFindingSummaryJSON,
+					}
+					return response, nil
+				},
+			}, nil
+		},
+		func(provider string, modelName string, modelVersion string, output clients.StructuredOutput) (any, bool, error) {
+			return nil, false, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("buildGraphWithModelFactories returned error: %v", err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{
+		"finding_draft": "The weld is partially blocked and needs a retake.",
+	})
+	if err != nil {
+		t.Fatalf("runnable.Invoke returned error: %v", err)
+	}
+
+	if callCount != 1 {
+		t.Fatalf("expected a single model call, got %d", callCount)
+	}
+	if got, _ := result["finding_summary"].(string); !strings.Contains(got, `"finding_type":"needs_better_image"`) {
+		t.Fatalf("expected forced tool output to be normalized, got %#v", result["finding_summary"])
+	}
+}
+
+const validFindingSummaryJSON = `{"finding_type":"needs_better_image","observation_text":"Retake with a closer, unobstructed view.","scene_description":"Close-up of a weld seam.","severity":"low","confidence":"low","manual_review_reason":null,"retake_recommendation":"Retake with better framing and even lighting."}`
+
+func TestBuildGraphWorkerOutputMappingWritesTypedStateKeys(t *testing.T) {
+	snapshot := &Snapshot{
+		AgentGraph: &dbmodels.AgentGraph{EntryNode: "extract_invoice"},
+		Nodes: []*SnapshotNode{
+			{
+				Node: &dbmodels.AgentGraphNode{
+					NodeKey:   "extract_invoice",
+					NodeType:  "worker",
+					InputKey:  stringPtr("ocr_text"),
+					OutputKey: stringPtr("invoice"),
+					Config:    `{"max_iterations":1,"output_schema":{"type":"object","required":["document_type","total_amount","vendor"],"properties":{"document_type":{"type":"string"},"total_amount":{"type":"number"},"vendor":{"type":"object","properties":{"name":{"type":"string"}}},"line_items":{"type":"array","items":{"type":"object","properties":{"sku":{"type":"string"}}}}}},"output_mapping":{"document_type":"document_type","total_amount":"total_amount","vendor.name":"vendor_name","line_items[0].sku":"first_sku","line_items[3].sku":"fourth_sku"}}`,
+				},
+				Model: fakeModel("OPENAI", "extract-model"),
+			},
+		},
+		Edges: []*dbmodels.AgentGraphEdge{{FromNode: "extract_invoice", ToNode: "END"}},
+	}
+
+	runnable, err := buildGraphWithModelFactory(snapshot, nil, func(provider string, modelName string, modelVersion string) (any, error) {
+		return &scriptedLLM{
+			generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+				return textResponse(`{"document_type":"invoice","total_amount":128.5,"vendor":{"name":"Acme"},"line_items":[{"sku":"A-1"}]}`), nil
+			},
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("buildGraphWithModelFactory returned error: %v", err)
+	}
+
+	result, err := runnable.Invoke(context.Background(), map[string]any{"ocr_text": "INVOICE Acme total 128.50"})
+	if err != nil {
+		t.Fatalf("runnable.Invoke returned error: %v", err)
+	}
+
+	if got := result["document_type"]; got != "invoice" {
+		t.Fatalf("expected document_type=invoice, got %#v", got)
+	}
+	if got := result["total_amount"]; got != 128.5 {
+		t.Fatalf("expected numeric total_amount=128.5, got %#v", got)
+	}
+	if got := result["vendor_name"]; got != "Acme" {
+		t.Fatalf("expected vendor_name=Acme, got %#v", got)
+	}
+	if got := result["first_sku"]; got != "A-1" {
+		t.Fatalf("expected first_sku=A-1, got %#v", got)
+	}
+	if _, ok := result["fourth_sku"]; ok {
+		t.Fatalf("expected missing path to be skipped, got %#v", result["fourth_sku"])
+	}
+	if got, _ := result["invoice"].(string); !strings.Contains(got, `"document_type":"invoice"`) {
+		t.Fatalf("expected whole object in output_key, got %#v", result["invoice"])
+	}
+}
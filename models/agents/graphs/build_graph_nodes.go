@@ -1,10 +1,13 @@
 package graphs
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
 	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/llms"
 )
@@ -12,6 +15,7 @@ import (
 func buildModelClients(
 	agentGraphSnapshot *Snapshot,
 	newModelClient modelClientFactory,
+	newStructuredModelClient structuredModelClientFactory,
 ) (map[string]any, error) {
 	modelClients := make(map[string]any)
 	for _, node := range agentGraphSnapshot.Nodes {
@@ -25,6 +29,27 @@ func buildModelClients(
 			node.Model.Name,
 			node.Model.Version,
 		)
+		if newStructuredModelClient != nil {
+			if output, ok := workerStructuredOutput(node); ok {
+				client, native, err := newStructuredModelClient(
+					node.Model.Provider,
+					node.Model.Name,
+					node.Model.Version,
+					output,
+				)
+				if err != nil {
+					return nil, fmt.Errorf(
+						"failed to create structured output model client for %q: %w",
+						node.Node.NodeKey,
+						err,
+					)
+				}
+				if native {
+					modelClients[structuredModelClientKey(node)] = client
+				}
+			}
+		}
+
 		if _, ok := modelClients[key]; ok {
 			continue
 		}
@@ -48,6 +73,7 @@ func buildWorkerAndToolNodes(
 	agentGraphSnapshot *Snapshot,
 	mcpClient *clients.MCPClient,
 	modelClients map[string]any,
+	supervisors map[string]*supervisorInfo,
 	supervisorMembers map[string]string,
 ) (map[string]*NodeToAdd, error) {
 	builtNodes := make(map[string]*NodeToAdd)
@@ -58,8 +84,14 @@ func buildWorkerAndToolNodes(
 		}
 
 		modelClient := graphNodeModelClient(node, modelClients)
-		if _, isMember := supervisorMembers[node.Node.NodeKey]; isMember && nodeType == "worker" {
-			built, err := BuildSupervisorMemberWorkerNode(node, modelClient, mcpClient)
+		if supervisorKey, isMember := supervisorMembers[node.Node.NodeKey]; isMember && nodeType == "worker" {
+			built, err := buildSupervisorMemberWorkerNode(
+				node,
+				modelClient,
+				graphNodePlainModelClient(node, modelClients),
+				mcpClient,
+				supervisors[supervisorKey].cfg,
+			)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"failed to build member worker %q: %w",
@@ -130,12 +162,13 @@ func addBuiltNodesToGraph(
 	supervisorMembers map[string]string,
 ) {
 	for key, built := range builtNodes {
+		fn := withNodeKey(key, built.Fn)
 		if _, isSupervisor := supervisors[key]; isSupervisor {
 			info := supervisors[key]
 			g.AddNodeWithTimeout(
 				built.Name,
 				built.Description,
-				built.Fn,
+				fn,
 				info.result.RoutingTimeout,
 			)
 			continue
@@ -145,29 +178,74 @@ func addBuiltNodesToGraph(
 			g.AddNodeWithTimeout(
 				built.Name,
 				built.Description,
-				built.Fn,
+				fn,
 				defaultMemberWorkerTimeout,
 			)
 			continue
 		}
 
-		g.AddNode(built.Name, built.Description, built.Fn)
+		g.AddNode(built.Name, built.Description, fn)
 	}
 }
 
+// withNodeKey runs fn with nodeKey on its context, so logs and MCP calls made
+// by the node carry it, inside a node span that its model and MCP spans nest
+// under.
+func withNodeKey(
+	nodeKey string,
+	fn func(ctx context.Context, state map[string]any) (map[string]any, error),
+) func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		ctx, span := startNodeSpan(logging.WithNodeKey(ctx, nodeKey), nodeKey)
+		result, err := fn(ctx, state)
+		telemetry.EndSpan(span, err)
+		return result, err
+	}
+}
+
+// graphNodeModelClient returns the client a node generates its output with:
+// the provider client with retries, held to the worker's output_schema
+// natively or by forcing an output tool call.
 func graphNodeModelClient(snapshotNode *SnapshotNode, modelClients map[string]any) any {
 	if snapshotNode.Model == nil {
 		return nil
 	}
 
+	if structured, native := modelClients[structuredModelClientKey(snapshotNode)]; native {
+		return withNodeProviderRetry(snapshotNode, structured)
+	}
+	modelClient := graphNodePlainModelClient(snapshotNode, modelClients)
+	model, ok := modelClient.(llms.Model)
+	if !ok {
+		return modelClient
+	}
+
+	// Without native support, a tool-less worker can still be held to its
+	// schema by forcing a single output tool call.
+	if output, ok := workerStructuredOutput(snapshotNode); ok && len(snapshotNode.Tools) == 0 {
+		model = clients.WithToolForcedOutput(model, output)
+	}
+	return model
+}
+
+// graphNodePlainModelClient returns the node's provider client with retries
+// but without its output_schema, for calls that do not produce the node's
+// output, such as summarizing supervisor history.
+func graphNodePlainModelClient(snapshotNode *SnapshotNode, modelClients map[string]any) any {
+	if snapshotNode.Model == nil {
+		return nil
+	}
+
 	key := fmt.Sprintf(
 		"%s:%s:%s",
 		snapshotNode.Model.Provider,
 		snapshotNode.Model.Name,
 		snapshotNode.Model.Version,
 	)
+	return withNodeProviderRetry(snapshotNode, modelClients[key])
+}
 
-	modelClient := modelClients[key]
+func withNodeProviderRetry(snapshotNode *SnapshotNode, modelClient any) any {
 	model, ok := modelClient.(llms.Model)
 	if !ok {
 		return modelClient
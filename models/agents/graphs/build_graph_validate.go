@@ -6,6 +6,12 @@ import (
 	"strings"
 )
 
+// ValidateSnapshot checks that a snapshot's entry node, node keys, and edges
+// form a graph that reaches END, as BuildGraph requires.
+func ValidateSnapshot(snapshot *Snapshot) error {
+	return validateSnapshot(snapshot)
+}
+
 func validateSnapshot(snapshot *Snapshot) error {
 	if snapshot == nil {
 		return errors.New("graph snapshot is nil")
@@ -137,6 +143,9 @@ func addImplicitRoutingAdjacency(
 			} else {
 				adjacency[supKey] = append(adjacency[supKey], "END")
 			}
+			if cfg.LoopTarget != "" {
+				adjacency[supKey] = append(adjacency[supKey], cfg.LoopTarget)
+			}
 		case "condition":
 			cfg, err := parseConditionConfig(snapshotNode)
 			if err != nil {
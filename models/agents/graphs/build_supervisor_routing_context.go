@@ -0,0 +1,263 @@
+package graphs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	supervisorContextStrategyTruncate  = "truncate"
+	supervisorContextStrategySummarize = "summarize"
+
+	// estimatedImageTokens is a flat per-image cost so data URLs don't dominate
+	// the character-based estimate.
+	estimatedImageTokens   = 1024
+	estimatedCharsPerToken = 4
+
+	supervisorContextSummaryPrompt = "Summarize the following earlier conversation between a supervisor and its specialist workers. " +
+		"Keep findings, extracted values, and open questions. Reply with the summary only."
+)
+
+// supervisorContextWindow keeps the shared supervisor conversation under a token
+// budget. The initial human message (which carries the image) is always kept,
+// the most recent messages are kept, and everything in between is either
+// dropped or summarized by model.
+type supervisorContextWindow struct {
+	nodeKey     string
+	tokenBudget int
+	keepRecent  int
+	strategy    string
+	model       llms.Model
+}
+
+// supervisorContextSummary is the summary of the messages before Through that
+// were dropped from the shared conversation. It is kept in state under
+// supervisorContextSummaryKey so later model calls only summarize the messages
+// dropped since.
+type supervisorContextSummary struct {
+	Through int    `json:"through"`
+	Text    string `json:"text"`
+}
+
+func newSupervisorContextWindow(nodeKey string, cfg supervisorConfig, model llms.Model) supervisorContextWindow {
+	return supervisorContextWindow{
+		nodeKey:     nodeKey,
+		tokenBudget: cfg.ContextTokenBudget,
+		keepRecent:  cfg.ContextKeepRecent,
+		strategy:    cfg.ContextStrategy,
+		model:       model,
+	}
+}
+
+// loadSupervisorContextSummary returns the stored summary, which is a map once
+// state has been through JSON.
+func loadSupervisorContextSummary(state map[string]any) supervisorContextSummary {
+	switch value := state[supervisorContextSummaryKey].(type) {
+	case supervisorContextSummary:
+		return value
+	case map[string]any:
+		var summary supervisorContextSummary
+		encoded, err := json.Marshal(value)
+		if err != nil || json.Unmarshal(encoded, &summary) != nil {
+			return supervisorContextSummary{}
+		}
+		return summary
+	default:
+		return supervisorContextSummary{}
+	}
+}
+
+// fit trims messages to the token budget. When it summarizes newly dropped
+// messages it also returns the summary to store in state; a stored summary
+// that still ends where the kept tail starts is reused without a model call.
+func (w supervisorContextWindow) fit(
+	ctx context.Context,
+	messages []llms.MessageContent,
+	summary supervisorContextSummary,
+) ([]llms.MessageContent, *supervisorContextSummary) {
+	if w.tokenBudget <= 0 {
+		return messages, nil
+	}
+	estimated := estimateMessagesTokens(messages)
+	if estimated <= w.tokenBudget {
+		return messages, nil
+	}
+
+	pinned := 0
+	if len(messages) > 0 && messages[0].Role == llms.ChatMessageTypeHuman {
+		pinned = 1
+	}
+
+	start := recentMessagesStart(messages, pinned, w.tokenBudget-estimateMessagesTokens(messages[:pinned]), w.keepRecent)
+	summarize := w.strategy == supervisorContextStrategySummarize && w.model != nil
+	if !summarize || summary.Text == "" || summary.Through <= pinned || summary.Through > len(messages) {
+		summary = supervisorContextSummary{}
+	}
+	// Messages already summarized stay dropped even if the tail would fit them.
+	start = max(start, summary.Through)
+	if start <= pinned {
+		return messages, nil
+	}
+
+	var replacement llms.MessageContent
+	var updated *supervisorContextSummary
+	switch {
+	case summarize && start == summary.Through:
+		replacement = supervisorSummaryMessage(start-pinned, summary.Text)
+	case summarize:
+		replacement, updated = w.summarize(ctx, messages, pinned, start, summary)
+	default:
+		replacement = supervisorOmissionMessage(start - pinned)
+	}
+
+	fitted := make([]llms.MessageContent, 0, pinned+1+len(messages)-start)
+	fitted = append(fitted, messages[:pinned]...)
+	fitted = append(fitted, replacement)
+	fitted = append(fitted, messages[start:]...)
+
+	slog.WarnContext(ctx, "graph supervisor_context_trimmed",
+		"node_key", w.nodeKey,
+		"strategy", w.strategy,
+		"dropped", start-pinned,
+		"estimated_tokens", estimated,
+		"budget", w.tokenBudget,
+	)
+	return fitted, updated
+}
+
+// summarize extends summary with the messages dropped since it was made, up to
+// start. On failure the dropped messages are replaced by an omission notice.
+func (w supervisorContextWindow) summarize(
+	ctx context.Context,
+	messages []llms.MessageContent,
+	pinned int,
+	start int,
+	summary supervisorContextSummary,
+) (llms.MessageContent, *supervisorContextSummary) {
+	from := max(pinned, summary.Through)
+	text, err := summarizeSupervisorMessages(ctx, w.model, summary.Text, messages[from:start])
+	if err == nil && text != "" {
+		return supervisorSummaryMessage(start-pinned, text), &supervisorContextSummary{Through: start, Text: text}
+	}
+	if err == nil {
+		err = fmt.Errorf("llm returned an empty summary")
+	}
+	slog.ErrorContext(ctx, "graph supervisor_context_summary_error",
+		"node_key", w.nodeKey,
+		"dropped", start-from,
+		"err", err,
+	)
+	return supervisorOmissionMessage(start - pinned), nil
+}
+
+func supervisorSummaryMessage(dropped int, summary string) llms.MessageContent {
+	return llms.TextParts(
+		llms.ChatMessageTypeAI,
+		fmt.Sprintf("[supervisor] summary of %d earlier messages: %s", dropped, summary),
+	)
+}
+
+func supervisorOmissionMessage(dropped int) llms.MessageContent {
+	return llms.TextParts(
+		llms.ChatMessageTypeAI,
+		fmt.Sprintf("[supervisor] %d earlier messages were omitted to fit the context window.", dropped),
+	)
+}
+
+// recentMessagesStart returns the index of the first message to keep after the
+// pinned prefix. At least keepRecent messages are kept even when they exceed
+// the budget, and the kept tail never starts with an orphaned tool response.
+func recentMessagesStart(messages []llms.MessageContent, pinned int, budget int, keepRecent int) int {
+	start := len(messages)
+	used := 0
+	for start > pinned {
+		cost := estimateMessageTokens(messages[start-1])
+		if len(messages)-start >= keepRecent && used+cost > budget {
+			break
+		}
+		used += cost
+		start--
+	}
+
+	for start < len(messages) && messages[start].Role == llms.ChatMessageTypeTool {
+		start++
+	}
+
+	return start
+}
+
+// summarizeSupervisorMessages summarizes messages, folding in the summary of
+// the messages before them when there is one.
+func summarizeSupervisorMessages(ctx context.Context, model llms.Model, previous string, messages []llms.MessageContent) (string, error) {
+	transcript := supervisorTranscript(messages)
+	if previous != "" {
+		transcript = "Summary of the conversation before this: " + previous + "\n" + transcript
+	}
+	resp, err := model.GenerateContent(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, supervisorContextSummaryPrompt),
+		llms.TextParts(llms.ChatMessageTypeHuman, transcript),
+	})
+	if err != nil {
+		return "", fmt.Errorf("llm call failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("llm returned no summary")
+	}
+
+	return strings.TrimSpace(resp.Choices[0].Content), nil
+}
+
+func supervisorTranscript(messages []llms.MessageContent) string {
+	var builder strings.Builder
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			switch typed := part.(type) {
+			case llms.TextContent:
+				fmt.Fprintf(&builder, "%s: %s\n", message.Role, typed.Text)
+			case llms.ToolCall:
+				if typed.FunctionCall != nil {
+					fmt.Fprintf(&builder, "%s: called %s(%s)\n", message.Role, typed.FunctionCall.Name, typed.FunctionCall.Arguments)
+				}
+			case llms.ToolCallResponse:
+				fmt.Fprintf(&builder, "%s: %s returned %s\n", message.Role, typed.Name, typed.Content)
+			case llms.ImageURLContent:
+				fmt.Fprintf(&builder, "%s: [image]\n", message.Role)
+			}
+		}
+	}
+	return builder.String()
+}
+
+func estimateMessagesTokens(messages []llms.MessageContent) int {
+	total := 0
+	for _, message := range messages {
+		total += estimateMessageTokens(message)
+	}
+	return total
+}
+
+func estimateMessageTokens(message llms.MessageContent) int {
+	chars := 0
+	images := 0
+	for _, part := range message.Parts {
+		switch typed := part.(type) {
+		case llms.TextContent:
+			chars += len(typed.Text)
+		case llms.ToolCall:
+			if typed.FunctionCall != nil {
+				chars += len(typed.FunctionCall.Name) + len(typed.FunctionCall.Arguments)
+			}
+		case llms.ToolCallResponse:
+			chars += len(typed.Name) + len(typed.Content)
+		case llms.ImageURLContent, llms.BinaryContent:
+			images++
+		}
+	}
+
+	return (chars+estimatedCharsPerToken-1)/estimatedCharsPerToken + images*estimatedImageTokens
+}
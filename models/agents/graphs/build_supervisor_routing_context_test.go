@@ -0,0 +1,335 @@
+package graphs
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestDetectSupervisorRoutingLoop(t *testing.T) {
+	tests := []struct {
+		name        string
+		routes      []string
+		limit       int
+		wantPattern []string
+		wantLoop    bool
+	}{
+		{name: "same member", routes: []string{"ocr", "ocr", "ocr"}, limit: 3, wantPattern: []string{"ocr"}, wantLoop: true},
+		{name: "two member bounce", routes: []string{"intake", "ocr", "review", "ocr", "review"}, limit: 2, wantPattern: []string{"ocr", "review"}, wantLoop: true},
+		{name: "below limit", routes: []string{"ocr", "review", "ocr", "review"}, limit: 3},
+		{name: "progressing", routes: []string{"intake", "ocr", "review"}, limit: 2},
+		{name: "disabled", routes: []string{"ocr", "ocr", "ocr"}, limit: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pattern, looped := detectSupervisorRoutingLoop(test.routes, test.limit)
+			if looped != test.wantLoop {
+				t.Fatalf("expected loop=%t, got %t", test.wantLoop, looped)
+			}
+			if !reflect.DeepEqual(pattern, test.wantPattern) {
+				t.Fatalf("expected pattern %v, got %v", test.wantPattern, pattern)
+			}
+		})
+	}
+}
+
+func TestSupervisorRoutesAreKeptPerSupervisorAndResetOnEscalation(t *testing.T) {
+	routeTo := func(next string) *scriptedLLM {
+		return &scriptedLLM{
+			generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+				return toolCallResponse(next), nil
+			},
+		}
+	}
+	buildRouter := func(nodeKey string) *SupervisorRoutingResult {
+		t.Helper()
+		result, err := BuildSupervisorRoutingNode(&SnapshotNode{
+			Node: &dbmodels.AgentGraphNode{
+				NodeKey:  nodeKey,
+				NodeType: "supervisor",
+				Config:   `{"members":["billing_worker","operations_worker"],"loop_repeat_limit":2,"loop_target":"manual_review"}`,
+			},
+		}, routeTo("operations_worker"))
+		if err != nil {
+			t.Fatalf("BuildSupervisorRoutingNode returned error: %v", err)
+		}
+		return result
+	}
+	intake := buildRouter("intake_supervisor")
+	review := buildRouter("review_supervisor")
+	state := map[string]any{
+		supervisorIterationKey: 3,
+		"messages": []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeHuman, "Review this receipt."),
+		},
+		supervisorRoutesKey("intake_supervisor"): []string{"billing_worker", "operations_worker", "billing_worker"},
+	}
+
+	// The intake supervisor completes billing/operations twice and escalates,
+	// starting its history over.
+	delta, err := intake.RoutingNode.Fn(context.Background(), state)
+	if err != nil {
+		t.Fatalf("intake routing returned error: %v", err)
+	}
+	if delta[supervisorNextKey] != "manual_review" {
+		t.Fatalf("expected intake supervisor to escalate, got %#v", delta[supervisorNextKey])
+	}
+	if routes := delta[supervisorRoutesKey("intake_supervisor")]; !reflect.DeepEqual(routes, []string{}) {
+		t.Fatalf("expected intake routes to be reset on escalation, got %#v", routes)
+	}
+
+	// The review supervisor makes the same decision without seeing the
+	// intake supervisor's history, so it does not escalate.
+	delta, err = review.RoutingNode.Fn(context.Background(), state)
+	if err != nil {
+		t.Fatalf("review routing returned error: %v", err)
+	}
+	if delta[supervisorNextKey] != "operations_worker" {
+		t.Fatalf("expected review supervisor to route to its member, got %#v", delta[supervisorNextKey])
+	}
+	if routes := delta[supervisorRoutesKey("review_supervisor")]; !reflect.DeepEqual(routes, []string{"operations_worker"}) {
+		t.Fatalf("expected review routes to hold only its own decision, got %#v", routes)
+	}
+	if _, ok := delta[supervisorRoutesKey("intake_supervisor")]; ok {
+		t.Fatalf("expected review supervisor to leave intake routes alone, got %#v", delta)
+	}
+}
+
+func TestParseSupervisorConfigRejectsUnknownContextStrategy(t *testing.T) {
+	_, err := parseSupervisorConfig(&SnapshotNode{Node: &dbmodels.AgentGraphNode{
+		NodeKey: "review",
+		Config:  `{"members":["ocr"],"context_strategy":"compress"}`,
+	}})
+	if err == nil || !strings.Contains(err.Error(), "context_strategy must be truncate or summarize") {
+		t.Fatalf("expected context strategy error, got %v", err)
+	}
+}
+
+func TestParseSupervisorConfigRejectsLoopSettingsThatCannotBreakALoop(t *testing.T) {
+	for config, want := range map[string]string{
+		`{"members":["ocr"],"loop_target":"review"}`:   "loop_target cannot be the supervisor itself",
+		`{"members":["ocr"],"loop_repeat_limit":1}`:    "loop_repeat_limit must be at least 2",
+		`{"members":["ocr"],"loop_target":" review "}`: "loop_target cannot be the supervisor itself",
+	} {
+		_, err := parseSupervisorConfig(&SnapshotNode{Node: &dbmodels.AgentGraphNode{NodeKey: "review", Config: config}})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", config, want, err)
+		}
+	}
+
+	cfg, err := parseSupervisorConfig(&SnapshotNode{Node: &dbmodels.AgentGraphNode{
+		NodeKey: "review",
+		Config:  `{"members":["ocr"],"loop_repeat_limit":-1}`,
+	}})
+	if err != nil || cfg.LoopRepeatLimit != -1 {
+		t.Fatalf("expected a negative loop_repeat_limit to disable loop detection, got %d, %v", cfg.LoopRepeatLimit, err)
+	}
+}
+
+func TestSupervisorContextWindowKeepsInitialMessageAndRecentTail(t *testing.T) {
+	initial := llms.MessageContent{
+		Role: llms.ChatMessageTypeHuman,
+		Parts: []llms.ContentPart{
+			llms.ImageURLPart("data:image/jpeg;base64," + strings.Repeat("A", 40000)),
+			llms.TextPart("Review this receipt."),
+		},
+	}
+	messages := []llms.MessageContent{initial}
+	for i := 0; i < 6; i++ {
+		messages = append(messages,
+			llms.TextParts(llms.ChatMessageTypeAI, "[supervisor] routing to: ocr"),
+			llms.TextParts(llms.ChatMessageTypeAI, strings.Repeat("ocr finding ", 100)),
+		)
+	}
+
+	window := supervisorContextWindow{
+		nodeKey:     "review",
+		tokenBudget: 1024 + 700,
+		keepRecent:  2,
+		strategy:    supervisorContextStrategyTruncate,
+	}
+	fitted, summary := window.fit(context.Background(), messages, supervisorContextSummary{})
+	if summary != nil {
+		t.Fatalf("expected truncation not to produce a summary, got %#v", summary)
+	}
+
+	if !reflect.DeepEqual(fitted[0], initial) {
+		t.Fatal("expected initial human message with image to be kept intact")
+	}
+	if len(fitted) >= len(messages) {
+		t.Fatalf("expected messages to be trimmed, got %d of %d", len(fitted), len(messages))
+	}
+	if got := allMessageText(fitted[1:2]); !strings.Contains(got, "earlier messages were omitted") {
+		t.Fatalf("expected omission notice after initial message, got %q", got)
+	}
+	if !reflect.DeepEqual(fitted[len(fitted)-1], messages[len(messages)-1]) {
+		t.Fatal("expected most recent message to be kept")
+	}
+	if estimateMessagesTokens(fitted[2:]) > window.tokenBudget-estimateMessageTokens(initial) {
+		t.Fatalf("expected kept tail to fit the remaining budget")
+	}
+}
+
+func TestSupervisorContextWindowSummarizesDroppedMessages(t *testing.T) {
+	var summarized string
+	window := supervisorContextWindow{
+		nodeKey:     "review",
+		tokenBudget: 50,
+		keepRecent:  1,
+		strategy:    supervisorContextStrategySummarize,
+		model: &scriptedLLM{
+			generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+				summarized = allMessageText(messages)
+				return textResponse("total is 482.15"), nil
+			},
+		},
+	}
+
+	fitted, summary := window.fit(context.Background(), []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "Review this receipt."),
+		llms.TextParts(llms.ChatMessageTypeAI, "Total Due: $482.15 "+strings.Repeat("detail ", 40)),
+		llms.TextParts(llms.ChatMessageTypeAI, "Looks consistent."),
+	}, supervisorContextSummary{})
+
+	if !strings.Contains(summarized, "Total Due: $482.15") {
+		t.Fatalf("expected dropped messages to be summarized, got %q", summarized)
+	}
+	if len(fitted) != 3 {
+		t.Fatalf("expected initial, summary and recent message, got %d", len(fitted))
+	}
+	if got := allMessageText(fitted[1:2]); got != "[supervisor] summary of 1 earlier messages: total is 482.15" {
+		t.Fatalf("unexpected summary message %q", got)
+	}
+	if summary == nil || *summary != (supervisorContextSummary{Through: 2, Text: "total is 482.15"}) {
+		t.Fatalf("expected the summary to be returned for state, got %#v", summary)
+	}
+}
+
+func TestSupervisorContextWindowSummarizesIncrementally(t *testing.T) {
+	var calls []string
+	window := supervisorContextWindow{
+		nodeKey:     "review",
+		tokenBudget: 50,
+		keepRecent:  1,
+		strategy:    supervisorContextStrategySummarize,
+		model: &scriptedLLM{
+			generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+				calls = append(calls, allMessageText(messages[1:]))
+				return textResponse("total is 482.15, tax checked"), nil
+			},
+		},
+	}
+	messages := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "Review this receipt."),
+		llms.TextParts(llms.ChatMessageTypeAI, "Total Due: $482.15 "+strings.Repeat("detail ", 40)),
+		llms.TextParts(llms.ChatMessageTypeAI, "Looks consistent."),
+	}
+	stored := supervisorContextSummary{Through: 2, Text: "total is 482.15"}
+
+	// The stored summary still covers everything dropped, so no model call.
+	fitted, updated := window.fit(context.Background(), messages, stored)
+	if len(calls) != 0 || updated != nil {
+		t.Fatalf("expected the stored summary to be reused, got %d calls and %#v", len(calls), updated)
+	}
+	if got := allMessageText(fitted[1:2]); got != "[supervisor] summary of 1 earlier messages: total is 482.15" {
+		t.Fatalf("unexpected summary message %q", got)
+	}
+
+	// Only the messages dropped since are sent, with the stored summary.
+	messages = append(messages, llms.TextParts(llms.ChatMessageTypeAI, "Tax: $38.20 "+strings.Repeat("line ", 40)))
+	fitted, updated = window.fit(context.Background(), messages, loadSupervisorContextSummary(map[string]any{
+		supervisorContextSummaryKey: map[string]any{"through": float64(2), "text": "total is 482.15"},
+	}))
+	if len(calls) != 1 || strings.Contains(calls[0], "Total Due") ||
+		!strings.Contains(calls[0], "total is 482.15") || !strings.Contains(calls[0], "Looks consistent.") {
+		t.Fatalf("expected only newly dropped messages and the stored summary to be summarized, got %q", calls)
+	}
+	if updated == nil || *updated != (supervisorContextSummary{Through: 3, Text: "total is 482.15, tax checked"}) {
+		t.Fatalf("unexpected updated summary %#v", updated)
+	}
+	if len(fitted) != 3 || allMessageText(fitted[1:2]) != "[supervisor] summary of 2 earlier messages: total is 482.15, tax checked" {
+		t.Fatalf("unexpected fitted messages %q", allMessageText(fitted))
+	}
+}
+
+func TestSupervisorMemberSummarizesWithoutItsOutputSchema(t *testing.T) {
+	snapshot := structuredSupervisorSnapshot()
+	member := snapshot.Nodes[1]
+	summaryCalls := 0
+	modelClients := map[string]any{
+		"OPENAI:inspection-model:": &scriptedLLM{
+			generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+				summaryCalls++
+				return textResponse("weld photo needs a retake"), nil
+			},
+		},
+		structuredModelClientKey(member): &scriptedLLM{
+			generate: func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+				return textResponse(validFindingSummaryJSON), nil
+			},
+		},
+	}
+	supervisors := map[string]*supervisorInfo{
+		"inspection_supervisor": {cfg: supervisorConfig{
+			ContextTokenBudget: 50,
+			ContextKeepRecent:  1,
+			ContextStrategy:    supervisorContextStrategySummarize,
+		}},
+	}
+
+	built, err := buildWorkerAndToolNodes(snapshot, nil, modelClients, supervisors, map[string]string{
+		"inspection_worker": "inspection_supervisor",
+	})
+	if err != nil {
+		t.Fatalf("buildWorkerAndToolNodes returned error: %v", err)
+	}
+	delta, err := built["inspection_worker"].Fn(context.Background(), map[string]any{
+		"messages": []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeHuman, "Review this inspection image."),
+			llms.TextParts(llms.ChatMessageTypeAI, "The weld seam is blocked "+strings.Repeat("detail ", 40)),
+			llms.TextParts(llms.ChatMessageTypeAI, "Routing to the inspection specialist."),
+		},
+	})
+	if err != nil {
+		t.Fatalf("member worker returned error: %v", err)
+	}
+
+	if summaryCalls != 1 {
+		t.Fatalf("expected one summary call on the plain model, got %d", summaryCalls)
+	}
+	if summary := delta[supervisorContextSummaryKey]; summary != (supervisorContextSummary{Through: 2, Text: "weld photo needs a retake"}) {
+		t.Fatalf("expected a plain-text summary in state, got %#v", summary)
+	}
+}
+
+func TestRecentMessagesStartSkipsOrphanedToolResponses(t *testing.T) {
+	messages := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "start"),
+		{
+			Role: llms.ChatMessageTypeAI,
+			Parts: []llms.ContentPart{llms.ToolCall{
+				ID:           "call-1",
+				Type:         "function",
+				FunctionCall: &llms.FunctionCall{Name: "create_document_ocr", Arguments: strings.Repeat("x", 400)},
+			}},
+		},
+		{
+			Role:  llms.ChatMessageTypeTool,
+			Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: "call-1", Name: "create_document_ocr", Content: "ok"}},
+		},
+		llms.TextParts(llms.ChatMessageTypeAI, "done"),
+	}
+
+	start := recentMessagesStart(messages, 1, 10, 1)
+	if messages[start].Role == llms.ChatMessageTypeTool {
+		t.Fatalf("kept tail starts with an orphaned tool response at %d", start)
+	}
+	if start != 3 {
+		t.Fatalf("expected tail to start at the final AI message, got %d", start)
+	}
+}
@@ -28,6 +28,42 @@ func parseSupervisorConfig(snapshotNode *SnapshotNode) (supervisorConfig, error)
 	if cfg.MaxIterations <= 0 {
 		cfg.MaxIterations = defaultSupervisorMaxIterations
 	}
+	cfg.LoopTarget = strings.TrimSpace(cfg.LoopTarget)
+	if cfg.LoopTarget == snapshotNode.Node.NodeKey {
+		return supervisorConfig{}, fmt.Errorf(
+			"supervisor node %q: loop_target cannot be the supervisor itself",
+			snapshotNode.Node.NodeKey,
+		)
+	}
+	if cfg.LoopRepeatLimit == 0 {
+		cfg.LoopRepeatLimit = defaultSupervisorLoopRepeatLimit
+	}
+	if cfg.LoopRepeatLimit == 1 {
+		return supervisorConfig{}, fmt.Errorf(
+			"supervisor node %q: loop_repeat_limit must be at least 2, or negative to disable loop detection",
+			snapshotNode.Node.NodeKey,
+		)
+	}
+	cfg.ContextStrategy = strings.ToLower(strings.TrimSpace(cfg.ContextStrategy))
+	switch cfg.ContextStrategy {
+	case "":
+		cfg.ContextStrategy = supervisorContextStrategyTruncate
+	case supervisorContextStrategyTruncate, supervisorContextStrategySummarize:
+	default:
+		return supervisorConfig{}, fmt.Errorf(
+			"supervisor node %q: context_strategy must be truncate or summarize",
+			snapshotNode.Node.NodeKey,
+		)
+	}
+	if cfg.ContextTokenBudget < 0 {
+		return supervisorConfig{}, fmt.Errorf(
+			"supervisor node %q: context_token_budget cannot be negative",
+			snapshotNode.Node.NodeKey,
+		)
+	}
+	if cfg.ContextKeepRecent <= 0 {
+		cfg.ContextKeepRecent = defaultSupervisorContextKeep
+	}
 	return cfg, nil
 }
 
@@ -0,0 +1,61 @@
+package graphs
+
+// supervisorRoutesKey returns the state key holding a supervisor's routes.
+func supervisorRoutesKey(nodeKey string) string {
+	return supervisorRoutesKeyPrefix + nodeKey
+}
+
+// loadSupervisorRoutes returns the member routing decisions the supervisor
+// has recorded since it last escalated.
+func loadSupervisorRoutes(state map[string]any, nodeKey string) []string {
+	switch value := state[supervisorRoutesKey(nodeKey)].(type) {
+	case []string:
+		return append([]string(nil), value...)
+	case []any:
+		routes := make([]string, 0, len(value))
+		for _, item := range value {
+			if route, ok := item.(string); ok {
+				routes = append(routes, route)
+			}
+		}
+		return routes
+	default:
+		return nil
+	}
+}
+
+// detectSupervisorRoutingLoop reports the shortest routing pattern that repeats
+// back-to-back at least repeatLimit times at the tail of routes, e.g.
+// [a b a b a b] repeats [a b] three times.
+func detectSupervisorRoutingLoop(routes []string, repeatLimit int) ([]string, bool) {
+	if repeatLimit < 2 {
+		return nil, false
+	}
+
+	for period := 1; period*repeatLimit <= len(routes); period++ {
+		pattern := routes[len(routes)-period:]
+		repeated := true
+		for repeat := 1; repeat < repeatLimit && repeated; repeat++ {
+			offset := len(routes) - period*(repeat+1)
+			for index := range pattern {
+				if routes[offset+index] != pattern[index] {
+					repeated = false
+					break
+				}
+			}
+		}
+		if repeated {
+			return append([]string(nil), pattern...), true
+		}
+	}
+
+	return nil, false
+}
+
+// loopExitTarget returns where a supervisor goes when it breaks out of a loop.
+func (cfg supervisorConfig) loopExitTarget() string {
+	if cfg.LoopTarget != "" {
+		return cfg.LoopTarget
+	}
+	return "FINISH"
+}
@@ -12,10 +12,19 @@ const (
 	// State keys used by supervisor routing. Prefixed to avoid collision with user state.
 	supervisorNextKey      = "__supervisor_next"
 	supervisorIterationKey = "__supervisor_iteration"
+	// supervisorRoutesKeyPrefix plus the supervisor's node key holds that
+	// supervisor's member routing decisions, so supervisors don't see each
+	// other's history.
+	supervisorRoutesKeyPrefix = "__supervisor_routes_"
+	// supervisorContextSummaryKey holds the supervisorContextSummary of the
+	// messages dropped from the shared conversation.
+	supervisorContextSummaryKey = "__supervisor_context_summary"
 
-	defaultSupervisorMaxIterations = 10
-	defaultSupervisorTimeout       = 60 * time.Second
-	defaultMemberWorkerTimeout     = 120 * time.Second
+	defaultSupervisorMaxIterations   = 10
+	defaultSupervisorTimeout         = 60 * time.Second
+	defaultMemberWorkerTimeout       = 120 * time.Second
+	defaultSupervisorLoopRepeatLimit = 3
+	defaultSupervisorContextKeep     = 6
 )
 
 type supervisorConfig struct {
@@ -25,6 +34,17 @@ type supervisorConfig struct {
 	InputPrompt    string   `json:"input_prompt"`
 	FinishTarget   string   `json:"finish_target"`
 	TimeoutSeconds int      `json:"timeout_seconds"`
+	// LoopRepeatLimit is how many consecutive repetitions of the same routing
+	// pattern force the supervisor out of the cycle. Negative disables it.
+	LoopRepeatLimit int `json:"loop_repeat_limit"`
+	// LoopTarget is the node to escalate to when a loop is detected. Empty
+	// means FINISH, which honors FinishTarget.
+	LoopTarget string `json:"loop_target"`
+	// ContextTokenBudget caps the estimated tokens of shared messages sent to
+	// the supervisor and its members. Zero disables context management.
+	ContextTokenBudget int    `json:"context_token_budget"`
+	ContextStrategy    string `json:"context_strategy"`
+	ContextKeepRecent  int    `json:"context_keep_recent"`
 }
 
 // SupervisorRoutingResult holds the outputs needed by BuildGraph to wire
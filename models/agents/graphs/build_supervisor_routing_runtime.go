@@ -3,7 +3,7 @@ package graphs
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/llms"
@@ -19,6 +19,7 @@ type supervisorRouter struct {
 	routeTool    llms.Tool
 	toolChoice   llms.ToolChoice
 	memberSet    map[string]struct{}
+	window       supervisorContextWindow
 }
 
 func newSupervisorRouter(snapshotNode *SnapshotNode, cfg supervisorConfig, model llms.Model) supervisorRouter {
@@ -43,33 +44,32 @@ func newSupervisorRouter(snapshotNode *SnapshotNode, cfg supervisorConfig, model
 			Function: &llms.FunctionReference{Name: "route"},
 		},
 		memberSet: memberSet,
+		window:    newSupervisorContextWindow(snapshotNode.Node.NodeKey, cfg, model),
 	}
 }
 
 func (r supervisorRouter) route(ctx context.Context, state map[string]any) (map[string]any, error) {
 	iteration := nextSupervisorIteration(state)
 	if iteration > r.cfg.MaxIterations {
-		log.Printf(
-			"graph supervisor_route_error node=%s iteration=%d error=%q limit=%d",
-			r.nodeKey,
-			iteration,
-			"max iterations reached",
-			r.cfg.MaxIterations,
+		slog.ErrorContext(ctx, "graph supervisor_route_error",
+			"node_key", r.nodeKey,
+			"iteration", iteration,
+			"err", "max iterations reached",
+			"limit", r.cfg.MaxIterations,
 		)
 		return nil, fmt.Errorf("supervisor node %q hit max iterations (%d)", r.nodeKey, r.cfg.MaxIterations)
 	}
 
-	inputMessages, initialHumanMessage, err := r.buildInputMessages(ctx, state, iteration)
+	inputMessages, initialHumanMessage, contextSummary, err := r.buildInputMessages(ctx, state, iteration)
 	if err != nil {
 		return nil, r.wrapRouteError(iteration, err)
 	}
 
-	log.Printf(
-		"graph supervisor_route node=%s iteration=%d members=%v message_count=%d",
-		r.nodeKey,
-		iteration,
-		r.cfg.Members,
-		len(inputMessages),
+	slog.InfoContext(ctx, "graph supervisor_route",
+		"node_key", r.nodeKey,
+		"iteration", iteration,
+		"members", r.cfg.Members,
+		"message_count", len(inputMessages),
 	)
 
 	next, err := r.routeNext(ctx, inputMessages)
@@ -77,10 +77,31 @@ func (r supervisorRouter) route(ctx context.Context, state map[string]any) (map[
 		return nil, r.wrapRouteError(iteration, err)
 	}
 
-	delta, err := r.buildDelta(state, iteration, next, initialHumanMessage)
+	routes := loadSupervisorRoutes(state, r.nodeKey)
+	if next != "FINISH" {
+		routes = append(routes, next)
+		if pattern, looped := detectSupervisorRoutingLoop(routes, r.cfg.LoopRepeatLimit); looped {
+			next = r.cfg.loopExitTarget()
+			// Start over so that when the loop target hands back to this
+			// supervisor, the escalated loop doesn't trip detection again.
+			routes = []string{}
+			slog.WarnContext(ctx, "graph supervisor_loop_detected",
+				"node_key", r.nodeKey,
+				"iteration", iteration,
+				"pattern", pattern,
+				"repeats", r.cfg.LoopRepeatLimit,
+				"next", next,
+			)
+		}
+	}
+
+	delta, err := r.buildDelta(state, iteration, next, routes, initialHumanMessage)
 	if err != nil {
 		return nil, r.wrapRouteError(iteration, err)
 	}
+	if contextSummary != nil {
+		delta[supervisorContextSummaryKey] = *contextSummary
+	}
 
 	r.logRouteDecision(iteration, next)
 	return delta, nil
@@ -101,7 +122,7 @@ func (r supervisorRouter) buildInputMessages(
 	ctx context.Context,
 	state map[string]any,
 	iteration int,
-) ([]llms.MessageContent, *llms.MessageContent, error) {
+) ([]llms.MessageContent, *llms.MessageContent, *supervisorContextSummary, error) {
 	inputMessages := []llms.MessageContent{
 		llms.TextParts(llms.ChatMessageTypeSystem, r.systemPrompt),
 	}
@@ -111,7 +132,7 @@ func (r supervisorRouter) buildInputMessages(
 		if r.inputKey != nil {
 			loadedInput, err := loadStateString(state, *r.inputKey)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			input = loadedInput
 		}
@@ -126,20 +147,21 @@ func (r supervisorRouter) buildInputMessages(
 			"Route this to the most appropriate specialist, then FINISH after the specialist responds.",
 		)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		inputMessages = append(inputMessages, humanMessage)
-		return inputMessages, &humanMessage, nil
+		return inputMessages, &humanMessage, nil, nil
 	}
 
 	messages, err := loadStateMessages(state, "messages")
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	inputMessages = append(inputMessages, messages...)
-	return inputMessages, nil, nil
+	fitted, contextSummary := r.window.fit(ctx, messages, loadSupervisorContextSummary(state))
+	inputMessages = append(inputMessages, fitted...)
+	return inputMessages, nil, contextSummary, nil
 }
 
 func (r supervisorRouter) routeNext(ctx context.Context, inputMessages []llms.MessageContent) (string, error) {
@@ -180,11 +202,13 @@ func (r supervisorRouter) buildDelta(
 	state map[string]any,
 	iteration int,
 	next string,
+	routes []string,
 	initialHumanMessage *llms.MessageContent,
 ) (map[string]any, error) {
 	delta := map[string]any{
-		supervisorNextKey:      next,
-		supervisorIterationKey: iteration,
+		supervisorNextKey:              next,
+		supervisorIterationKey:         iteration,
+		supervisorRoutesKey(r.nodeKey): routes,
 	}
 
 	routingMessage := llms.TextParts(
@@ -198,7 +222,7 @@ func (r supervisorRouter) buildDelta(
 		delta["messages"] = []llms.MessageContent{routingMessage}
 	}
 
-	if next != "FINISH" || r.outputKey == nil {
+	if _, isMember := r.memberSet[next]; isMember || r.outputKey == nil {
 		return delta, nil
 	}
 
@@ -216,32 +240,22 @@ func (r supervisorRouter) buildDelta(
 }
 
 func (r supervisorRouter) wrapRouteError(iteration int, err error) error {
-	log.Printf(
-		"graph supervisor_route_error node=%s iteration=%d error=%q",
-		r.nodeKey,
-		iteration,
-		err,
-	)
+	slog.Error("graph supervisor_route_error", "node_key", r.nodeKey, "iteration", iteration, "err", err)
 	return fmt.Errorf("supervisor node %q: %w", r.nodeKey, err)
 }
 
 func (r supervisorRouter) logRouteDecision(iteration int, next string) {
 	if next == "FINISH" {
-		log.Printf(
-			"graph supervisor_route node=%s iteration=%d next=FINISH total_iterations=%d",
-			r.nodeKey,
-			iteration,
-			iteration,
+		slog.Info("graph supervisor_route",
+			"node_key", r.nodeKey,
+			"iteration", iteration,
+			"next", "FINISH",
+			"total_iterations", iteration,
 		)
 		return
 	}
 
-	log.Printf(
-		"graph supervisor_route node=%s iteration=%d next=%s",
-		r.nodeKey,
-		iteration,
-		next,
-	)
+	slog.Info("graph supervisor_route", "node_key", r.nodeKey, "iteration", iteration, "next", next)
 }
 
 func nextSupervisorIteration(state map[string]any) int {
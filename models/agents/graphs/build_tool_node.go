@@ -3,10 +3,15 @@ package graphs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -19,6 +24,13 @@ const constPrefix = "_const:"
 // (e.g. "_const:OPENAI" → the string "OPENAI"). Non-string input_mapping values
 // are resolved recursively, which allows tool configs to provide structured
 // input such as objects or arrays that can still reference state keys.
+// State references and output_mapping keys may be JSON paths such as
+// "scope.project_ids", "matches[0].id" or "matches[*].id".
+// The assembled input is validated against the tool's inputSchema before the
+// call; with skip_if_missing, a node whose required inputs are absent is
+// skipped (it writes nothing) instead of failing the run. With fan_out, the
+// tool is called once per element of one list-valued input field and each
+// mapped output becomes a list in item order.
 func BuildToolNode(snapshotNode *SnapshotNode, mcpClient *clients.MCPClient) (*NodeToAdd, error) {
 	if len(snapshotNode.Tools) != 1 {
 		return nil, fmt.Errorf("tool node %q requires exactly 1 tool, got %d", snapshotNode.Node.NodeKey, len(snapshotNode.Tools))
@@ -32,6 +44,8 @@ func BuildToolNode(snapshotNode *SnapshotNode, mcpClient *clients.MCPClient) (*N
 	var config struct {
 		InputMapping  map[string]any    `json:"input_mapping"`
 		OutputMapping map[string]string `json:"output_mapping"`
+		SkipIfMissing bool              `json:"skip_if_missing"`
+		FanOut        *toolFanOutConfig `json:"fan_out"`
 	}
 	if err := json.Unmarshal([]byte(snapshotNode.Node.Config), &config); err != nil {
 		return nil, fmt.Errorf("tool node %q: invalid config json: %w", snapshotNode.Node.NodeKey, err)
@@ -42,10 +56,49 @@ func BuildToolNode(snapshotNode *SnapshotNode, mcpClient *clients.MCPClient) (*N
 	if err != nil {
 		return nil, fmt.Errorf("tool node %q: invalid input schema: %w", snapshotNode.Node.NodeKey, err)
 	}
+	inputSchema, err := parseToolInputSchema(dbTool.InputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("tool node %q: unsupported input schema: %w", snapshotNode.Node.NodeKey, err)
+	}
 	outputFields, err := schemaFieldNames(dbTool.OutputSchema)
 	if err != nil {
 		return nil, fmt.Errorf("tool node %q: invalid output schema: %w", snapshotNode.Node.NodeKey, err)
 	}
+	unknownPaths, err := validateToolOutputMapping(config.OutputMapping, outputFields)
+	if err != nil {
+		return nil, fmt.Errorf("tool node %q: %w", snapshotNode.Node.NodeKey, err)
+	}
+	for _, path := range unknownPaths {
+		slog.Warn("graph tool_output_mapping_ignored",
+			"node_key", snapshotNode.Node.NodeKey,
+			"tool", dbTool.Name,
+			"path", path,
+			"available_fields", describeFieldList(outputFields),
+		)
+	}
+	fanOut, err := parseToolFanOut(config.FanOut, inputFields)
+	if err != nil {
+		return nil, fmt.Errorf("tool node %q: %w", snapshotNode.Node.NodeKey, err)
+	}
+
+	callTool := func(ctx context.Context, input map[string]any) (map[string]any, error) {
+		if err := inputSchema.validate(input); err != nil {
+			return nil, err
+		}
+		result, err := mcpClient.CallTool(ctx, dbTool.Name, input)
+		if err != nil {
+			return nil, err
+		}
+		return decodeToolOutput(result, outputFields)
+	}
+	skipMissing := func(ctx context.Context, missing []string) (map[string]any, error) {
+		slog.InfoContext(ctx, "graph tool_skipped",
+			"node_key", snapshotNode.Node.NodeKey,
+			"tool", dbTool.Name,
+			"missing", strings.Join(missing, ","),
+		)
+		return map[string]any{}, nil
+	}
 
 	return &NodeToAdd{
 		Name:        snapshotNode.Node.NodeKey,
@@ -56,7 +109,11 @@ func BuildToolNode(snapshotNode *SnapshotNode, mcpClient *clients.MCPClient) (*N
 			toolInput := make(map[string]any)
 			for _, field := range inputFields {
 				if mapped, ok := config.InputMapping[field]; ok {
-					if v, ok := resolveToolInputMappingValue(mapped, state); ok {
+					v, ok, err := resolveToolInputMappingValue(mapped, state)
+					if err != nil {
+						return nil, fmt.Errorf("tool node %q: input_mapping %q: %w", snapshotNode.Node.NodeKey, field, err)
+					}
+					if ok {
 						toolInput[field] = v
 					}
 				} else if v, ok := state[field]; ok {
@@ -64,74 +121,212 @@ func BuildToolNode(snapshotNode *SnapshotNode, mcpClient *clients.MCPClient) (*N
 				}
 			}
 
-			result, err := mcpClient.CallTool(ctx, dbTool.Name, toolInput)
+			if fanOut != nil {
+				return runToolFanOutNode(ctx, snapshotNode.Node.NodeKey, fanOut, toolInput, inputSchema, config.SkipIfMissing, skipMissing, callTool, outputFields, config.OutputMapping)
+			}
+
+			toolOutput, err := callTool(ctx, toolInput)
 			if err != nil {
+				var inputErr *toolInputError
+				if config.SkipIfMissing && errors.As(err, &inputErr) && inputErr.onlyMissing() {
+					return skipMissing(ctx, inputErr.Missing)
+				}
 				return nil, fmt.Errorf("tool node %q: %w", snapshotNode.Node.NodeKey, err)
 			}
 
-			toolOutput, err := decodeToolOutput(result, outputFields)
+			delta, err := mapToolOutputToState(toolOutput, outputFields, config.OutputMapping)
 			if err != nil {
 				return nil, fmt.Errorf("tool node %q: %w", snapshotNode.Node.NodeKey, err)
 			}
-
-			return mapToolOutputToState(toolOutput, outputFields, config.OutputMapping), nil
+			return delta, nil
 		},
 	}, nil
 }
 
-func mapToolOutputToState(toolOutput map[string]any, outputFields []string, outputMapping map[string]string) map[string]any {
+func runToolFanOutNode(
+	ctx context.Context,
+	nodeKey string,
+	fanOut *toolFanOut,
+	toolInput map[string]any,
+	inputSchema *toolInputSchema,
+	skipIfMissing bool,
+	skipMissing func(ctx context.Context, missing []string) (map[string]any, error),
+	callTool toolCallFunc,
+	outputFields []string,
+	outputMapping map[string]string,
+) (map[string]any, error) {
+	value, ok := toolInput[fanOut.field]
+	if !ok {
+		if skipIfMissing {
+			return skipMissing(ctx, []string{fanOut.field})
+		}
+		return nil, fmt.Errorf("tool node %q: missing fan_out field %q", nodeKey, fanOut.field)
+	}
+	// Every item shares the other inputs, so a missing one skips the whole
+	// node here instead of failing each item's call.
+	if skipIfMissing {
+		if missing := inputSchema.missingRequired(toolInput); len(missing) > 0 {
+			return skipMissing(ctx, missing)
+		}
+	}
+	items, err := fanOutItems(fanOut.field, value)
+	if err != nil {
+		return nil, fmt.Errorf("tool node %q: %w", nodeKey, err)
+	}
+
+	results, err := fanOut.run(ctx, nodeKey, toolInput, items, callTool)
+	if err != nil {
+		return nil, fmt.Errorf("tool node %q: %w", nodeKey, err)
+	}
+
+	delta, err := fanOutDelta(nodeKey, results, outputFields, outputMapping)
+	if err != nil {
+		return nil, fmt.Errorf("tool node %q: %w", nodeKey, err)
+	}
+	return delta, nil
+}
+
+// validateToolOutputMapping checks that every output_mapping path parses and
+// starts at a field name. It returns the paths whose first field is not one of
+// the tool's declared output fields. Those are skipped when output is mapped,
+// so graphs saved before the tool's schema changed still build; the editor
+// rejects them when the graph is next saved.
+func validateToolOutputMapping(outputMapping map[string]string, outputFields []string) ([]string, error) {
+	var unknownPaths []string
+	for _, path := range sortedStringMapKeys(outputMapping) {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("output_mapping: %w", err)
+		}
+		if segments[0].isIndex || segments[0].wildcard {
+			return nil, fmt.Errorf("output_mapping %q must start with an output field name", path)
+		}
+		if !slices.Contains(outputFields, segments[0].key) {
+			unknownPaths = append(unknownPaths, path)
+		}
+	}
+	return unknownPaths, nil
+}
+
+// mapToolOutputToState copies tool output into a state delta. Without
+// output_mapping every declared output field is written under its own name;
+// with it, only the mapped paths are written. A mapped path whose top-level
+// field is absent from the output is skipped, matching optional output fields.
+func mapToolOutputToState(toolOutput map[string]any, outputFields []string, outputMapping map[string]string) (map[string]any, error) {
 	delta := make(map[string]any)
-	restrictToMappedOutputs := len(outputMapping) > 0
+	if len(outputMapping) == 0 {
+		for _, field := range outputFields {
+			if v, ok := toolOutput[field]; ok {
+				delta[field] = v
+			}
+		}
+		return delta, nil
+	}
 
-	for _, field := range outputFields {
-		stateKey, isMapped := outputMapping[field]
-		if restrictToMappedOutputs && !isMapped {
+	for _, path := range sortedStringMapKeys(outputMapping) {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("output_mapping: %w", err)
+		}
+		if segments[0].isIndex || segments[0].wildcard || !slices.Contains(outputFields, segments[0].key) {
 			continue
 		}
-		if !isMapped {
-			stateKey = field
+		if _, ok := toolOutput[segments[0].key]; !ok {
+			continue
 		}
 
-		if v, ok := toolOutput[field]; ok {
-			delta[stateKey] = v
+		v, err := resolveJSONPath(toolOutput, segments)
+		if err != nil {
+			return nil, fmt.Errorf("output_mapping %q: %w", path, err)
 		}
+		delta[outputMapping[path]] = v
 	}
 
-	return delta
+	return delta, nil
 }
 
-func resolveToolInputMappingValue(mappingValue any, state map[string]any) (any, bool) {
+func resolveToolInputMappingValue(mappingValue any, state map[string]any) (any, bool, error) {
 	switch value := mappingValue.(type) {
 	case string:
 		if strings.HasPrefix(value, constPrefix) {
-			return strings.TrimPrefix(value, constPrefix), true
+			return strings.TrimPrefix(value, constPrefix), true, nil
 		}
 
-		resolved, exists := state[value]
-		return resolved, exists
+		return resolveToolStateReference(value, state)
 	case map[string]any:
 		resolved := make(map[string]any, len(value))
-		for key, item := range value {
-			next, ok := resolveToolInputMappingValue(item, state)
-			if !ok {
-				return nil, false
+		for _, key := range sortedJSONKeys(value) {
+			next, ok, err := resolveToolInputMappingValue(value[key], state)
+			if err != nil || !ok {
+				return nil, false, err
 			}
 			resolved[key] = next
 		}
-		return resolved, true
+		return resolved, true, nil
 	case []any:
 		resolved := make([]any, 0, len(value))
 		for _, item := range value {
-			next, ok := resolveToolInputMappingValue(item, state)
-			if !ok {
-				return nil, false
+			next, ok, err := resolveToolInputMappingValue(item, state)
+			if err != nil || !ok {
+				return nil, false, err
 			}
 			resolved = append(resolved, next)
 		}
-		return resolved, true
+		return resolved, true, nil
 	default:
-		return mappingValue, true
+		return mappingValue, true, nil
+	}
+}
+
+// resolveToolStateReference resolves a state key, or a JSON path into state.
+// As with plain keys, a path whose top-level key is missing from state reports
+// !exists so the input field is omitted; a path that breaks further down is an
+// error naming where it stopped.
+func resolveToolStateReference(reference string, state map[string]any) (any, bool, error) {
+	if resolved, exists := state[reference]; exists {
+		return resolved, true, nil
+	}
+
+	segments, err := parseJSONPath(reference)
+	if err != nil || len(segments) < 2 || segments[0].isIndex || segments[0].wildcard {
+		return nil, false, nil
+	}
+	if _, exists := state[segments[0].key]; !exists {
+		return nil, false, nil
+	}
+
+	resolved, err := resolveJSONPath(state, segments)
+	if err != nil {
+		return nil, false, fmt.Errorf("path %q: %w", reference, err)
+	}
+	return resolved, true, nil
+}
+
+func sortedStringMapKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func describeFieldList(fields []string) string {
+	if len(fields) == 0 {
+		return "none"
+	}
+	sorted := append([]string(nil), fields...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+// toolResultErrorCode reads the error code an MCP tool attached to a failed
+// result's _meta, falling back to tool_failed.
+func toolResultErrorCode(result *mcp.CallToolResult) errorcodes.Code {
+	if value, ok := result.Meta[errorcodes.MetaKey].(string); ok {
+		return errorcodes.Parse(value)
 	}
+	return errorcodes.ToolFailed
 }
 
 func decodeToolOutput(result *mcp.CallToolResult, outputFields []string) (map[string]any, error) {
@@ -142,7 +337,10 @@ func decodeToolOutput(result *mcp.CallToolResult, outputFields []string) (map[st
 		return nil, fmt.Errorf("tool returned no result")
 	}
 	if result.IsError {
-		return nil, fmt.Errorf("tool returned error: %s", toolErrorText(result))
+		return nil, errorcodes.Wrap(
+			toolResultErrorCode(result),
+			fmt.Errorf("tool returned error: %s", toolErrorText(result)),
+		)
 	}
 
 	if parsed, ok, err := parseStructuredOutput(result.StructuredContent); err != nil {
@@ -0,0 +1,244 @@
+package graphs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+)
+
+const (
+	toolFanOutStateKeyPrefix   = "__fan_out_"
+	defaultToolFanOutParallel  = 4
+	maxToolFanOutParallel      = 16
+	toolFanOutOnErrorFailFast  = "fail_fast"
+	toolFanOutOnErrorCollect   = "collect"
+	toolFanOutItemStatusOK     = "completed"
+	toolFanOutItemStatusFailed = "failed"
+)
+
+// toolFanOutConfig is the "fan_out" block of a tool node config:
+//
+//	{"fan_out": {"field": "document_id", "concurrency": 4, "on_error": "collect"}}
+type toolFanOutConfig struct {
+	Field       string `json:"field"`
+	Concurrency int    `json:"concurrency"`
+	OnError     string `json:"on_error"`
+}
+
+// toolFanOut calls a tool once per element of one list-valued input field.
+type toolFanOut struct {
+	field         string
+	concurrency   int
+	collectErrors bool
+}
+
+// toolFanOutItem is the per-item record written to the node's fan-out state
+// key, which makes every item call visible in the run step's state delta.
+type toolFanOutItem struct {
+	Index      int            `json:"index"`
+	Input      any            `json:"input"`
+	Status     string         `json:"status"`
+	Output     map[string]any `json:"output,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	ErrorCode  string         `json:"error_code,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+}
+
+type toolCallFunc func(ctx context.Context, input map[string]any) (map[string]any, error)
+
+func parseToolFanOut(config *toolFanOutConfig, inputFields []string) (*toolFanOut, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	field := strings.TrimSpace(config.Field)
+	if field == "" {
+		return nil, fmt.Errorf("fan_out.field is required")
+	}
+	if !slices.Contains(inputFields, field) {
+		return nil, fmt.Errorf(
+			"fan_out.field %q is not an input field of the tool (available fields: %s)",
+			field,
+			describeFieldList(inputFields),
+		)
+	}
+
+	concurrency := config.Concurrency
+	switch {
+	case concurrency == 0:
+		concurrency = defaultToolFanOutParallel
+	case concurrency < 0 || concurrency > maxToolFanOutParallel:
+		return nil, fmt.Errorf("fan_out.concurrency must be between 1 and %d", maxToolFanOutParallel)
+	}
+
+	onError := strings.TrimSpace(config.OnError)
+	switch onError {
+	case "", toolFanOutOnErrorFailFast, toolFanOutOnErrorCollect:
+	default:
+		return nil, fmt.Errorf(
+			"fan_out.on_error must be %q or %q, got %q",
+			toolFanOutOnErrorFailFast,
+			toolFanOutOnErrorCollect,
+			onError,
+		)
+	}
+
+	return &toolFanOut{
+		field:         field,
+		concurrency:   concurrency,
+		collectErrors: onError == toolFanOutOnErrorCollect,
+	}, nil
+}
+
+func toolFanOutStateKey(nodeKey string) string {
+	return toolFanOutStateKeyPrefix + nodeKey
+}
+
+// fanOutItems returns the list held by the fan-out field. Go slices of any
+// element type are accepted by round-tripping through JSON.
+func fanOutItems(field string, value any) ([]any, error) {
+	if items, ok := value.([]any); ok {
+		return items, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("fan_out field %q could not be encoded: %w", field, err)
+	}
+	var items []any
+	if err := json.Unmarshal(encoded, &items); err != nil {
+		return nil, fmt.Errorf("fan_out field %q must be a list, got %s", field, describeJSONValue(value))
+	}
+	return items, nil
+}
+
+// run calls the tool once per item with at most f.concurrency calls in flight.
+// Results keep the item order. With fail-fast, the first failure cancels the
+// calls still pending and is returned; with collect-errors every item runs and
+// failures are recorded on their item. Either way, cancelling ctx fails the
+// fan-out.
+func (f *toolFanOut) run(
+	ctx context.Context,
+	nodeKey string,
+	baseInput map[string]any,
+	items []any,
+	call toolCallFunc,
+) ([]toolFanOutItem, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]toolFanOutItem, len(items))
+	semaphore := make(chan struct{}, f.concurrency)
+	var wg sync.WaitGroup
+	var failOnce sync.Once
+	var firstErr error
+
+	for index, item := range items {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, item any) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			input := make(map[string]any, len(baseInput))
+			for key, value := range baseInput {
+				input[key] = value
+			}
+			input[f.field] = item
+
+			started := time.Now()
+			output, err := call(ctx, input)
+			result := toolFanOutItem{
+				Index:      index,
+				Input:      item,
+				Status:     toolFanOutItemStatusOK,
+				Output:     output,
+				DurationMS: time.Since(started).Milliseconds(),
+			}
+			if err != nil {
+				result.Status = toolFanOutItemStatusFailed
+				result.Output = nil
+				result.Error = err.Error()
+				result.ErrorCode = string(errorcodes.Of(err))
+				if !f.collectErrors {
+					failOnce.Do(func() {
+						firstErr = fmt.Errorf("item %d: %w", index, err)
+						cancel()
+					})
+				}
+			}
+			results[index] = result
+
+			slog.InfoContext(ctx, "graph tool_item",
+				"node_key", nodeKey,
+				"index", index,
+				"items", len(items),
+				"status", result.Status,
+				"duration_ms", result.DurationMS,
+			)
+		}(index, item)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// Collect mode never cancels ctx itself, so an error here means the
+	// caller gave up and some items never ran.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// fanOutDelta maps every item's output to state and collects each state key
+// into a list aligned with the input items. Failed items contribute nil.
+func fanOutDelta(
+	nodeKey string,
+	results []toolFanOutItem,
+	outputFields []string,
+	outputMapping map[string]string,
+) (map[string]any, error) {
+	stateKeys := outputFields
+	if len(outputMapping) > 0 {
+		stateKeys = make([]string, 0, len(outputMapping))
+		for _, path := range sortedStringMapKeys(outputMapping) {
+			stateKeys = append(stateKeys, outputMapping[path])
+		}
+	}
+
+	delta := make(map[string]any, len(stateKeys)+1)
+	for _, stateKey := range stateKeys {
+		delta[stateKey] = make([]any, len(results))
+	}
+
+	for index, result := range results {
+		if result.Status != toolFanOutItemStatusOK {
+			continue
+		}
+		itemDelta, err := mapToolOutputToState(result.Output, outputFields, outputMapping)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", index, err)
+		}
+		for stateKey, value := range itemDelta {
+			delta[stateKey].([]any)[index] = value
+		}
+	}
+
+	delta[toolFanOutStateKey(nodeKey)] = results
+	return delta, nil
+}
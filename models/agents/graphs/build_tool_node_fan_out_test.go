@@ -0,0 +1,224 @@
+package graphs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseToolFanOut_ValidatesConfig(t *testing.T) {
+	inputFields := []string{"document_id", "mode"}
+
+	fanOut, err := parseToolFanOut(&toolFanOutConfig{Field: "document_id"}, inputFields)
+	if err != nil {
+		t.Fatalf("parseToolFanOut returned error: %v", err)
+	}
+	if fanOut.concurrency != defaultToolFanOutParallel || fanOut.collectErrors {
+		t.Fatalf("unexpected defaults: %+v", fanOut)
+	}
+
+	tests := []struct {
+		config  toolFanOutConfig
+		wantErr string
+	}{
+		{config: toolFanOutConfig{}, wantErr: "fan_out.field is required"},
+		{
+			config:  toolFanOutConfig{Field: "document_ids"},
+			wantErr: `fan_out.field "document_ids" is not an input field of the tool (available fields: document_id, mode)`,
+		},
+		{
+			config:  toolFanOutConfig{Field: "document_id", Concurrency: 64},
+			wantErr: "fan_out.concurrency must be between 1 and 16",
+		},
+		{
+			config:  toolFanOutConfig{Field: "document_id", OnError: "ignore"},
+			wantErr: `fan_out.on_error must be "fail_fast" or "collect", got "ignore"`,
+		},
+	}
+	for _, test := range tests {
+		config := test.config
+		if _, err := parseToolFanOut(&config, inputFields); err == nil || err.Error() != test.wantErr {
+			t.Fatalf("expected %q, got %v", test.wantErr, err)
+		}
+	}
+}
+
+func TestToolFanOutRun_KeepsItemOrderAndBoundsConcurrency(t *testing.T) {
+	fanOut := &toolFanOut{field: "document_id", concurrency: 2}
+	var inFlight, peak atomic.Int32
+
+	results, err := fanOut.run(
+		context.Background(),
+		"describe_documents",
+		map[string]any{"mode": "fast"},
+		[]any{"doc-1", "doc-2", "doc-3", "doc-4", "doc-5"},
+		func(ctx context.Context, input map[string]any) (map[string]any, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				previous := peak.Load()
+				if current <= previous || peak.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return map[string]any{"text": input["document_id"].(string) + ":" + input["mode"].(string)}, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("expected at most 2 calls in flight, got %d", got)
+	}
+	for index, result := range results {
+		want := map[string]any{"text": results[index].Input.(string) + ":fast"}
+		if result.Index != index || result.Status != toolFanOutItemStatusOK || !reflect.DeepEqual(result.Output, want) {
+			t.Fatalf("unexpected result %d: %+v", index, result)
+		}
+	}
+	if results[4].Input != "doc-5" {
+		t.Fatalf("expected results in item order, got %+v", results)
+	}
+}
+
+func TestToolFanOutRun_HandlesItemErrors(t *testing.T) {
+	call := func(ctx context.Context, input map[string]any) (map[string]any, error) {
+		if input["document_id"] == "doc-2" {
+			return nil, errors.New("document not found")
+		}
+		return map[string]any{"text": "ok"}, nil
+	}
+	items := []any{"doc-1", "doc-2", "doc-3"}
+
+	failFast := &toolFanOut{field: "document_id", concurrency: 1}
+	_, err := failFast.run(context.Background(), "describe_documents", nil, items, call)
+	if err == nil || err.Error() != "item 1: document not found" {
+		t.Fatalf("expected fail-fast item error, got %v", err)
+	}
+
+	collect := &toolFanOut{field: "document_id", concurrency: 1, collectErrors: true}
+	results, err := collect.run(context.Background(), "describe_documents", nil, items, call)
+	if err != nil {
+		t.Fatalf("expected collect mode to succeed, got %v", err)
+	}
+	statuses := []string{results[0].Status, results[1].Status, results[2].Status}
+	wantStatuses := []string{toolFanOutItemStatusOK, toolFanOutItemStatusFailed, toolFanOutItemStatusOK}
+	if !reflect.DeepEqual(statuses, wantStatuses) || results[1].Error != "document not found" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestToolFanOutRun_CollectFailsWhenParentIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+
+	collect := &toolFanOut{field: "document_id", concurrency: 1, collectErrors: true}
+	results, err := collect.run(ctx, "describe_documents", nil, []any{"doc-1", "doc-2", "doc-3"},
+		func(ctx context.Context, input map[string]any) (map[string]any, error) {
+			calls.Add(1)
+			cancel()
+			return map[string]any{"text": "ok"}, nil
+		},
+	)
+	if !errors.Is(err, context.Canceled) || results != nil {
+		t.Fatalf("expected the cancelled fan-out to fail, got %+v, %v", results, err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected no items to start after cancellation, got %d calls", calls.Load())
+	}
+}
+
+func TestFanOutDelta_CollectsOrderedLists(t *testing.T) {
+	results := []toolFanOutItem{
+		{Index: 0, Input: "doc-1", Status: toolFanOutItemStatusOK, Output: map[string]any{"description_id": "desc-1", "text": "a"}},
+		{Index: 1, Input: "doc-2", Status: toolFanOutItemStatusFailed, Error: "document not found"},
+		{Index: 2, Input: "doc-3", Status: toolFanOutItemStatusOK, Output: map[string]any{"description_id": "desc-3", "text": "c"}},
+	}
+
+	delta, err := fanOutDelta(
+		"describe_documents",
+		results,
+		[]string{"description_id", "text"},
+		map[string]string{"description_id": "description_ids"},
+	)
+	if err != nil {
+		t.Fatalf("fanOutDelta returned error: %v", err)
+	}
+
+	if got, want := delta["description_ids"], []any{"desc-1", nil, "desc-3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if _, ok := delta["text"]; ok {
+		t.Fatalf("expected unmapped output fields to be omitted, got %v", delta)
+	}
+	if got := delta[toolFanOutStateKey("describe_documents")]; !reflect.DeepEqual(got, results) {
+		t.Fatalf("expected item records in delta, got %v", got)
+	}
+}
+
+func TestFanOutItems_RequiresList(t *testing.T) {
+	items, err := fanOutItems("document_id", []string{"doc-1", "doc-2"})
+	if err != nil || !reflect.DeepEqual(items, []any{"doc-1", "doc-2"}) {
+		t.Fatalf("expected typed slice to be accepted, got %v %v", items, err)
+	}
+
+	_, err = fanOutItems("document_id", "doc-1")
+	if err == nil || !strings.Contains(err.Error(), `fan_out field "document_id" must be a list, got a string`) {
+		t.Fatalf("expected list error, got %v", err)
+	}
+}
+
+func TestToolFanOutRun_ValidatesRefSchemasConcurrently(t *testing.T) {
+	inputSchema, err := parseToolInputSchema(`{
+		"type": "object",
+		"required": ["document"],
+		"properties": {"document": {"$ref": "#/$defs/document"}},
+		"$defs": {
+			"document": {
+				"type": "object",
+				"required": ["id"],
+				"properties": {"id": {"type": "string"}, "parent": {"$ref": "#/$defs/document"}}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("parseToolInputSchema returned error: %v", err)
+	}
+
+	items := make([]any, 32)
+	for index := range items {
+		items[index] = map[string]any{"id": "doc", "parent": map[string]any{"id": "root"}}
+	}
+	items[7] = map[string]any{"id": 7}
+
+	fanOut := &toolFanOut{field: "document", concurrency: 16, collectErrors: true}
+	results, err := fanOut.run(context.Background(), "describe_documents", nil, items,
+		func(ctx context.Context, input map[string]any) (map[string]any, error) {
+			if err := inputSchema.validate(input); err != nil {
+				return nil, err
+			}
+			return map[string]any{"text": "ok"}, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	for index, result := range results {
+		wantStatus := toolFanOutItemStatusOK
+		if index == 7 {
+			wantStatus = toolFanOutItemStatusFailed
+		}
+		if result.Status != wantStatus {
+			t.Fatalf("unexpected result %d: %+v", index, result)
+		}
+	}
+	if !strings.Contains(results[7].Error, "document.id") {
+		t.Fatalf("expected the failing field to be named, got %q", results[7].Error)
+	}
+}
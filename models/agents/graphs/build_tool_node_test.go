@@ -1,10 +1,15 @@
 package graphs
 
 import (
+	"context"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -25,6 +30,27 @@ func TestDecodeToolOutput_IsError(t *testing.T) {
 	}
 }
 
+func TestDecodeToolOutput_ReadsErrorCode(t *testing.T) {
+	result := &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "failed to find model REPLICATE/openai/clip"},
+		},
+		Meta: mcp.Meta{errorcodes.MetaKey: "model_not_found"},
+	}
+
+	_, err := decodeToolOutput(result, []string{"embedding_id"})
+	if got := errorcodes.Of(err); got != errorcodes.ModelNotFound {
+		t.Fatalf("expected model_not_found, got %q (%v)", got, err)
+	}
+
+	result.Meta = nil
+	_, err = decodeToolOutput(result, []string{"embedding_id"})
+	if got := errorcodes.Of(err); got != errorcodes.ToolFailed {
+		t.Fatalf("expected tool_failed without a code, got %q", got)
+	}
+}
+
 func TestDecodeToolOutput_ParsesStructuredOutput(t *testing.T) {
 	result := &mcp.CallToolResult{
 		StructuredContent: map[string]any{
@@ -73,12 +99,12 @@ func TestResolveToolInputMappingValue_ObjectLiteral(t *testing.T) {
 		"temp_url": "https://example.com/image.png",
 	}
 
-	got, ok := resolveToolInputMappingValue(map[string]any{
+	got, ok, err := resolveToolInputMappingValue(map[string]any{
 		"text_prompt": "_const:passport",
 		"threshold":   0.5,
 	}, state)
-	if !ok {
-		t.Fatal("expected literal object mapping to resolve")
+	if err != nil || !ok {
+		t.Fatalf("expected literal object mapping to resolve: %v", err)
 	}
 
 	want := map[string]any{
@@ -95,12 +121,12 @@ func TestResolveToolInputMappingValue_NestedStateLookup(t *testing.T) {
 		"segmentation_prompt": "rocky shoreline",
 	}
 
-	got, ok := resolveToolInputMappingValue(map[string]any{
+	got, ok, err := resolveToolInputMappingValue(map[string]any{
 		"text_prompt": "segmentation_prompt",
 		"mode":        "_const:fast",
 	}, state)
-	if !ok {
-		t.Fatal("expected nested state lookup to resolve")
+	if err != nil || !ok {
+		t.Fatalf("expected nested state lookup to resolve: %v", err)
 	}
 
 	want := map[string]any{
@@ -117,9 +143,9 @@ func TestResolveToolInputMappingValue_StateLookup(t *testing.T) {
 		"temp_url": "https://example.com/image.png",
 	}
 
-	got, ok := resolveToolInputMappingValue("temp_url", state)
-	if !ok {
-		t.Fatal("expected state lookup to resolve")
+	got, ok, err := resolveToolInputMappingValue("temp_url", state)
+	if err != nil || !ok {
+		t.Fatalf("expected state lookup to resolve: %v", err)
 	}
 	if got != "https://example.com/image.png" {
 		t.Fatalf("unexpected mapped value: %v", got)
@@ -127,9 +153,9 @@ func TestResolveToolInputMappingValue_StateLookup(t *testing.T) {
 }
 
 func TestResolveToolInputMappingValue_StringConstant(t *testing.T) {
-	got, ok := resolveToolInputMappingValue("_const:REPLICATE", map[string]any{})
-	if !ok {
-		t.Fatal("expected string constant to resolve")
+	got, ok, err := resolveToolInputMappingValue("_const:REPLICATE", map[string]any{})
+	if err != nil || !ok {
+		t.Fatalf("expected string constant to resolve: %v", err)
 	}
 	if got != "REPLICATE" {
 		t.Fatalf("expected REPLICATE, got %v", got)
@@ -137,7 +163,7 @@ func TestResolveToolInputMappingValue_StringConstant(t *testing.T) {
 }
 
 func TestMapToolOutputToState_DefaultsToSchemaFieldNames(t *testing.T) {
-	got := mapToolOutputToState(
+	got, err := mapToolOutputToState(
 		map[string]any{
 			"description_id": "desc-123",
 			"text":           "saved text",
@@ -146,6 +172,10 @@ func TestMapToolOutputToState_DefaultsToSchemaFieldNames(t *testing.T) {
 		nil,
 	)
 
+	if err != nil {
+		t.Fatalf("mapToolOutputToState returned error: %v", err)
+	}
+
 	want := map[string]any{
 		"description_id": "desc-123",
 		"text":           "saved text",
@@ -156,7 +186,7 @@ func TestMapToolOutputToState_DefaultsToSchemaFieldNames(t *testing.T) {
 }
 
 func TestMapToolOutputToState_RestrictsToExplicitMappings(t *testing.T) {
-	got := mapToolOutputToState(
+	got, err := mapToolOutputToState(
 		map[string]any{
 			"description_id": "desc-123",
 			"text":           "saved text",
@@ -167,6 +197,10 @@ func TestMapToolOutputToState_RestrictsToExplicitMappings(t *testing.T) {
 		},
 	)
 
+	if err != nil {
+		t.Fatalf("mapToolOutputToState returned error: %v", err)
+	}
+
 	want := map[string]any{
 		"document_description_id": "desc-123",
 	}
@@ -174,3 +208,221 @@ func TestMapToolOutputToState_RestrictsToExplicitMappings(t *testing.T) {
 		t.Fatalf("expected %v, got %v", want, got)
 	}
 }
+
+func TestMapToolOutputToState_ResolvesPathsAndWildcards(t *testing.T) {
+	toolOutput := map[string]any{
+		"matches": []any{
+			map[string]any{"id": "doc-1", "score": 0.91},
+			map[string]any{"id": "doc-2", "score": 0.72},
+		},
+		"count": 2.0,
+	}
+
+	got, err := mapToolOutputToState(
+		toolOutput,
+		[]string{"matches", "count"},
+		map[string]string{
+			"matches[0].id":    "best_match_id",
+			"matches[*].score": "match_scores",
+			"count":            "match_count",
+		},
+	)
+	if err != nil {
+		t.Fatalf("mapToolOutputToState returned error: %v", err)
+	}
+
+	want := map[string]any{
+		"best_match_id": "doc-1",
+		"match_scores":  []any{0.91, 0.72},
+		"match_count":   2.0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMapToolOutputToState_ReportsBrokenPath(t *testing.T) {
+	_, err := mapToolOutputToState(
+		map[string]any{"matches": []any{map[string]any{"id": "doc-1", "score": 0.91}}},
+		[]string{"matches"},
+		map[string]string{"matches[0].document_id": "best_match_id"},
+	)
+	if err == nil {
+		t.Fatal("expected broken output path to fail")
+	}
+	want := `output_mapping "matches[0].document_id": no field "document_id" at "matches[0]" (available fields: id, score)`
+	if err.Error() != want {
+		t.Fatalf("expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestValidateToolOutputMapping_ReturnsUnknownFields(t *testing.T) {
+	unknownPaths, err := validateToolOutputMapping(
+		map[string]string{"match[0].id": "best_match_id", "count": "match_count"},
+		[]string{"matches", "count"},
+	)
+	if err != nil {
+		t.Fatalf("expected unknown output fields not to fail, got %v", err)
+	}
+	if !slices.Equal(unknownPaths, []string{"match[0].id"}) {
+		t.Fatalf("expected only match[0].id to be unknown, got %v", unknownPaths)
+	}
+}
+
+func TestValidateToolOutputMapping_RejectsPathWithoutField(t *testing.T) {
+	_, err := validateToolOutputMapping(
+		map[string]string{"[0].id": "best_match_id"},
+		[]string{"matches"},
+	)
+	want := `output_mapping "[0].id" must start with an output field name`
+	if err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+}
+
+func TestResolveToolInputMappingValue_StatePaths(t *testing.T) {
+	state := map[string]any{
+		"scope": map[string]any{
+			"project_ids": []any{"p-1", "p-2"},
+		},
+		"documents": []any{
+			map[string]any{"id": "doc-1"},
+			map[string]any{"id": "doc-2"},
+		},
+	}
+
+	got, ok, err := resolveToolInputMappingValue(map[string]any{
+		"project_ids":  "scope.project_ids",
+		"first_id":     "documents[0].id",
+		"document_ids": "documents[*].id",
+	}, state)
+	if err != nil || !ok {
+		t.Fatalf("expected state paths to resolve: ok=%v err=%v", ok, err)
+	}
+
+	want := map[string]any{
+		"project_ids":  []any{"p-1", "p-2"},
+		"first_id":     "doc-1",
+		"document_ids": []any{"doc-1", "doc-2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if _, ok, err := resolveToolInputMappingValue("missing.project_ids", state); err != nil || ok {
+		t.Fatalf("expected missing top-level key to be omitted, got ok=%v err=%v", ok, err)
+	}
+
+	_, _, err = resolveToolInputMappingValue("scope.project_idz", state)
+	want2 := `path "scope.project_idz": no field "project_idz" at "scope" (available fields: project_ids)`
+	if err == nil || err.Error() != want2 {
+		t.Fatalf("expected %q, got %v", want2, err)
+	}
+}
+
+func TestToolInputSchema_ReportsEveryInvalidField(t *testing.T) {
+	schema, err := parseToolInputSchema(`{"type":"object","required":["document_id","mode"],"properties":{"document_id":{"type":"string","x-order":0},"mode":{"type":"string","enum":["fast","accurate"]},"limit":{"type":"integer","minimum":1}}}`)
+	if err != nil {
+		t.Fatalf("parseToolInputSchema returned error: %v", err)
+	}
+
+	if err := schema.validate(map[string]any{"document_id": "doc-1", "mode": "fast", "limit": 5}); err != nil {
+		t.Fatalf("expected valid input, got %v", err)
+	}
+
+	err = schema.validate(map[string]any{"mode": "slow", "limit": 0})
+	want := `invalid tool input: missing required field "document_id"; field "limit" must be at least 1; field "mode" must be one of [fast accurate]`
+	if err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+}
+
+func TestBuildToolNode_ValidatesInputBeforeCallingTool(t *testing.T) {
+	t.Setenv("MCP_SERVER_URL", "http://127.0.0.1:0/mcp")
+	mcpClient, err := clients.NewMCPClient()
+	if err != nil {
+		t.Fatalf("NewMCPClient returned error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "fails with field errors",
+			config:  `{}`,
+			wantErr: `tool node "save_description": invalid tool input: missing required field "document_id"`,
+		},
+		{
+			name:   "skips when required inputs are missing",
+			config: `{"skip_if_missing":true}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node, err := BuildToolNode(&SnapshotNode{
+				Node: &dbmodels.AgentGraphNode{
+					NodeKey:  "save_description",
+					NodeType: "tool",
+					Config:   test.config,
+				},
+				Tools: []*dbmodels.Tool{{
+					Name:         "create_document_description",
+					InputSchema:  `{"type":"object","required":["document_id","text"],"properties":{"document_id":{"type":"string"},"text":{"type":"string"}}}`,
+					OutputSchema: `{"type":"object","properties":{"description_id":{"type":"string"}}}`,
+				}},
+			}, mcpClient)
+			if err != nil {
+				t.Fatalf("BuildToolNode returned error: %v", err)
+			}
+
+			delta, err := node.Fn(context.Background(), map[string]any{"text": "A rusty hinge."})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected node to be skipped, got %v", err)
+			}
+			if len(delta) != 0 {
+				t.Fatalf("expected empty delta when skipped, got %v", delta)
+			}
+		})
+	}
+}
+
+func TestBuildToolNode_FanOutSkipsWhenSharedInputsAreMissing(t *testing.T) {
+	t.Setenv("MCP_SERVER_URL", "http://127.0.0.1:0/mcp")
+	mcpClient, err := clients.NewMCPClient()
+	if err != nil {
+		t.Fatalf("NewMCPClient returned error: %v", err)
+	}
+
+	node, err := BuildToolNode(&SnapshotNode{
+		Node: &dbmodels.AgentGraphNode{
+			NodeKey:  "save_descriptions",
+			NodeType: "tool",
+			Config:   `{"skip_if_missing":true,"fan_out":{"field":"text"}}`,
+		},
+		Tools: []*dbmodels.Tool{{
+			Name:         "create_document_description",
+			InputSchema:  `{"type":"object","required":["document_id","text"],"properties":{"document_id":{"type":"string"},"text":{"type":"string"}}}`,
+			OutputSchema: `{"type":"object","properties":{"description_id":{"type":"string"}}}`,
+		}},
+	}, mcpClient)
+	if err != nil {
+		t.Fatalf("BuildToolNode returned error: %v", err)
+	}
+
+	delta, err := node.Fn(context.Background(), map[string]any{"text": []any{"A rusty hinge.", "A bent nail."}})
+	if err != nil {
+		t.Fatalf("expected node to be skipped, got %v", err)
+	}
+	if len(delta) != 0 {
+		t.Fatalf("expected empty delta when skipped, got %v", delta)
+	}
+}
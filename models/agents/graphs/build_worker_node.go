@@ -3,10 +3,11 @@ package graphs
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
 	agenttools "github.com/arcnem-ai/arcnem-vision/models/agents/tools"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -26,6 +27,10 @@ func BuildWorkerNode(snapshotNode *SnapshotNode, modelClient any, mcpClient *cli
 		return nil, err
 	}
 	outputRetries := outputRetryCount(workerConfig)
+	outputMappings, err := parseWorkerOutputMappings(workerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("worker node %q: %w", snapshotNode.Node.NodeKey, err)
+	}
 	inputConfig, err := parseNodeInputConfig(snapshotNode.Node.Config)
 	if err != nil {
 		return nil, fmt.Errorf("worker node %q: invalid input config json: %w", snapshotNode.Node.NodeKey, err)
@@ -50,38 +55,36 @@ func BuildWorkerNode(snapshotNode *SnapshotNode, modelClient any, mcpClient *cli
 					return nil, fmt.Errorf("worker node %q: %w", snapshotNode.Node.NodeKey, err)
 				}
 			}
-			log.Printf(
-				"graph worker start node=%s max_iterations=%d input_len=%d",
-				snapshotNode.Node.NodeKey,
-				maxIterations,
-				len(input),
+			slog.InfoContext(ctx, "graph worker start",
+				"node_key", snapshotNode.Node.NodeKey,
+				"max_iterations", maxIterations,
+				"input_len", len(input),
 			)
 
 			humanMessage, err := buildHumanInputMessage(ctx, input, inputConfig, "Analyze this image and provide a detailed description.")
 			if err != nil {
-				log.Printf(
-					"graph worker input_prepare_error node=%s err=%v",
-					snapshotNode.Node.NodeKey,
-					err,
+				slog.ErrorContext(ctx, "graph worker input_prepare_error",
+					"node_key", snapshotNode.Node.NodeKey,
+					"err", err,
 				)
 				return nil, fmt.Errorf("worker node %q: %w", snapshotNode.Node.NodeKey, err)
 			}
 
 			messages := []llms.MessageContent{humanMessage}
 			var output string
+			var record map[string]any
 			var messageCount int
 			for attempt := 1; attempt <= outputRetries; attempt++ {
 				result, err := agent.Invoke(ctx, map[string]any{
 					"messages": messages,
 				})
 				if err != nil {
-					log.Printf(
-						"graph worker error node=%s max_iterations=%d attempt=%d/%d err=%v",
-						snapshotNode.Node.NodeKey,
-						maxIterations,
-						attempt,
-						outputRetries,
-						err,
+					slog.ErrorContext(ctx, "graph worker error",
+						"node_key", snapshotNode.Node.NodeKey,
+						"max_iterations", maxIterations,
+						"attempt", attempt,
+						"max_attempts", outputRetries,
+						"err", err,
 					)
 					return nil, fmt.Errorf("worker node %q: %w", snapshotNode.Node.NodeKey, err)
 				}
@@ -98,33 +101,32 @@ func BuildWorkerNode(snapshotNode *SnapshotNode, modelClient any, mcpClient *cli
 
 				messageCount = len(messages)
 				if isMaxIterationsMessage(output) {
-					log.Printf(
-						"graph worker iteration_limit node=%s max_iterations=%d input_len=%d output_len=%d",
-						snapshotNode.Node.NodeKey,
-						maxIterations,
-						len(input),
-						len(output),
+					slog.WarnContext(ctx, "graph worker iteration_limit",
+						"node_key", snapshotNode.Node.NodeKey,
+						"max_iterations", maxIterations,
+						"input_len", len(input),
+						"output_len", len(output),
 					)
 					return nil, fmt.Errorf("worker node %q hit max iterations", snapshotNode.Node.NodeKey)
 				}
 
-				normalizedOutput, validationErr := normalizeStructuredWorkerOutput(output, workerConfig.OutputSchema)
+				normalizedOutput, decoded, validationErr := decodeStructuredWorkerOutput(output, workerConfig.OutputSchema)
 				if validationErr == nil {
 					output = normalizedOutput
+					record = decoded
 					break
 				}
 
 				if attempt == outputRetries {
-					return nil, fmt.Errorf("worker node %q: invalid structured output: %w", snapshotNode.Node.NodeKey, validationErr)
+					return nil, fmt.Errorf("worker node %q: invalid structured output: %w", snapshotNode.Node.NodeKey, errorcodes.Wrap(errorcodes.SchemaViolation, validationErr))
 				}
 
-				log.Printf(
-					"graph worker output_retry node=%s attempt=%d/%d err=%v output_len=%d",
-					snapshotNode.Node.NodeKey,
-					attempt,
-					outputRetries,
-					validationErr,
-					len(output),
+				slog.WarnContext(ctx, "graph worker output_retry",
+					"node_key", snapshotNode.Node.NodeKey,
+					"attempt", attempt,
+					"max_attempts", outputRetries,
+					"err", validationErr,
+					"output_len", len(output),
 				)
 				messages = append(messages, llms.TextParts(
 					llms.ChatMessageTypeHuman,
@@ -132,17 +134,18 @@ func BuildWorkerNode(snapshotNode *SnapshotNode, modelClient any, mcpClient *cli
 				))
 			}
 
-			log.Printf(
-				"graph worker end node=%s message_count=%d output_len=%d",
-				snapshotNode.Node.NodeKey,
-				messageCount,
-				len(output),
+			slog.InfoContext(ctx, "graph worker end",
+				"node_key", snapshotNode.Node.NodeKey,
+				"message_count", messageCount,
+				"output_len", len(output),
 			)
 
+			delta := map[string]any{}
 			if outputKey != nil {
-				return map[string]any{*outputKey: output}, nil
+				delta[*outputKey] = output
 			}
-			return map[string]any{}, nil
+			applyWorkerOutputMappings(snapshotNode.Node.NodeKey, record, outputMappings, delta)
+			return delta, nil
 		},
 	}, nil
 }
@@ -155,6 +158,20 @@ func BuildWorkerNode(snapshotNode *SnapshotNode, modelClient any, mcpClient *cli
 // returns only the newly generated messages (delta) to prevent duplication when
 // the AppendReducer merges them back into state.
 func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any, mcpClient *clients.MCPClient) (*NodeToAdd, error) {
+	return buildSupervisorMemberWorkerNode(snapshotNode, modelClient, modelClient, mcpClient, supervisorConfig{})
+}
+
+// buildSupervisorMemberWorkerNode applies the owning supervisor's context
+// window to the shared messages before each member invocation. The window
+// summarizes with summaryModelClient, which must not be held to the member's
+// output_schema.
+func buildSupervisorMemberWorkerNode(
+	snapshotNode *SnapshotNode,
+	modelClient any,
+	summaryModelClient any,
+	mcpClient *clients.MCPClient,
+	supervisorCfg supervisorConfig,
+) (*NodeToAdd, error) {
 	model, ok := modelClient.(llms.Model)
 	if !ok {
 		return nil, fmt.Errorf("member worker %q: model client does not implement llms.Model", snapshotNode.Node.NodeKey)
@@ -169,6 +186,10 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 		return nil, err
 	}
 	outputRetries := outputRetryCount(workerConfig)
+	outputMappings, err := parseWorkerOutputMappings(workerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("member worker %q: %w", snapshotNode.Node.NodeKey, err)
+	}
 
 	baseAgent, err := buildAgentMap(model, graphTools, maxIterations, opts...)
 	if err != nil {
@@ -176,6 +197,8 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 	}
 
 	nodeKey := snapshotNode.Node.NodeKey
+	summaryModel, _ := summaryModelClient.(llms.Model)
+	window := newSupervisorContextWindow(nodeKey, supervisorCfg, summaryModel)
 
 	return &NodeToAdd{
 		Name:        nodeKey,
@@ -185,10 +208,18 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 			if err != nil {
 				return nil, fmt.Errorf("member worker %q: %w", nodeKey, err)
 			}
+			inputMessages, contextSummary := window.fit(ctx, inputMessages, loadSupervisorContextSummary(state))
+			withContextSummary := func(delta map[string]any) map[string]any {
+				if contextSummary != nil {
+					delta[supervisorContextSummaryKey] = *contextSummary
+				}
+				return delta
+			}
 
-			log.Printf(
-				"graph supervisor_member_start node=%s max_iterations=%d message_count=%d",
-				nodeKey, maxIterations, len(inputMessages),
+			slog.InfoContext(ctx, "graph supervisor_member_start",
+				"node_key", nodeKey,
+				"max_iterations", maxIterations,
+				"message_count", len(inputMessages),
 			)
 
 			messages := inputMessages
@@ -198,9 +229,11 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 					"messages": messages,
 				})
 				if err != nil {
-					log.Printf(
-						"graph supervisor_member_error node=%s attempt=%d/%d err=%v",
-						nodeKey, attempt, outputRetries, err,
+					slog.ErrorContext(ctx, "graph supervisor_member_error",
+						"node_key", nodeKey,
+						"attempt", attempt,
+						"max_attempts", outputRetries,
+						"err", err,
 					)
 					return nil, fmt.Errorf("member worker %q: %w", nodeKey, err)
 				}
@@ -210,11 +243,11 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 					return nil, fmt.Errorf("member worker %q: %w", nodeKey, err)
 				}
 				if len(resultMessages) == 0 {
-					log.Printf(
-						"graph supervisor_member_end node=%s message_count=0",
-						nodeKey,
+					slog.InfoContext(ctx, "graph supervisor_member_end",
+						"node_key", nodeKey,
+						"message_count", 0,
 					)
-					return map[string]any{}, nil
+					return withContextSummary(map[string]any{}), nil
 				}
 
 				// CreateAgentMap returns full conversation history. Keep only the delta
@@ -224,11 +257,11 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 					outputMessages = resultMessages[len(messages):]
 				}
 				if len(outputMessages) == 0 {
-					log.Printf(
-						"graph supervisor_member_end node=%s message_count=0",
-						nodeKey,
+					slog.InfoContext(ctx, "graph supervisor_member_end",
+						"node_key", nodeKey,
+						"message_count", 0,
 					)
-					return map[string]any{}, nil
+					return withContextSummary(map[string]any{}), nil
 				}
 
 				lastOutput, err := extractLastAIMessage(outputMessages)
@@ -236,14 +269,14 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 					return nil, fmt.Errorf("member worker %q: %w", nodeKey, err)
 				}
 				if isMaxIterationsMessage(lastOutput) {
-					log.Printf(
-						"graph supervisor_member_iteration_limit node=%s max_iterations=%d",
-						nodeKey, maxIterations,
+					slog.WarnContext(ctx, "graph supervisor_member_iteration_limit",
+						"node_key", nodeKey,
+						"max_iterations", maxIterations,
 					)
 					return nil, fmt.Errorf("member worker %q hit max iterations", nodeKey)
 				}
 
-				normalizedOutput, validationErr := normalizeStructuredWorkerOutput(lastOutput, workerConfig.OutputSchema)
+				normalizedOutput, record, validationErr := decodeStructuredWorkerOutput(lastOutput, workerConfig.OutputSchema)
 				if validationErr == nil {
 					if workerConfig.OutputSchema != nil {
 						outputMessages, err = replaceLastAIMessage(outputMessages, normalizedOutput)
@@ -252,27 +285,28 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 						}
 					}
 
-					log.Printf(
-						"graph supervisor_member_end node=%s message_count=%d",
-						nodeKey, len(outputMessages),
+					slog.InfoContext(ctx, "graph supervisor_member_end",
+						"node_key", nodeKey,
+						"message_count", len(outputMessages),
 					)
 
-					return map[string]any{
+					delta := map[string]any{
 						"messages": outputMessages,
-					}, nil
+					}
+					applyWorkerOutputMappings(nodeKey, record, outputMappings, delta)
+					return withContextSummary(delta), nil
 				}
 
 				if attempt == outputRetries {
-					return nil, fmt.Errorf("member worker %q: invalid structured output: %w", nodeKey, validationErr)
+					return nil, fmt.Errorf("member worker %q: invalid structured output: %w", nodeKey, errorcodes.Wrap(errorcodes.SchemaViolation, validationErr))
 				}
 
-				log.Printf(
-					"graph supervisor_member_output_retry node=%s attempt=%d/%d err=%v output_len=%d",
-					nodeKey,
-					attempt,
-					outputRetries,
-					validationErr,
-					len(lastOutput),
+				slog.WarnContext(ctx, "graph supervisor_member_output_retry",
+					"node_key", nodeKey,
+					"attempt", attempt,
+					"max_attempts", outputRetries,
+					"err", validationErr,
+					"output_len", len(lastOutput),
 				)
 
 				messages = append(resultMessages, llms.TextParts(
@@ -281,7 +315,7 @@ func BuildSupervisorMemberWorkerNode(snapshotNode *SnapshotNode, modelClient any
 				))
 			}
 
-			return map[string]any{}, nil
+			return withContextSummary(map[string]any{}), nil
 		},
 	}, nil
 }
@@ -15,6 +15,8 @@ type workerAgentConfig struct {
 	MaxIterations int                 `json:"max_iterations"`
 	OutputRetries int                 `json:"output_retries"`
 	OutputSchema  *workerOutputSchema `json:"output_schema"`
+	NativeOutput  *bool               `json:"native_output"`
+	OutputMapping map[string]string   `json:"output_mapping"`
 }
 
 const defaultWorkerMaxIterations = 10
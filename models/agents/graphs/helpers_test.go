@@ -2,6 +2,7 @@ package graphs
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
@@ -78,3 +79,32 @@ func TestLoadStateStringJSONEncodesObjects(t *testing.T) {
 		t.Fatalf("expected apiKeyBound=false, got %#v", decoded["apiKeyBound"])
 	}
 }
+
+func TestParseWorkerOutputMappingsValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{name: "requires schema", config: `{"output_mapping":{"total":"total"}}`, want: "output_mapping requires output_schema"},
+		{name: "empty state key", config: `{"output_schema":{"type":"object"},"output_mapping":{"total":" "}}`, want: `output_mapping "total" has an empty state key`},
+		{name: "bad path", config: `{"output_schema":{"type":"object"},"output_mapping":{"items[x]":"item"}}`, want: `json path "items[x]" has invalid index "x"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, _, _, err := parseWorkerConfig(&SnapshotNode{Node: &dbmodels.AgentGraphNode{
+				NodeKey: "extract",
+				Config:  test.config,
+			}})
+			if err != nil {
+				t.Fatalf("parseWorkerConfig returned error: %v", err)
+			}
+
+			_, err = parseWorkerOutputMappings(config)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected %q, got %v", test.want, err)
+			}
+		})
+	}
+}
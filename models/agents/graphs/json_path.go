@@ -0,0 +1,228 @@
+package graphs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegment is one step of a JSON path: an object key, an array index,
+// or a wildcard over every array item / object value.
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func (segment jsonPathSegment) String() string {
+	switch {
+	case segment.wildcard && segment.isIndex:
+		return "[*]"
+	case segment.wildcard:
+		return "*"
+	case segment.isIndex:
+		return fmt.Sprintf("[%d]", segment.index)
+	default:
+		return segment.key
+	}
+}
+
+// parseJSONPath parses dotted paths with array indexes and wildcards such as
+// "line_items[0].amount", "matches[*].id" or "scores.*". A leading "$." is
+// accepted and ignored.
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	trimmed := strings.TrimSpace(path)
+	trimmed = strings.TrimPrefix(strings.TrimPrefix(trimmed, "$"), ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("json path %q is empty", path)
+	}
+
+	var segments []jsonPathSegment
+	for _, part := range strings.Split(trimmed, ".") {
+		key, rest, hasIndex := strings.Cut(part, "[")
+		if key == "" && !hasIndex {
+			return nil, fmt.Errorf("json path %q has an empty segment", path)
+		}
+		switch key {
+		case "":
+		case "*":
+			segments = append(segments, jsonPathSegment{wildcard: true})
+		default:
+			segments = append(segments, jsonPathSegment{key: key})
+		}
+
+		for hasIndex {
+			indexText, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("json path %q has an unclosed index", path)
+			}
+			if indexText == "*" {
+				segments = append(segments, jsonPathSegment{isIndex: true, wildcard: true})
+			} else {
+				index, err := strconv.Atoi(indexText)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("json path %q has invalid index %q", path, indexText)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("json path %q has unexpected %q after index", path, after)
+			}
+			rest = after[1:]
+		}
+	}
+
+	return segments, nil
+}
+
+// LookupStatePath resolves a JSON path such as "scores.avg_confidence" in a
+// run's state and reports whether it exists.
+func LookupStatePath(state map[string]any, path string) (any, bool) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+	return lookupJSONPath(state, segments)
+}
+
+// lookupJSONPath walks value along segments and reports whether the path exists.
+func lookupJSONPath(value any, segments []jsonPathSegment) (any, bool) {
+	resolved, err := resolveJSONPath(value, segments)
+	return resolved, err == nil
+}
+
+// resolveJSONPath walks value along segments. Wildcards collect the remaining
+// path from every item into a list, skipping items where it does not exist.
+// Errors describe where the walk stopped and which fields were available there.
+func resolveJSONPath(value any, segments []jsonPathSegment) (any, error) {
+	return resolveJSONPathFrom(value, segments, "")
+}
+
+func resolveJSONPathFrom(value any, segments []jsonPathSegment, walked string) (any, error) {
+	current := value
+	for position, segment := range segments {
+		if segment.wildcard {
+			items, err := jsonPathWildcardItems(current, segment, walked)
+			if err != nil {
+				return nil, err
+			}
+			collected := make([]any, 0, len(items))
+			for _, item := range items {
+				resolved, err := resolveJSONPathFrom(item, segments[position+1:], jsonPathWildcardLocation(walked, segment))
+				if err == nil {
+					collected = append(collected, resolved)
+				}
+			}
+			return collected, nil
+		}
+
+		if segment.isIndex {
+			items, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is %s, not an array", describeJSONPathLocation(walked), describeJSONValue(current))
+			}
+			if segment.index >= len(items) {
+				return nil, fmt.Errorf(
+					"index %d is out of range at %s (length %d)",
+					segment.index,
+					describeJSONPathLocation(walked),
+					len(items),
+				)
+			}
+			current = items[segment.index]
+			walked += segment.String()
+			continue
+		}
+
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is %s, not an object", describeJSONPathLocation(walked), describeJSONValue(current))
+		}
+		next, ok := object[segment.key]
+		if !ok {
+			return nil, fmt.Errorf(
+				"no field %q at %s (available fields: %s)",
+				segment.key,
+				describeJSONPathLocation(walked),
+				strings.Join(sortedJSONKeys(object), ", "),
+			)
+		}
+		current = next
+		walked = joinJSONPath(walked, segment.key)
+	}
+
+	return current, nil
+}
+
+func jsonPathWildcardItems(value any, segment jsonPathSegment, walked string) ([]any, error) {
+	switch typed := value.(type) {
+	case []any:
+		return typed, nil
+	case map[string]any:
+		if segment.isIndex {
+			return nil, fmt.Errorf("%s is an object, not an array", describeJSONPathLocation(walked))
+		}
+		items := make([]any, 0, len(typed))
+		for _, key := range sortedJSONKeys(typed) {
+			items = append(items, typed[key])
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("%s is %s and cannot be expanded by %s", describeJSONPathLocation(walked), describeJSONValue(value), segment)
+	}
+}
+
+func jsonPathWildcardLocation(walked string, segment jsonPathSegment) string {
+	if segment.isIndex {
+		return walked + segment.String()
+	}
+	return joinJSONPath(walked, segment.String())
+}
+
+func joinJSONPath(walked string, key string) string {
+	if walked == "" {
+		return key
+	}
+	return walked + "." + key
+}
+
+func describeJSONPathLocation(walked string) string {
+	if walked == "" {
+		return "the root"
+	}
+	return fmt.Sprintf("%q", walked)
+}
+
+func describeJSONValue(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64, int, int64:
+		return "a number"
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func sortedJSONKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/url"
 	"path"
 	"strings"
@@ -54,16 +54,15 @@ func buildHumanInputMessage(ctx context.Context, input string, cfg nodeInputConf
 			if dataURL == "" {
 				return llms.MessageContent{}, fmt.Errorf("optimized image data url is empty")
 			}
-			log.Printf(
-				"graph image optimized original_bytes=%d final_bytes=%d original_size=%dx%d final_size=%dx%d reencoded=%t original_content_type=%q",
-				prepared.OriginalBytes,
-				prepared.FinalBytes,
-				prepared.OriginalWidth,
-				prepared.OriginalHeight,
-				prepared.FinalWidth,
-				prepared.FinalHeight,
-				prepared.Reencoded,
-				prepared.OriginalContentType,
+			slog.InfoContext(ctx, "graph image optimized",
+				"original_bytes", prepared.OriginalBytes,
+				"final_bytes", prepared.FinalBytes,
+				"original_width", prepared.OriginalWidth,
+				"original_height", prepared.OriginalHeight,
+				"final_width", prepared.FinalWidth,
+				"final_height", prepared.FinalHeight,
+				"reencoded", prepared.Reencoded,
+				"original_content_type", prepared.OriginalContentType,
 			)
 			parts = append(parts, llms.ImageURLPart(dataURL))
 		}
@@ -0,0 +1,88 @@
+package graphs
+
+import "strings"
+
+// RouteKind says why a built graph can move from one node to another.
+type RouteKind string
+
+const (
+	// RouteEdge is a row in agent_graph_edges.
+	RouteEdge RouteKind = "edge"
+	// RouteSupervisorMember is a supervisor handing work to a member.
+	RouteSupervisorMember RouteKind = "member"
+	// RouteSupervisorReturn is a member reporting back to its supervisor.
+	RouteSupervisorReturn RouteKind = "return"
+	// RouteSupervisorFinish is a supervisor choosing FINISH.
+	RouteSupervisorFinish RouteKind = "finish"
+	// RouteSupervisorLoop is a supervisor escalating out of a detected loop.
+	RouteSupervisorLoop RouteKind = "loop"
+	RouteConditionTrue  RouteKind = "true"
+	RouteConditionFalse RouteKind = "false"
+)
+
+type Route struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Kind RouteKind `json:"kind"`
+}
+
+// Routes lists the transitions BuildGraph wires for a snapshot, in node then
+// edge order. Supervisor and condition nodes route by their config, so their
+// rows in agent_graph_edges are left out, as BuildGraph ignores them. Nodes
+// whose config does not parse keep their edges.
+func Routes(snapshot *Snapshot) []Route {
+	if snapshot == nil {
+		return nil
+	}
+	var routes []Route
+	routers := make(map[string]struct{})
+	for _, snapshotNode := range snapshot.Nodes {
+		if snapshotNode == nil || snapshotNode.Node == nil {
+			continue
+		}
+		key := snapshotNode.Node.NodeKey
+		switch strings.ToLower(strings.TrimSpace(snapshotNode.Node.NodeType)) {
+		case "supervisor":
+			cfg, err := parseSupervisorConfig(snapshotNode)
+			if err != nil {
+				continue
+			}
+			routers[key] = struct{}{}
+			for _, member := range cfg.Members {
+				routes = append(routes,
+					Route{From: key, To: member, Kind: RouteSupervisorMember},
+					Route{From: member, To: key, Kind: RouteSupervisorReturn},
+				)
+			}
+			finishTarget := cfg.FinishTarget
+			if finishTarget == "" {
+				finishTarget = "END"
+			}
+			routes = append(routes, Route{From: key, To: finishTarget, Kind: RouteSupervisorFinish})
+			if cfg.LoopTarget != "" {
+				routes = append(routes, Route{From: key, To: cfg.LoopTarget, Kind: RouteSupervisorLoop})
+			}
+		case "condition":
+			cfg, err := parseConditionConfig(snapshotNode)
+			if err != nil {
+				continue
+			}
+			routers[key] = struct{}{}
+			routes = append(routes,
+				Route{From: key, To: cfg.TrueTarget, Kind: RouteConditionTrue},
+				Route{From: key, To: cfg.FalseTarget, Kind: RouteConditionFalse},
+			)
+		}
+	}
+
+	for _, edge := range snapshot.Edges {
+		if edge == nil {
+			continue
+		}
+		if _, isRouter := routers[edge.FromNode]; isRouter {
+			continue
+		}
+		routes = append(routes, Route{From: edge.FromNode, To: edge.ToNode, Kind: RouteEdge})
+	}
+	return routes
+}
@@ -0,0 +1,61 @@
+package graphs
+
+import (
+	"reflect"
+	"testing"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func TestRoutesFollowRoutingConfig(t *testing.T) {
+	snapshot := &Snapshot{
+		AgentGraph: &dbmodels.AgentGraph{Name: "Review", EntryNode: "check"},
+		Nodes: []*SnapshotNode{
+			{Node: &dbmodels.AgentGraphNode{
+				NodeKey:  "check",
+				NodeType: "condition",
+				Config:   `{"source_key":"kind","operator":"equals","value":"receipt","true_target":"team","false_target":"END"}`,
+			}},
+			{Node: &dbmodels.AgentGraphNode{
+				NodeKey:  "team",
+				NodeType: "supervisor",
+				Config:   `{"members":["reader"],"finish_target":"save","loop_target":"save"}`,
+			}},
+			{Node: &dbmodels.AgentGraphNode{NodeKey: "reader", NodeType: "worker", Config: `{}`}},
+			{Node: &dbmodels.AgentGraphNode{NodeKey: "save", NodeType: "tool", Config: `{}`}},
+		},
+		Edges: []*dbmodels.AgentGraphEdge{
+			{FromNode: "check", ToNode: "team"},
+			{FromNode: "team", ToNode: "save"},
+			{FromNode: "save", ToNode: "END"},
+		},
+	}
+
+	want := []Route{
+		{From: "check", To: "team", Kind: RouteConditionTrue},
+		{From: "check", To: "END", Kind: RouteConditionFalse},
+		{From: "team", To: "reader", Kind: RouteSupervisorMember},
+		{From: "reader", To: "team", Kind: RouteSupervisorReturn},
+		{From: "team", To: "save", Kind: RouteSupervisorFinish},
+		{From: "team", To: "save", Kind: RouteSupervisorLoop},
+		{From: "save", To: "END", Kind: RouteEdge},
+	}
+	if got := Routes(snapshot); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected routes:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestRoutesKeepEdgesOfUnparsableRouters(t *testing.T) {
+	snapshot := &Snapshot{
+		AgentGraph: &dbmodels.AgentGraph{Name: "Broken", EntryNode: "check"},
+		Nodes: []*SnapshotNode{
+			{Node: &dbmodels.AgentGraphNode{NodeKey: "check", NodeType: "condition", Config: `{}`}},
+		},
+		Edges: []*dbmodels.AgentGraphEdge{{FromNode: "check", ToNode: "END"}},
+	}
+
+	want := []Route{{From: "check", To: "END", Kind: RouteEdge}}
+	if got := Routes(snapshot); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected routes: %#v", got)
+	}
+}
@@ -0,0 +1,34 @@
+package graphs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"gorm.io/gorm"
+)
+
+// RunAttempt is one failed execution attempt, appended to agent_graph_runs.attempts.
+type RunAttempt struct {
+	Attempt   int        `json:"attempt"`
+	Step      string     `json:"step"`
+	Error     string     `json:"error"`
+	ErrorCode string     `json:"error_code"`
+	Transient bool       `json:"transient"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+	FailedAt  time.Time  `json:"failed_at"`
+}
+
+// RecordRunAttempt appends attempt to the run's attempt history.
+func RecordRunAttempt(db *gorm.DB, runID string, attempt RunAttempt) error {
+	payload, err := json.Marshal([]RunAttempt{attempt})
+	if err != nil {
+		return fmt.Errorf("failed to encode run attempt: %w", err)
+	}
+
+	return db.Model(&dbmodels.AgentGraphRun{}).
+		Where("id = ?", runID).
+		Update("attempts", gorm.Expr("coalesce(attempts, '[]'::jsonb) || ?::jsonb", string(payload))).
+		Error
+}
@@ -0,0 +1,117 @@
+package graphs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"gorm.io/gorm"
+)
+
+// ErrRunCancelled is the context cause of a graph invocation whose run was
+// cancelled while it was executing.
+var ErrRunCancelled = errorcodes.Wrap(errorcodes.Cancelled, errors.New("run cancelled"))
+
+// ErrRunInterrupted is the context cause of a graph invocation stopped
+// because its worker is shutting down. The run is left running so the job
+// that owns it can continue it on its next attempt.
+var ErrRunInterrupted = errors.New("run interrupted by shutdown")
+
+var runCancellationPollInterval = 2 * time.Second
+
+// activeRunCancels holds the cancel functions of runs executing in this
+// process so a cancel request handled here stops them without waiting for
+// the next status poll.
+var activeRunCancels sync.Map
+
+// CancelRun transitions a running run to cancelled, publishes its terminal
+// event, and stops the graph if it is executing in this process. It returns
+// false when the run had already finished.
+func CancelRun(db *gorm.DB, runID string, organizationID string, reason string) (bool, error) {
+	runErr := ErrRunCancelled
+	if reason != "" {
+		runErr = fmt.Errorf("%w: %s", ErrRunCancelled, reason)
+	}
+
+	cancelled, err := FinalizeRun(db, runID, organizationID, "cancelled", nil, runErr)
+	if err != nil {
+		return false, err
+	}
+	if cancelled {
+		cancelActiveRun(runID)
+	}
+	return cancelled, nil
+}
+
+// WithRunCancellation returns a context for invoking a run's graph. It is
+// cancelled with ErrRunCancelled when CancelRun is called in this process, or
+// when polling finds the run cancelled by another worker. Callers must call
+// the returned stop function once the graph returns.
+func WithRunCancellation(ctx context.Context, db *gorm.DB, runID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	activeRunCancels.Store(runID, cancel)
+
+	go watchRunCancellation(ctx, db, runID, cancel)
+
+	return ctx, func() {
+		activeRunCancels.Delete(runID)
+		cancel(context.Canceled)
+	}
+}
+
+// IsRunCancelled reports whether ctx was cancelled because its run was.
+func IsRunCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrRunCancelled)
+}
+
+// IsRunInterrupted reports whether ctx was cancelled because its worker is
+// shutting down.
+func IsRunInterrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrRunInterrupted)
+}
+
+func cancelActiveRun(runID string) {
+	value, ok := activeRunCancels.LoadAndDelete(runID)
+	if !ok {
+		return
+	}
+	slog.Info("graph run cancelled", "run_id", runID, "source", "local")
+	value.(context.CancelCauseFunc)(ErrRunCancelled)
+}
+
+func watchRunCancellation(ctx context.Context, db *gorm.DB, runID string, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(runCancellationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var status string
+		err := db.WithContext(ctx).
+			Model(&dbmodels.AgentGraphRun{}).
+			Select("status").
+			Where("id = ?", runID).
+			Scan(&status).Error
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "graph run cancellation_check_failed", "run_id", runID, "err", err)
+			}
+			continue
+		}
+		if status == "cancelled" {
+			activeRunCancels.Delete(runID)
+			slog.InfoContext(ctx, "graph run cancelled", "run_id", runID, "source", "poll")
+			cancel(ErrRunCancelled)
+			return
+		}
+	}
+}
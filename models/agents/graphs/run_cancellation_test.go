@@ -0,0 +1,80 @@
+package graphs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/realtime"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+func TestTerminalRunUpdatesRecordsCancellation(t *testing.T) {
+	updates, err := terminalRunUpdates("cancelled", nil, ErrRunCancelled)
+	if err != nil {
+		t.Fatalf("terminalRunUpdates returned error: %v", err)
+	}
+	if updates["status"] != "cancelled" || updates["error"] != "run cancelled" || updates["error_code"] != errorcodes.Cancelled {
+		t.Fatalf("unexpected cancellation updates: %#v", updates)
+	}
+}
+
+func TestCancelRunStopsLocalInvocation(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	if err := db.Callback().Update().Replace("gorm:update", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}); err != nil {
+		t.Fatalf("replace dry-run update callback: %v", err)
+	}
+
+	previousPublisher := publishDashboardEvent
+	var reasons []string
+	publishDashboardEvent = func(_ context.Context, event realtime.DashboardEvent) error {
+		reasons = append(reasons, event.Reason)
+		return nil
+	}
+	t.Cleanup(func() { publishDashboardEvent = previousPublisher })
+
+	runCtx, stop := WithRunCancellation(context.Background(), db, "run-1")
+	defer stop()
+
+	cancelled, err := CancelRun(db, "run-1", "org-1", "looping supervisor")
+	if err != nil || !cancelled {
+		t.Fatalf("expected run to be cancelled, got cancelled=%v err=%v", cancelled, err)
+	}
+
+	select {
+	case <-runCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected run context to be cancelled")
+	}
+	if !IsRunCancelled(runCtx) {
+		t.Fatalf("expected cancellation cause, got %v", context.Cause(runCtx))
+	}
+	if len(reasons) != 1 || reasons[0] != realtime.DashboardReasonRunFinished {
+		t.Fatalf("expected run-finished notification, got %#v", reasons)
+	}
+}
+
+func TestRunCancellationStopDoesNotReportCancelledRun(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+
+	runCtx, stop := WithRunCancellation(context.Background(), db, "run-2")
+	stop()
+
+	if IsRunCancelled(runCtx) || !errors.Is(context.Cause(runCtx), context.Canceled) {
+		t.Fatalf("expected plain cancellation after stop, got %v", context.Cause(runCtx))
+	}
+	if _, ok := activeRunCancels.Load("run-2"); ok {
+		t.Fatal("expected stopped run to be unregistered")
+	}
+}
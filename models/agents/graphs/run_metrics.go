@@ -0,0 +1,29 @@
+package graphs
+
+import (
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// NodeTypes maps the node keys of a snapshot to their node types. Node
+// metrics are labelled by type because node keys are chosen per workflow.
+func NodeTypes(snapshot *Snapshot) map[string]string {
+	nodeTypes := make(map[string]string)
+	if snapshot == nil {
+		return nodeTypes
+	}
+	for _, node := range snapshot.Nodes {
+		if node == nil || node.Node == nil {
+			continue
+		}
+		nodeTypes[node.Node.NodeKey] = strings.ToLower(strings.TrimSpace(node.Node.NodeType))
+	}
+	return nodeTypes
+}
+
+// observeNode records the duration of a finished node under its node type.
+func (t *RunTracker) observeNode(event *graph.TraceSpan, failed bool) {
+	metrics.ObserveNode(t.nodeTypes[event.NodeName], failed, event.Duration)
+}
@@ -0,0 +1,34 @@
+package graphs
+
+import (
+	"context"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = telemetry.Tracer("github.com/arcnem-ai/arcnem-vision/models/agents/graphs")
+
+// StartRunSpan starts the span covering one attempt of a run's graph. Node,
+// model and MCP spans started from the returned context nest under it. The
+// returned function ends the span with the graph's error.
+func StartRunSpan(ctx context.Context, runID string, agentGraphID string) (context.Context, func(error)) {
+	ctx, span := tracer.Start(ctx, "graph.run", trace.WithAttributes(
+		attribute.String("arcnem.run_id", runID),
+		attribute.String("arcnem.agent_graph_id", agentGraphID),
+	))
+	return ctx, func(err error) {
+		telemetry.EndSpan(span, err)
+	}
+}
+
+// startNodeSpan starts the span of one node execution under the run span on
+// ctx. Model and MCP spans started from the returned context nest under it.
+func startNodeSpan(ctx context.Context, nodeKey string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "graph.node "+nodeKey, trace.WithAttributes(
+		attribute.String("arcnem.run_id", logging.RunID(ctx)),
+		attribute.String("arcnem.node", nodeKey),
+	))
+}
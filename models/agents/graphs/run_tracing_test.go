@@ -0,0 +1,57 @@
+package graphs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNodeSpansNestUnderRunSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	ctx, endRunSpan := StartRunSpan(context.Background(), "run-1", "graph-1")
+	ocr := withNodeKey("ocr", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		_, span := tracer.Start(ctx, "model.call")
+		span.End()
+		return state, nil
+	})
+	describe := withNodeKey("describe", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errors.New("provider unavailable")
+	})
+	if _, err := ocr(ctx, map[string]any{}); err != nil {
+		t.Fatalf("ocr: %v", err)
+	}
+	if _, err := describe(ctx, map[string]any{}); err == nil {
+		t.Fatal("expected describe to fail")
+	}
+	endRunSpan(nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("expected run, two node and one model span, got %d", len(spans))
+	}
+	modelSpan, ocrSpan, describeSpan, runSpan := spans[0], spans[1], spans[2], spans[3]
+	if runSpan.Name != "graph.run" {
+		t.Fatalf("expected the run span to end last, got %q", runSpan.Name)
+	}
+	for _, nodeSpan := range []tracetest.SpanStub{ocrSpan, describeSpan} {
+		if nodeSpan.Parent.SpanID() != runSpan.SpanContext.SpanID() {
+			t.Fatalf("expected %q to be a child of the run span", nodeSpan.Name)
+		}
+	}
+	if modelSpan.Parent.SpanID() != ocrSpan.SpanContext.SpanID() {
+		t.Fatalf("expected the model span to be a child of the ocr node span")
+	}
+	if ocrSpan.Name != "graph.node ocr" || ocrSpan.Status.Code == codes.Error {
+		t.Fatalf("unexpected ocr node span %q status=%v", ocrSpan.Name, ocrSpan.Status)
+	}
+	if describeSpan.Name != "graph.node describe" || describeSpan.Status.Code != codes.Error {
+		t.Fatalf("expected failed node span, got %q status=%v", describeSpan.Name, describeSpan.Status)
+	}
+}
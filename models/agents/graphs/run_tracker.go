@@ -4,15 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/arcnem-ai/arcnem-vision/models/shared/realtime"
 	"github.com/smallnest/langgraphgo/graph"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Verify RunTracker implements TraceHook.
@@ -20,6 +23,17 @@ var _ graph.TraceHook = (*RunTracker)(nil)
 
 var publishDashboardEvent = realtime.PublishDashboardEvent
 
+// runFinalizedHooks are called after FinalizeRun moves a run to a terminal
+// status. They are registered at startup.
+var runFinalizedHooks []func(db *gorm.DB, runID string, status string)
+
+// OnRunFinalized registers fn to be called after every run FinalizeRun
+// finalizes, whichever job or cancellation finalized it. Hooks handle their
+// own errors so they cannot change the run's outcome.
+func OnRunFinalized(fn func(db *gorm.DB, runID string, status string)) {
+	runFinalizedHooks = append(runFinalizedHooks, fn)
+}
+
 // RunTracker records graph execution to the agent_graph_runs and agent_graph_run_steps tables.
 type RunTracker struct {
 	db             *gorm.DB
@@ -29,11 +43,27 @@ type RunTracker struct {
 	mu             sync.Mutex
 	// steps tracks in-flight steps by span ID so we can update them on end.
 	steps map[string]*dbmodels.AgentGraphRunStep
+	// nodeTypes maps node keys to node types for node metrics.
+	nodeTypes map[string]string
+	// retryTransientFailures leaves the run running when a node fails with a
+	// transient error so the job can retry it.
+	retryTransientFailures bool
 }
 
 type RunTrackerOptions struct {
-	RunID     string
+	RunID string
+	// NewRunID is the ID given to a new run. When a run with this ID already
+	// exists the tracker continues it, so a job that repeats its create step
+	// on retry keeps one run.
+	NewRunID  string
 	ProjectID string
+	// BackfillID links a new run to the backfill that enqueued it.
+	BackfillID string
+	// NodeTypes maps node keys to node types, as returned by NodeTypes.
+	NodeTypes map[string]string
+	// RetryTransientFailures keeps the run running after a transient node
+	// failure instead of finalizing it as failed.
+	RetryTransientFailures bool
 }
 
 // NewRunTracker creates a new run record and returns a tracker.
@@ -62,12 +92,20 @@ func NewRunTrackerWithOptions(
 	run := &dbmodels.AgentGraphRun{
 		AgentGraphID: agentGraphID,
 		ProjectID:    toNullableString(options.ProjectID),
+		BackfillID:   toNullableString(options.BackfillID),
 		Status:       "running",
 	}
+	continued := options.RunID != ""
 	if options.RunID != "" {
 		run.ID = options.RunID
 		if err := db.Select("id").Where("id = ? AND status = ?", options.RunID, "running").Take(run).Error; err != nil {
-			return nil, fmt.Errorf("failed to find accepted running run: %w", err)
+			return nil, fmt.Errorf("failed to find accepted running run: %w", runerrors.Database(err))
+		}
+		// Runs accepted by the API stay queued until a job picks them up.
+		if err := db.Model(&dbmodels.AgentGraphRun{}).
+			Where("id = ? AND picked_up_at IS NULL", run.ID).
+			Update("picked_up_at", time.Now()).Error; err != nil {
+			return nil, fmt.Errorf("failed to mark run picked up: %w", runerrors.Database(err))
 		}
 	} else {
 		stateJSON, err := json.Marshal(initialState)
@@ -75,17 +113,39 @@ func NewRunTrackerWithOptions(
 			return nil, fmt.Errorf("failed to encode initial state: %w", err)
 		}
 		stateStr := string(stateJSON)
+		run.ID = options.NewRunID
 		run.InitialState = &stateStr
-		if err := db.Create(run).Error; err != nil {
-			return nil, fmt.Errorf("failed to create run record: %w", err)
+		pickedUpAt := time.Now()
+		run.PickedUpAt = &pickedUpAt
+		create := db
+		if options.NewRunID != "" {
+			create = db.Clauses(clause.OnConflict{DoNothing: true})
 		}
+		result := create.Create(run)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to create run record: %w", runerrors.Database(result.Error))
+		}
+		continued = options.NewRunID != "" && result.RowsAffected == 0
 	}
 
 	tracker := &RunTracker{
-		db:             db,
-		run:            run,
-		organizationID: organizationID,
-		steps:          make(map[string]*dbmodels.AgentGraphRunStep),
+		db:                     db,
+		run:                    run,
+		organizationID:         organizationID,
+		steps:                  make(map[string]*dbmodels.AgentGraphRunStep),
+		nodeTypes:              options.NodeTypes,
+		retryTransientFailures: options.RetryTransientFailures,
+	}
+	if continued {
+		// A retried attempt continues the step numbering of earlier attempts.
+		var lastStepOrder int32
+		if err := db.Model(&dbmodels.AgentGraphRunStep{}).
+			Select("coalesce(max(step_order), 0)").
+			Where("run_id = ?", run.ID).
+			Scan(&lastStepOrder).Error; err != nil {
+			return nil, fmt.Errorf("failed to load run step order: %w", runerrors.Database(err))
+		}
+		tracker.stepOrder.Store(lastStepOrder)
 	}
 
 	tracker.publish(realtime.DashboardReasonRunCreated)
@@ -102,7 +162,7 @@ func toNullableString(value string) *string {
 }
 
 // OnEvent implements graph.TraceHook.
-func (t *RunTracker) OnEvent(_ context.Context, span *graph.TraceSpan) {
+func (t *RunTracker) OnEvent(ctx context.Context, span *graph.TraceSpan) {
 	switch span.Event {
 	case graph.TraceEventNodeStart:
 		order := t.stepOrder.Add(1)
@@ -113,27 +173,26 @@ func (t *RunTracker) OnEvent(_ context.Context, span *graph.TraceSpan) {
 			StartedAt: span.StartTime,
 		}
 		if err := t.db.Create(step).Error; err != nil {
-			log.Printf(
-				"graph run node_start db_write_failed run_id=%s step_order=%d node=%s err=%v",
-				t.run.ID,
-				order,
-				span.NodeName,
-				err,
+			slog.ErrorContext(ctx, "graph run node_start db_write_failed",
+				"run_id", t.run.ID,
+				"step_order", order,
+				"node_key", span.NodeName,
+				"err", err,
 			)
 			return
 		}
 		t.mu.Lock()
 		t.steps[span.ID] = step
 		t.mu.Unlock()
-		log.Printf(
-			"graph run node_start run_id=%s step_order=%d node=%s",
-			t.run.ID,
-			order,
-			span.NodeName,
+		slog.InfoContext(ctx, "graph run node_start",
+			"run_id", t.run.ID,
+			"step_order", order,
+			"node_key", span.NodeName,
 		)
 		t.publish(realtime.DashboardReasonRunStepChanged)
 
 	case graph.TraceEventNodeEnd:
+		t.observeNode(span, false)
 		t.mu.Lock()
 		step, ok := t.steps[span.ID]
 		if ok {
@@ -150,20 +209,18 @@ func (t *RunTracker) OnEvent(_ context.Context, span *graph.TraceSpan) {
 			}
 		}
 		if err := t.db.Model(step).Updates(updates).Error; err != nil {
-			log.Printf(
-				"graph run node_end db_write_failed run_id=%s step_order=%d node=%s err=%v",
-				t.run.ID,
-				step.StepOrder,
-				step.NodeKey,
-				err,
+			slog.ErrorContext(ctx, "graph run node_end db_write_failed",
+				"run_id", t.run.ID,
+				"step_order", step.StepOrder,
+				"node_key", step.NodeKey,
+				"err", err,
 			)
 		}
-		log.Printf(
-			"graph run node_end run_id=%s step_order=%d node=%s duration_ms=%d",
-			t.run.ID,
-			step.StepOrder,
-			step.NodeKey,
-			span.Duration.Milliseconds(),
+		slog.InfoContext(ctx, "graph run node_end",
+			"run_id", t.run.ID,
+			"step_order", step.StepOrder,
+			"node_key", step.NodeKey,
+			"duration_ms", span.Duration.Milliseconds(),
 		)
 		t.publish(realtime.DashboardReasonRunStepChanged)
 
@@ -177,7 +234,10 @@ func (t *RunTracker) OnEvent(_ context.Context, span *graph.TraceSpan) {
 		updates := map[string]any{"finished_at": span.EndTime}
 		errorPayload := map[string]any{}
 		if span.Error != nil {
+			errorCode := errorcodes.Of(span.Error)
 			errorPayload["error"] = span.Error.Error()
+			errorPayload["error_code"] = errorCode
+			updates["error_code"] = errorCode
 		}
 		if span.State != nil {
 			errorPayload["state"] = span.State
@@ -189,12 +249,11 @@ func (t *RunTracker) OnEvent(_ context.Context, span *graph.TraceSpan) {
 		}
 		if ok {
 			if err := t.db.Model(step).Updates(updates).Error; err != nil {
-				log.Printf(
-					"graph run node_error db_write_failed run_id=%s step_order=%d node=%s err=%v",
-					t.run.ID,
-					step.StepOrder,
-					step.NodeKey,
-					err,
+				slog.ErrorContext(ctx, "graph run node_error db_write_failed",
+					"run_id", t.run.ID,
+					"step_order", step.StepOrder,
+					"node_key", step.NodeKey,
+					"err", err,
 				)
 			}
 		}
@@ -204,29 +263,43 @@ func (t *RunTracker) OnEvent(_ context.Context, span *graph.TraceSpan) {
 		} else {
 			runErr = fmt.Errorf("node %s failed", span.NodeName)
 		}
+		t.observeNode(span, true)
 		stepOrder := int32(0)
 		if ok {
 			stepOrder = step.StepOrder
 		}
-		log.Printf(
-			"graph run node_error run_id=%s step_order=%d node=%s duration_ms=%d",
-			t.run.ID,
-			stepOrder,
-			span.NodeName,
-			span.Duration.Milliseconds(),
+		slog.ErrorContext(ctx, "graph run node_error",
+			"run_id", t.run.ID,
+			"step_order", stepOrder,
+			"node_key", span.NodeName,
+			"duration_ms", span.Duration.Milliseconds(),
 		)
 		if ok {
 			t.publish(realtime.DashboardReasonRunStepChanged)
 		}
+		if IsRunInterrupted(ctx) {
+			slog.WarnContext(ctx, "graph run node_error_interrupted",
+				"run_id", t.run.ID,
+				"node_key", span.NodeName,
+			)
+			return
+		}
+		if t.retryTransientFailures && runerrors.IsTransient(runErr) {
+			slog.ErrorContext(ctx, "graph run node_error_retryable",
+				"run_id", t.run.ID,
+				"node_key", span.NodeName,
+			)
+			return
+		}
 		if _, err := FinalizeRun(t.db, t.run.ID, t.organizationID, "failed", span.State, runErr); err != nil {
-			log.Printf("graph run failed db_write_failed run_id=%s err=%v", t.run.ID, err)
+			slog.ErrorContext(ctx, "graph run failed db_write_failed", "run_id", t.run.ID, "err", err)
 		}
 
 	}
 }
 
 func terminalRunUpdates(status string, finalState any, runErr error) (map[string]any, error) {
-	if status != "completed" && status != "failed" {
+	if status != "completed" && status != "failed" && status != "cancelled" {
 		return nil, fmt.Errorf("invalid terminal run status %q", status)
 	}
 	if status == "failed" && runErr == nil {
@@ -237,9 +310,11 @@ func terminalRunUpdates(status string, finalState any, runErr error) (map[string
 		"status":      status,
 		"finished_at": time.Now(),
 		"error":       nil,
+		"error_code":  nil,
 	}
 	if runErr != nil {
 		updates["error"] = runErr.Error()
+		updates["error_code"] = errorcodes.Of(runErr)
 	}
 	if finalState != nil {
 		stateJSON, err := json.Marshal(finalState)
@@ -251,20 +326,25 @@ func terminalRunUpdates(status string, finalState any, runErr error) (map[string
 	return updates, nil
 }
 
-func updateRunningRun(db *gorm.DB, runID string, updates map[string]any) *gorm.DB {
+// updateRunningRun applies updates to runID while it is running and belongs
+// to a workflow of organizationID, so a run ID from another organization
+// matches nothing.
+func updateRunningRun(db *gorm.DB, runID string, organizationID string, updates map[string]any) *gorm.DB {
 	return db.Model(&dbmodels.AgentGraphRun{}).
 		Where("id = ? AND status = ?", runID, "running").
+		Where("agent_graph_id IN (SELECT id FROM agent_graphs WHERE organization_id = ?)", organizationID).
 		Updates(updates)
 }
 
-// FinalizeRun atomically transitions a running run once and publishes its terminal event.
+// FinalizeRun atomically transitions a running run of organizationID once and
+// publishes its terminal event.
 func FinalizeRun(db *gorm.DB, runID string, organizationID string, status string, finalState any, runErr error) (bool, error) {
 	updates, err := terminalRunUpdates(status, finalState, runErr)
 	if err != nil {
 		return false, err
 	}
 
-	tx := updateRunningRun(db, runID, updates)
+	tx := updateRunningRun(db, runID, organizationID, updates)
 	if tx.Error != nil {
 		return false, tx.Error
 	}
@@ -275,13 +355,15 @@ func FinalizeRun(db *gorm.DB, runID string, organizationID string, status string
 	event := realtime.NewDashboardEvent(realtime.DashboardReasonRunFinished, organizationID)
 	event.RunID = runID
 	if err := publishDashboardEvent(context.Background(), event); err != nil {
-		log.Printf(
-			"graph run realtime_publish_failed run_id=%s reason=%s err=%v",
-			runID,
-			realtime.DashboardReasonRunFinished,
-			err,
+		slog.Error("graph run realtime_publish_failed",
+			"run_id", runID,
+			"reason", realtime.DashboardReasonRunFinished,
+			"err", err,
 		)
 	}
+	for _, hook := range runFinalizedHooks {
+		hook(db, runID, status)
+	}
 
 	return true, nil
 }
@@ -296,11 +378,6 @@ func (t *RunTracker) publish(reason string) {
 	event.RunID = t.run.ID
 
 	if err := publishDashboardEvent(context.Background(), event); err != nil {
-		log.Printf(
-			"graph run realtime_publish_failed run_id=%s reason=%s err=%v",
-			t.run.ID,
-			reason,
-			err,
-		)
+		slog.Error("graph run realtime_publish_failed", "run_id", t.run.ID, "reason", reason, "err", err)
 	}
 }
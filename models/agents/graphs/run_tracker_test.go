@@ -8,6 +8,7 @@ import (
 	"time"
 
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/arcnem-ai/arcnem-vision/models/shared/realtime"
 	"github.com/smallnest/langgraphgo/graph"
 	"gorm.io/gorm"
@@ -54,6 +55,48 @@ func TestNodeErrorFinalizesAndPublishesWithoutGraphEnd(t *testing.T) {
 	}
 }
 
+func TestNodeErrorLeavesInterruptedRunRunning(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	if err := db.Callback().Update().Replace("gorm:update", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}); err != nil {
+		t.Fatalf("replace dry-run update callback: %v", err)
+	}
+
+	previousPublisher := publishDashboardEvent
+	var reasons []string
+	publishDashboardEvent = func(_ context.Context, event realtime.DashboardEvent) error {
+		reasons = append(reasons, event.Reason)
+		return nil
+	}
+	t.Cleanup(func() { publishDashboardEvent = previousPublisher })
+
+	ctx, interrupt := context.WithCancelCause(context.Background())
+	interrupt(ErrRunInterrupted)
+	tracker := &RunTracker{
+		db:             db,
+		run:            &dbmodels.AgentGraphRun{ID: "run-1"},
+		organizationID: "org-1",
+		steps: map[string]*dbmodels.AgentGraphRunStep{
+			"span-1": {RunID: "run-1", NodeKey: "inspect", StepOrder: 1},
+		},
+	}
+	tracker.OnEvent(ctx, &graph.TraceSpan{
+		ID:       "span-1",
+		Event:    graph.TraceEventNodeError,
+		NodeName: "inspect",
+		EndTime:  time.Now(),
+		Error:    context.Canceled,
+	})
+
+	if len(reasons) != 1 || reasons[0] != realtime.DashboardReasonRunStepChanged {
+		t.Fatalf("expected the interrupted run to stay running, got %#v", reasons)
+	}
+}
+
 func TestTerminalRunUpdatesRecordsFailureState(t *testing.T) {
 	updates, err := terminalRunUpdates(
 		"failed",
@@ -66,6 +109,9 @@ func TestTerminalRunUpdatesRecordsFailureState(t *testing.T) {
 	if updates["status"] != "failed" || updates["error"] != "provider unavailable" {
 		t.Fatalf("unexpected failure updates: %#v", updates)
 	}
+	if updates["error_code"] != errorcodes.Unknown {
+		t.Fatalf("unexpected error code: %#v", updates["error_code"])
+	}
 	if updates["final_state"] != `{"node":"inspect"}` {
 		t.Fatalf("unexpected final state: %#v", updates["final_state"])
 	}
@@ -99,9 +145,12 @@ func TestUpdateRunningRunGuardsTerminalState(t *testing.T) {
 		t.Fatalf("open dry-run db: %v", err)
 	}
 
-	tx := updateRunningRun(db, "run-1", map[string]any{"status": "failed"})
+	tx := updateRunningRun(db, "run-1", "org-1", map[string]any{"status": "failed"})
 	query := tx.Statement.SQL.String()
 	if !strings.Contains(query, "id = ? AND status = ?") {
 		t.Fatalf("terminal update is not guarded by running status: %s", query)
 	}
+	if !strings.Contains(query, "agent_graph_id IN (SELECT id FROM agent_graphs WHERE organization_id = ?)") {
+		t.Fatalf("terminal update is not scoped to the organization: %s", query)
+	}
 }
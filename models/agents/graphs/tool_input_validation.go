@@ -0,0 +1,203 @@
+package graphs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+)
+
+// toolInputSchema validates a tool node's assembled input against the tool's
+// inputSchema before the MCP call is made.
+type toolInputSchema struct {
+	schema   *workerOutputSchema
+	resolver *workerOutputRefResolver
+}
+
+// toolInputError lists every problem with a tool node's input. Missing holds
+// the required fields that were absent, which skip_if_missing acts on.
+type toolInputError struct {
+	Missing  []string
+	Problems []string
+}
+
+func (err *toolInputError) Error() string {
+	return "invalid tool input: " + strings.Join(err.Problems, "; ")
+}
+
+func (err *toolInputError) ErrorCode() errorcodes.Code {
+	return errorcodes.ToolInputInvalid
+}
+
+// onlyMissing reports whether the input failed solely because required fields
+// were absent.
+func (err *toolInputError) onlyMissing() bool {
+	return len(err.Missing) > 0 && len(err.Missing) == len(err.Problems)
+}
+
+// parseToolInputSchema decodes a tool's inputSchema. Unlike worker
+// output_schema, tool schemas come from MCP servers and may carry vendor
+// keywords (e.g. "x-order"), which JSON Schema says to ignore, so unknown
+// keywords are pruned before decoding.
+func parseToolInputSchema(schemaJSON string) (*toolInputSchema, error) {
+	if strings.TrimSpace(schemaJSON) == "" {
+		return nil, nil
+	}
+
+	var raw any
+	if err := json.Unmarshal([]byte(schemaJSON), &raw); err != nil {
+		return nil, err
+	}
+	pruned, err := json.Marshal(pruneUnknownSchemaKeywords(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	var schema workerOutputSchema
+	if err := json.Unmarshal(pruned, &schema); err != nil {
+		return nil, err
+	}
+	resolver, err := newWorkerOutputRefResolver(&schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &toolInputSchema{schema: &schema, resolver: resolver}, nil
+}
+
+// validate checks input and returns a *toolInputError naming each failing
+// field. Values are round-tripped through JSON first so state values of Go
+// types (ints, typed slices, structs) are checked the way the tool will see them.
+// Fan-out nodes call validate from several goroutines, so each call gets its
+// own validator and only the resolver is shared.
+func (s *toolInputSchema) validate(input map[string]any) error {
+	if s == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("invalid tool input: failed to encode input: %w", err)
+	}
+	var record map[string]any
+	if err := json.Unmarshal(encoded, &record); err != nil {
+		return fmt.Errorf("invalid tool input: failed to decode input: %w", err)
+	}
+
+	validator := &workerOutputValidator{resolver: s.resolver, activeRefs: make(map[string]bool)}
+	inputErr := &toolInputError{Missing: s.missingRequired(record)}
+	for _, field := range inputErr.Missing {
+		inputErr.Problems = append(inputErr.Problems, fmt.Sprintf("missing required field %q", field))
+	}
+
+	for _, field := range sortedJSONKeys(record) {
+		property, ok := s.schema.Properties[field]
+		if !ok {
+			continue
+		}
+		if _, err := validator.validate(field, record[field], &property); err != nil {
+			inputErr.Problems = append(inputErr.Problems, err.Error())
+		}
+	}
+
+	// Whole-object keywords (oneOf, dependentRequired, ...) only once every
+	// field checks out, so their errors don't repeat the per-field ones.
+	if len(inputErr.Problems) == 0 {
+		root := workerOutputProperty(*s.schema)
+		if _, err := validator.validate("", record, &root); err != nil {
+			inputErr.Problems = append(inputErr.Problems, err.Error())
+		}
+	}
+
+	if len(inputErr.Problems) == 0 {
+		return nil
+	}
+	return inputErr
+}
+
+// missingRequired returns the required fields absent from input.
+func (s *toolInputSchema) missingRequired(input map[string]any) []string {
+	if s == nil {
+		return nil
+	}
+	var missing []string
+	for _, field := range s.schema.Required {
+		if _, ok := input[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+var (
+	schemaMapKeywords = map[string]bool{
+		"$defs": true, "definitions": true, "properties": true,
+		"patternProperties": true, "dependentSchemas": true,
+	}
+	schemaListKeywords = map[string]bool{
+		"allOf": true, "anyOf": true, "oneOf": true, "prefixItems": true,
+	}
+	schemaValueKeywords = map[string]bool{
+		"not": true, "if": true, "then": true, "else": true, "items": true,
+		"contains": true, "additionalProperties": true, "propertyNames": true,
+		"unevaluatedItems": true, "unevaluatedProperties": true, "contentSchema": true,
+	}
+	schemaPlainKeywords = map[string]bool{
+		"$schema": true, "$id": true, "$anchor": true, "$dynamicAnchor": true,
+		"$ref": true, "$dynamicRef": true, "$vocabulary": true, "$comment": true,
+		"title": true, "description": true, "default": true, "examples": true,
+		"readOnly": true, "writeOnly": true, "deprecated": true,
+		"type": true, "nullable": true, "enum": true, "const": true,
+		"multipleOf": true, "minimum": true, "maximum": true,
+		"exclusiveMinimum": true, "exclusiveMaximum": true,
+		"minLength": true, "maxLength": true, "pattern": true, "format": true,
+		"contentEncoding": true, "contentMediaType": true,
+		"minItems": true, "maxItems": true, "uniqueItems": true,
+		"minContains": true, "maxContains": true,
+		"minProperties": true, "maxProperties": true,
+		"required": true, "dependentRequired": true,
+	}
+)
+
+// pruneUnknownSchemaKeywords drops keywords workerOutputProperty does not
+// model from every (sub)schema in node.
+func pruneUnknownSchemaKeywords(node any) any {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return node
+	}
+
+	pruned := make(map[string]any, len(schema))
+	for keyword, value := range schema {
+		switch {
+		case schemaPlainKeywords[keyword]:
+			pruned[keyword] = value
+		case schemaValueKeywords[keyword]:
+			pruned[keyword] = pruneUnknownSchemaKeywords(value)
+		case schemaMapKeywords[keyword]:
+			children, ok := value.(map[string]any)
+			if !ok {
+				pruned[keyword] = value
+				continue
+			}
+			prunedChildren := make(map[string]any, len(children))
+			for name, child := range children {
+				prunedChildren[name] = pruneUnknownSchemaKeywords(child)
+			}
+			pruned[keyword] = prunedChildren
+		case schemaListKeywords[keyword]:
+			children, ok := value.([]any)
+			if !ok {
+				pruned[keyword] = value
+				continue
+			}
+			prunedChildren := make([]any, len(children))
+			for index, child := range children {
+				prunedChildren[index] = pruneUnknownSchemaKeywords(child)
+			}
+			pruned[keyword] = prunedChildren
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,64 @@
+package graphs
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	workerOutputHostnamePattern    = regexp.MustCompile(`^(?i:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)(?:\.(?i:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?))*$`)
+	workerOutputUUIDPattern        = regexp.MustCompile(`^(?i:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+	workerOutputDurationPattern    = regexp.MustCompile(`^P(?:\d+W|(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+(?:\.\d+)?S)?)?)$`)
+	workerOutputJSONPointerPattern = regexp.MustCompile(`^(?:/(?:[^~/]|~[01])*)*$`)
+)
+
+// matchesWorkerOutputFormat asserts the draft 2020-12 formats workers are likely
+// to produce. Unknown formats are annotations only and always match.
+func matchesWorkerOutputFormat(format string, text string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, text)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, text)
+		return err == nil
+	case "time":
+		for _, layout := range []string{"15:04:05Z07:00", "15:04:05.999999999Z07:00"} {
+			if _, err := time.Parse(layout, text); err == nil {
+				return true
+			}
+		}
+		return false
+	case "duration":
+		return workerOutputDurationPattern.MatchString(text) && text != "P" && !strings.HasSuffix(text, "T")
+	case "email", "idn-email":
+		address, err := mail.ParseAddress(text)
+		return err == nil && address.Address == text
+	case "hostname", "idn-hostname":
+		return len(text) <= 253 && workerOutputHostnamePattern.MatchString(text)
+	case "ipv4":
+		ip := net.ParseIP(text)
+		return ip != nil && ip.To4() != nil && !strings.Contains(text, ":")
+	case "ipv6":
+		return net.ParseIP(text) != nil && strings.Contains(text, ":")
+	case "uri", "iri":
+		parsed, err := url.Parse(text)
+		return err == nil && parsed.IsAbs()
+	case "uri-reference", "iri-reference":
+		_, err := url.Parse(text)
+		return err == nil
+	case "uuid":
+		return workerOutputUUIDPattern.MatchString(text)
+	case "regex":
+		_, err := regexp.Compile(text)
+		return err == nil
+	case "json-pointer":
+		return workerOutputJSONPointerPattern.MatchString(text)
+	default:
+		return true
+	}
+}
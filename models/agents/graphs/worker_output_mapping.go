@@ -0,0 +1,71 @@
+package graphs
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// workerOutputMapping copies one JSON path of a worker's validated structured
+// output into a state key, keeping the decoded JSON type.
+type workerOutputMapping struct {
+	path     string
+	segments []jsonPathSegment
+	stateKey string
+}
+
+// parseWorkerOutputMappings compiles output_mapping ({"json.path": "state_key"})
+// into mappings ordered by path.
+func parseWorkerOutputMappings(config workerAgentConfig) ([]workerOutputMapping, error) {
+	if len(config.OutputMapping) == 0 {
+		return nil, nil
+	}
+	if config.OutputSchema == nil {
+		return nil, fmt.Errorf("output_mapping requires output_schema")
+	}
+
+	mappings := make([]workerOutputMapping, 0, len(config.OutputMapping))
+	for path, stateKey := range config.OutputMapping {
+		stateKey = strings.TrimSpace(stateKey)
+		if stateKey == "" {
+			return nil, fmt.Errorf("output_mapping %q has an empty state key", path)
+		}
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("output_mapping: %w", err)
+		}
+		mappings = append(mappings, workerOutputMapping{
+			path:     path,
+			segments: segments,
+			stateKey: stateKey,
+		})
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].path < mappings[j].path
+	})
+
+	return mappings, nil
+}
+
+// applyWorkerOutputMappings writes each mapped value into delta. Paths missing
+// from the output (e.g. optional fields) are skipped.
+func applyWorkerOutputMappings(
+	nodeKey string,
+	record map[string]any,
+	mappings []workerOutputMapping,
+	delta map[string]any,
+) {
+	for _, mapping := range mappings {
+		value, ok := lookupJSONPath(record, mapping.segments)
+		if !ok {
+			slog.Warn("graph worker output_mapping_missing",
+				"node_key", nodeKey,
+				"path", mapping.path,
+				"state_key", mapping.stateKey,
+			)
+			continue
+		}
+		delta[mapping.stateKey] = value
+	}
+}
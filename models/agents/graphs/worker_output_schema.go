@@ -0,0 +1,441 @@
+package graphs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// workerOutputSchema is the root of a worker output_schema. It is a JSON Schema
+// draft 2020-12 document whose root must describe an object.
+type workerOutputSchema workerOutputProperty
+
+// workerOutputProperty is a JSON Schema draft 2020-12 (sub)schema. Boolean
+// schemas (true/false) are represented by boolean. additionalProperties keeps
+// its boolean form in AdditionalProperties and its schema form in
+// AdditionalPropertiesSchema. Unknown keywords, including vendor extensions
+// such as x-* or propertyOrder, are ignored as draft 2020-12 requires.
+// pattern and patternProperties are compiled once when the schema is decoded.
+type workerOutputProperty struct {
+	SchemaURI     string                          `json:"$schema,omitempty"`
+	ID            string                          `json:"$id,omitempty"`
+	Anchor        string                          `json:"$anchor,omitempty"`
+	DynamicAnchor string                          `json:"$dynamicAnchor,omitempty"`
+	Ref           string                          `json:"$ref,omitempty"`
+	DynamicRef    string                          `json:"$dynamicRef,omitempty"`
+	Defs          map[string]workerOutputProperty `json:"$defs,omitempty"`
+	Definitions   map[string]workerOutputProperty `json:"definitions,omitempty"`
+	Vocabulary    map[string]bool                 `json:"$vocabulary,omitempty"`
+	Comment       string                          `json:"$comment,omitempty"`
+
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Examples    []any  `json:"examples,omitempty"`
+	ReadOnly    bool   `json:"readOnly,omitempty"`
+	WriteOnly   bool   `json:"writeOnly,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+
+	Type     any   `json:"type,omitempty"`
+	Nullable bool  `json:"nullable,omitempty"`
+	Enum     []any `json:"enum,omitempty"`
+	Const    any   `json:"const,omitempty"`
+
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	MinLength        *int   `json:"minLength,omitempty"`
+	MaxLength        *int   `json:"maxLength,omitempty"`
+	Pattern          string `json:"pattern,omitempty"`
+	Format           string `json:"format,omitempty"`
+	ContentEncoding  string `json:"contentEncoding,omitempty"`
+	ContentMediaType string `json:"contentMediaType,omitempty"`
+
+	MinItems    *int `json:"minItems,omitempty"`
+	MaxItems    *int `json:"maxItems,omitempty"`
+	UniqueItems bool `json:"uniqueItems,omitempty"`
+	MinContains *int `json:"minContains,omitempty"`
+	MaxContains *int `json:"maxContains,omitempty"`
+
+	MinProperties     *int                `json:"minProperties,omitempty"`
+	MaxProperties     *int                `json:"maxProperties,omitempty"`
+	Required          []string            `json:"required,omitempty"`
+	DependentRequired map[string][]string `json:"dependentRequired,omitempty"`
+
+	AllOf                      []workerOutputProperty          `json:"allOf,omitempty"`
+	AnyOf                      []workerOutputProperty          `json:"anyOf,omitempty"`
+	OneOf                      []workerOutputProperty          `json:"oneOf,omitempty"`
+	Not                        *workerOutputProperty           `json:"not,omitempty"`
+	If                         *workerOutputProperty           `json:"if,omitempty"`
+	Then                       *workerOutputProperty           `json:"then,omitempty"`
+	Else                       *workerOutputProperty           `json:"else,omitempty"`
+	DependentSchemas           map[string]workerOutputProperty `json:"dependentSchemas,omitempty"`
+	PrefixItems                []workerOutputProperty          `json:"prefixItems,omitempty"`
+	Items                      *workerOutputProperty           `json:"items,omitempty"`
+	Contains                   *workerOutputProperty           `json:"contains,omitempty"`
+	Properties                 map[string]workerOutputProperty `json:"properties,omitempty"`
+	PatternProperties          map[string]workerOutputProperty `json:"patternProperties,omitempty"`
+	AdditionalProperties       *bool                           `json:"-"`
+	AdditionalPropertiesSchema *workerOutputProperty           `json:"-"`
+	PropertyNames              *workerOutputProperty           `json:"propertyNames,omitempty"`
+	UnevaluatedItems           *workerOutputProperty           `json:"unevaluatedItems,omitempty"`
+	UnevaluatedProperties      *workerOutputProperty           `json:"unevaluatedProperties,omitempty"`
+	ContentSchema              *workerOutputProperty           `json:"contentSchema,omitempty"`
+
+	boolean           *bool
+	hasConst          bool
+	pattern           *regexp.Regexp
+	patternProperties map[string]*regexp.Regexp
+}
+
+type workerOutputPropertyFields workerOutputProperty
+
+// workerOutputPropertyJSON is the wire form of workerOutputProperty. The outer
+// fields shadow the embedded ones for the keywords that need special handling.
+type workerOutputPropertyJSON struct {
+	workerOutputPropertyFields
+	AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	Const                json.RawMessage `json:"const,omitempty"`
+}
+
+func (schema *workerOutputSchema) UnmarshalJSON(data []byte) error {
+	var decoded workerOutputProperty
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("unsupported worker output schema: %w", err)
+	}
+	if decoded.boolean != nil {
+		return fmt.Errorf("unsupported worker output schema: root must be a schema object")
+	}
+
+	*schema = workerOutputSchema(decoded)
+	if err := schema.checkReferences(); err != nil {
+		return fmt.Errorf("unsupported worker output schema: %w", err)
+	}
+	return nil
+}
+
+func (schema workerOutputSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(workerOutputProperty(schema))
+}
+
+func (property *workerOutputProperty) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if value, err := strconv.ParseBool(string(trimmed)); err == nil {
+		*property = workerOutputProperty{boolean: &value}
+		return nil
+	}
+
+	var decoded workerOutputPropertyJSON
+	if err := json.Unmarshal(trimmed, &decoded); err != nil {
+		return err
+	}
+
+	result := workerOutputProperty(decoded.workerOutputPropertyFields)
+	if len(decoded.AdditionalProperties) > 0 {
+		if allowed, err := strconv.ParseBool(string(bytes.TrimSpace(decoded.AdditionalProperties))); err == nil {
+			result.AdditionalProperties = &allowed
+		} else {
+			var additional workerOutputProperty
+			if err := json.Unmarshal(decoded.AdditionalProperties, &additional); err != nil {
+				return err
+			}
+			result.AdditionalPropertiesSchema = &additional
+		}
+	}
+	if len(decoded.Const) > 0 {
+		if err := json.Unmarshal(decoded.Const, &result.Const); err != nil {
+			return err
+		}
+		result.hasConst = true
+	}
+	if err := result.compilePatterns(); err != nil {
+		return err
+	}
+
+	*property = result
+	return nil
+}
+
+// compilePatterns compiles pattern and the patternProperties keys so an
+// invalid regular expression fails when the workflow is loaded and
+// validation does not recompile them for every value.
+func (property *workerOutputProperty) compilePatterns() error {
+	if property.Pattern != "" {
+		re, err := regexp.Compile(property.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", property.Pattern, err)
+		}
+		property.pattern = re
+	}
+	if len(property.PatternProperties) > 0 {
+		property.patternProperties = make(map[string]*regexp.Regexp, len(property.PatternProperties))
+		for pattern := range property.PatternProperties {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid patternProperties key %q: %w", pattern, err)
+			}
+			property.patternProperties[pattern] = re
+		}
+	}
+	return nil
+}
+
+// patternRegexp returns the compiled pattern. Schemas assembled in Go rather
+// than decoded from JSON have not been compiled yet, so it falls back to
+// compiling on demand.
+func (property *workerOutputProperty) patternRegexp() (*regexp.Regexp, error) {
+	if property.pattern != nil {
+		return property.pattern, nil
+	}
+	return regexp.Compile(property.Pattern)
+}
+
+// patternPropertyRegexp is patternRegexp for a patternProperties key.
+func (property *workerOutputProperty) patternPropertyRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := property.patternProperties[pattern]; ok {
+		return re, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+func (property workerOutputProperty) MarshalJSON() ([]byte, error) {
+	if property.boolean != nil {
+		return json.Marshal(*property.boolean)
+	}
+
+	encoded := workerOutputPropertyJSON{workerOutputPropertyFields: workerOutputPropertyFields(property)}
+	switch {
+	case property.AdditionalPropertiesSchema != nil:
+		raw, err := json.Marshal(property.AdditionalPropertiesSchema)
+		if err != nil {
+			return nil, err
+		}
+		encoded.AdditionalProperties = raw
+	case property.AdditionalProperties != nil:
+		encoded.AdditionalProperties = json.RawMessage(strconv.FormatBool(*property.AdditionalProperties))
+	}
+	if property.hasConst || property.Const != nil {
+		raw, err := json.Marshal(property.Const)
+		if err != nil {
+			return nil, err
+		}
+		encoded.Const = raw
+	}
+
+	return json.Marshal(encoded)
+}
+
+// subschemas calls visit for every schema nested directly in property.
+func (property *workerOutputProperty) subschemas(visit func(*workerOutputProperty)) {
+	for _, group := range []map[string]workerOutputProperty{
+		property.Defs,
+		property.Definitions,
+		property.DependentSchemas,
+		property.Properties,
+		property.PatternProperties,
+	} {
+		for key := range group {
+			child := group[key]
+			visit(&child)
+		}
+	}
+	for _, group := range [][]workerOutputProperty{
+		property.AllOf,
+		property.AnyOf,
+		property.OneOf,
+		property.PrefixItems,
+	} {
+		for index := range group {
+			visit(&group[index])
+		}
+	}
+	for _, child := range []*workerOutputProperty{
+		property.Not,
+		property.If,
+		property.Then,
+		property.Else,
+		property.Items,
+		property.Contains,
+		property.AdditionalPropertiesSchema,
+		property.PropertyNames,
+		property.UnevaluatedItems,
+		property.UnevaluatedProperties,
+		property.ContentSchema,
+	} {
+		if child != nil {
+			visit(child)
+		}
+	}
+}
+
+// checkReferences resolves every $ref up front so a broken reference fails
+// when the workflow is loaded instead of on the first model response.
+func (schema *workerOutputSchema) checkReferences() error {
+	resolver, err := newWorkerOutputRefResolver(schema)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	var visit func(*workerOutputProperty)
+	visit = func(property *workerOutputProperty) {
+		if firstErr != nil {
+			return
+		}
+		for _, ref := range []string{property.Ref, property.DynamicRef} {
+			if ref == "" {
+				continue
+			}
+			if _, err := resolver.resolve(ref); err != nil {
+				firstErr = err
+				return
+			}
+		}
+		property.subschemas(visit)
+	}
+
+	root := workerOutputProperty(*schema)
+	visit(&root)
+	return firstErr
+}
+
+// workerOutputRefResolver resolves references within a single output schema
+// document: "#", JSON pointers such as "#/$defs/item", and "#name" anchors.
+// References to other documents are not supported. A resolver may be shared
+// by concurrent validators; mu guards the resolved cache.
+type workerOutputRefResolver struct {
+	baseID   string
+	document any
+	anchors  map[string]any
+
+	mu       sync.Mutex
+	resolved map[string]*workerOutputProperty
+}
+
+func newWorkerOutputRefResolver(schema *workerOutputSchema) (*workerOutputRefResolver, error) {
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	var document any
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode schema: %w", err)
+	}
+
+	resolver := &workerOutputRefResolver{
+		baseID:   strings.TrimSuffix(schema.ID, "#"),
+		document: document,
+		anchors:  make(map[string]any),
+		resolved: make(map[string]*workerOutputProperty),
+	}
+	collectWorkerOutputAnchors(document, resolver.anchors)
+	return resolver, nil
+}
+
+func collectWorkerOutputAnchors(node any, anchors map[string]any) {
+	switch typed := node.(type) {
+	case map[string]any:
+		for _, keyword := range []string{"$anchor", "$dynamicAnchor"} {
+			if name, ok := typed[keyword].(string); ok && name != "" {
+				anchors[name] = typed
+			}
+		}
+		for _, child := range typed {
+			collectWorkerOutputAnchors(child, anchors)
+		}
+	case []any:
+		for _, child := range typed {
+			collectWorkerOutputAnchors(child, anchors)
+		}
+	}
+}
+
+func (resolver *workerOutputRefResolver) resolve(ref string) (*workerOutputProperty, error) {
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+	if resolved, ok := resolver.resolved[ref]; ok {
+		return resolved, nil
+	}
+
+	fragment, ok := resolver.localFragment(ref)
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q: only references within the output schema are supported", ref)
+	}
+
+	var target any
+	switch {
+	case fragment == "":
+		target = resolver.document
+	case strings.HasPrefix(fragment, "/"):
+		node, err := resolveWorkerOutputPointer(resolver.document, fragment)
+		if err != nil {
+			return nil, fmt.Errorf("unresolvable $ref %q: %w", ref, err)
+		}
+		target = node
+	default:
+		node, ok := resolver.anchors[fragment]
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q: unknown anchor %q", ref, fragment)
+		}
+		target = node
+	}
+
+	encoded, err := json.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("unresolvable $ref %q: %w", ref, err)
+	}
+	var property workerOutputProperty
+	if err := json.Unmarshal(encoded, &property); err != nil {
+		return nil, fmt.Errorf("$ref %q does not point to a schema: %w", ref, err)
+	}
+
+	resolver.resolved[ref] = &property
+	return &property, nil
+}
+
+// localFragment returns the decoded fragment of ref when it targets this document.
+func (resolver *workerOutputRefResolver) localFragment(ref string) (string, bool) {
+	base, fragment, _ := strings.Cut(ref, "#")
+	if base != "" && base != resolver.baseID {
+		return "", false
+	}
+
+	decoded, err := url.PathUnescape(fragment)
+	if err != nil {
+		return "", false
+	}
+	return decoded, true
+}
+
+func resolveWorkerOutputPointer(document any, pointer string) (any, error) {
+	current := document
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch typed := current.(type) {
+		case map[string]any:
+			next, ok := typed[token]
+			if !ok {
+				return nil, fmt.Errorf("no %q member", token)
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(typed) {
+				return nil, fmt.Errorf("invalid array index %q", token)
+			}
+			current = typed[index]
+		default:
+			return nil, fmt.Errorf("cannot descend into %q", token)
+		}
+	}
+	return current, nil
+}
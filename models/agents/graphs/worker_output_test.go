@@ -25,18 +25,40 @@ func TestParseWorkerConfigAcceptsSupportedOutputSchemaProfile(t *testing.T) {
 	}
 }
 
-func TestParseWorkerConfigRejectsUnsupportedOutputSchemaKeyword(t *testing.T) {
+func TestParseWorkerConfigIgnoresVendorOutputSchemaKeywords(t *testing.T) {
 	snapshotNode := &SnapshotNode{Node: &dbmodels.AgentGraphNode{
 		NodeKey: "extract",
-		Config:  `{"output_schema":{"type":"object","properties":{"items":{"type":"array","items":{"type":"object","properties":{"label":{"type":"string","maxLength":10}}}}}}}`,
+		Config:  `{"output_schema":{"type":"object","x-order":["label"],"propertyOrder":["label"],"properties":{"label":{"type":"string","x-display":"Label"}}}}`,
 	}}
 
-	_, _, _, err := parseWorkerConfig(snapshotNode)
-	if err == nil {
-		t.Fatal("expected unsupported output schema keyword to fail")
+	config, _, _, err := parseWorkerConfig(snapshotNode)
+	if err != nil {
+		t.Fatalf("expected vendor keywords to be ignored: %v", err)
+	}
+	if config.OutputSchema == nil {
+		t.Fatal("expected output schema")
 	}
-	if !strings.Contains(err.Error(), `unsupported worker output schema: json: unknown field "maxLength"`) {
-		t.Fatalf("expected clear unsupported-keyword error, got %v", err)
+	if _, err := normalizeStructuredWorkerOutput(`{"label":"match"}`, config.OutputSchema); err != nil {
+		t.Fatalf("expected output to validate: %v", err)
+	}
+}
+
+func TestParseWorkerConfigRejectsInvalidOutputSchemaPattern(t *testing.T) {
+	for name, schema := range map[string]string{
+		"pattern":           `{"type":"object","properties":{"code":{"type":"string","pattern":"[A-Z"}}}`,
+		"patternProperties": `{"type":"object","patternProperties":{"(x":{"type":"string"}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			snapshotNode := &SnapshotNode{Node: &dbmodels.AgentGraphNode{
+				NodeKey: "extract",
+				Config:  `{"output_schema":` + schema + `}`,
+			}}
+
+			_, _, _, err := parseWorkerConfig(snapshotNode)
+			if err == nil || !strings.Contains(err.Error(), "unsupported worker output schema: invalid pattern") {
+				t.Fatalf("expected invalid pattern error, got %v", err)
+			}
+		})
 	}
 }
 
@@ -113,6 +135,77 @@ func TestNormalizeStructuredWorkerOutputValidatesNestedArrayItems(t *testing.T)
 	}
 }
 
+func TestNormalizeStructuredWorkerOutputValidatesDraft2020Keywords(t *testing.T) {
+	snapshotNode := &SnapshotNode{Node: &dbmodels.AgentGraphNode{
+		NodeKey: "extract",
+		Config:  `{"output_schema":{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","required":["items"],"$defs":{"item":{"type":"object","required":["kind"],"properties":{"kind":{"const":"box"},"width":{"type":"number","multipleOf":0.5}},"unevaluatedProperties":false}},"properties":{"items":{"type":"array","minItems":1,"items":{"$ref":"#/$defs/item"}},"captured_at":{"type":"string","format":"date-time"},"code":{"type":"string","maxLength":3},"label":{"oneOf":[{"type":"string"},{"type":"integer"}]},"contact":{"anyOf":[{"type":"null"},{"type":"string","format":"email"}]},"note":{"type":"string","nullable":true}},"additionalProperties":{"type":"string"}}}`,
+	}}
+
+	config, _, _, err := parseWorkerConfig(snapshotNode)
+	if err != nil {
+		t.Fatalf("expected draft 2020-12 output schema to decode: %v", err)
+	}
+
+	valid := `{"items":[{"kind":"box","width":1.5}],"captured_at":"2026-01-02T03:04:05Z","code":"abc","label":3,"contact":null,"note":null,"extra":"kept"}`
+	if _, err := normalizeStructuredWorkerOutput(valid, config.OutputSchema); err != nil {
+		t.Fatalf("expected valid output: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "minItems", output: `{"items":[]}`, want: `field "items" must contain at least 1 items`},
+		{name: "const via $ref", output: `{"items":[{"kind":"crate"}]}`, want: `field "items[0].kind" must equal "box"`},
+		{name: "unevaluatedProperties", output: `{"items":[{"kind":"box","depth":2}]}`, want: `unexpected field "items[0].depth"`},
+		{name: "multipleOf", output: `{"items":[{"kind":"box","width":1.2}]}`, want: `field "items[0].width" must be a multiple of 0.5`},
+		{name: "format", output: `{"items":[{"kind":"box"}],"captured_at":"yesterday"}`, want: `field "captured_at" must be a valid date-time`},
+		{name: "maxLength", output: `{"items":[{"kind":"box"}],"code":"abcd"}`, want: `field "code" must have at most 3 characters`},
+		{name: "oneOf", output: `{"items":[{"kind":"box"}],"label":1.5}`, want: `field "label" must match exactly one oneOf schema`},
+		{name: "anyOf", output: `{"items":[{"kind":"box"}],"contact":"not-an-email"}`, want: `field "contact" must be a valid email`},
+		{name: "additionalProperties schema", output: `{"items":[{"kind":"box"}],"extra":1}`, want: `field "extra" has invalid type float64`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := normalizeStructuredWorkerOutput(test.output, config.OutputSchema)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeStructuredWorkerOutputFollowsRecursiveRefs(t *testing.T) {
+	snapshotNode := &SnapshotNode{Node: &dbmodels.AgentGraphNode{
+		NodeKey: "extract",
+		Config:  `{"output_schema":{"type":"object","properties":{"tree":{"$ref":"#/$defs/node"}},"$defs":{"node":{"type":"object","properties":{"value":{"type":"integer"},"child":{"$ref":"#/$defs/node"}}}}}}`,
+	}}
+
+	config, _, _, err := parseWorkerConfig(snapshotNode)
+	if err != nil {
+		t.Fatalf("expected recursive output schema to decode: %v", err)
+	}
+
+	_, err = normalizeStructuredWorkerOutput(`{"tree":{"child":{"child":{"value":"three"}}}}`, config.OutputSchema)
+	if err == nil || !strings.Contains(err.Error(), `field "tree.child.child.value" has invalid type string`) {
+		t.Fatalf("expected nested path in error, got %v", err)
+	}
+}
+
+func TestParseWorkerConfigRejectsUnresolvableOutputSchemaRef(t *testing.T) {
+	snapshotNode := &SnapshotNode{Node: &dbmodels.AgentGraphNode{
+		NodeKey: "extract",
+		Config:  `{"output_schema":{"type":"object","properties":{"item":{"$ref":"#/$defs/missing"}}}}`,
+	}}
+
+	_, _, _, err := parseWorkerConfig(snapshotNode)
+	if err == nil || !strings.Contains(err.Error(), `unresolvable $ref "#/$defs/missing"`) {
+		t.Fatalf("expected unresolvable ref error, got %v", err)
+	}
+}
+
 func float64Ptr(value float64) *float64 {
 	return &value
 }
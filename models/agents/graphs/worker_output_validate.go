@@ -0,0 +1,649 @@
+package graphs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// workerOutputValidator validates decoded worker output against a draft
+// 2020-12 schema. Errors name the failing field path (e.g. "items[0].score")
+// so they can be fed back to the model in a repair prompt.
+type workerOutputValidator struct {
+	resolver *workerOutputRefResolver
+	// activeRefs guards against $ref cycles that never descend into the value.
+	activeRefs map[string]bool
+}
+
+// workerOutputEvaluation records which properties and items a schema evaluated,
+// which unevaluatedProperties and unevaluatedItems build on.
+type workerOutputEvaluation struct {
+	properties    map[string]bool
+	items         int
+	allItems      bool
+	containsItems map[int]bool
+}
+
+func (evaluation *workerOutputEvaluation) markProperty(key string) {
+	if evaluation.properties == nil {
+		evaluation.properties = make(map[string]bool)
+	}
+	evaluation.properties[key] = true
+}
+
+func (evaluation *workerOutputEvaluation) markContains(index int) {
+	if evaluation.containsItems == nil {
+		evaluation.containsItems = make(map[int]bool)
+	}
+	evaluation.containsItems[index] = true
+}
+
+func (evaluation *workerOutputEvaluation) itemEvaluated(index int) bool {
+	return evaluation.allItems || index < evaluation.items || evaluation.containsItems[index]
+}
+
+func (evaluation *workerOutputEvaluation) merge(other workerOutputEvaluation) {
+	for key := range other.properties {
+		evaluation.markProperty(key)
+	}
+	for index := range other.containsItems {
+		evaluation.markContains(index)
+	}
+	evaluation.items = max(evaluation.items, other.items)
+	evaluation.allItems = evaluation.allItems || other.allItems
+}
+
+func validateStructuredWorkerOutput(record map[string]any, schema *workerOutputSchema) error {
+	if schema == nil {
+		return nil
+	}
+
+	root := workerOutputProperty(*schema)
+	allowedTypes, err := workerOutputTypes(root.Type)
+	if err != nil || (len(allowedTypes) > 0 && !allowedTypes["object"]) {
+		return fmt.Errorf("worker output schema must declare type object")
+	}
+
+	validator, err := newWorkerOutputValidator(schema)
+	if err != nil {
+		return fmt.Errorf("worker output schema is invalid: %w", err)
+	}
+
+	_, err = validator.validate("", record, &root)
+	return err
+}
+
+func newWorkerOutputValidator(schema *workerOutputSchema) (*workerOutputValidator, error) {
+	resolver, err := newWorkerOutputRefResolver(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &workerOutputValidator{
+		resolver:   resolver,
+		activeRefs: make(map[string]bool),
+	}, nil
+}
+
+func (v *workerOutputValidator) validate(field string, value any, schema *workerOutputProperty) (workerOutputEvaluation, error) {
+	var evaluation workerOutputEvaluation
+	if schema.boolean != nil {
+		if *schema.boolean {
+			return evaluation, nil
+		}
+		return evaluation, fmt.Errorf("%s is not allowed", describeWorkerOutputField(field))
+	}
+
+	for _, ref := range []string{schema.Ref, schema.DynamicRef} {
+		if ref == "" {
+			continue
+		}
+		refEvaluation, err := v.validateRef(field, value, ref)
+		if err != nil {
+			return evaluation, err
+		}
+		evaluation.merge(refEvaluation)
+	}
+
+	allowedTypes, err := workerOutputTypes(schema.Type)
+	if err != nil {
+		return evaluation, fmt.Errorf("%s has invalid schema: %w", describeWorkerOutputField(field), err)
+	}
+	if schema.Nullable && len(allowedTypes) > 0 {
+		allowedTypes["null"] = true
+	}
+
+	// A null accepted by a nullable type skips enum, so ["string","null"] with a
+	// string enum keeps accepting null as it always has.
+	nullAccepted := false
+	if len(allowedTypes) > 0 {
+		if value == nil {
+			if !allowedTypes["null"] {
+				return evaluation, fmt.Errorf("%s does not allow null", describeWorkerOutputField(field))
+			}
+			nullAccepted = true
+		} else if !matchesWorkerOutputType(value, allowedTypes) {
+			return evaluation, fmt.Errorf("%s has invalid type %T", describeWorkerOutputField(field), value)
+		}
+	}
+
+	if schema.hasConst || schema.Const != nil {
+		if !workerOutputValuesEqual(value, schema.Const) {
+			encoded, _ := json.Marshal(schema.Const)
+			return evaluation, fmt.Errorf("%s must equal %s", describeWorkerOutputField(field), encoded)
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		if err := v.validateObject(field, typed, schema, &evaluation); err != nil {
+			return evaluation, err
+		}
+	case []any:
+		if err := v.validateArray(field, typed, schema, &evaluation); err != nil {
+			return evaluation, err
+		}
+	case float64:
+		if err := validateWorkerOutputNumber(field, typed, schema); err != nil {
+			return evaluation, err
+		}
+	case string:
+		if err := validateWorkerOutputString(field, typed, schema); err != nil {
+			return evaluation, err
+		}
+	}
+
+	if len(schema.Enum) > 0 && !nullAccepted {
+		if err := validateWorkerOutputEnum(field, value, schema.Enum); err != nil {
+			return evaluation, err
+		}
+	}
+
+	if err := v.validateApplicators(field, value, schema, &evaluation); err != nil {
+		return evaluation, err
+	}
+
+	return evaluation, v.validateUnevaluated(field, value, schema, &evaluation)
+}
+
+func (v *workerOutputValidator) validateRef(field string, value any, ref string) (workerOutputEvaluation, error) {
+	target, err := v.resolver.resolve(ref)
+	if err != nil {
+		return workerOutputEvaluation{}, fmt.Errorf("%s has invalid schema: %w", describeWorkerOutputField(field), err)
+	}
+
+	active := ref + "\x00" + field
+	if v.activeRefs[active] {
+		return workerOutputEvaluation{}, fmt.Errorf(
+			"%s has invalid schema: $ref %q loops without consuming input",
+			describeWorkerOutputField(field),
+			ref,
+		)
+	}
+	v.activeRefs[active] = true
+	defer delete(v.activeRefs, active)
+
+	return v.validate(field, value, target)
+}
+
+func (v *workerOutputValidator) validateObject(
+	field string,
+	record map[string]any,
+	schema *workerOutputProperty,
+	evaluation *workerOutputEvaluation,
+) error {
+	for _, key := range schema.Required {
+		if _, ok := record[key]; !ok {
+			return fmt.Errorf("missing required field %q", joinWorkerOutputField(field, key))
+		}
+	}
+
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := record[key]
+		childField := joinWorkerOutputField(field, key)
+		matched := false
+
+		if property, ok := schema.Properties[key]; ok {
+			matched = true
+			if _, err := v.validate(childField, value, &property); err != nil {
+				return err
+			}
+			evaluation.markProperty(key)
+		}
+
+		for pattern, property := range schema.PatternProperties {
+			re, err := schema.patternPropertyRegexp(pattern)
+			if err != nil {
+				return fmt.Errorf("%s has invalid pattern %q: %w", describeWorkerOutputField(field), pattern, err)
+			}
+			if !re.MatchString(key) {
+				continue
+			}
+			matched = true
+			if _, err := v.validate(childField, value, &property); err != nil {
+				return err
+			}
+			evaluation.markProperty(key)
+		}
+
+		if matched {
+			continue
+		}
+		if schema.AdditionalProperties != nil {
+			if !*schema.AdditionalProperties {
+				return fmt.Errorf("unexpected field %q", childField)
+			}
+			evaluation.markProperty(key)
+		}
+		if schema.AdditionalPropertiesSchema != nil {
+			if _, err := v.validate(childField, value, schema.AdditionalPropertiesSchema); err != nil {
+				return err
+			}
+			evaluation.markProperty(key)
+		}
+	}
+
+	if schema.MinProperties != nil && len(record) < *schema.MinProperties {
+		return fmt.Errorf("%s must have at least %d properties", describeWorkerOutputField(field), *schema.MinProperties)
+	}
+	if schema.MaxProperties != nil && len(record) > *schema.MaxProperties {
+		return fmt.Errorf("%s must have at most %d properties", describeWorkerOutputField(field), *schema.MaxProperties)
+	}
+
+	if schema.PropertyNames != nil {
+		for _, key := range keys {
+			if _, err := v.validate(field, key, schema.PropertyNames); err != nil {
+				return fmt.Errorf("%s has invalid property name %q: %w", describeWorkerOutputField(field), key, err)
+			}
+		}
+	}
+
+	for _, key := range sortedWorkerOutputKeys(schema.DependentRequired) {
+		if _, present := record[key]; !present {
+			continue
+		}
+		for _, dependency := range schema.DependentRequired[key] {
+			if _, ok := record[dependency]; !ok {
+				return fmt.Errorf(
+					"missing required field %q (required when %q is present)",
+					joinWorkerOutputField(field, dependency),
+					joinWorkerOutputField(field, key),
+				)
+			}
+		}
+	}
+
+	for _, key := range sortedWorkerOutputKeys(schema.DependentSchemas) {
+		if _, present := record[key]; !present {
+			continue
+		}
+		dependent := schema.DependentSchemas[key]
+		dependentEvaluation, err := v.validate(field, record, &dependent)
+		if err != nil {
+			return err
+		}
+		evaluation.merge(dependentEvaluation)
+	}
+
+	return nil
+}
+
+func (v *workerOutputValidator) validateArray(
+	field string,
+	values []any,
+	schema *workerOutputProperty,
+	evaluation *workerOutputEvaluation,
+) error {
+	if schema.MinItems != nil && len(values) < *schema.MinItems {
+		return fmt.Errorf("%s must contain at least %d items", describeWorkerOutputField(field), *schema.MinItems)
+	}
+	if schema.MaxItems != nil && len(values) > *schema.MaxItems {
+		return fmt.Errorf("%s must contain at most %d items", describeWorkerOutputField(field), *schema.MaxItems)
+	}
+	if schema.UniqueItems {
+		seen := make(map[string]struct{}, len(values))
+		for _, item := range values {
+			encoded, err := json.Marshal(item)
+			if err != nil {
+				return fmt.Errorf("%s could not compare array items: %w", describeWorkerOutputField(field), err)
+			}
+			key := string(encoded)
+			if _, exists := seen[key]; exists {
+				return fmt.Errorf("%s must contain unique items", describeWorkerOutputField(field))
+			}
+			seen[key] = struct{}{}
+		}
+	}
+
+	for index := range schema.PrefixItems {
+		if index >= len(values) {
+			break
+		}
+		if _, err := v.validate(workerOutputItemField(field, index), values[index], &schema.PrefixItems[index]); err != nil {
+			return err
+		}
+		evaluation.items = max(evaluation.items, index+1)
+	}
+
+	if schema.Items != nil {
+		for index := len(schema.PrefixItems); index < len(values); index++ {
+			if _, err := v.validate(workerOutputItemField(field, index), values[index], schema.Items); err != nil {
+				return err
+			}
+		}
+		evaluation.allItems = true
+	}
+
+	if schema.Contains == nil {
+		return nil
+	}
+
+	matches := 0
+	for index, item := range values {
+		if _, err := v.validate(workerOutputItemField(field, index), item, schema.Contains); err == nil {
+			matches++
+			evaluation.markContains(index)
+		}
+	}
+	minContains := 1
+	if schema.MinContains != nil {
+		minContains = *schema.MinContains
+	}
+	if matches < minContains {
+		return fmt.Errorf("%s must contain at least %d matching items", describeWorkerOutputField(field), minContains)
+	}
+	if schema.MaxContains != nil && matches > *schema.MaxContains {
+		return fmt.Errorf("%s must contain at most %d matching items", describeWorkerOutputField(field), *schema.MaxContains)
+	}
+
+	return nil
+}
+
+func validateWorkerOutputNumber(field string, number float64, schema *workerOutputProperty) error {
+	if schema.Minimum != nil && number < *schema.Minimum {
+		return fmt.Errorf("%s must be at least %v", describeWorkerOutputField(field), *schema.Minimum)
+	}
+	if schema.Maximum != nil && number > *schema.Maximum {
+		return fmt.Errorf("%s must be at most %v", describeWorkerOutputField(field), *schema.Maximum)
+	}
+	if schema.ExclusiveMinimum != nil && number <= *schema.ExclusiveMinimum {
+		return fmt.Errorf("%s must be greater than %v", describeWorkerOutputField(field), *schema.ExclusiveMinimum)
+	}
+	if schema.ExclusiveMaximum != nil && number >= *schema.ExclusiveMaximum {
+		return fmt.Errorf("%s must be less than %v", describeWorkerOutputField(field), *schema.ExclusiveMaximum)
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		quotient := number / *schema.MultipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			return fmt.Errorf("%s must be a multiple of %v", describeWorkerOutputField(field), *schema.MultipleOf)
+		}
+	}
+	return nil
+}
+
+func validateWorkerOutputString(field string, text string, schema *workerOutputProperty) error {
+	length := utf8.RuneCountInString(text)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("%s must have at least %d characters", describeWorkerOutputField(field), *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("%s must have at most %d characters", describeWorkerOutputField(field), *schema.MaxLength)
+	}
+	if schema.Pattern != "" {
+		re, err := schema.patternRegexp()
+		if err != nil {
+			return fmt.Errorf("%s has invalid pattern %q: %w", describeWorkerOutputField(field), schema.Pattern, err)
+		}
+		if !re.MatchString(text) {
+			return fmt.Errorf("%s must match %q", describeWorkerOutputField(field), schema.Pattern)
+		}
+	}
+	if schema.Format != "" && !matchesWorkerOutputFormat(schema.Format, text) {
+		return fmt.Errorf("%s must be a valid %s", describeWorkerOutputField(field), schema.Format)
+	}
+	return nil
+}
+
+func validateWorkerOutputEnum(field string, value any, enum []any) error {
+	for _, allowed := range enum {
+		if workerOutputValuesEqual(value, allowed) {
+			return nil
+		}
+	}
+
+	if _, isString := value.(string); !isString {
+		allStrings := true
+		for _, allowed := range enum {
+			if _, ok := allowed.(string); !ok {
+				allStrings = false
+				break
+			}
+		}
+		if allStrings {
+			return fmt.Errorf("%s must be a string to use enum validation", describeWorkerOutputField(field))
+		}
+	}
+
+	return fmt.Errorf("%s must be one of %v", describeWorkerOutputField(field), enum)
+}
+
+func (v *workerOutputValidator) validateApplicators(
+	field string,
+	value any,
+	schema *workerOutputProperty,
+	evaluation *workerOutputEvaluation,
+) error {
+	for index := range schema.AllOf {
+		branchEvaluation, err := v.validate(field, value, &schema.AllOf[index])
+		if err != nil {
+			return err
+		}
+		evaluation.merge(branchEvaluation)
+	}
+
+	if len(schema.AnyOf) > 0 {
+		var failures []string
+		for index := range schema.AnyOf {
+			branchEvaluation, err := v.validate(field, value, &schema.AnyOf[index])
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			evaluation.merge(branchEvaluation)
+		}
+		if len(failures) == len(schema.AnyOf) {
+			return fmt.Errorf(
+				"%s must match at least one anyOf schema (%s)",
+				describeWorkerOutputField(field),
+				strings.Join(failures, "; "),
+			)
+		}
+	}
+
+	if len(schema.OneOf) > 0 {
+		var failures []string
+		var matched []workerOutputEvaluation
+		for index := range schema.OneOf {
+			branchEvaluation, err := v.validate(field, value, &schema.OneOf[index])
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			matched = append(matched, branchEvaluation)
+		}
+		switch len(matched) {
+		case 0:
+			return fmt.Errorf(
+				"%s must match exactly one oneOf schema (%s)",
+				describeWorkerOutputField(field),
+				strings.Join(failures, "; "),
+			)
+		case 1:
+			evaluation.merge(matched[0])
+		default:
+			return fmt.Errorf(
+				"%s must match exactly one oneOf schema but matched %d",
+				describeWorkerOutputField(field),
+				len(matched),
+			)
+		}
+	}
+
+	if schema.Not != nil {
+		if _, err := v.validate(field, value, schema.Not); err == nil {
+			return fmt.Errorf("%s must not match the schema in not", describeWorkerOutputField(field))
+		}
+	}
+
+	if schema.If != nil {
+		conditionEvaluation, err := v.validate(field, value, schema.If)
+		branch := schema.Else
+		if err == nil {
+			evaluation.merge(conditionEvaluation)
+			branch = schema.Then
+		}
+		if branch != nil {
+			branchEvaluation, err := v.validate(field, value, branch)
+			if err != nil {
+				return err
+			}
+			evaluation.merge(branchEvaluation)
+		}
+	}
+
+	return nil
+}
+
+func (v *workerOutputValidator) validateUnevaluated(
+	field string,
+	value any,
+	schema *workerOutputProperty,
+	evaluation *workerOutputEvaluation,
+) error {
+	switch typed := value.(type) {
+	case map[string]any:
+		if schema.UnevaluatedProperties == nil {
+			return nil
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if evaluation.properties[key] {
+				continue
+			}
+			childField := joinWorkerOutputField(field, key)
+			if rejected := schema.UnevaluatedProperties.boolean; rejected != nil && !*rejected {
+				return fmt.Errorf("unexpected field %q", childField)
+			}
+			if _, err := v.validate(childField, typed[key], schema.UnevaluatedProperties); err != nil {
+				return err
+			}
+			evaluation.markProperty(key)
+		}
+	case []any:
+		if schema.UnevaluatedItems == nil {
+			return nil
+		}
+		for index, item := range typed {
+			if evaluation.itemEvaluated(index) {
+				continue
+			}
+			if _, err := v.validate(workerOutputItemField(field, index), item, schema.UnevaluatedItems); err != nil {
+				return err
+			}
+		}
+		evaluation.allItems = true
+	}
+	return nil
+}
+
+func describeWorkerOutputField(field string) string {
+	if field == "" {
+		return "output"
+	}
+	return fmt.Sprintf("field %q", field)
+}
+
+func joinWorkerOutputField(parent string, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+func workerOutputItemField(parent string, index int) string {
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
+
+func sortedWorkerOutputKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func workerOutputValuesEqual(left any, right any) bool {
+	leftJSON, leftErr := json.Marshal(left)
+	rightJSON, rightErr := json.Marshal(right)
+	return leftErr == nil && rightErr == nil && string(leftJSON) == string(rightJSON)
+}
+
+func workerOutputTypes(raw any) (map[string]bool, error) {
+	allowed := make(map[string]bool)
+
+	switch value := raw.(type) {
+	case string:
+		allowed[value] = true
+	case []any:
+		for _, item := range value {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("type entries must be strings")
+			}
+			allowed[text] = true
+		}
+	case []string:
+		for _, item := range value {
+			allowed[item] = true
+		}
+	case nil:
+		return allowed, nil
+	default:
+		return nil, fmt.Errorf("unsupported type declaration %T", raw)
+	}
+
+	return allowed, nil
+}
+
+func matchesWorkerOutputType(value any, allowedTypes map[string]bool) bool {
+	switch typed := value.(type) {
+	case string:
+		return allowedTypes["string"]
+	case bool:
+		return allowedTypes["boolean"]
+	case float64:
+		if allowedTypes["number"] {
+			return true
+		}
+		return allowedTypes["integer"] && typed == math.Trunc(typed) && !math.IsInf(typed, 0)
+	case []any:
+		return allowedTypes["array"]
+	case map[string]any:
+		return allowedTypes["object"]
+	default:
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+package graphs
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+)
+
+// jsonSchema returns the schema as a plain JSON Schema object, the form the
+// provider clients translate into their native structured output formats.
+func (schema *workerOutputSchema) jsonSchema() (map[string]any, error) {
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	for key, value := range decoded {
+		if value == nil {
+			delete(decoded, key)
+		}
+	}
+
+	return decoded, nil
+}
+
+// workerStructuredOutput returns the structured output a worker node asks the
+// provider to enforce. Workers without an output_schema, and workers that set
+// "native_output": false, only rely on post-hoc validation.
+func workerStructuredOutput(snapshotNode *SnapshotNode) (clients.StructuredOutput, bool) {
+	if snapshotNode == nil || snapshotNode.Node == nil {
+		return clients.StructuredOutput{}, false
+	}
+	if strings.ToLower(strings.TrimSpace(snapshotNode.Node.NodeType)) != "worker" {
+		return clients.StructuredOutput{}, false
+	}
+
+	var config workerAgentConfig
+	if err := json.Unmarshal([]byte(snapshotNode.Node.Config), &config); err != nil {
+		return clients.StructuredOutput{}, false
+	}
+	if config.OutputSchema == nil || (config.NativeOutput != nil && !*config.NativeOutput) {
+		return clients.StructuredOutput{}, false
+	}
+
+	schema, err := config.OutputSchema.jsonSchema()
+	if err != nil {
+		return clients.StructuredOutput{}, false
+	}
+
+	return clients.NewStructuredOutput(snapshotNode.Node.NodeKey+"_output", schema), true
+}
+
+func structuredModelClientKey(snapshotNode *SnapshotNode) string {
+	return "structured:" + snapshotNode.Node.NodeKey
+}
@@ -0,0 +1,17 @@
+package inputs
+
+import "github.com/google/uuid"
+
+type BackfillWorkflowInput struct {
+	BackfillID     uuid.UUID `json:"backfill_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// Generation is the backfill generation the job was started for. A
+	// resume starts a new generation, and jobs of older ones stop.
+	Generation int32 `json:"generation"`
+}
+
+type CancelBackfillInput struct {
+	BackfillID     uuid.UUID `json:"backfill_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Reason         string    `json:"reason,omitempty"`
+}
@@ -0,0 +1,9 @@
+package inputs
+
+import "github.com/google/uuid"
+
+type CancelWorkflowInput struct {
+	ExecutionID    uuid.UUID `json:"execution_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Reason         string    `json:"reason,omitempty"`
+}
@@ -0,0 +1,7 @@
+package inputs
+
+import "github.com/google/uuid"
+
+type DeliverWebhookInput struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
@@ -6,6 +6,8 @@ type ExecuteWorkflowInput struct {
 	ExecutionID    uuid.UUID      `json:"execution_id"`
 	WorkflowID     uuid.UUID      `json:"workflow_id"`
 	OrganizationID uuid.UUID      `json:"organization_id"`
+	APIKeyID       string         `json:"api_key_id,omitempty"`
+	Source         string         `json:"source,omitempty"`
 	DocumentIDs    []uuid.UUID    `json:"document_ids,omitempty"`
 	Scope          map[string]any `json:"scope,omitempty"`
 	InitialState   map[string]any `json:"initial_state,omitempty"`
@@ -0,0 +1,12 @@
+package inputs
+
+// Job sources say who queued a run. Dashboard runs start ahead of runs queued
+// through API keys, backfills, schedules, workflow triggers, or the run API.
+const (
+	JobSourceDashboard = "dashboard"
+	JobSourceAPIKey    = "api_key"
+	JobSourceBackfill  = "backfill"
+	JobSourceSchedule  = "schedule"
+	JobSourceTrigger   = "trigger"
+	JobSourceRunAPI    = "run_api"
+)
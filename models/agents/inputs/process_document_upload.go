@@ -3,6 +3,10 @@ package inputs
 import "github.com/google/uuid"
 
 type ProcessDocumentUploadInput struct {
-	DocumentID   uuid.UUID  `json:"document_id"`
-	AgentGraphID *uuid.UUID `json:"agent_graph_id,omitempty"`
+	DocumentID     uuid.UUID  `json:"document_id"`
+	AgentGraphID   *uuid.UUID `json:"agent_graph_id,omitempty"`
+	BackfillID     *uuid.UUID `json:"backfill_id,omitempty"`
+	OrganizationID string     `json:"organization_id,omitempty"`
+	APIKeyID       string     `json:"api_key_id,omitempty"`
+	Source         string     `json:"source,omitempty"`
 }
@@ -0,0 +1,13 @@
+package inputs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FireScheduleInput struct {
+	ScheduleID     uuid.UUID `json:"schedule_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	FiredAt        time.Time `json:"fired_at"`
+}
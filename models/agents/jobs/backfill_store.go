@@ -0,0 +1,264 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultBackfillRatePerMinute = 60
+	maxBackfillRatePerMinute     = 600
+
+	// backfillSettleTimeout bounds how long a backfill whose documents are all
+	// enqueued waits for runs that never report back.
+	backfillSettleTimeout = 30 * time.Minute
+)
+
+// documentScope selects the documents a backfill or schedule runs over. It
+// follows the document chat scope; the organization comes from the record
+// that owns it.
+type documentScope struct {
+	ProjectIDs           []string `json:"project_ids,omitempty"`
+	APIKeyIDs            []string `json:"api_key_ids,omitempty"`
+	DocumentIDs          []string `json:"document_ids,omitempty"`
+	DashboardUploadsOnly bool     `json:"dashboard_uploads_only,omitempty"`
+	APIKeyUploadsOnly    bool     `json:"api_key_uploads_only,omitempty"`
+}
+
+type backfillOptions struct {
+	WithoutOCR         bool `json:"without_ocr,omitempty"`
+	WithoutDescription bool `json:"without_description,omitempty"`
+	// RatePerMinute is how many documents are enqueued per minute.
+	RatePerMinute int `json:"rate_per_minute,omitempty"`
+}
+
+func (o backfillOptions) pageSize() int {
+	switch {
+	case o.RatePerMinute <= 0:
+		return defaultBackfillRatePerMinute
+	case o.RatePerMinute > maxBackfillRatePerMinute:
+		return maxBackfillRatePerMinute
+	default:
+		return o.RatePerMinute
+	}
+}
+
+// backfillPage is one claimed batch of documents. Status is the backfill
+// status after the claim, or backfillSuperseded for a job of an older
+// generation; anything but "running" stops the job.
+type backfillPage struct {
+	Status         string   `json:"status"`
+	AgentGraphID   string   `json:"agent_graph_id"`
+	OrganizationID string   `json:"organization_id"`
+	APIKeyID       string   `json:"api_key_id"`
+	DocumentIDs    []string `json:"document_ids"`
+}
+
+// backfillSuperseded is the page status of a job whose backfill was resumed
+// since it started, so a newer job now claims its pages.
+const backfillSuperseded = "superseded"
+
+// claimBackfillPage refreshes the backfill's progress and claims the next page
+// of matching documents by advancing its cursor. Once every document has been
+// claimed, the backfill completes when all of its runs have finished, or when
+// none has reported back for backfillSettleTimeout. Jobs of an older
+// generation than the backfill's claim nothing.
+func claimBackfillPage(db *gorm.DB, backfillID string, generation int32, now time.Time) (backfillPage, error) {
+	var page backfillPage
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var backfill dbmodels.WorkflowBackfill
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", backfillID).
+			Take(&backfill).Error; err != nil {
+			return fmt.Errorf("failed to load backfill %s: %w", backfillID, runerrors.Database(err))
+		}
+		page = backfillPage{
+			Status:         backfill.Status,
+			AgentGraphID:   backfill.AgentGraphID,
+			OrganizationID: backfill.OrganizationID,
+		}
+		if backfill.APIKeyID != nil {
+			page.APIKeyID = *backfill.APIKeyID
+		}
+		if backfill.Status != "running" {
+			return nil
+		}
+		if backfill.Generation != generation {
+			page.Status = backfillSuperseded
+			return nil
+		}
+
+		var scope documentScope
+		if err := json.Unmarshal([]byte(backfill.Scope), &scope); err != nil {
+			return fmt.Errorf("invalid backfill scope: %w", err)
+		}
+		var options backfillOptions
+		if err := json.Unmarshal([]byte(backfill.Options), &options); err != nil {
+			return fmt.Errorf("invalid backfill options: %w", err)
+		}
+
+		completed, failed, err := countBackfillRuns(tx, backfill.ID)
+		if err != nil {
+			return err
+		}
+		updates := map[string]any{}
+		if completed != backfill.Completed || failed != backfill.Failed {
+			updates["completed"] = completed
+			updates["failed"] = failed
+		}
+
+		if backfill.Total == nil {
+			var total int64
+			if err := backfillDocuments(tx, &backfill, scope, options).Count(&total).Error; err != nil {
+				return fmt.Errorf("failed to count backfill documents: %w", runerrors.Database(err))
+			}
+			updates["total"] = total
+		}
+
+		documents := backfillDocuments(tx, &backfill, scope, options)
+		if backfill.Cursor != nil {
+			documents = documents.Where("d.id > ?", *backfill.Cursor)
+		}
+		if err := documents.Order("d.id").
+			Limit(options.pageSize()).
+			Pluck("d.id", &page.DocumentIDs).Error; err != nil {
+			return fmt.Errorf("failed to select backfill documents: %w", runerrors.Database(err))
+		}
+
+		if len(page.DocumentIDs) > 0 {
+			updates["cursor"] = page.DocumentIDs[len(page.DocumentIDs)-1]
+			updates["enqueued"] = backfill.Enqueued + int32(len(page.DocumentIDs))
+		} else if backfillSettled(&backfill, completed, failed, len(updates) > 0, now) {
+			updates["status"] = "completed"
+			updates["finished_at"] = now
+			page.Status = "completed"
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&dbmodels.WorkflowBackfill{}).
+			Where("id = ?", backfill.ID).
+			Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update backfill %s: %w", backfill.ID, runerrors.Database(err))
+		}
+		return nil
+	})
+	return page, err
+}
+
+// backfillSettled reports whether a backfill with no documents left to claim
+// is done: every enqueued document has a finished run, or progress stalled.
+func backfillSettled(backfill *dbmodels.WorkflowBackfill, completed int32, failed int32, progressed bool, now time.Time) bool {
+	if completed+failed >= backfill.Enqueued {
+		return true
+	}
+	return !progressed && now.Sub(backfill.UpdatedAt) >= backfillSettleTimeout
+}
+
+// backfillDocuments selects the documents in a backfill's scope, leaving out
+// documents uploaded after the backfill started.
+func backfillDocuments(db *gorm.DB, backfill *dbmodels.WorkflowBackfill, scope documentScope, options backfillOptions) *gorm.DB {
+	query := scopedDocuments(db, backfill.OrganizationID, scope).
+		Where("d.created_at <= ?", backfill.CreatedAt)
+	if options.WithoutOCR {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM document_ocr_results dor WHERE dor.document_id = d.id)")
+	}
+	if options.WithoutDescription {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM document_descriptions dd WHERE dd.document_id = d.id)")
+	}
+	return query
+}
+
+// scopedDocuments selects an organization's documents, aliased as d, that
+// match a scope. Derived segmentation outputs are left out.
+func scopedDocuments(db *gorm.DB, organizationID string, scope documentScope) *gorm.DB {
+	query := db.Table("documents AS d").
+		Where("d.organization_id = ?", organizationID).
+		Where("NOT EXISTS (SELECT 1 FROM document_segmentations ds WHERE ds.segmented_document_id = d.id)")
+
+	if ids := nonEmptyUnique(scope.ProjectIDs); len(ids) > 0 {
+		query = query.Where("d.project_id IN ?", ids)
+	}
+	if ids := nonEmptyUnique(scope.APIKeyIDs); len(ids) > 0 {
+		query = query.Where("d.api_key_id IN ?", ids)
+	}
+	if ids := nonEmptyUnique(scope.DocumentIDs); len(ids) > 0 {
+		query = query.Where("d.id IN ?", ids)
+	}
+	if scope.DashboardUploadsOnly {
+		query = query.Where("d.api_key_id IS NULL")
+	}
+	if scope.APIKeyUploadsOnly {
+		query = query.Where("d.api_key_id IS NOT NULL")
+	}
+	return query
+}
+
+// countBackfillRuns counts the finished runs a backfill has produced.
+// Cancelled runs count as failed.
+func countBackfillRuns(db *gorm.DB, backfillID string) (completed int32, failed int32, err error) {
+	var rows []struct {
+		Status string
+		Count  int32
+	}
+	if err := db.Model(&dbmodels.AgentGraphRun{}).
+		Select("status, count(*) AS count").
+		Where("backfill_id = ? AND status <> ?", backfillID, "running").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to count backfill runs: %w", runerrors.Database(err))
+	}
+	for _, row := range rows {
+		if row.Status == "completed" {
+			completed += row.Count
+		} else {
+			failed += row.Count
+		}
+	}
+	return completed, failed, nil
+}
+
+// setBackfillStatus moves a backfill out of one of the from statuses. It
+// returns false when the backfill was in none of them.
+func setBackfillStatus(db *gorm.DB, backfillID string, organizationID string, status string, reason string, from ...string) (bool, error) {
+	updates := map[string]any{"status": status}
+	switch status {
+	case "completed", "failed", "cancelled":
+		updates["finished_at"] = time.Now()
+	}
+	if reason != "" {
+		updates["error"] = reason
+	}
+	result := db.Model(&dbmodels.WorkflowBackfill{}).
+		Where("id = ? AND organization_id = ? AND status IN ?", backfillID, organizationID, from).
+		Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark backfill %s %s: %w", backfillID, status, runerrors.Database(result.Error))
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func nonEmptyUnique(values []string) []string {
+	unique := make([]string, 0, len(values))
+	seen := map[string]struct{}{}
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := seen[trimmed]; ok {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		unique = append(unique, trimmed)
+	}
+	return unique
+}
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+func TestBackfillDocumentsAppliesScopeAndOptions(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+
+	var documentIDs []string
+	tx := backfillDocuments(
+		db,
+		&dbmodels.WorkflowBackfill{OrganizationID: "org-1", CreatedAt: time.Now()},
+		documentScope{ProjectIDs: []string{"project-1", " project-1 ", ""}, DashboardUploadsOnly: true},
+		backfillOptions{WithoutOCR: true},
+	).Pluck("d.id", &documentIDs)
+	query := tx.Statement.SQL.String()
+
+	for _, fragment := range []string{
+		"d.organization_id = ?",
+		"d.created_at <= ?",
+		"ds.segmented_document_id = d.id",
+		"d.project_id IN (?)",
+		"d.api_key_id IS NULL",
+		"document_ocr_results",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in backfill query: %s", fragment, query)
+		}
+	}
+	if strings.Contains(query, "document_descriptions") {
+		t.Fatalf("description filter applied without the option: %s", query)
+	}
+}
+
+func TestBackfillOptionsPageSize(t *testing.T) {
+	for rate, expected := range map[int]int{
+		0:    defaultBackfillRatePerMinute,
+		-5:   defaultBackfillRatePerMinute,
+		120:  120,
+		5000: maxBackfillRatePerMinute,
+	} {
+		if got := (backfillOptions{RatePerMinute: rate}).pageSize(); got != expected {
+			t.Fatalf("pageSize for rate %d = %d, want %d", rate, got, expected)
+		}
+	}
+}
+
+func TestBackfillSettled(t *testing.T) {
+	now := time.Now()
+	backfill := &dbmodels.WorkflowBackfill{Enqueued: 10, UpdatedAt: now.Add(-time.Minute)}
+
+	if !backfillSettled(backfill, 7, 3, true, now) {
+		t.Fatal("expected backfill with every run finished to settle")
+	}
+	if backfillSettled(backfill, 7, 2, false, now) {
+		t.Fatal("expected backfill with outstanding runs to keep waiting")
+	}
+
+	backfill.UpdatedAt = now.Add(-backfillSettleTimeout)
+	if !backfillSettled(backfill, 7, 2, false, now) {
+		t.Fatal("expected stalled backfill to settle")
+	}
+	if backfillSettled(backfill, 7, 2, true, now) {
+		t.Fatal("expected backfill that just progressed to keep waiting")
+	}
+}
+
+func TestBackfillUploadEventsAreIdempotentPerDocument(t *testing.T) {
+	backfillID := "0194f3a0-0000-7000-8000-000000000001"
+	events, err := backfillUploadEvents(backfillID, backfillPage{
+		AgentGraphID:   "0194f3a0-0000-7000-8000-000000000002",
+		OrganizationID: "org-1",
+		DocumentIDs:    []string{"0194f3a0-0000-7000-8000-000000000003"},
+	})
+	if err != nil {
+		t.Fatalf("backfillUploadEvents returned error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+	event := events[0]
+	if event.ID == nil || *event.ID != "backfill-"+backfillID+"-0194f3a0-0000-7000-8000-000000000003" {
+		t.Fatalf("unexpected event id: %v", event.ID)
+	}
+	if event.Data.BackfillID == nil || event.Data.BackfillID.String() != backfillID || event.Data.Source != "backfill" {
+		t.Fatalf("unexpected event data: %#v", event.Data)
+	}
+
+	if _, err := backfillUploadEvents(backfillID, backfillPage{AgentGraphID: "not-a-uuid"}); err == nil {
+		t.Fatal("expected invalid agent graph id to be rejected")
+	}
+}
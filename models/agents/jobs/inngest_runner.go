@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/inngest/inngestgo"
+)
+
+// InngestRunner runs jobs as Inngest functions served from /api/inngest.
+type InngestRunner struct {
+	inngestgo.Client
+	limits JobLimits
+}
+
+var _ JobRunner = (*InngestRunner)(nil)
+
+func NewInngestRunner(client inngestgo.Client, limits JobLimits) *InngestRunner {
+	return &InngestRunner{Client: client, limits: limits}
+}
+
+func (r *InngestRunner) Register(job Job) {
+	if err := job.handler.createFunction(r.Client, r.functionOpts(job), job); err != nil {
+		slog.Error("job register_failed", "job", job.ID, "backend", JobBackendInngest, "err", err)
+	}
+}
+
+// functionOpts maps a job's limits and cancellation onto Inngest function
+// configuration.
+func (r *InngestRunner) functionOpts(job Job) inngestgo.FunctionOpts {
+	opts := inngestgo.FunctionOpts{
+		ID:      job.ID,
+		Retries: job.Retries,
+	}
+	if job.Limited {
+		opts.Concurrency = r.limits.concurrency()
+		opts.Priority = r.limits.priority()
+	}
+	if job.SerializeBy != "" {
+		if opts.Concurrency == nil {
+			opts.Concurrency = &inngestgo.ConfigConcurrency{}
+		}
+		opts.Concurrency.Fn = append(opts.Concurrency.Fn, inngestgo.ConfigFnConcurrency{
+			Limit: 1,
+			Key:   inngestgo.StrPtr("event.data." + job.SerializeBy),
+		})
+	}
+	for _, cancel := range job.CancelOn {
+		opts.Cancel = append(opts.Cancel, inngestgo.ConfigCancel{
+			Event: cancel.Event,
+			If:    inngestgo.StrPtr(fmt.Sprintf("event.data.%[1]s == async.data.%[1]s", cancel.Key)),
+		})
+	}
+	return opts
+}
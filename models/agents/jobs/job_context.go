@@ -11,9 +11,10 @@ import (
 type JobContextKey string
 
 const (
-	dbKey  JobContextKey = "db"
-	s3Key  JobContextKey = "s3"
-	mcpKey JobContextKey = "mcp"
+	dbKey          JobContextKey = "db"
+	s3Key          JobContextKey = "s3"
+	mcpKey         JobContextKey = "mcp"
+	eventSenderKey JobContextKey = "event_sender"
 )
 
 func WithDBClient(ctx context.Context, dbClient *gorm.DB) context.Context {
@@ -43,6 +44,22 @@ func WithMCPClient(ctx context.Context, mcpClient *clients.MCPClient) context.Co
 	return context.WithValue(ctx, mcpKey, mcpClient)
 }
 
+func WithEventSender[T any](sender EventSender, fn func(ctx context.Context, input inngestgo.Input[T]) (any, error),
+) func(ctx context.Context, input inngestgo.Input[T]) (any, error) {
+	return func(ctx context.Context, input inngestgo.Input[T]) (any, error) {
+		return fn(withEventSender(ctx, sender), input)
+	}
+}
+
+func withEventSender(ctx context.Context, sender EventSender) context.Context {
+	return context.WithValue(ctx, eventSenderKey, sender)
+}
+
+func GetEventSender(ctx context.Context) (EventSender, bool) {
+	sender, ok := ctx.Value(eventSenderKey).(EventSender)
+	return sender, ok
+}
+
 func WithJobContext[T any](dbClient *gorm.DB, s3Client *clients.S3Client, mcpClient *clients.MCPClient, fn func(ctx context.Context, input inngestgo.Input[T]) (any, error),
 ) func(ctx context.Context, input inngestgo.Input[T]) (any, error) {
 	return func(ctx context.Context, input inngestgo.Input[T]) (any, error) {
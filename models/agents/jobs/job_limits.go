@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/inngest/inngestgo"
+)
+
+const (
+	defaultOrganizationConcurrency = 10
+	defaultAPIKeyConcurrency       = 5
+	defaultDashboardPriority       = 600
+
+	// maxRunPriority is the largest priority Inngest accepts, in seconds.
+	maxRunPriority = 600
+
+	// Concurrency keys are evaluated by Inngest against the triggering event.
+	// Events without an API key (dashboard runs) are keyed by their own event
+	// ID, so only the organization limit applies to them.
+	organizationConcurrencyKey = "event.data.organization_id"
+	apiKeyConcurrencyKey       = "has(event.data.api_key_id) && event.data.api_key_id != '' ? event.data.organization_id + ':' + event.data.api_key_id : event.id"
+)
+
+// JobLimits bounds how many graph runs each tenant can have in flight and how
+// far dashboard runs jump ahead of automated uploads. Runs over a limit stay
+// queued until capacity frees up.
+type JobLimits struct {
+	// OrganizationConcurrency caps concurrently running steps per organization.
+	// Zero disables the limit.
+	OrganizationConcurrency int
+	// APIKeyConcurrency caps concurrently running steps per API key. Runs
+	// without an API key are not counted. Zero disables the limit.
+	APIKeyConcurrency int
+	// DashboardPriority is how many seconds of queue time a dashboard run skips
+	// ahead of API-key runs.
+	DashboardPriority int
+}
+
+func LoadJobLimits() (JobLimits, error) {
+	organizationConcurrency, err := limitFromEnv("WORKFLOW_ORGANIZATION_CONCURRENCY", defaultOrganizationConcurrency)
+	if err != nil {
+		return JobLimits{}, err
+	}
+	apiKeyConcurrency, err := limitFromEnv("WORKFLOW_API_KEY_CONCURRENCY", defaultAPIKeyConcurrency)
+	if err != nil {
+		return JobLimits{}, err
+	}
+	dashboardPriority, err := limitFromEnv("WORKFLOW_DASHBOARD_PRIORITY_SECONDS", defaultDashboardPriority)
+	if err != nil {
+		return JobLimits{}, err
+	}
+	if dashboardPriority > maxRunPriority {
+		return JobLimits{}, fmt.Errorf(
+			"invalid WORKFLOW_DASHBOARD_PRIORITY_SECONDS value %d: must be at most %d",
+			dashboardPriority,
+			maxRunPriority,
+		)
+	}
+
+	return JobLimits{
+		OrganizationConcurrency: organizationConcurrency,
+		APIKeyConcurrency:       apiKeyConcurrency,
+		DashboardPriority:       dashboardPriority,
+	}, nil
+}
+
+// limitFromEnv reads a non-negative integer setting, falling back when unset.
+func limitFromEnv(name string, fallback int) (int, error) {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q: %w", name, raw, err)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("invalid %s value %d: must not be negative", name, value)
+	}
+	return value, nil
+}
+
+// concurrency returns the per-organization and per-API-key limits for a run
+// function, or nil when both are disabled.
+func (l JobLimits) concurrency() *inngestgo.ConfigConcurrency {
+	var limits []inngestgo.ConfigStepConcurrency
+	if l.OrganizationConcurrency > 0 {
+		limits = append(limits, inngestgo.ConfigStepConcurrency{
+			Limit: l.OrganizationConcurrency,
+			Key:   inngestgo.StrPtr(organizationConcurrencyKey),
+		})
+	}
+	if l.APIKeyConcurrency > 0 {
+		limits = append(limits, inngestgo.ConfigStepConcurrency{
+			Limit: l.APIKeyConcurrency,
+			Key:   inngestgo.StrPtr(apiKeyConcurrencyKey),
+		})
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	return &inngestgo.ConfigConcurrency{Step: limits}
+}
+
+// priority starts queued dashboard runs ahead of API-key runs.
+func (l JobLimits) priority() *inngestgo.ConfigPriority {
+	if l.DashboardPriority == 0 {
+		return nil
+	}
+	return &inngestgo.ConfigPriority{
+		Run: inngestgo.StrPtr(fmt.Sprintf("event.data.source == '%s' ? %d : 0", inputs.JobSourceDashboard, l.DashboardPriority)),
+	}
+}
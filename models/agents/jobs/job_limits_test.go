@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadJobLimitsDefaults(t *testing.T) {
+	t.Setenv("WORKFLOW_ORGANIZATION_CONCURRENCY", "")
+	t.Setenv("WORKFLOW_API_KEY_CONCURRENCY", "")
+	t.Setenv("WORKFLOW_DASHBOARD_PRIORITY_SECONDS", "")
+
+	limits, err := LoadJobLimits()
+	if err != nil {
+		t.Fatalf("LoadJobLimits returned error: %v", err)
+	}
+	if limits.OrganizationConcurrency != defaultOrganizationConcurrency ||
+		limits.APIKeyConcurrency != defaultAPIKeyConcurrency ||
+		limits.DashboardPriority != defaultDashboardPriority {
+		t.Fatalf("unexpected default limits: %#v", limits)
+	}
+}
+
+func TestLoadJobLimitsRejectsInvalidValues(t *testing.T) {
+	for name, value := range map[string]string{
+		"WORKFLOW_ORGANIZATION_CONCURRENCY":   "many",
+		"WORKFLOW_API_KEY_CONCURRENCY":        "-1",
+		"WORKFLOW_DASHBOARD_PRIORITY_SECONDS": "601",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := LoadJobLimits()
+			if err == nil || !strings.Contains(err.Error(), name) {
+				t.Fatalf("expected error naming %s, got %v", name, err)
+			}
+		})
+	}
+}
+
+func TestJobLimitsKeysConcurrencyByOrganizationAndAPIKey(t *testing.T) {
+	concurrency := JobLimits{OrganizationConcurrency: 8, APIKeyConcurrency: 2}.concurrency()
+	if concurrency == nil || len(concurrency.Step) != 2 {
+		t.Fatalf("expected two concurrency limits, got %#v", concurrency)
+	}
+	if concurrency.Step[0].Limit != 8 || *concurrency.Step[0].Key != organizationConcurrencyKey {
+		t.Fatalf("unexpected organization limit: %#v", concurrency.Step[0])
+	}
+	if concurrency.Step[1].Limit != 2 || *concurrency.Step[1].Key != apiKeyConcurrencyKey {
+		t.Fatalf("unexpected API key limit: %#v", concurrency.Step[1])
+	}
+}
+
+func TestJobLimitsZeroDisablesLimits(t *testing.T) {
+	limits := JobLimits{}
+	if concurrency := limits.concurrency(); concurrency != nil {
+		t.Fatalf("expected no concurrency limits, got %#v", concurrency)
+	}
+	if priority := limits.priority(); priority != nil {
+		t.Fatalf("expected no priority, got %#v", priority)
+	}
+}
+
+func TestJobLimitsPrioritizesDashboardRuns(t *testing.T) {
+	priority := JobLimits{DashboardPriority: 300}.priority()
+	if priority == nil || *priority.Run != "event.data.source == 'dashboard' ? 300 : 0" {
+		t.Fatalf("unexpected priority: %#v", priority)
+	}
+}
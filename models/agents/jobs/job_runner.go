@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/inngest/inngestgo"
+)
+
+// JobBackend names the queue the agents' jobs run on.
+type JobBackend string
+
+const (
+	JobBackendInngest  JobBackend = "inngest"
+	JobBackendPostgres JobBackend = "postgres"
+)
+
+// LoadJobBackend reads JOB_BACKEND, defaulting to Inngest.
+func LoadJobBackend() (JobBackend, error) {
+	raw := strings.TrimSpace(os.Getenv("JOB_BACKEND"))
+	switch JobBackend(raw) {
+	case "", JobBackendInngest:
+		return JobBackendInngest, nil
+	case JobBackendPostgres:
+		return JobBackendPostgres, nil
+	default:
+		return "", fmt.Errorf("invalid JOB_BACKEND value %q: must be %q or %q", raw, JobBackendInngest, JobBackendPostgres)
+	}
+}
+
+// EventSender enqueues job events. inngestgo.Client satisfies it, so events
+// are built as inngestgo.GenericEvent values on every backend.
+type EventSender interface {
+	Send(ctx context.Context, evt any) (string, error)
+	SendMany(ctx context.Context, evts []any) ([]string, error)
+}
+
+// JobRunner is a queue backend for the agents' jobs. RegisterJobs adds every
+// job to a runner, and code that starts jobs sends events through it.
+type JobRunner interface {
+	EventSender
+	// Register adds a job. Runners skip jobs they cannot run.
+	Register(job Job)
+}
+
+// Job is a function run for each event named Event, or on the Cron schedule.
+type Job struct {
+	ID    string
+	Event string
+	Cron  string
+	// Retries is how many times a failed job is retried. Nil uses the
+	// backend's default.
+	Retries *int
+	// Limited applies the JobLimits tenant concurrency and dashboard priority.
+	Limited bool
+	// CancelOn drops pending jobs when a matching event is sent.
+	CancelOn []JobCancel
+	// SerializeBy runs one job at a time per value of this event data key.
+	SerializeBy string
+	// Durable jobs sleep or send events through Inngest steps, which need
+	// Inngest's step memoization, so they only run on the Inngest backend.
+	Durable bool
+
+	handler jobHandler
+}
+
+// JobCancel matches an event that cancels a job when both events carry the
+// same value under the event data key Key.
+type JobCancel struct {
+	Event string
+	Key   string
+}
+
+// jobHandler is a job function whose event data type is known only to the
+// handler, so runners can register and call it without generics.
+type jobHandler interface {
+	createFunction(client inngestgo.Client, opts inngestgo.FunctionOpts, job Job) error
+	run(ctx context.Context, event queuedEvent, inputCtx inngestgo.InputCtx) (any, error)
+}
+
+// queuedEvent is an event as it is stored by runners that queue it
+// themselves.
+type queuedEvent struct {
+	ID        *string         `json:"id,omitempty"`
+	Name      string          `json:"name"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp int64           `json:"ts,omitempty"`
+}
+
+type jobFunc[T any] func(ctx context.Context, input inngestgo.Input[T]) (any, error)
+
+func (fn jobFunc[T]) createFunction(client inngestgo.Client, opts inngestgo.FunctionOpts, job Job) error {
+	trigger := inngestgo.EventTrigger(job.Event, nil)
+	if job.Cron != "" {
+		trigger = inngestgo.CronTrigger(job.Cron)
+	}
+	_, err := inngestgo.CreateFunction(client, opts, trigger, inngestgo.SDKFunction[T](fn))
+	return err
+}
+
+func (fn jobFunc[T]) run(ctx context.Context, event queuedEvent, inputCtx inngestgo.InputCtx) (any, error) {
+	var data T
+	if len(event.Data) > 0 {
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, inngestgo.NoRetryError(fmt.Errorf("failed to decode %s event: %w", event.Name, err))
+		}
+	}
+	return fn(ctx, inngestgo.Input[T]{
+		Event: inngestgo.GenericEvent[T]{
+			ID:        event.ID,
+			Name:      event.Name,
+			Data:      data,
+			Timestamp: event.Timestamp,
+		},
+		InputCtx: inputCtx,
+	})
+}
+
+// decodeEvent reads the name, ID, and data of an event built for
+// EventSender.Send.
+func decodeEvent(evt any) (queuedEvent, error) {
+	encoded, err := json.Marshal(evt)
+	if err != nil {
+		return queuedEvent{}, fmt.Errorf("failed to encode event: %w", err)
+	}
+	var event queuedEvent
+	if err := json.Unmarshal(encoded, &event); err != nil {
+		return queuedEvent{}, fmt.Errorf("failed to decode event: %w", err)
+	}
+	if event.Name == "" {
+		return queuedEvent{}, fmt.Errorf("event has no name")
+	}
+	if len(event.Data) == 0 || string(event.Data) == "null" {
+		event.Data = json.RawMessage("{}")
+	}
+	return event, nil
+}
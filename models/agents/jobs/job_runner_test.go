@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/inngest/inngestgo"
+)
+
+func TestLoadJobBackend(t *testing.T) {
+	for value, expected := range map[string]JobBackend{
+		"":         JobBackendInngest,
+		"inngest":  JobBackendInngest,
+		"postgres": JobBackendPostgres,
+	} {
+		t.Setenv("JOB_BACKEND", value)
+		backend, err := LoadJobBackend()
+		if err != nil || backend != expected {
+			t.Fatalf("expected %q for JOB_BACKEND=%q, got %q (%v)", expected, value, backend, err)
+		}
+	}
+
+	t.Setenv("JOB_BACKEND", "redis")
+	if _, err := LoadJobBackend(); err == nil {
+		t.Fatal("expected an error for an unknown JOB_BACKEND")
+	}
+}
+
+func TestDecodeEventReadsGenericEvents(t *testing.T) {
+	id := "execution-1"
+	event, err := decodeEvent(inngestgo.GenericEvent[map[string]any]{
+		ID:   &id,
+		Name: "workflow/execute",
+		Data: map[string]any{"execution_id": "execution-1"},
+	})
+	if err != nil {
+		t.Fatalf("decodeEvent returned error: %v", err)
+	}
+	if event.ID == nil || *event.ID != id || event.Name != "workflow/execute" ||
+		string(event.Data) != `{"execution_id":"execution-1"}` {
+		t.Fatalf("unexpected event: %#v", event)
+	}
+
+	event, err = decodeEvent(inngestgo.Event{Name: "workflow/cancel"})
+	if err != nil || string(event.Data) != "{}" {
+		t.Fatalf("expected empty data for an event without data, got %#v (%v)", event, err)
+	}
+
+	if _, err := decodeEvent(inngestgo.Event{}); err == nil {
+		t.Fatal("expected an error for an event without a name")
+	}
+}
+
+func TestInngestRunnerMapsJobOptions(t *testing.T) {
+	runner := NewInngestRunner(nil, JobLimits{OrganizationConcurrency: 8})
+	opts := runner.functionOpts(Job{
+		ID:          "workflow-backfill",
+		Limited:     true,
+		SerializeBy: "backfill_id",
+		CancelOn:    []JobCancel{{Event: "workflow/backfill.cancel", Key: "backfill_id"}},
+	})
+
+	if opts.ID != "workflow-backfill" || opts.Concurrency == nil || len(opts.Concurrency.Step) != 1 {
+		t.Fatalf("expected the tenant limit, got %#v", opts)
+	}
+	if len(opts.Concurrency.Fn) != 1 || opts.Concurrency.Fn[0].Limit != 1 ||
+		*opts.Concurrency.Fn[0].Key != "event.data.backfill_id" {
+		t.Fatalf("expected one run per backfill, got %#v", opts.Concurrency.Fn)
+	}
+	if len(opts.Cancel) != 1 || opts.Cancel[0].Event != "workflow/backfill.cancel" ||
+		*opts.Cancel[0].If != "event.data.backfill_id == async.data.backfill_id" {
+		t.Fatalf("unexpected cancellation: %#v", opts.Cancel)
+	}
+}
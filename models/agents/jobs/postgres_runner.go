@@ -0,0 +1,556 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/inngest/inngestgo"
+	inngesterrors "github.com/inngest/inngestgo/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultJobWorkers           = 10
+	defaultJobVisibilityTimeout = 5 * time.Minute
+	defaultJobPollInterval      = time.Second
+
+	// jobInterruptGrace bounds how long Wait waits for interrupted jobs to
+	// be released.
+	jobInterruptGrace = 5 * time.Second
+
+	// defaultJobRetries matches Inngest's default for jobs without Retries.
+	defaultJobRetries = 4
+)
+
+// PostgresRunnerOptions tunes the Postgres job runner.
+type PostgresRunnerOptions struct {
+	// Workers is how many jobs this process runs at once.
+	Workers int
+	// VisibilityTimeout is how long a claimed job stays hidden from other
+	// workers. Workers extend it while a job runs, so it bounds how long the
+	// jobs of a crashed worker wait before they are attempted again.
+	VisibilityTimeout time.Duration
+	// PollInterval is how long an idle worker waits before looking again.
+	PollInterval time.Duration
+}
+
+func LoadPostgresRunnerOptions() (PostgresRunnerOptions, error) {
+	workers, err := limitFromEnv("JOB_WORKERS", defaultJobWorkers)
+	if err != nil {
+		return PostgresRunnerOptions{}, err
+	}
+	if workers == 0 {
+		return PostgresRunnerOptions{}, fmt.Errorf("invalid JOB_WORKERS value 0: must be positive")
+	}
+	visibilityTimeout, err := limitFromEnv("JOB_VISIBILITY_TIMEOUT_SECONDS", int(defaultJobVisibilityTimeout.Seconds()))
+	if err != nil {
+		return PostgresRunnerOptions{}, err
+	}
+	if visibilityTimeout < 3 {
+		return PostgresRunnerOptions{}, fmt.Errorf("invalid JOB_VISIBILITY_TIMEOUT_SECONDS value %d: must be at least 3", visibilityTimeout)
+	}
+
+	return PostgresRunnerOptions{
+		Workers:           workers,
+		VisibilityTimeout: time.Duration(visibilityTimeout) * time.Second,
+		PollInterval:      defaultJobPollInterval,
+	}, nil
+}
+
+// PostgresRunner runs jobs from the job_queue table for deployments without
+// Inngest. Workers claim jobs with FOR UPDATE SKIP LOCKED and call the same
+// handlers Inngest does. Handlers run outside Inngest's step memoization, so
+// each attempt repeats every step. Jobs that fail for good are moved to
+// job_dead_letters.
+type PostgresRunner struct {
+	db      *gorm.DB
+	limits  JobLimits
+	options PostgresRunnerOptions
+	// jobs maps event names to the jobs they trigger. It is filled by Register
+	// before Start and only read afterwards.
+	jobs map[string]Job
+	// skipped lists the IDs of registered jobs that need Inngest.
+	skipped []string
+	workers sync.WaitGroup
+	// jobCtx is the context handlers run with. It is not cancelled with the
+	// context given to Start, so running jobs can finish during shutdown, and
+	// interruptJobs cancels it once Wait's deadline passes.
+	jobCtx        context.Context
+	interruptJobs context.CancelCauseFunc
+}
+
+var _ JobRunner = (*PostgresRunner)(nil)
+
+func NewPostgresRunner(db *gorm.DB, limits JobLimits, options PostgresRunnerOptions) *PostgresRunner {
+	return &PostgresRunner{
+		db:      db,
+		limits:  limits,
+		options: options,
+		jobs:    make(map[string]Job),
+	}
+}
+
+// Register adds an event-triggered job. Durable and cron jobs need Inngest and
+// are skipped.
+func (r *PostgresRunner) Register(job Job) {
+	if job.Durable || job.Event == "" {
+		r.skipped = append(r.skipped, job.ID)
+		return
+	}
+	r.jobs[job.Event] = job
+}
+
+func (r *PostgresRunner) Send(ctx context.Context, evt any) (string, error) {
+	ids, err := r.SendMany(ctx, []any{evt})
+	if err != nil {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// SendMany queues one job per event and drops pending jobs the events
+// cancel. Events carrying an ID are queued at most once while a job with that
+// ID is pending.
+func (r *PostgresRunner) SendMany(ctx context.Context, evts []any) ([]string, error) {
+	ids := make([]string, 0, len(evts))
+	for _, evt := range evts {
+		event, err := decodeEvent(evt)
+		if err != nil {
+			return ids, err
+		}
+		id, err := r.enqueue(ctx, event)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *PostgresRunner) enqueue(ctx context.Context, event queuedEvent) (string, error) {
+	cancels, err := r.cancelPending(ctx, event)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := r.jobs[event.Name]; !ok {
+		if cancels {
+			return "", nil
+		}
+		return "", fmt.Errorf("job backend %s does not run %s events", JobBackendPostgres, event.Name)
+	}
+
+	row := &dbmodels.JobQueue{
+		Event:   event.Name,
+		EventID: event.ID,
+		Data:    string(event.Data),
+	}
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "event_id"}}, DoNothing: true}).
+		Create(row).Error; err != nil {
+		return "", fmt.Errorf("failed to queue %s job: %w", event.Name, runerrors.Database(err))
+	}
+	if event.ID != nil {
+		return *event.ID, nil
+	}
+	return row.ID, nil
+}
+
+// cancelPending deletes jobs that have not started and are cancelled by
+// event, mirroring Inngest's cancellation of queued functions. Running jobs
+// are stopped by the event's own job, such as workflow/cancel marking the run
+// cancelled. It reports whether any registered job is cancelled by event.
+func (r *PostgresRunner) cancelPending(ctx context.Context, event queuedEvent) (bool, error) {
+	var data map[string]any
+	cancels := false
+	for _, job := range r.jobs {
+		for _, cancel := range job.CancelOn {
+			if cancel.Event != event.Name {
+				continue
+			}
+			cancels = true
+			if data == nil {
+				if err := json.Unmarshal(event.Data, &data); err != nil {
+					return cancels, fmt.Errorf("failed to decode %s event: %w", event.Name, err)
+				}
+			}
+			value, _ := data[cancel.Key].(string)
+			if value == "" {
+				continue
+			}
+			result := r.db.WithContext(ctx).
+				Where("event = ? AND data->>? = ?", job.Event, cancel.Key, value).
+				Where("locked_until IS NULL").
+				Delete(&dbmodels.JobQueue{})
+			if result.Error != nil {
+				return cancels, fmt.Errorf("failed to cancel %s jobs: %w", job.Event, runerrors.Database(result.Error))
+			}
+			if result.RowsAffected > 0 {
+				slog.InfoContext(ctx, "job cancelled",
+					"event", job.Event,
+					"cancelled_by", event.Name,
+					"jobs", result.RowsAffected,
+				)
+			}
+		}
+	}
+	return cancels, nil
+}
+
+// Start runs the runner's workers until ctx is done. Jobs must be registered
+// before Start is called. Workers stop claiming jobs once ctx is done but
+// finish the jobs they are running; see Wait.
+func (r *PostgresRunner) Start(ctx context.Context) {
+	r.jobCtx, r.interruptJobs = context.WithCancelCause(context.WithoutCancel(ctx))
+	slog.InfoContext(ctx, "job runner started",
+		"backend", JobBackendPostgres,
+		"workers", r.options.Workers,
+		"events", r.events(),
+	)
+	if len(r.skipped) > 0 {
+		slog.WarnContext(ctx, "job runner features disabled",
+			"backend", JobBackendPostgres,
+			"jobs", r.skipped,
+			"reason", "requires_inngest",
+		)
+	}
+	for range r.options.Workers {
+		r.workers.Add(1)
+		go func() {
+			defer r.workers.Done()
+			r.work(ctx)
+		}()
+	}
+}
+
+// Wait blocks until the workers started by Start have returned. When ctx is
+// done first, running jobs are interrupted with graphs.ErrRunInterrupted and
+// released back to the queue without using up an attempt. Their runs stay
+// running for the next attempt.
+func (r *PostgresRunner) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	if r.interruptJobs != nil {
+		r.interruptJobs(graphs.ErrRunInterrupted)
+	}
+	select {
+	case <-done:
+	case <-time.After(jobInterruptGrace):
+	}
+	return ctx.Err()
+}
+
+func (r *PostgresRunner) work(ctx context.Context) {
+	for {
+		claimed, err := r.runNext(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "job claim_failed", "backend", JobBackendPostgres, "err", err)
+		}
+		if claimed {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.options.PollInterval):
+		}
+	}
+}
+
+// runNext claims one due job and runs it. It reports whether a job was
+// claimed.
+func (r *PostgresRunner) runNext(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+	row, err := r.claim(ctx)
+	if err != nil || row == nil {
+		return false, err
+	}
+	r.process(r.jobCtx, row)
+	return true, nil
+}
+
+// claim takes the next due job, counts the attempt, and hides it from other
+// workers for the visibility timeout.
+func (r *PostgresRunner) claim(ctx context.Context) (*dbmodels.JobQueue, error) {
+	var row dbmodels.JobQueue
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.claimQuery(tx).Take(&row).Error; err != nil {
+			return err
+		}
+		return tx.Model(&dbmodels.JobQueue{}).
+			Where("id = ?", row.ID).
+			Updates(map[string]any{
+				"attempts":     gorm.Expr("attempts + 1"),
+				"locked_until": r.leaseExpiry(),
+				"updated_at":   gorm.Expr("now()"),
+			}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", runerrors.Database(err))
+	}
+	row.Attempts++
+	return &row, nil
+}
+
+// claimQuery selects the next due job of a registered event. Limited jobs
+// skip tenants already at their JobLimits concurrency and dashboard runs start
+// ahead of the rest by JobLimits.DashboardPriority. Limits are checked when a
+// job is claimed, so workers claiming at the same moment can briefly exceed
+// them.
+func (r *PostgresRunner) claimQuery(tx *gorm.DB) *gorm.DB {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("event IN ?", r.events()).
+		Where("run_at <= now()").
+		Where("locked_until IS NULL OR locked_until <= now()")
+
+	limited := r.limitedEvents()
+	order := clause.Expr{SQL: "run_at, id"}
+	if len(limited) > 0 {
+		var limits []string
+		var args []any
+		if r.limits.OrganizationConcurrency > 0 {
+			limits = append(limits, `(SELECT count(*) FROM job_queue AS running
+				WHERE running.event = job_queue.event
+				AND running.locked_until > now()
+				AND coalesce(running.data->>'organization_id', '') = coalesce(job_queue.data->>'organization_id', '')) < ?`)
+			args = append(args, r.limits.OrganizationConcurrency)
+		}
+		if r.limits.APIKeyConcurrency > 0 {
+			// Jobs without an API key only count against their organization.
+			limits = append(limits, `(coalesce(job_queue.data->>'api_key_id', '') = '' OR (SELECT count(*) FROM job_queue AS running
+				WHERE running.event = job_queue.event
+				AND running.locked_until > now()
+				AND coalesce(running.data->>'organization_id', '') = coalesce(job_queue.data->>'organization_id', '')
+				AND running.data->>'api_key_id' = job_queue.data->>'api_key_id') < ?)`)
+			args = append(args, r.limits.APIKeyConcurrency)
+		}
+		if len(limits) > 0 {
+			query = query.Where(
+				"event NOT IN ? OR ("+strings.Join(limits, " AND ")+")",
+				append([]any{limited}, args...)...,
+			)
+		}
+		if r.limits.DashboardPriority > 0 {
+			order = clause.Expr{
+				SQL:  "run_at - CASE WHEN event IN ? AND data->>'source' = ? THEN make_interval(secs => ?) ELSE interval '0' END, id",
+				Vars: []any{limited, inputs.JobSourceDashboard, r.limits.DashboardPriority},
+			}
+		}
+	}
+	return query.Clauses(clause.OrderBy{Expression: order})
+}
+
+// process runs a claimed job and records its outcome. ctx is cancelled only
+// when the job is interrupted during shutdown.
+func (r *PostgresRunner) process(ctx context.Context, row *dbmodels.JobQueue) {
+	job := r.jobs[row.Event]
+	attempt := int(row.Attempts) - 1
+	maxAttempts := jobMaxAttempts(job)
+	if attempt >= maxAttempts {
+		// The worker running the final attempt stopped without reporting.
+		r.deadLetter(ctx, row, fmt.Sprintf("visibility timeout expired on the final attempt of %d", maxAttempts))
+		return
+	}
+
+	leaseCtx, releaseLease := context.WithCancel(ctx)
+	go r.holdLease(leaseCtx, row)
+	err := r.call(ctx, job, row, attempt)
+	releaseLease()
+
+	outcome, retryAt := jobOutcomeOf(err, attempt, maxAttempts, ctx.Err() != nil, time.Now())
+	logAttrs := []any{
+		"job_id", row.ID,
+		"event", row.Event,
+		"attempt", attempt + 1,
+		"max_attempts", maxAttempts,
+	}
+	switch outcome {
+	case jobSucceeded:
+		r.recordOutcome(ctx, row, r.db.Where("id = ? AND attempts = ?", row.ID, row.Attempts).Delete(&dbmodels.JobQueue{}).Error)
+	case jobReleased:
+		slog.InfoContext(ctx, "job released", append(logAttrs, "reason", "runner_stopped")...)
+		r.recordOutcome(ctx, row, r.db.Model(&dbmodels.JobQueue{}).
+			Where("id = ? AND attempts = ?", row.ID, row.Attempts).
+			Updates(map[string]any{
+				"attempts":     gorm.Expr("attempts - 1"),
+				"locked_until": nil,
+				"updated_at":   gorm.Expr("now()"),
+			}).Error)
+	case jobRetried:
+		slog.WarnContext(ctx, "job retry_scheduled", append(logAttrs,
+			"retry_at", retryAt.Format(time.RFC3339),
+			"err", err,
+		)...)
+		r.recordOutcome(ctx, row, r.db.Model(&dbmodels.JobQueue{}).
+			Where("id = ? AND attempts = ?", row.ID, row.Attempts).
+			Updates(map[string]any{
+				"run_at":       gorm.Expr("now() + make_interval(secs => ?)", time.Until(retryAt).Seconds()),
+				"locked_until": nil,
+				"last_error":   err.Error(),
+				"updated_at":   gorm.Expr("now()"),
+			}).Error)
+	case jobFailed:
+		slog.ErrorContext(ctx, "job failed", append(logAttrs, "err", err)...)
+		r.deadLetter(ctx, row, err.Error())
+	}
+}
+
+// call runs a job's handler, turning a panic into an error.
+func (r *PostgresRunner) call(ctx context.Context, job Job, row *dbmodels.JobQueue, attempt int) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	_, err = job.handler.run(ctx, queuedEvent{
+		ID:        row.EventID,
+		Name:      row.Event,
+		Data:      json.RawMessage(row.Data),
+		Timestamp: row.CreatedAt.UnixMilli(),
+	}, inngestgo.InputCtx{
+		FunctionID: job.ID,
+		RunID:      row.ID,
+		Attempt:    attempt,
+	})
+	return err
+}
+
+// holdLease keeps extending a running job's visibility timeout until ctx is
+// done.
+func (r *PostgresRunner) holdLease(ctx context.Context, row *dbmodels.JobQueue) {
+	ticker := time.NewTicker(r.options.VisibilityTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		result := r.db.Model(&dbmodels.JobQueue{}).
+			Where("id = ? AND attempts = ?", row.ID, row.Attempts).
+			Update("locked_until", r.leaseExpiry())
+		if result.Error != nil {
+			slog.WarnContext(ctx, "job lease_extend_failed", "job_id", row.ID, "event", row.Event, "err", result.Error)
+		} else if result.RowsAffected == 0 {
+			slog.WarnContext(ctx, "job lease_lost", "job_id", row.ID, "event", row.Event)
+			return
+		}
+	}
+}
+
+func (r *PostgresRunner) leaseExpiry() clause.Expr {
+	return gorm.Expr("now() + make_interval(secs => ?)", r.options.VisibilityTimeout.Seconds())
+}
+
+// deadLetter moves a job that failed for good to job_dead_letters.
+func (r *PostgresRunner) deadLetter(ctx context.Context, row *dbmodels.JobQueue, cause string) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Where("id = ? AND attempts = ?", row.ID, row.Attempts).Delete(&dbmodels.JobQueue{})
+		if deleted.Error != nil || deleted.RowsAffected == 0 {
+			return deleted.Error
+		}
+		return tx.Create(&dbmodels.JobDeadLetter{
+			ID:         row.ID,
+			Event:      row.Event,
+			EventID:    row.EventID,
+			Data:       row.Data,
+			Attempts:   row.Attempts,
+			Error:      cause,
+			EnqueuedAt: row.CreatedAt,
+		}).Error
+	})
+	r.recordOutcome(ctx, row, err)
+}
+
+// recordOutcome logs a failed write of a job's outcome. The job becomes
+// visible again once its lease expires.
+func (r *PostgresRunner) recordOutcome(ctx context.Context, row *dbmodels.JobQueue, err error) {
+	if err != nil {
+		slog.ErrorContext(ctx, "job outcome_record_failed",
+			"job_id", row.ID,
+			"event", row.Event,
+			"err", runerrors.Database(err),
+		)
+	}
+}
+
+// events returns the registered event names in a stable order.
+func (r *PostgresRunner) events() []string {
+	events := make([]string, 0, len(r.jobs))
+	for event := range r.jobs {
+		events = append(events, event)
+	}
+	slices.Sort(events)
+	return events
+}
+
+func (r *PostgresRunner) limitedEvents() []string {
+	var events []string
+	for _, event := range r.events() {
+		if r.jobs[event].Limited {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func jobMaxAttempts(job Job) int {
+	if job.Retries == nil {
+		return defaultJobRetries + 1
+	}
+	return *job.Retries + 1
+}
+
+type jobOutcome int
+
+const (
+	jobSucceeded jobOutcome = iota
+	// jobReleased returns the job to the queue without counting the attempt.
+	jobReleased
+	jobRetried
+	jobFailed
+)
+
+// jobOutcomeOf decides what happens to a job after the given zero-based
+// attempt returned err. It follows the Inngest contract handlers are written
+// for: NoRetryError fails the job, RetryAtError picks the next attempt time,
+// and other errors back off like run retries. Jobs interrupted because the
+// runner is stopping are released rather than retried.
+func jobOutcomeOf(err error, attempt int, maxAttempts int, stopping bool, now time.Time) (jobOutcome, time.Time) {
+	switch {
+	case err == nil:
+		return jobSucceeded, time.Time{}
+	case stopping:
+		return jobReleased, time.Time{}
+	case inngesterrors.IsNoRetryError(err), attempt+1 >= maxAttempts:
+		return jobFailed, time.Time{}
+	}
+	if retryAt := inngesterrors.GetRetryAtTime(err); retryAt != nil {
+		return jobRetried, *retryAt
+	}
+	return jobRetried, now.Add(runRetryDelay(attempt))
+}
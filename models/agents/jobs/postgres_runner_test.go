@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/inngest/inngestgo"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+func TestLoadPostgresRunnerOptionsDefaults(t *testing.T) {
+	t.Setenv("JOB_WORKERS", "")
+	t.Setenv("JOB_VISIBILITY_TIMEOUT_SECONDS", "")
+
+	options, err := LoadPostgresRunnerOptions()
+	if err != nil {
+		t.Fatalf("LoadPostgresRunnerOptions returned error: %v", err)
+	}
+	if options.Workers != defaultJobWorkers ||
+		options.VisibilityTimeout != defaultJobVisibilityTimeout ||
+		options.PollInterval != defaultJobPollInterval {
+		t.Fatalf("unexpected default options: %#v", options)
+	}
+}
+
+func TestLoadPostgresRunnerOptionsRejectsInvalidValues(t *testing.T) {
+	for name, value := range map[string]string{
+		"JOB_WORKERS":                    "0",
+		"JOB_VISIBILITY_TIMEOUT_SECONDS": "2",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := LoadPostgresRunnerOptions()
+			if err == nil || !strings.Contains(err.Error(), name) {
+				t.Fatalf("expected error naming %s, got %v", name, err)
+			}
+		})
+	}
+}
+
+func TestPostgresRunnerSkipsJobsThatNeedInngest(t *testing.T) {
+	runner := NewPostgresRunner(nil, JobLimits{}, PostgresRunnerOptions{})
+	runner.Register(Job{ID: "workflow-execute", Event: "workflow/execute"})
+	runner.Register(Job{ID: "workflow-backfill", Event: "workflow/backfill", Durable: true})
+	runner.Register(Job{ID: "workflow-schedules-tick", Cron: "* * * * *"})
+
+	if events := runner.events(); len(events) != 1 || events[0] != "workflow/execute" {
+		t.Fatalf("expected only workflow/execute to be registered, got %v", events)
+	}
+	if !slices.Equal(runner.skipped, []string{"workflow-backfill", "workflow-schedules-tick"}) {
+		t.Fatalf("expected the skipped jobs to be recorded, got %v", runner.skipped)
+	}
+}
+
+func TestClaimQuerySkipsLockedJobsAndAppliesLimits(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	runner := NewPostgresRunner(db, JobLimits{
+		OrganizationConcurrency: 8,
+		APIKeyConcurrency:       2,
+		DashboardPriority:       30,
+	}, PostgresRunnerOptions{})
+	runner.Register(Job{ID: "workflow-execute", Event: "workflow/execute", Limited: true})
+	runner.Register(Job{ID: "webhook-deliver", Event: "webhook/deliver"})
+
+	query := runner.claimQuery(db).Take(&dbmodels.JobQueue{}).Statement.SQL.String()
+
+	for _, fragment := range []string{
+		"event IN (?,?)",
+		"run_at <= now()",
+		"locked_until IS NULL OR locked_until <= now()",
+		"event NOT IN (?) OR",
+		"running.data->>'organization_id'",
+		"coalesce(job_queue.data->>'api_key_id', '') = '' OR",
+		"running.data->>'api_key_id' = job_queue.data->>'api_key_id'",
+		"ORDER BY run_at - CASE WHEN event IN (?) AND data->>'source' = ?",
+		"FOR UPDATE SKIP LOCKED",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in claim query: %s", fragment, query)
+		}
+	}
+}
+
+func TestClaimQueryOrdersByRunAtWithoutLimits(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	runner := NewPostgresRunner(db, JobLimits{}, PostgresRunnerOptions{})
+	runner.Register(Job{ID: "workflow-execute", Event: "workflow/execute", Limited: true})
+
+	query := runner.claimQuery(db).Take(&dbmodels.JobQueue{}).Statement.SQL.String()
+
+	if strings.Contains(query, "NOT IN") || !strings.Contains(query, "ORDER BY run_at, id") {
+		t.Fatalf("unexpected claim query without limits: %s", query)
+	}
+}
+
+func TestJobOutcomeOf(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 2, 0, 0, 0, time.UTC)
+	retryAt := now.Add(time.Hour)
+	failure := errors.New("model unavailable")
+
+	for _, tc := range []struct {
+		name     string
+		err      error
+		attempt  int
+		stopping bool
+		outcome  jobOutcome
+		retryAt  time.Time
+	}{
+		{name: "success", outcome: jobSucceeded},
+		{name: "stopping", err: failure, stopping: true, outcome: jobReleased},
+		{name: "backoff", err: failure, attempt: 1, outcome: jobRetried, retryAt: now.Add(runRetryDelay(1))},
+		{name: "retry at", err: inngestgo.RetryAtError(failure, retryAt), outcome: jobRetried, retryAt: retryAt},
+		{name: "no retry", err: inngestgo.NoRetryError(failure), outcome: jobFailed},
+		{name: "final attempt", err: failure, attempt: 2, outcome: jobFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			outcome, at := jobOutcomeOf(tc.err, tc.attempt, 3, tc.stopping, now)
+			if outcome != tc.outcome || !at.Equal(tc.retryAt) {
+				t.Fatalf("expected outcome %d at %s, got %d at %s", tc.outcome, tc.retryAt, outcome, at)
+			}
+		})
+	}
+}
+
+func TestPostgresRunnerFinishesRunningJobsAfterStop(t *testing.T) {
+	runner := NewPostgresRunner(nil, JobLimits{}, PostgresRunnerOptions{})
+	stopCtx, stop := context.WithCancel(context.Background())
+	runner.Start(stopCtx)
+	stop()
+
+	if err := runner.jobCtx.Err(); err != nil {
+		t.Fatalf("expected running jobs to outlive the stop signal, got %v", err)
+	}
+
+	deadline, cancel := context.WithCancel(context.Background())
+	cancel()
+	runner.workers.Add(1)
+	go func() {
+		defer runner.workers.Done()
+		<-runner.jobCtx.Done()
+	}()
+	if err := runner.Wait(deadline); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected Wait to report the expired deadline, got %v", err)
+	}
+	if !graphs.IsRunInterrupted(runner.jobCtx) {
+		t.Fatalf("expected running jobs to be interrupted, got %v", context.Cause(runner.jobCtx))
+	}
+}
@@ -2,14 +2,19 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/load"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/google/uuid"
 	"github.com/inngest/inngestgo"
 	"github.com/inngest/inngestgo/step"
 	"github.com/smallnest/langgraphgo/graph"
@@ -29,18 +34,24 @@ func ProcessDocumentUpload(ctx context.Context, input inngestgo.Input[inputs.Pro
 		return nil, inngestgo.NoRetryError(fmt.Errorf("mcp not found in context"))
 	}
 
+	attempt := input.InputCtx.Attempt
+	ctx = logging.WithDocumentID(ctx, input.Event.Data.DocumentID.String())
+
 	result, err := step.Run(ctx, "load-document-and-agent-graph", func(ctx context.Context) (*load.DocumentAndAgentGraph, error) {
-		return load.LoadDocumentAndAgentGraph(
+		result, err := load.LoadDocumentAndAgentGraph(
 			ctx,
 			db,
 			input.Event.Data.DocumentID,
 			input.Event.Data.AgentGraphID,
 		)
+		if err != nil {
+			stepErr, _ := stepFailure(attempt, fmt.Errorf("failed to load document and agent graph: %w", err))
+			return nil, stepErr
+		}
+		return result, nil
 	})
 	if err != nil {
-		return nil, inngestgo.NoRetryError(
-			fmt.Errorf("failed to load document and agent graph: %w", err),
-		)
+		return nil, err
 	}
 	if result == nil {
 		return nil, inngestgo.NoRetryError(
@@ -64,60 +75,107 @@ func ProcessDocumentUpload(ctx context.Context, input inngestgo.Input[inputs.Pro
 			fmt.Errorf("document %s has no workflow assigned", input.Event.Data.DocumentID),
 		)
 	}
+	organizationID := result.GraphSnapshot.AgentGraph.OrganizationID
+	ctx = logging.WithOrganizationID(ctx, organizationID)
 
 	tempURL, err := step.Run(ctx, "get-doc-temp-link", func(ctx context.Context) (string, error) {
-		return s3Client.PresignDownload(
+		tempURL, err := s3Client.PresignDownload(
 			ctx,
 			result.Document.Bucket,
 			result.Document.ObjectKey,
 			15*time.Minute,
 		)
+		if err != nil {
+			stepErr, _ := stepFailure(attempt, fmt.Errorf(
+				"failed to produce temp url for document: %w",
+				runerrors.Transient(err),
+			))
+			return "", stepErr
+		}
+		return tempURL, nil
 	})
 	if err != nil {
-		return nil, inngestgo.NoRetryError(
-			fmt.Errorf("failed to produce temp url for document: %w", err),
-		)
+		return nil, err
 	}
 
-	graphResult, err := step.Run(ctx, "run-graph", func(ctx context.Context) (graphState map[string]any, runErr error) {
-		builtGraph, err := graphs.BuildGraph(result.GraphSnapshot, mcpClient)
-		if err != nil {
-			return nil, inngestgo.NoRetryError(fmt.Errorf("failed to build graph: %w", err))
-		}
-		if builtGraph == nil {
-			return nil, inngestgo.NoRetryError(fmt.Errorf("built graph is nil"))
-		}
+	backfillID := ""
+	if input.Event.Data.BackfillID != nil {
+		backfillID = input.Event.Data.BackfillID.String()
+	}
 
-		initialState := map[string]any{
-			"document_id": result.Document.ID,
-			"temp_url":    tempURL,
-		}
+	initialState := map[string]any{
+		"document_id": result.Document.ID,
+		"temp_url":    tempURL,
+	}
 
+	// The run record is created in its own step so retried graph attempts
+	// reuse it instead of starting a new run. Runners without step memoization
+	// repeat the step, so the run ID is derived from the job run.
+	runID, err := step.Run(ctx, "create-run", func(ctx context.Context) (string, error) {
 		tracker, err := graphs.NewRunTrackerWithOptions(
 			db,
 			result.GraphSnapshot.AgentGraph.ID,
-			result.GraphSnapshot.AgentGraph.OrganizationID,
+			organizationID,
 			initialState,
 			graphs.RunTrackerOptions{
-				ProjectID: result.Document.ProjectID,
+				NewRunID:   uploadRunID(input.Event.Data.DocumentID, input.InputCtx.RunID),
+				ProjectID:  result.Document.ProjectID,
+				BackfillID: backfillID,
 			},
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create run tracker: %w", err)
+			stepErr, _ := stepFailure(attempt, fmt.Errorf("failed to create run tracker: %w", err))
+			return "", stepErr
 		}
+		return tracker.RunID(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx = logging.WithRunID(ctx, runID)
+
+	graphResult, err := step.Run(ctx, "run-graph", func(ctx context.Context) (graphState map[string]any, runErr error) {
 		defer func() {
+			if errors.Is(runErr, graphs.ErrRunCancelled) {
+				slog.InfoContext(ctx, "graph run stopped", "run_id", runID, "status", "cancelled")
+				graphState, runErr = nil, nil
+				return
+			}
+			if errors.Is(runErr, graphs.ErrRunInterrupted) {
+				slog.WarnContext(ctx, "graph run interrupted", "run_id", runID)
+				graphState = nil
+				return
+			}
+			if runErr != nil {
+				stepErr, retrying := recordStepFailure(db, runID, "run-graph", attempt, runErr)
+				if retrying {
+					graphState, runErr = nil, stepErr
+					return
+				}
+			}
 			status := "failed"
 			if runErr == nil {
 				status = "completed"
 			}
-			_, finalizeErr := graphs.FinalizeRun(
+			finalized, finalizeErr := graphs.FinalizeRun(
 				db,
-				tracker.RunID(),
-				result.GraphSnapshot.AgentGraph.OrganizationID,
+				runID,
+				organizationID,
 				status,
 				graphState,
 				runErr,
 			)
+			if finalized {
+				fireRunTriggers(ctx, db, finishedRun{
+					RunID:          runID,
+					AgentGraphID:   result.GraphSnapshot.AgentGraph.ID,
+					OrganizationID: organizationID,
+					ProjectID:      result.Document.ProjectID,
+					Status:         status,
+					FinalState:     graphState,
+					DocumentIDs:    []string{result.Document.ID},
+				})
+			}
 			if finalizeErr == nil {
 				if runErr != nil {
 					runErr = inngestgo.NoRetryError(fmt.Errorf("graph run failed: %w", runErr))
@@ -128,16 +186,51 @@ func ProcessDocumentUpload(ctx context.Context, input inngestgo.Input[inputs.Pro
 				graphState = nil
 				runErr = fmt.Errorf("failed to finalize graph run: %w", finalizeErr)
 			} else {
-				log.Printf("graph run finalization failed run_id=%s err=%v", tracker.RunID(), finalizeErr)
+				slog.ErrorContext(ctx, "graph run finalization failed", "run_id", runID, "err", finalizeErr)
 			}
 			runErr = inngestgo.NoRetryError(fmt.Errorf("graph run failed: %w", runErr))
 		}()
 
+		builtGraph, err := graphs.BuildGraph(result.GraphSnapshot, mcpClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build graph: %w", errorcodes.Wrap(errorcodes.GraphInvalid, err))
+		}
+		if builtGraph == nil {
+			return nil, fmt.Errorf("built graph is nil")
+		}
+
+		tracker, err := graphs.NewRunTrackerWithOptions(
+			db,
+			result.GraphSnapshot.AgentGraph.ID,
+			organizationID,
+			initialState,
+			graphs.RunTrackerOptions{
+				RunID:                  runID,
+				ProjectID:              result.Document.ProjectID,
+				RetryTransientFailures: canRetryRun(attempt),
+				NodeTypes:              graphs.NodeTypes(result.GraphSnapshot),
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create run tracker: %w", err)
+		}
+
 		tracer := graph.NewTracer()
 		tracer.AddHook(tracker)
 		// Attach tracer directly to the compiled runnable so node-level events fire.
 		builtGraph.SetTracer(tracer)
-		return builtGraph.Invoke(clients.ContextWithExecutionID(ctx, tracker.RunID()), initialState)
+		runCtx, stopCancellation := graphs.WithRunCancellation(ctx, db, tracker.RunID())
+		defer stopCancellation()
+		spanCtx, endRunSpan := graphs.StartRunSpan(runCtx, tracker.RunID(), result.GraphSnapshot.AgentGraph.ID)
+		graphState, runErr = builtGraph.Invoke(clients.ContextWithExecutionID(spanCtx, tracker.RunID()), initialState)
+		endRunSpan(runErr)
+		if runErr != nil && graphs.IsRunCancelled(runCtx) {
+			return nil, graphs.ErrRunCancelled
+		}
+		if runErr != nil && graphs.IsRunInterrupted(runCtx) {
+			return nil, graphs.ErrRunInterrupted
+		}
+		return graphState, runErr
 	})
 	if err != nil {
 		return nil, err
@@ -145,3 +238,12 @@ func ProcessDocumentUpload(ctx context.Context, input inngestgo.Input[inputs.Pro
 
 	return graphResult, nil
 }
+
+// uploadRunID derives the run ID of an upload from the job run processing it,
+// or returns "" when the job run is unknown.
+func uploadRunID(documentID uuid.UUID, jobRunID string) string {
+	if jobRunID == "" {
+		return ""
+	}
+	return uuid.NewSHA1(documentID, []byte(jobRunID)).String()
+}
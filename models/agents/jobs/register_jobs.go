@@ -2,24 +2,80 @@ package jobs
 
 import (
 	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
-	"github.com/inngest/inngestgo"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
 	"gorm.io/gorm"
 )
 
-func RegisterJobs(inngestClient inngestgo.Client, dbClient *gorm.DB, s3Client *clients.S3Client, mcpClient *clients.MCPClient) {
-	seedInitialWithContext := WithJobContext(dbClient, s3Client, mcpClient, ProcessDocumentUpload)
-	inngestgo.CreateFunction(inngestClient, inngestgo.FunctionOpts{
-		ID: "process-document-upload",
-	},
-		inngestgo.EventTrigger("document/process.upload", nil),
-		seedInitialWithContext,
-	)
-
-	executeWorkflowWithContext := WithJobContext(dbClient, s3Client, mcpClient, ExecuteWorkflow)
-	inngestgo.CreateFunction(inngestClient, inngestgo.FunctionOpts{
-		ID: "workflow-execute",
-	},
-		inngestgo.EventTrigger("workflow/execute", nil),
-		executeWorkflowWithContext,
-	)
+func RegisterJobs(runner JobRunner, dbClient *gorm.DB, s3Client *clients.S3Client, mcpClient *clients.MCPClient) {
+	seedInitialWithContext := WithJobContext(dbClient, s3Client, mcpClient, WithEventSender(runner, ProcessDocumentUpload))
+	runner.Register(Job{
+		ID:       "process-document-upload",
+		Event:    "document/process.upload",
+		Retries:  runRetries,
+		Limited:  true,
+		CancelOn: []JobCancel{{Event: "workflow/backfill.cancel", Key: "backfill_id"}},
+		handler:  jobFunc[inputs.ProcessDocumentUploadInput](seedInitialWithContext),
+	})
+
+	executeWorkflowWithContext := WithJobContext(dbClient, s3Client, mcpClient, WithEventSender(runner, ExecuteWorkflow))
+	runner.Register(Job{
+		ID:       "workflow-execute",
+		Event:    "workflow/execute",
+		Retries:  runRetries,
+		Limited:  true,
+		CancelOn: []JobCancel{{Event: "workflow/cancel", Key: "execution_id"}},
+		handler:  jobFunc[inputs.ExecuteWorkflowInput](executeWorkflowWithContext),
+	})
+
+	cancelWorkflowWithContext := WithJobContext(dbClient, s3Client, mcpClient, CancelWorkflow)
+	runner.Register(Job{
+		ID:      "workflow-cancel",
+		Event:   "workflow/cancel",
+		handler: jobFunc[inputs.CancelWorkflowInput](cancelWorkflowWithContext),
+	})
+
+	backfillWorkflowWithContext := WithJobContext(dbClient, s3Client, mcpClient, BackfillWorkflow)
+	runner.Register(Job{
+		ID:          "workflow-backfill",
+		Event:       "workflow/backfill",
+		SerializeBy: "backfill_id",
+		CancelOn:    []JobCancel{{Event: "workflow/backfill.cancel", Key: "backfill_id"}},
+		Durable:     true,
+		handler:     jobFunc[inputs.BackfillWorkflowInput](backfillWorkflowWithContext),
+	})
+
+	cancelBackfillWithContext := WithJobContext(dbClient, s3Client, mcpClient, CancelBackfill)
+	runner.Register(Job{
+		ID:      "workflow-backfill-cancel",
+		Event:   "workflow/backfill.cancel",
+		handler: jobFunc[inputs.CancelBackfillInput](cancelBackfillWithContext),
+	})
+
+	tickSchedulesWithContext := WithJobContext(dbClient, s3Client, mcpClient, TickWorkflowSchedules)
+	runner.Register(Job{
+		ID:      "workflow-schedules-tick",
+		Cron:    workflowScheduleTick,
+		Durable: true,
+		handler: jobFunc[map[string]any](tickSchedulesWithContext),
+	})
+
+	fireScheduleWithContext := WithJobContext(dbClient, s3Client, mcpClient, FireWorkflowSchedule)
+	runner.Register(Job{
+		ID:      "workflow-schedule-fire",
+		Event:   "workflow/schedule.fire",
+		Retries: runRetries,
+		Durable: true,
+		handler: jobFunc[inputs.FireScheduleInput](fireScheduleWithContext),
+	})
+
+	graphs.OnRunFinalized(observeRunFinished)
+	graphs.OnRunFinalized(enqueueWebhooksOnFinalize(runner))
+	deliverWebhookWithContext := WithJobContext(dbClient, s3Client, mcpClient, DeliverWebhook)
+	runner.Register(Job{
+		ID:      "webhook-deliver",
+		Event:   "webhook/deliver",
+		Retries: webhookRetries,
+		handler: jobFunc[inputs.DeliverWebhookInput](deliverWebhookWithContext),
+	})
 }
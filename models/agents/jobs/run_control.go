@@ -0,0 +1,265 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/google/uuid"
+	"github.com/inngest/inngestgo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RequeueMode says how RequeueRun starts a new run from an earlier one.
+type RequeueMode string
+
+const (
+	// RequeueRetry repeats a failed run with the graph it was pinned to.
+	RequeueRetry RequeueMode = "retry"
+	// RequeueRerun runs the workflow's current graph on a finished run's
+	// documents.
+	RequeueRerun RequeueMode = "rerun"
+)
+
+var (
+	// ErrRunNotFound is returned when a run to control does not exist.
+	ErrRunNotFound = errors.New("run not found")
+	// ErrRunStatusConflict is returned when a run's status does not allow
+	// the requested action.
+	ErrRunStatusConflict = errors.New("run status does not allow this action")
+)
+
+// runtimeStateKeys are filled in by ExecuteWorkflow for every attempt, so
+// they are dropped from the state a requeued run starts with.
+var runtimeStateKeys = []string{"document_id", "document_ids", "documents", "temp_url"}
+
+// RequeueRun records a new run on the documents of runID and enqueues it.
+// Retrying the same run twice returns the same new run, so a repeated request
+// does not start a second one. Once that retry has finished, retrying the
+// source again is a conflict; the retry itself can be retried instead.
+func RequeueRun(ctx context.Context, db *gorm.DB, sender EventSender, runID string, mode RequeueMode) (*inputs.ExecuteWorkflowInput, error) {
+	source, organizationID, err := loadControlledRun(ctx, db, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var graphSnapshot, graphSnapshotHash string
+	switch mode {
+	case RequeueRetry:
+		if source.Status != "failed" {
+			return nil, fmt.Errorf("%w: only failed runs can be retried, run %s is %s", ErrRunStatusConflict, runID, source.Status)
+		}
+		if source.GraphSnapshot != nil && source.GraphSnapshotHash != nil {
+			graphSnapshot, graphSnapshotHash = *source.GraphSnapshot, *source.GraphSnapshotHash
+		}
+	case RequeueRerun:
+		if source.Status == "running" {
+			return nil, fmt.Errorf("%w: run %s is still running", ErrRunStatusConflict, runID)
+		}
+	default:
+		return nil, fmt.Errorf("unknown requeue mode %q", mode)
+	}
+	if graphSnapshot == "" {
+		agentGraphID, err := uuid.Parse(source.AgentGraphID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run workflow id %q: %w", source.AgentGraphID, err)
+		}
+		graphSnapshot, graphSnapshotHash, err = loadPinnedSnapshot(ctx, db, agentGraphID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	run, execution, err := buildRequeuedRun(source, organizationID, mode)
+	if err != nil {
+		return nil, err
+	}
+	run.GraphSnapshot = &graphSnapshot
+	run.GraphSnapshotHash = &graphSnapshotHash
+	created := db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(run)
+	if created.Error != nil {
+		return nil, fmt.Errorf("failed to create requeued run: %w", runerrors.Database(created.Error))
+	}
+	if created.RowsAffected == 0 {
+		// The retry already exists. While it runs, the request is a repeat;
+		// once it has finished, the event would be dropped as a duplicate.
+		var existing dbmodels.AgentGraphRun
+		if err := db.WithContext(ctx).Select("id, status").Where("id = ?", run.ID).Take(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to load requeued run %s: %w", run.ID, runerrors.Database(err))
+		}
+		if existing.Status != "running" {
+			return nil, fmt.Errorf("%w: run %s was already retried as run %s, which is %s; retry that run instead",
+				ErrRunStatusConflict, runID, existing.ID, existing.Status)
+		}
+	}
+
+	if _, err := sender.Send(ctx, inngestgo.GenericEvent[inputs.ExecuteWorkflowInput]{
+		ID:   inngestgo.StrPtr(execution.ExecutionID.String()),
+		Name: "workflow/execute",
+		Data: *execution,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to enqueue requeued run: %w", err)
+	}
+	slog.InfoContext(ctx, "workflow run requeued",
+		"run_id", runID,
+		"mode", string(mode),
+		"new_run_id", execution.ExecutionID,
+	)
+	return execution, nil
+}
+
+// buildRequeuedRun returns the run record and execution for running source
+// again. The new run keeps the source's initial state, project, and API key
+// so it is queued and delivered like the original, and links back to source
+// as its parent.
+func buildRequeuedRun(source *dbmodels.AgentGraphRun, organizationID string, mode RequeueMode) (*dbmodels.AgentGraphRun, *inputs.ExecuteWorkflowInput, error) {
+	rawDocumentIDs := runDocumentIDs(source)
+	if len(rawDocumentIDs) == 0 {
+		return nil, nil, fmt.Errorf("run %s has no documents to run again", source.ID)
+	}
+	documentIDs := make([]uuid.UUID, 0, len(rawDocumentIDs))
+	for _, rawDocumentID := range rawDocumentIDs {
+		documentID, err := uuid.Parse(rawDocumentID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid document id %q: %w", rawDocumentID, err)
+		}
+		documentIDs = append(documentIDs, documentID)
+	}
+	sourceID, err := uuid.Parse(source.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid run id %q: %w", source.ID, err)
+	}
+	agentGraphID, err := uuid.Parse(source.AgentGraphID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid run workflow id %q: %w", source.AgentGraphID, err)
+	}
+	parsedOrganizationID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid organization id %q: %w", organizationID, err)
+	}
+
+	initialState := decodeRunState(source.InitialState)
+	if initialState == nil {
+		initialState = map[string]any{}
+	}
+	for _, key := range runtimeStateKeys {
+		delete(initialState, key)
+	}
+	executionScope := map[string]any{}
+	if scope, ok := initialState["scope"].(map[string]any); ok {
+		for key, value := range scope {
+			executionScope[key] = value
+		}
+	}
+	executionScope["documentIds"] = rawDocumentIDs
+	initialState["scope"] = executionScope
+	if source.ProjectID != nil {
+		initialState["project_id"] = *source.ProjectID
+	}
+	stateJSON, err := json.Marshal(initialState)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode initial state: %w", err)
+	}
+	stateStr := string(stateJSON)
+
+	executionID := uuid.NewSHA1(sourceID, []byte(RequeueRetry))
+	if mode != RequeueRetry {
+		executionID, err = uuid.NewV7()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate run id: %w", err)
+		}
+	}
+	apiKeyID := ""
+	if source.APIKeyID != nil {
+		apiKeyID = *source.APIKeyID
+	}
+
+	run := &dbmodels.AgentGraphRun{
+		ID:           executionID.String(),
+		AgentGraphID: source.AgentGraphID,
+		ProjectID:    source.ProjectID,
+		APIKeyID:     source.APIKeyID,
+		ParentRunID:  &source.ID,
+		Status:       "running",
+		InitialState: &stateStr,
+	}
+	return run, &inputs.ExecuteWorkflowInput{
+		ExecutionID:    executionID,
+		WorkflowID:     agentGraphID,
+		OrganizationID: parsedOrganizationID,
+		APIKeyID:       apiKeyID,
+		Source:         inputs.JobSourceRunAPI,
+		DocumentIDs:    documentIDs,
+		Scope:          executionScope,
+		InitialState:   initialState,
+	}, nil
+}
+
+// CancelRun cancels a running run right away and sends workflow/cancel so an
+// execution still queued is dropped. It returns false when the run
+// was no longer running.
+func CancelRun(ctx context.Context, db *gorm.DB, sender EventSender, runID string, reason string) (bool, error) {
+	run, organizationID, err := loadControlledRun(ctx, db, runID)
+	if err != nil {
+		return false, err
+	}
+	if run.Status != "running" {
+		return false, nil
+	}
+
+	cancelled, err := graphs.CancelRun(db, run.ID, organizationID, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel run %s: %w", runID, runerrors.Database(err))
+	}
+	if !cancelled {
+		return false, nil
+	}
+
+	executionID, err := uuid.Parse(run.ID)
+	if err != nil {
+		return true, nil
+	}
+	parsedOrganizationID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return true, nil
+	}
+	if _, err := sender.Send(ctx, inngestgo.GenericEvent[inputs.CancelWorkflowInput]{
+		Name: "workflow/cancel",
+		Data: inputs.CancelWorkflowInput{
+			ExecutionID:    executionID,
+			OrganizationID: parsedOrganizationID,
+			Reason:         reason,
+		},
+	}); err != nil {
+		slog.WarnContext(ctx, "workflow run cancel_event_failed", "run_id", runID, "err", err)
+	}
+	return true, nil
+}
+
+// loadControlledRun loads a run and the organization that owns its workflow.
+func loadControlledRun(ctx context.Context, db *gorm.DB, runID string) (*dbmodels.AgentGraphRun, string, error) {
+	var run dbmodels.AgentGraphRun
+	if err := db.WithContext(ctx).Where("id = ?", runID).Take(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+		}
+		return nil, "", fmt.Errorf("failed to load run %s: %w", runID, runerrors.Database(err))
+	}
+	var agentGraph dbmodels.AgentGraph
+	if err := db.WithContext(ctx).
+		Select("id, organization_id").
+		Where("id = ?", run.AgentGraphID).
+		Take(&agentGraph).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load run %s workflow: %w", runID, runerrors.Database(err))
+	}
+	return &run, agentGraph.OrganizationID, nil
+}
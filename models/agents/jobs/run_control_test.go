@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func requeueSource() *dbmodels.AgentGraphRun {
+	initialState := `{"project_id":"0190f2a0-0000-7000-8000-000000000003","scope":{"documentIds":["0190f2a0-0000-7000-8000-000000000004"],"apiKeyUploadsOnly":true},"label":"nightly","temp_url":"https://example.test/stale"}`
+	projectID := "0190f2a0-0000-7000-8000-000000000003"
+	apiKeyID := "0190f2a0-0000-7000-8000-000000000005"
+	return &dbmodels.AgentGraphRun{
+		ID:           "0190f2a0-0000-7000-8000-000000000001",
+		AgentGraphID: "0190f2a0-0000-7000-8000-000000000002",
+		Status:       "failed",
+		ProjectID:    &projectID,
+		APIKeyID:     &apiKeyID,
+		InitialState: &initialState,
+	}
+}
+
+func TestBuildRequeuedRunKeepsDocumentsAndScope(t *testing.T) {
+	run, execution, err := buildRequeuedRun(requeueSource(), "0190f2a0-0000-7000-8000-000000000006", RequeueRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(execution.DocumentIDs) != 1 || execution.DocumentIDs[0].String() != "0190f2a0-0000-7000-8000-000000000004" {
+		t.Fatalf("unexpected documents: %v", execution.DocumentIDs)
+	}
+	if execution.Scope["apiKeyUploadsOnly"] != true {
+		t.Fatalf("expected scope filters to be kept, got %v", execution.Scope)
+	}
+	if execution.InitialState["label"] != "nightly" {
+		t.Fatalf("expected seeded state to be kept, got %v", execution.InitialState)
+	}
+	if _, ok := execution.InitialState["temp_url"]; ok {
+		t.Fatalf("expected runtime keys to be dropped, got %v", execution.InitialState)
+	}
+	if execution.Source != inputs.JobSourceRunAPI || execution.APIKeyID != "0190f2a0-0000-7000-8000-000000000005" {
+		t.Fatalf("unexpected routing: %q %q", execution.Source, execution.APIKeyID)
+	}
+	if run.ID != execution.ExecutionID.String() || run.Status != "running" || *run.APIKeyID != execution.APIKeyID {
+		t.Fatalf("unexpected run record: %#v", run)
+	}
+	if run.ParentRunID == nil || *run.ParentRunID != "0190f2a0-0000-7000-8000-000000000001" {
+		t.Fatalf("expected the retry to link to its source run, got %v", run.ParentRunID)
+	}
+}
+
+func TestBuildRequeuedRunRetryIDIsStable(t *testing.T) {
+	first, _, err := buildRequeuedRun(requeueSource(), "0190f2a0-0000-7000-8000-000000000006", RequeueRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := buildRequeuedRun(requeueSource(), "0190f2a0-0000-7000-8000-000000000006", RequeueRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != second.ID {
+		t.Fatalf("expected retries of one run to share an ID, got %s and %s", first.ID, second.ID)
+	}
+
+	rerun, _, err := buildRequeuedRun(requeueSource(), "0190f2a0-0000-7000-8000-000000000006", RequeueRerun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rerun.ID == first.ID {
+		t.Fatal("expected a re-run to get a fresh ID")
+	}
+}
+
+func TestBuildRequeuedRunReadsUploadRunDocument(t *testing.T) {
+	source := requeueSource()
+	initialState := `{"document_id":"0190f2a0-0000-7000-8000-000000000007","temp_url":"https://example.test/stale"}`
+	source.InitialState = &initialState
+
+	_, execution, err := buildRequeuedRun(source, "0190f2a0-0000-7000-8000-000000000006", RequeueRerun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(execution.DocumentIDs) != 1 || execution.DocumentIDs[0].String() != "0190f2a0-0000-7000-8000-000000000007" {
+		t.Fatalf("unexpected documents: %v", execution.DocumentIDs)
+	}
+	if _, ok := execution.InitialState["document_id"]; ok {
+		t.Fatalf("expected document_id to be filled in by the execution, got %v", execution.InitialState)
+	}
+}
+
+func TestBuildRequeuedRunRequiresDocuments(t *testing.T) {
+	source := requeueSource()
+	source.InitialState = nil
+
+	if _, _, err := buildRequeuedRun(source, "0190f2a0-0000-7000-8000-000000000006", RequeueRetry); err == nil {
+		t.Fatal("expected a run without documents to be rejected")
+	} else if errors.Is(err, ErrRunStatusConflict) {
+		t.Fatalf("unexpected status conflict: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"log/slog"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
+	"gorm.io/gorm"
+)
+
+// observeRunFinished is a run-finalized hook that counts the run under its
+// workflow, so cancelled runs are counted along with completed and failed ones.
+func observeRunFinished(db *gorm.DB, runID string, status string) {
+	var agentGraphID string
+	if err := db.Model(&dbmodels.AgentGraphRun{}).
+		Select("agent_graph_id").
+		Where("id = ?", runID).
+		Scan(&agentGraphID).Error; err != nil {
+		slog.Error("run metrics workflow_lookup_failed", "run_id", runID, "err", err)
+	}
+	metrics.ObserveRunFinished(agentGraphID, status)
+}
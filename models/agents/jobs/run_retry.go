@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/inngest/inngestgo"
+	"gorm.io/gorm"
+)
+
+const (
+	// runMaxAttempts bounds how many times a step of a run is attempted when it
+	// keeps failing with transient errors.
+	runMaxAttempts    = 4
+	runRetryBaseDelay = 15 * time.Second
+	runRetryMaxDelay  = 5 * time.Minute
+)
+
+// runRetries is the Inngest retry count matching runMaxAttempts.
+var runRetries = inngestgo.IntPtr(runMaxAttempts - 1)
+
+// runRetryDelay returns the backoff before retrying after the given zero-based
+// attempt: 15s, 1m, 4m, capped at runRetryMaxDelay.
+func runRetryDelay(attempt int) time.Duration {
+	delay := runRetryBaseDelay
+	for range attempt {
+		delay *= 4
+		if delay >= runRetryMaxDelay {
+			return runRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// canRetryRun reports whether a step on the given zero-based attempt has
+// attempts left after this one.
+func canRetryRun(attempt int) bool {
+	return attempt+1 < runMaxAttempts
+}
+
+// stepFailure decides how a failed step is reported to Inngest. Transient
+// failures with attempts left are re-enqueued with backoff; everything else
+// fails the run without retries. retryAt is nil when the run will not be retried.
+func stepFailure(attempt int, err error) (stepErr error, retryAt *time.Time) {
+	if !runerrors.IsTransient(err) || !canRetryRun(attempt) {
+		return inngestgo.NoRetryError(err), nil
+	}
+	at := time.Now().Add(runRetryDelay(attempt))
+	return inngestgo.RetryAtError(err, at), &at
+}
+
+// recordStepFailure appends the failed attempt to the run's history and
+// returns the error the step should report. retrying is false when the run
+// must now be finalized as failed.
+func recordStepFailure(db *gorm.DB, runID string, step string, attempt int, err error) (stepErr error, retrying bool) {
+	stepErr, retryAt := stepFailure(attempt, err)
+	record := graphs.RunAttempt{
+		Attempt:   attempt + 1,
+		Step:      step,
+		Error:     err.Error(),
+		ErrorCode: string(errorcodes.Of(err)),
+		Transient: runerrors.IsTransient(err),
+		RetryAt:   retryAt,
+		FailedAt:  time.Now(),
+	}
+	if recordErr := graphs.RecordRunAttempt(db, runID, record); recordErr != nil {
+		slog.Error("graph run attempt_record_failed", "run_id", runID, "step", step, "err", recordErr)
+	}
+	if retryAt != nil {
+		slog.Warn("graph run retry_scheduled",
+			"run_id", runID,
+			"step", step,
+			"attempt", attempt+1,
+			"max_attempts", runMaxAttempts,
+			"retry_at", retryAt.Format(time.RFC3339),
+		)
+	}
+	return stepErr, retryAt != nil
+}
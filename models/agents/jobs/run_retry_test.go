@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunRetryDelayBacksOffAndCaps(t *testing.T) {
+	want := []time.Duration{15 * time.Second, time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for attempt, delay := range want {
+		if got := runRetryDelay(attempt); got != delay {
+			t.Fatalf("attempt %d: expected %s, got %s", attempt, delay, got)
+		}
+	}
+}
+
+func TestCanRetryRunStopsAtMaxAttempts(t *testing.T) {
+	if !canRetryRun(0) || !canRetryRun(runMaxAttempts-2) {
+		t.Fatal("expected early attempts to be retryable")
+	}
+	if canRetryRun(runMaxAttempts - 1) {
+		t.Fatal("expected the last attempt not to be retried")
+	}
+}
@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression (minute, hour, day of
+// month, month, day of week) evaluated in a fixed time zone.
+type cronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// When both day fields are restricted, a day matching either one runs,
+	// as in standard cron.
+	dayOfMonthAny bool
+	dayOfWeekAny  bool
+	location      *time.Location
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinuteField     = cronField{name: "minute", min: 0, max: 59}
+	cronHourField       = cronField{name: "hour", min: 0, max: 23}
+	cronDayOfMonthField = cronField{name: "day of month", min: 1, max: 31}
+	cronMonthField      = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week accepts 7 as a second spelling of Sunday.
+	cronDayOfWeekField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSearchLimit bounds how far ahead next looks, so expressions that can
+// never match (such as February 30th) end instead of looping.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+func parseCronSchedule(expression string, timezone string) (cronSchedule, error) {
+	location, err := time.LoadLocation(strings.TrimSpace(timezone))
+	if err != nil {
+		return cronSchedule{}, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+	}
+
+	trimmed := strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(trimmed)]; ok {
+		trimmed = macro
+	}
+	fields := strings.Fields(trimmed)
+	if len(fields) != 5 {
+		return cronSchedule{}, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expression, len(fields))
+	}
+
+	schedule := cronSchedule{location: location}
+	for i, target := range []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{cronMinuteField, &schedule.minute},
+		{cronHourField, &schedule.hour},
+		{cronDayOfMonthField, &schedule.dayOfMonth},
+		{cronMonthField, &schedule.month},
+		{cronDayOfWeekField, &schedule.dayOfWeek},
+	} {
+		bits, err := parseCronField(fields[i], target.field)
+		if err != nil {
+			return cronSchedule{}, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+		}
+		*target.bits = bits
+	}
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+	schedule.dayOfMonthAny = strings.HasPrefix(fields[2], "*")
+	schedule.dayOfWeekAny = strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges, and steps
+// into a bit set.
+func parseCronField(raw string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(raw, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", field.name, stepPart)
+			}
+			step = parsed
+		}
+
+		start, end := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			rawStart, rawEnd, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = field.value(rawStart); err != nil {
+				return 0, err
+			}
+			if end, err = field.value(rawEnd); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid %s range %q", field.name, rangePart)
+			}
+		default:
+			value, err := field.value(rangePart)
+			if err != nil {
+				return 0, err
+			}
+			start = value
+			// "5/15" means every 15 starting at 5; a bare value is just itself.
+			if !hasStep {
+				end = value
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(raw string) (int, error) {
+	if value, ok := f.names[strings.ToLower(raw)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid %s value %q: must be %d-%d", f.name, raw, f.min, f.max)
+	}
+	return value, nil
+}
+
+// next returns the first minute strictly after the given time that matches
+// the schedule. It returns false when nothing matches within cronSearchLimit.
+func (s cronSchedule) next(after time.Time) (time.Time, bool) {
+	local := after.In(s.location)
+	t := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, s.location).
+		Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func (s cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthAny || s.dayOfWeekAny {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	after := time.Date(2026, time.March, 14, 10, 17, 30, 0, time.UTC)
+	for _, testCase := range []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.March, 14, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.March, 14, 10, 30, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, time.March, 15, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2026, time.March, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either the 20th or any Monday.
+		{"0 0 20 * 1", time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC)},
+	} {
+		t.Run(testCase.expression, func(t *testing.T) {
+			schedule, err := parseCronSchedule(testCase.expression, "UTC")
+			if err != nil {
+				t.Fatalf("parseCronSchedule returned error: %v", err)
+			}
+			next, ok := schedule.next(after)
+			if !ok || !next.Equal(testCase.expected) {
+				t.Fatalf("next = %s (%t), want %s", next, ok, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNextUsesTimeZone(t *testing.T) {
+	schedule, err := parseCronSchedule("0 2 * * *", "America/New_York")
+	if err != nil {
+		t.Fatalf("parseCronSchedule returned error: %v", err)
+	}
+	next, ok := schedule.next(time.Date(2026, time.January, 10, 12, 0, 0, 0, time.UTC))
+	if !ok || !next.Equal(time.Date(2026, time.January, 11, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next firing in New York: %s", next.UTC())
+	}
+}
+
+func TestCronScheduleNeverMatching(t *testing.T) {
+	schedule, err := parseCronSchedule("0 0 30 2 *", "UTC")
+	if err != nil {
+		t.Fatalf("parseCronSchedule returned error: %v", err)
+	}
+	if _, ok := schedule.next(time.Now()); ok {
+		t.Fatal("expected February 30th to never match")
+	}
+}
+
+func TestParseCronScheduleRejectsInvalidExpressions(t *testing.T) {
+	for expression, message := range map[string]string{
+		"* * * *":     "expected 5 fields",
+		"60 * * * *":  "invalid minute value",
+		"* * * * 8":   "invalid day of week value",
+		"*/0 * * * *": "invalid minute step",
+		"5-1 * * * *": "invalid minute range",
+		"* * * foo *": "invalid month value",
+	} {
+		if _, err := parseCronSchedule(expression, "UTC"); err == nil || !strings.Contains(err.Error(), message) {
+			t.Fatalf("expected %q for %q, got %v", message, expression, err)
+		}
+	}
+	if _, err := parseCronSchedule("* * * * *", "Mars/Olympus"); err == nil {
+		t.Fatal("expected unknown time zone to be rejected")
+	}
+}
@@ -0,0 +1,285 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/load"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxScheduledDocuments matches the document cap of API-started executions.
+const maxScheduledDocuments = 500
+
+// scheduleScope narrows a schedule's documents. CreatedWithinHours limits a
+// firing to documents uploaded in the hours before it, so a nightly schedule
+// covers only that day's uploads; zero includes every matching document.
+type scheduleScope struct {
+	documentScope
+	CreatedWithinHours int `json:"created_within_hours,omitempty"`
+}
+
+type scheduleFiring struct {
+	ScheduleID     string    `json:"schedule_id"`
+	OrganizationID string    `json:"organization_id"`
+	FiredAt        time.Time `json:"fired_at"`
+}
+
+// claimDueSchedules advances every enabled schedule whose next firing has
+// passed and returns one firing for each. Locked rows are skipped, so
+// overlapping ticks never fire a schedule twice.
+func claimDueSchedules(db *gorm.DB, now time.Time) ([]scheduleFiring, error) {
+	var firings []scheduleFiring
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var schedules []dbmodels.WorkflowSchedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("enabled AND (next_fire_at IS NULL OR next_fire_at <= ?)", now).
+			Order("next_fire_at").
+			Find(&schedules).Error; err != nil {
+			return fmt.Errorf("failed to load due schedules: %w", runerrors.Database(err))
+		}
+
+		for i := range schedules {
+			updates, firing := advanceSchedule(&schedules[i], now)
+			if err := tx.Model(&dbmodels.WorkflowSchedule{}).
+				Where("id = ?", schedules[i].ID).
+				Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to advance schedule %s: %w", schedules[i].ID, runerrors.Database(err))
+			}
+			if firing != nil {
+				firings = append(firings, *firing)
+			}
+		}
+		return nil
+	})
+	return firings, err
+}
+
+// advanceSchedule works out a due schedule's next firing. A schedule that has
+// not been evaluated yet counts from its last edit, and firings missed while
+// the worker was down collapse into one. Schedules whose expression cannot
+// fire are disabled with the reason recorded.
+func advanceSchedule(schedule *dbmodels.WorkflowSchedule, now time.Time) (map[string]any, *scheduleFiring) {
+	disable := func(reason string) map[string]any {
+		return map[string]any{"enabled": false, "next_fire_at": nil, "last_error": reason}
+	}
+
+	cron, err := parseCronSchedule(schedule.CronExpression, schedule.Timezone)
+	if err != nil {
+		return disable(err.Error()), nil
+	}
+
+	due := schedule.NextFireAt
+	if due == nil {
+		first, ok := cron.next(schedule.UpdatedAt)
+		if !ok {
+			return disable(fmt.Sprintf("cron expression %q never fires", schedule.CronExpression)), nil
+		}
+		if first.After(now) {
+			return map[string]any{"next_fire_at": first.UTC()}, nil
+		}
+		due = &first
+	}
+
+	updates := map[string]any{"last_fired_at": due.UTC()}
+	if next, ok := cron.next(now); ok {
+		updates["next_fire_at"] = next.UTC()
+	} else {
+		updates["enabled"] = false
+		updates["next_fire_at"] = nil
+	}
+	return updates, &scheduleFiring{
+		ScheduleID:     schedule.ID,
+		OrganizationID: schedule.OrganizationID,
+		FiredAt:        due.UTC(),
+	}
+}
+
+// createScheduledRun records the run for one schedule firing, pinned to the
+// workflow's current graph, and returns the execution to enqueue. It returns
+// nil when the schedule is gone or disabled, or when no documents match. The
+// run ID is derived from the firing so a retried step reuses the same run.
+func createScheduledRun(ctx context.Context, db *gorm.DB, firing inputs.FireScheduleInput) (*inputs.ExecuteWorkflowInput, error) {
+	var schedule dbmodels.WorkflowSchedule
+	if err := db.WithContext(ctx).
+		Where("id = ? AND organization_id = ?", firing.ScheduleID, firing.OrganizationID).
+		Take(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load schedule %s: %w", firing.ScheduleID, runerrors.Database(err))
+	}
+	if !schedule.Enabled {
+		return nil, nil
+	}
+
+	var scope scheduleScope
+	if err := json.Unmarshal([]byte(schedule.Scope), &scope); err != nil {
+		return nil, fmt.Errorf("invalid schedule scope: %w", err)
+	}
+	initialState := map[string]any{}
+	if schedule.InitialState != nil {
+		if err := json.Unmarshal([]byte(*schedule.InitialState), &initialState); err != nil {
+			return nil, fmt.Errorf("invalid schedule initial state: %w", err)
+		}
+	}
+
+	agentGraphID, err := uuid.Parse(schedule.AgentGraphID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule workflow id %q: %w", schedule.AgentGraphID, err)
+	}
+	graphSnapshot, graphSnapshotHash, err := loadPinnedSnapshot(ctx, db, agentGraphID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawDocumentIDs []string
+	if err := scheduledDocuments(db.WithContext(ctx), &schedule, scope, firing.FiredAt).
+		Order("d.created_at DESC, d.id DESC").
+		Limit(maxScheduledDocuments).
+		Pluck("d.id", &rawDocumentIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to select scheduled documents: %w", runerrors.Database(err))
+	}
+	if len(rawDocumentIDs) == 0 {
+		return nil, nil
+	}
+	documentIDs := make([]uuid.UUID, 0, len(rawDocumentIDs))
+	for _, rawDocumentID := range rawDocumentIDs {
+		documentID, err := uuid.Parse(rawDocumentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduled document id %q: %w", rawDocumentID, err)
+		}
+		documentIDs = append(documentIDs, documentID)
+	}
+
+	executionScope := map[string]any{"documentIds": rawDocumentIDs}
+	initialState["project_id"] = schedule.ProjectID
+	initialState["scope"] = executionScope
+	stateJSON, err := json.Marshal(initialState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode initial state: %w", err)
+	}
+	stateStr := string(stateJSON)
+
+	executionID := uuid.NewSHA1(firing.ScheduleID, []byte(firing.FiredAt.UTC().Format(time.RFC3339)))
+	run := &dbmodels.AgentGraphRun{
+		ID:                executionID.String(),
+		AgentGraphID:      schedule.AgentGraphID,
+		ProjectID:         &schedule.ProjectID,
+		ScheduleID:        &schedule.ID,
+		Status:            "running",
+		InitialState:      &stateStr,
+		GraphSnapshot:     &graphSnapshot,
+		GraphSnapshotHash: &graphSnapshotHash,
+	}
+	if err := db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to create scheduled run: %w", runerrors.Database(err))
+	}
+
+	return &inputs.ExecuteWorkflowInput{
+		ExecutionID:    executionID,
+		WorkflowID:     agentGraphID,
+		OrganizationID: firing.OrganizationID,
+		Source:         inputs.JobSourceSchedule,
+		DocumentIDs:    documentIDs,
+		Scope:          executionScope,
+		InitialState:   initialState,
+	}, nil
+}
+
+// scheduledDocuments selects the documents in a schedule's project and scope
+// that existed when it fired.
+func scheduledDocuments(db *gorm.DB, schedule *dbmodels.WorkflowSchedule, scope scheduleScope, firedAt time.Time) *gorm.DB {
+	query := scopedDocuments(db, schedule.OrganizationID, scope.documentScope).
+		Where("d.project_id = ?", schedule.ProjectID).
+		Where("d.created_at <= ?", firedAt)
+	if scope.CreatedWithinHours > 0 {
+		query = query.Where("d.created_at > ?", firedAt.Add(-time.Duration(scope.CreatedWithinHours)*time.Hour))
+	}
+	return query
+}
+
+// recordScheduleError stores the outcome of a firing on the schedule; a nil
+// error clears the previous failure.
+func recordScheduleError(db *gorm.DB, scheduleID string, fireErr error) error {
+	var lastError any
+	if fireErr != nil {
+		lastError = fireErr.Error()
+	}
+	if err := db.Model(&dbmodels.WorkflowSchedule{}).
+		Where("id = ?", scheduleID).
+		Update("last_error", lastError).Error; err != nil {
+		return fmt.Errorf("failed to record schedule %s outcome: %w", scheduleID, runerrors.Database(err))
+	}
+	return nil
+}
+
+// loadPinnedSnapshot loads a workflow's current graph and pins it for a new
+// run.
+func loadPinnedSnapshot(ctx context.Context, db *gorm.DB, agentGraphID uuid.UUID) (string, string, error) {
+	snapshot, err := load.LoadAgentGraphSnapshot(ctx, db, agentGraphID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load workflow %s: %w", agentGraphID, err)
+	}
+	return pinGraphSnapshot(snapshot)
+}
+
+// pinGraphSnapshot encodes a workflow snapshot for agent_graph_runs along
+// with the SHA-256 of its canonical JSON. Nodes, tools, and edges are sorted
+// first so an unchanged workflow always pins the same hash.
+func pinGraphSnapshot(snapshot *graphs.Snapshot) (string, string, error) {
+	sort.SliceStable(snapshot.Nodes, func(i, j int) bool {
+		return snapshot.Nodes[i].Node.NodeKey < snapshot.Nodes[j].Node.NodeKey
+	})
+	for _, node := range snapshot.Nodes {
+		sort.SliceStable(node.Tools, func(i, j int) bool {
+			if node.Tools[i].Name != node.Tools[j].Name {
+				return node.Tools[i].Name < node.Tools[j].Name
+			}
+			return node.Tools[i].ID < node.Tools[j].ID
+		})
+	}
+	sort.SliceStable(snapshot.Edges, func(i, j int) bool {
+		if snapshot.Edges[i].FromNode != snapshot.Edges[j].FromNode {
+			return snapshot.Edges[i].FromNode < snapshot.Edges[j].FromNode
+		}
+		return snapshot.Edges[i].ToNode < snapshot.Edges[j].ToNode
+	})
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode graph snapshot: %w", err)
+	}
+	// Round-tripping through a generic value sorts every object's keys.
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return "", "", fmt.Errorf("failed to canonicalize graph snapshot: %w", err)
+	}
+	var canonical bytes.Buffer
+	encoder := json.NewEncoder(&canonical)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return "", "", fmt.Errorf("failed to canonicalize graph snapshot: %w", err)
+	}
+	canonicalJSON := strings.TrimSuffix(canonical.String(), "\n")
+	hash := sha256.Sum256([]byte(canonicalJSON))
+	return canonicalJSON, hex.EncodeToString(hash[:]), nil
+}
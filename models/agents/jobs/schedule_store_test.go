@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+func TestAdvanceScheduleFiresDueSchedule(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 2, 0, 20, 0, time.UTC)
+	due := time.Date(2026, time.March, 15, 2, 0, 0, 0, time.UTC)
+	updates, firing := advanceSchedule(&dbmodels.WorkflowSchedule{
+		ID:             "schedule-1",
+		OrganizationID: "org-1",
+		CronExpression: "0 2 * * *",
+		Timezone:       "UTC",
+		NextFireAt:     &due,
+	}, now)
+
+	if firing == nil || !firing.FiredAt.Equal(due) || firing.ScheduleID != "schedule-1" {
+		t.Fatalf("unexpected firing: %#v", firing)
+	}
+	if updates["last_fired_at"] != due || updates["next_fire_at"] != due.AddDate(0, 0, 1) {
+		t.Fatalf("unexpected updates: %#v", updates)
+	}
+}
+
+func TestAdvanceScheduleCollapsesMissedFirings(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 9, 42, 0, 0, time.UTC)
+	due := time.Date(2026, time.March, 15, 6, 0, 0, 0, time.UTC)
+	updates, firing := advanceSchedule(&dbmodels.WorkflowSchedule{
+		CronExpression: "0 * * * *",
+		Timezone:       "UTC",
+		NextFireAt:     &due,
+	}, now)
+
+	if firing == nil || !firing.FiredAt.Equal(due) {
+		t.Fatalf("expected one firing for the missed hours, got %#v", firing)
+	}
+	if updates["next_fire_at"] != time.Date(2026, time.March, 15, 10, 0, 0, 0, time.UTC) {
+		t.Fatalf("unexpected next firing: %#v", updates["next_fire_at"])
+	}
+}
+
+func TestAdvanceScheduleInitializesNewSchedule(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 9, 42, 0, 0, time.UTC)
+	updates, firing := advanceSchedule(&dbmodels.WorkflowSchedule{
+		CronExpression: "0 2 * * *",
+		Timezone:       "UTC",
+		UpdatedAt:      now.Add(-time.Minute),
+	}, now)
+
+	if firing != nil {
+		t.Fatalf("new schedule fired before its first time: %#v", firing)
+	}
+	if updates["next_fire_at"] != time.Date(2026, time.March, 16, 2, 0, 0, 0, time.UTC) {
+		t.Fatalf("unexpected first firing: %#v", updates)
+	}
+}
+
+func TestAdvanceScheduleDisablesInvalidExpression(t *testing.T) {
+	updates, firing := advanceSchedule(&dbmodels.WorkflowSchedule{
+		CronExpression: "every night",
+		Timezone:       "UTC",
+	}, time.Now())
+
+	if firing != nil || updates["enabled"] != false {
+		t.Fatalf("expected invalid schedule to be disabled, got %#v %#v", updates, firing)
+	}
+	if reason, _ := updates["last_error"].(string); !strings.Contains(reason, "expected 5 fields") {
+		t.Fatalf("unexpected disable reason: %#v", updates["last_error"])
+	}
+}
+
+func TestScheduledDocumentsAppliesProjectAndLookback(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+
+	var documentIDs []string
+	tx := scheduledDocuments(
+		db,
+		&dbmodels.WorkflowSchedule{OrganizationID: "org-1", ProjectID: "project-1"},
+		scheduleScope{documentScope: documentScope{APIKeyUploadsOnly: true}, CreatedWithinHours: 24},
+		time.Now(),
+	).Pluck("d.id", &documentIDs)
+	query := tx.Statement.SQL.String()
+
+	for _, fragment := range []string{
+		"d.organization_id = ?",
+		"d.project_id = ?",
+		"d.created_at <= ?",
+		"d.created_at > ?",
+		"d.api_key_id IS NOT NULL",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in schedule query: %s", fragment, query)
+		}
+	}
+}
+
+func TestPinGraphSnapshotIsOrderIndependent(t *testing.T) {
+	build := func(nodeKeys ...string) *graphs.Snapshot {
+		snapshot := &graphs.Snapshot{AgentGraph: &dbmodels.AgentGraph{ID: "graph-1", Name: "Describe <nightly>"}}
+		for _, nodeKey := range nodeKeys {
+			snapshot.Nodes = append(snapshot.Nodes, &graphs.SnapshotNode{
+				Node: &dbmodels.AgentGraphNode{NodeKey: nodeKey},
+			})
+		}
+		return snapshot
+	}
+
+	encoded, hash, err := pinGraphSnapshot(build("describe", "ocr"))
+	if err != nil {
+		t.Fatalf("pinGraphSnapshot returned error: %v", err)
+	}
+	_, reorderedHash, err := pinGraphSnapshot(build("ocr", "describe"))
+	if err != nil {
+		t.Fatalf("pinGraphSnapshot returned error: %v", err)
+	}
+	if hash != reorderedHash || len(hash) != 64 {
+		t.Fatalf("expected stable 64-character hash, got %q and %q", hash, reorderedHash)
+	}
+	if !strings.Contains(encoded, "Describe <nightly>") {
+		t.Fatalf("snapshot JSON was HTML-escaped: %s", encoded)
+	}
+}
+
+func TestScheduleFireEventsAreIdempotentPerFiring(t *testing.T) {
+	firedAt := time.Date(2026, time.March, 15, 2, 0, 0, 0, time.UTC)
+	events, err := scheduleFireEvents([]scheduleFiring{{
+		ScheduleID:     "0194f3a0-0000-7000-8000-000000000001",
+		OrganizationID: "0194f3a0-0000-7000-8000-000000000002",
+		FiredAt:        firedAt,
+	}})
+	if err != nil {
+		t.Fatalf("scheduleFireEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].ID == nil ||
+		*events[0].ID != "schedule-0194f3a0-0000-7000-8000-000000000001-1773540000" {
+		t.Fatalf("unexpected events: %#v", events)
+	}
+	if _, err := scheduleFireEvents([]scheduleFiring{{ScheduleID: "nope"}}); err == nil {
+		t.Fatal("expected invalid schedule id to be rejected")
+	}
+}
@@ -0,0 +1,276 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxTriggerChainDepth bounds how many triggered runs can follow one another
+// from a run that was not itself triggered.
+const maxTriggerChainDepth = 5
+
+// finishedRun is a run that has just been finalized, with what its triggers
+// need to start the next workflow.
+type finishedRun struct {
+	RunID          string
+	AgentGraphID   string
+	OrganizationID string
+	ProjectID      string
+	Status         string
+	FinalState     map[string]any
+	DocumentIDs    []string
+}
+
+// triggerCondition is checked against a finished run's final state. Key is a
+// JSON path into the state. Without a condition a trigger always fires.
+type triggerCondition struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"`
+	Value    any    `json:"value"`
+}
+
+func (condition triggerCondition) matches(state map[string]any) (bool, error) {
+	actual, exists := graphs.LookupStatePath(state, condition.Key)
+	switch condition.Operator {
+	case "exists":
+		return exists && actual != nil, nil
+	case "equals", "not_equals":
+		equal := exists && conditionValuesEqual(actual, condition.Value)
+		return equal == (condition.Operator == "equals"), nil
+	case "contains":
+		if !exists {
+			return false, nil
+		}
+		if items, ok := actual.([]any); ok {
+			for _, item := range items {
+				if conditionValuesEqual(item, condition.Value) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return strings.Contains(fmt.Sprint(actual), fmt.Sprint(condition.Value)), nil
+	case "lt", "lte", "gt", "gte":
+		left, leftOK := conditionNumber(actual)
+		right, rightOK := conditionNumber(condition.Value)
+		if !exists || !leftOK || !rightOK {
+			return false, nil
+		}
+		switch condition.Operator {
+		case "lt":
+			return left < right, nil
+		case "lte":
+			return left <= right, nil
+		case "gt":
+			return left > right, nil
+		default:
+			return left >= right, nil
+		}
+	default:
+		return false, fmt.Errorf("unknown trigger condition operator %q", condition.Operator)
+	}
+}
+
+func conditionValuesEqual(left any, right any) bool {
+	leftNumber, leftOK := conditionNumber(left)
+	rightNumber, rightOK := conditionNumber(right)
+	if leftOK && rightOK {
+		return leftNumber == rightNumber
+	}
+	return fmt.Sprint(left) == fmt.Sprint(right)
+}
+
+// conditionNumber reads numbers from state values, accepting numeric strings
+// because model output often reports scores as text.
+func conditionNumber(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case float32:
+		return float64(typed), true
+	case int:
+		return float64(typed), true
+	case int32:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case json.Number:
+		number, err := typed.Float64()
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// loadRunTriggers returns the enabled triggers of a finished run's workflow
+// that listen for its status.
+func loadRunTriggers(db *gorm.DB, finished finishedRun) ([]dbmodels.WorkflowTrigger, error) {
+	var triggers []dbmodels.WorkflowTrigger
+	if err := db.
+		Where("agent_graph_id = ? AND organization_id = ? AND enabled", finished.AgentGraphID, finished.OrganizationID).
+		Where("on_status IN ?", []string{finished.Status, "finished"}).
+		Order("created_at, id").
+		Find(&triggers).Error; err != nil {
+		return nil, fmt.Errorf("failed to load workflow triggers: %w", runerrors.Database(err))
+	}
+	return triggers, nil
+}
+
+// runChain returns the workflows of a run and every run that triggered it,
+// starting with the run itself. Retries and reruns also record the run they
+// requeue as their parent; those hops are followed so a requeued run keeps its
+// source's chain, but they add no workflow and no depth.
+func runChain(db *gorm.DB, runID string) ([]string, error) {
+	var agentGraphIDs []string
+	if err := db.Raw(`
+		WITH RECURSIVE chain AS (
+			SELECT id, agent_graph_id, parent_run_id, trigger_id, 0 AS depth, true AS triggered
+			FROM agent_graph_runs
+			WHERE id = ?
+			UNION ALL
+			SELECT r.id, r.agent_graph_id, r.parent_run_id, r.trigger_id,
+				c.depth + CASE WHEN c.trigger_id IS NULL THEN 0 ELSE 1 END,
+				c.trigger_id IS NOT NULL
+			FROM agent_graph_runs r
+			JOIN chain c ON r.id = c.parent_run_id
+			WHERE c.depth < ?
+		)
+		SELECT agent_graph_id FROM chain WHERE triggered ORDER BY depth`,
+		runID,
+		maxTriggerChainDepth,
+	).Scan(&agentGraphIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load run chain: %w", runerrors.Database(err))
+	}
+	return agentGraphIDs, nil
+}
+
+// triggerSkipReason explains why a trigger must not start another run from
+// the finished run and its chain, or returns "" when it may. Executions need
+// documents, so runs without any start nothing. Chains stop at
+// maxTriggerChainDepth and never return to a workflow that already ran in them.
+func triggerSkipReason(trigger *dbmodels.WorkflowTrigger, finished finishedRun, chain []string) string {
+	if len(finished.DocumentIDs) == 0 {
+		return "no_documents"
+	}
+	if len(chain) > maxTriggerChainDepth {
+		return "max_chain_depth"
+	}
+	for _, agentGraphID := range chain {
+		if agentGraphID == trigger.TargetAgentGraphID {
+			return "loop"
+		}
+	}
+	return ""
+}
+
+// selectStateKeys copies the given keys from a run's final state. Keys missing
+// from the state are left out.
+func selectStateKeys(finalState map[string]any, keys []string) map[string]any {
+	state := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if value, ok := finalState[key]; ok {
+			state[key] = value
+		}
+	}
+	return state
+}
+
+// createTriggeredRun records the run a trigger starts for a finished run,
+// pinned to the target workflow's current graph, and returns the execution to
+// enqueue. The run ID is derived from the parent run and trigger, so firing
+// the same trigger twice reuses the same run.
+func createTriggeredRun(ctx context.Context, db *gorm.DB, finished finishedRun, trigger *dbmodels.WorkflowTrigger, chainDepth int) (*inputs.ExecuteWorkflowInput, error) {
+	var passKeys []string
+	if err := json.Unmarshal([]byte(trigger.PassKeys), &passKeys); err != nil {
+		return nil, fmt.Errorf("invalid trigger pass keys: %w", err)
+	}
+	targetID, err := uuid.Parse(trigger.TargetAgentGraphID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trigger target workflow id %q: %w", trigger.TargetAgentGraphID, err)
+	}
+	parentRunID, err := uuid.Parse(finished.RunID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent run id %q: %w", finished.RunID, err)
+	}
+	organizationID, err := uuid.Parse(finished.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid organization id %q: %w", finished.OrganizationID, err)
+	}
+	documentIDs := make([]uuid.UUID, 0, len(finished.DocumentIDs))
+	for _, rawDocumentID := range finished.DocumentIDs {
+		documentID, err := uuid.Parse(rawDocumentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid document id %q: %w", rawDocumentID, err)
+		}
+		documentIDs = append(documentIDs, documentID)
+	}
+
+	graphSnapshot, graphSnapshotHash, err := loadPinnedSnapshot(ctx, db, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	initialState := selectStateKeys(finished.FinalState, passKeys)
+	executionScope := map[string]any{"documentIds": finished.DocumentIDs}
+	storedState := make(map[string]any, len(initialState)+2)
+	for key, value := range initialState {
+		storedState[key] = value
+	}
+	storedState["project_id"] = finished.ProjectID
+	storedState["scope"] = executionScope
+	stateJSON, err := json.Marshal(storedState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode initial state: %w", err)
+	}
+	stateStr := string(stateJSON)
+
+	executionID := uuid.NewSHA1(parentRunID, []byte(trigger.ID))
+	run := &dbmodels.AgentGraphRun{
+		ID:                executionID.String(),
+		AgentGraphID:      trigger.TargetAgentGraphID,
+		ProjectID:         toOptionalString(finished.ProjectID),
+		ParentRunID:       &finished.RunID,
+		TriggerID:         &trigger.ID,
+		ChainDepth:        int32(chainDepth),
+		Status:            "running",
+		InitialState:      &stateStr,
+		GraphSnapshot:     &graphSnapshot,
+		GraphSnapshotHash: &graphSnapshotHash,
+	}
+	if err := db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to create triggered run: %w", runerrors.Database(err))
+	}
+
+	return &inputs.ExecuteWorkflowInput{
+		ExecutionID:    executionID,
+		WorkflowID:     targetID,
+		OrganizationID: organizationID,
+		Source:         inputs.JobSourceTrigger,
+		DocumentIDs:    documentIDs,
+		Scope:          executionScope,
+		InitialState:   storedState,
+	}, nil
+}
+
+func toOptionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"encoding/json"
+	"testing"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func TestTriggerConditionMatches(t *testing.T) {
+	state := map[string]any{
+		"avg_confidence": 54.5,
+		"score_text":     " 72 ",
+		"labels":         []any{"invoice", "receipt"},
+		"review":         map[string]any{"status": "needs_review"},
+		"count":          json.Number("3"),
+	}
+	for _, testCase := range []struct {
+		name      string
+		condition triggerCondition
+		expected  bool
+	}{
+		{"lt", triggerCondition{Key: "avg_confidence", Operator: "lt", Value: 60.0}, true},
+		{"gte", triggerCondition{Key: "avg_confidence", Operator: "gte", Value: 60.0}, false},
+		{"numeric string", triggerCondition{Key: "score_text", Operator: "gt", Value: "70"}, true},
+		{"json number", triggerCondition{Key: "count", Operator: "equals", Value: 3.0}, true},
+		{"nested path", triggerCondition{Key: "review.status", Operator: "equals", Value: "needs_review"}, true},
+		{"not equals", triggerCondition{Key: "review.status", Operator: "not_equals", Value: "done"}, true},
+		{"list contains", triggerCondition{Key: "labels", Operator: "contains", Value: "receipt"}, true},
+		{"exists", triggerCondition{Key: "labels", Operator: "exists"}, true},
+		{"missing key", triggerCondition{Key: "missing", Operator: "lt", Value: 60.0}, false},
+		{"missing not equals", triggerCondition{Key: "missing", Operator: "not_equals", Value: "x"}, true},
+		{"non-numeric", triggerCondition{Key: "review.status", Operator: "lt", Value: 60.0}, false},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			matched, err := testCase.condition.matches(state)
+			if err != nil {
+				t.Fatalf("matches returned error: %v", err)
+			}
+			if matched != testCase.expected {
+				t.Fatalf("matches = %t, want %t", matched, testCase.expected)
+			}
+		})
+	}
+
+	if _, err := (triggerCondition{Key: "avg_confidence", Operator: "between"}).matches(state); err == nil {
+		t.Fatal("expected unknown operator to be rejected")
+	}
+}
+
+func TestTriggerSkipReason(t *testing.T) {
+	trigger := &dbmodels.WorkflowTrigger{TargetAgentGraphID: "graph-review"}
+	finished := finishedRun{DocumentIDs: []string{"document-1"}}
+
+	if reason := triggerSkipReason(trigger, finished, []string{"graph-ocr"}); reason != "" {
+		t.Fatalf("expected trigger to fire, got %q", reason)
+	}
+	if reason := triggerSkipReason(trigger, finishedRun{}, []string{"graph-ocr"}); reason != "no_documents" {
+		t.Fatalf("expected run without documents to be skipped, got %q", reason)
+	}
+	if reason := triggerSkipReason(trigger, finished, []string{"graph-ocr", "graph-review"}); reason != "loop" {
+		t.Fatalf("expected loop to be blocked, got %q", reason)
+	}
+	chain := make([]string, maxTriggerChainDepth+1)
+	if reason := triggerSkipReason(trigger, finished, chain); reason != "max_chain_depth" {
+		t.Fatalf("expected deep chain to be blocked, got %q", reason)
+	}
+}
+
+func TestSelectStateKeysCopiesPresentKeys(t *testing.T) {
+	state := selectStateKeys(
+		map[string]any{"avg_confidence": 54.5, "ocr_text": "total 12", "documents": []any{}},
+		[]string{"avg_confidence", "ocr_text", "missing"},
+	)
+	if len(state) != 2 || state["avg_confidence"] != 54.5 || state["ocr_text"] != "total 12" {
+		t.Fatalf("unexpected initial state: %#v", state)
+	}
+}
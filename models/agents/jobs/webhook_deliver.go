@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/google/uuid"
+	"github.com/inngest/inngestgo"
+	"github.com/inngest/inngestgo/step"
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookMaxAttempts bounds how many times one delivery is POSTed before
+	// it is marked failed. Failed deliveries can still be redelivered by hand.
+	webhookMaxAttempts    = 8
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = 4 * time.Hour
+)
+
+// webhookRetries is the Inngest retry count matching webhookMaxAttempts.
+var webhookRetries = inngestgo.IntPtr(webhookMaxAttempts - 1)
+
+// webhookHTTPClient refuses to connect to internal addresses. The check runs
+// on the address actually dialed, so a public name that resolves to a private
+// address, or a redirect to one, is refused too.
+var webhookHTTPClient = &http.Client{
+	Timeout: 15 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: webhookDialControl,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+var errWebhookTargetNotAllowed = errors.New("webhook target resolves to a loopback, private, or link-local address")
+
+// webhookAllowPrivateTargets reports whether WEBHOOK_ALLOW_PRIVATE_TARGETS
+// opts in to delivering to internal addresses, such as a local test stub.
+func webhookAllowPrivateTargets() bool {
+	return strings.TrimSpace(os.Getenv("WEBHOOK_ALLOW_PRIVATE_TARGETS")) == "true"
+}
+
+// webhookDialControl rejects connections to internal addresses unless
+// WEBHOOK_ALLOW_PRIVATE_TARGETS is set.
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	if webhookAllowPrivateTargets() {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid webhook dial address %q: %w", address, err)
+	}
+	if !webhookAddrAllowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errWebhookTargetNotAllowed, addrPort.Addr())
+	}
+	return nil
+}
+
+// webhookAddrAllowed reports whether addr is a public unicast address.
+func webhookAddrAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!webhookSharedAddressSpace.Contains(addr)
+}
+
+// webhookSharedAddressSpace is the carrier-grade NAT range, which netip does
+// not count as private.
+var webhookSharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// webhookRetryDelay returns the backoff before retrying after the given
+// zero-based attempt: 30s, 2m, 8m, 32m, 2h8m, capped at webhookRetryMaxDelay.
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for range attempt {
+		delay *= 4
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// DeliverWebhook POSTs one recorded delivery to its subscriber, retrying with
+// backoff until the endpoint answers with a 2xx status or attempts run out.
+func DeliverWebhook(ctx context.Context, input inngestgo.Input[inputs.DeliverWebhookInput]) (any, error) {
+	db, ok := GetDBClient(ctx)
+	if !ok {
+		return nil, inngestgo.NoRetryError(fmt.Errorf("db not found in context"))
+	}
+	deliveryID := input.Event.Data.DeliveryID.String()
+	attempt := input.InputCtx.Attempt
+
+	statusCode, err := step.Run(ctx, "post-webhook", func(ctx context.Context) (int, error) {
+		delivery, subscription, err := loadWebhookDelivery(db, deliveryID)
+		if err != nil {
+			return 0, err
+		}
+		if delivery == nil || !subscription.Enabled {
+			slog.InfoContext(ctx, "webhook delivery skipped",
+				"delivery_id", deliveryID,
+				"reason", "deleted_or_disabled",
+			)
+			return 0, nil
+		}
+		if delivery.Status == "succeeded" {
+			return 0, nil
+		}
+
+		statusCode, postErr := postWebhook(ctx, webhookHTTPClient, subscription.URL, subscription.Secret, deliveryID, []byte(delivery.Payload), time.Now())
+		final := postErr == nil || attempt+1 >= webhookMaxAttempts || errors.Is(postErr, errWebhookTargetNotAllowed)
+		if recordErr := recordWebhookAttempt(db, deliveryID, statusCode, postErr, final); recordErr != nil {
+			slog.WarnContext(ctx, "webhook delivery attempt_not_recorded",
+				"delivery_id", deliveryID,
+				"err", recordErr,
+			)
+		}
+		if postErr == nil {
+			return statusCode, nil
+		}
+		if final {
+			slog.ErrorContext(ctx, "webhook delivery failed",
+				"delivery_id", deliveryID,
+				"attempts", attempt+1,
+				"err", postErr,
+			)
+			return statusCode, inngestgo.NoRetryError(postErr)
+		}
+		retryAt := time.Now().Add(webhookRetryDelay(attempt))
+		slog.WarnContext(ctx, "webhook delivery retry_scheduled",
+			"delivery_id", deliveryID,
+			"attempt", attempt+1,
+			"max_attempts", webhookMaxAttempts,
+			"retry_at", retryAt.Format(time.RFC3339),
+			"err", postErr,
+		)
+		return statusCode, inngestgo.RetryAtError(postErr, retryAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"delivery_id": deliveryID,
+		"status_code": statusCode,
+	}, nil
+}
+
+// enqueueWebhooksOnFinalize returns a run-finalized hook that records the
+// run's webhook deliveries and sends one delivery event for each. Event IDs
+// match delivery IDs so finalizing the same run twice sends nothing new.
+func enqueueWebhooksOnFinalize(sender EventSender) func(db *gorm.DB, runID string, status string) {
+	return func(db *gorm.DB, runID string, status string) {
+		deliveryIDs, err := enqueueRunWebhooks(db, runID)
+		if err != nil {
+			slog.Error("webhook delivery enqueue_failed", "run_id", runID, "status", status, "err", err)
+			return
+		}
+		if len(deliveryIDs) == 0 {
+			return
+		}
+
+		events := make([]any, 0, len(deliveryIDs))
+		for _, deliveryID := range deliveryIDs {
+			events = append(events, inngestgo.GenericEvent[inputs.DeliverWebhookInput]{
+				ID:   inngestgo.StrPtr(deliveryID),
+				Name: "webhook/deliver",
+				Data: inputs.DeliverWebhookInput{DeliveryID: uuid.MustParse(deliveryID)},
+			})
+		}
+		if _, err := sender.SendMany(context.Background(), events); err != nil {
+			slog.Error("webhook delivery enqueue_failed", "run_id", runID, "status", status, "err", err)
+			return
+		}
+		slog.Info("webhook delivery enqueued",
+			"run_id", runID,
+			"status", status,
+			"deliveries", len(deliveryIDs),
+		)
+	}
+}
@@ -0,0 +1,311 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	webhookPayloadVersion = 1
+	webhookEventType      = "workflow.execution.finished"
+
+	webhookIDHeader        = "X-Arcnem-Webhook-Id"
+	webhookTimestampHeader = "X-Arcnem-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Arcnem-Webhook-Signature"
+)
+
+// webhookPayload is the versioned body POSTed to webhook subscribers. Field
+// names follow the service API.
+type webhookPayload struct {
+	Version    int              `json:"version"`
+	Type       string           `json:"type"`
+	DeliveryID string           `json:"deliveryId"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	Execution  webhookExecution `json:"execution"`
+}
+
+type webhookExecution struct {
+	ExecutionID string         `json:"executionId"`
+	WorkflowID  string         `json:"workflowId"`
+	ProjectID   *string        `json:"projectId"`
+	Status      string         `json:"status"`
+	DocumentIDs []string       `json:"documentIds"`
+	FinalState  map[string]any `json:"finalState"`
+	Error       *string        `json:"error"`
+	ErrorCode   *string        `json:"errorCode"`
+	StartedAt   time.Time      `json:"startedAt"`
+	FinishedAt  *time.Time     `json:"finishedAt"`
+}
+
+// enqueueRunWebhooks records one pending delivery for every enabled
+// subscription in a finished run's project that wants its status, and returns
+// the delivery IDs. Delivery IDs are derived from the subscription and run,
+// so recording the same run twice keeps the first payload.
+func enqueueRunWebhooks(db *gorm.DB, runID string) ([]string, error) {
+	var run dbmodels.AgentGraphRun
+	if err := db.Where("id = ?", runID).Take(&run).Error; err != nil {
+		return nil, fmt.Errorf("failed to load run %s: %w", runID, runerrors.Database(err))
+	}
+	if run.ProjectID == nil {
+		return nil, nil
+	}
+
+	var subscriptions []dbmodels.WebhookSubscription
+	if err := db.
+		Where("project_id = ? AND enabled", *run.ProjectID).
+		Order("created_at, id").
+		Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscriptions: %w", runerrors.Database(err))
+	}
+	if len(subscriptions) == 0 {
+		return nil, nil
+	}
+
+	finalState := decodeRunState(run.FinalState)
+	documentIDs := runDocumentIDs(&run)
+	deliveries := make([]dbmodels.WebhookDelivery, 0, len(subscriptions))
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		var statuses, stateKeys []string
+		if err := json.Unmarshal([]byte(subscription.Statuses), &statuses); err != nil {
+			return nil, fmt.Errorf("invalid webhook subscription %s statuses: %w", subscription.ID, err)
+		}
+		if !slices.Contains(statuses, run.Status) {
+			continue
+		}
+		if subscription.APIKeyID != nil {
+			matched, err := runStartedByAPIKey(db, &run, documentIDs, *subscription.APIKeyID)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		if err := json.Unmarshal([]byte(subscription.StateKeys), &stateKeys); err != nil {
+			return nil, fmt.Errorf("invalid webhook subscription %s state keys: %w", subscription.ID, err)
+		}
+		subscriptionID, err := uuid.Parse(subscription.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook subscription id %q: %w", subscription.ID, err)
+		}
+
+		deliveryID := uuid.NewSHA1(subscriptionID, []byte(run.ID)).String()
+		payload, err := json.Marshal(buildWebhookPayload(deliveryID, &run, documentIDs, selectStateKeys(finalState, stateKeys)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+		deliveries = append(deliveries, dbmodels.WebhookDelivery{
+			ID:             deliveryID,
+			SubscriptionID: subscription.ID,
+			RunID:          run.ID,
+			Status:         "pending",
+			Payload:        string(payload),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to record webhook deliveries: %w", runerrors.Database(err))
+	}
+	deliveryIDs := make([]string, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryIDs = append(deliveryIDs, delivery.ID)
+	}
+	return deliveryIDs, nil
+}
+
+func buildWebhookPayload(deliveryID string, run *dbmodels.AgentGraphRun, documentIDs []string, finalState map[string]any) webhookPayload {
+	createdAt := time.Now().UTC()
+	if run.FinishedAt != nil {
+		createdAt = run.FinishedAt.UTC()
+	}
+	if documentIDs == nil {
+		documentIDs = []string{}
+	}
+	return webhookPayload{
+		Version:    webhookPayloadVersion,
+		Type:       webhookEventType,
+		DeliveryID: deliveryID,
+		CreatedAt:  createdAt,
+		Execution: webhookExecution{
+			ExecutionID: run.ID,
+			WorkflowID:  run.AgentGraphID,
+			ProjectID:   run.ProjectID,
+			Status:      run.Status,
+			DocumentIDs: documentIDs,
+			FinalState:  finalState,
+			Error:       run.Error,
+			ErrorCode:   run.ErrorCode,
+			StartedAt:   run.StartedAt.UTC(),
+			FinishedAt:  run.FinishedAt,
+		},
+	}
+}
+
+// runStartedByAPIKey reports whether a run belongs to an API key, either
+// because the key started it or because the key uploaded its documents.
+func runStartedByAPIKey(db *gorm.DB, run *dbmodels.AgentGraphRun, documentIDs []string, apiKeyID string) (bool, error) {
+	if run.APIKeyID != nil && *run.APIKeyID == apiKeyID {
+		return true, nil
+	}
+	if len(documentIDs) == 0 {
+		return false, nil
+	}
+	var count int64
+	if err := db.Model(&dbmodels.Document{}).
+		Where("id IN ? AND api_key_id = ?", documentIDs, apiKeyID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to match run documents to api key: %w", runerrors.Database(err))
+	}
+	return count > 0, nil
+}
+
+// runDocumentIDs reads the documents a run processed from its state. Upload
+// runs carry a single document_id; executions carry document_ids.
+func runDocumentIDs(run *dbmodels.AgentGraphRun) []string {
+	for _, state := range []map[string]any{decodeRunState(run.FinalState), decodeRunState(run.InitialState)} {
+		if ids := stateStrings(state["document_ids"]); len(ids) > 0 {
+			return ids
+		}
+		if id, ok := state["document_id"].(string); ok && id != "" {
+			return []string{id}
+		}
+		if scope, ok := state["scope"].(map[string]any); ok {
+			if ids := stateStrings(scope["documentIds"]); len(ids) > 0 {
+				return ids
+			}
+		}
+	}
+	return nil
+}
+
+func decodeRunState(raw *string) map[string]any {
+	if raw == nil {
+		return nil
+	}
+	var state map[string]any
+	if err := json.Unmarshal([]byte(*raw), &state); err != nil {
+		return nil
+	}
+	return state
+}
+
+func stateStrings(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if text, ok := item.(string); ok && text != "" {
+			values = append(values, text)
+		}
+	}
+	return values
+}
+
+// loadWebhookDelivery returns a delivery and its subscription, or nil when
+// either has been deleted.
+func loadWebhookDelivery(db *gorm.DB, deliveryID string) (*dbmodels.WebhookDelivery, *dbmodels.WebhookSubscription, error) {
+	var delivery dbmodels.WebhookDelivery
+	if err := db.Where("id = ?", deliveryID).Take(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to load webhook delivery %s: %w", deliveryID, runerrors.Database(err))
+	}
+	var subscription dbmodels.WebhookSubscription
+	if err := db.Where("id = ?", delivery.SubscriptionID).Take(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to load webhook subscription %s: %w", delivery.SubscriptionID, runerrors.Database(err))
+	}
+	return &delivery, &subscription, nil
+}
+
+// recordWebhookAttempt logs one delivery attempt. A delivery stays pending
+// while it will be retried.
+func recordWebhookAttempt(db *gorm.DB, deliveryID string, statusCode int, attemptErr error, final bool) error {
+	updates := map[string]any{
+		"attempts":         gorm.Expr("attempts + 1"),
+		"last_status_code": nil,
+		"last_error":       nil,
+		"status":           "pending",
+	}
+	if statusCode != 0 {
+		updates["last_status_code"] = statusCode
+	}
+	switch {
+	case attemptErr == nil:
+		updates["status"] = "succeeded"
+		updates["delivered_at"] = time.Now()
+	case final:
+		updates["status"] = "failed"
+		updates["last_error"] = attemptErr.Error()
+	default:
+		updates["last_error"] = attemptErr.Error()
+	}
+	if err := db.Model(&dbmodels.WebhookDelivery{}).
+		Where("id = ?", deliveryID).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record webhook delivery %s attempt: %w", deliveryID, runerrors.Database(err))
+	}
+	return nil
+}
+
+// signWebhookPayload signs "<timestamp>.<payload>" with HMAC-SHA256 so
+// subscribers can verify the body and reject replays of old deliveries.
+func signWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhook sends one signed delivery and returns the response status.
+// Anything other than a 2xx response is an error.
+func postWebhook(ctx context.Context, client *http.Client, url string, secret string, deliveryID string, payload []byte, now time.Time) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	timestamp := now.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "arcnem-vision-webhooks/1")
+	request.Header.Set(webhookIDHeader, deliveryID)
+	request.Header.Set(webhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, timestamp, payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook endpoint returned status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,155 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func TestPostWebhookSignsPayload(t *testing.T) {
+	payload := []byte(`{"version":1,"type":"workflow.execution.finished"}`)
+	now := time.Unix(1760000000, 0)
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	statusCode, err := postWebhook(context.Background(), server.Client(), server.URL, "whsec_test", "delivery-1", payload, now)
+	if err != nil {
+		t.Fatalf("postWebhook returned error: %v", err)
+	}
+	if statusCode != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", statusCode, http.StatusNoContent)
+	}
+	if string(body) != string(payload) {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if received.Header.Get(webhookIDHeader) != "delivery-1" {
+		t.Fatalf("unexpected delivery id header %q", received.Header.Get(webhookIDHeader))
+	}
+	timestamp, err := strconv.ParseInt(received.Header.Get(webhookTimestampHeader), 10, 64)
+	if err != nil || timestamp != now.Unix() {
+		t.Fatalf("unexpected timestamp header %q", received.Header.Get(webhookTimestampHeader))
+	}
+	expected := signWebhookPayload("whsec_test", timestamp, body)
+	if received.Header.Get(webhookSignatureHeader) != expected {
+		t.Fatalf("signature = %q, want %q", received.Header.Get(webhookSignatureHeader), expected)
+	}
+	if expected == signWebhookPayload("whsec_other", timestamp, body) {
+		t.Fatal("expected signature to depend on the secret")
+	}
+}
+
+func TestPostWebhookRejectsNonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	statusCode, err := postWebhook(context.Background(), server.Client(), server.URL, "whsec_test", "delivery-1", []byte(`{}`), time.Now())
+	if err == nil {
+		t.Fatal("expected non-2xx response to be an error")
+	}
+	if statusCode != http.StatusBadGateway {
+		t.Fatalf("status = %d, want %d", statusCode, http.StatusBadGateway)
+	}
+}
+
+func TestWebhookHTTPClientRefusesPrivateTargets(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err := postWebhook(context.Background(), webhookHTTPClient, server.URL, "whsec_test", "delivery-1", []byte(`{}`), time.Now())
+	if !errors.Is(err, errWebhookTargetNotAllowed) {
+		t.Fatalf("expected loopback target to be refused, got %v", err)
+	}
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "true")
+	statusCode, err := postWebhook(context.Background(), webhookHTTPClient, server.URL, "whsec_test", "delivery-1", []byte(`{}`), time.Now())
+	if err != nil || statusCode != http.StatusNoContent {
+		t.Fatalf("expected opted-in loopback target to succeed, got %d, %v", statusCode, err)
+	}
+}
+
+func TestWebhookAddrAllowed(t *testing.T) {
+	for address, allowed := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if got := webhookAddrAllowed(netip.MustParseAddr(address)); got != allowed {
+			t.Fatalf("webhookAddrAllowed(%s) = %t, want %t", address, got, allowed)
+		}
+	}
+}
+
+func TestWebhookRetryDelayBacksOffToCap(t *testing.T) {
+	for _, testCase := range []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 2 * time.Minute},
+		{2, 8 * time.Minute},
+		{5, webhookRetryMaxDelay},
+		{7, webhookRetryMaxDelay},
+	} {
+		if delay := webhookRetryDelay(testCase.attempt); delay != testCase.expected {
+			t.Fatalf("webhookRetryDelay(%d) = %s, want %s", testCase.attempt, delay, testCase.expected)
+		}
+	}
+}
+
+func TestRunDocumentIDs(t *testing.T) {
+	finalState := `{"document_ids":["doc-1","doc-2"]}`
+	uploadState := `{"document_id":"doc-3"}`
+	executionState := `{"scope":{"documentIds":["doc-4"]}}`
+
+	for _, testCase := range []struct {
+		name     string
+		run      dbmodels.AgentGraphRun
+		expected []string
+	}{
+		{"final state", dbmodels.AgentGraphRun{FinalState: &finalState, InitialState: &uploadState}, []string{"doc-1", "doc-2"}},
+		{"upload", dbmodels.AgentGraphRun{InitialState: &uploadState}, []string{"doc-3"}},
+		{"execution scope", dbmodels.AgentGraphRun{InitialState: &executionState}, []string{"doc-4"}},
+		{"no state", dbmodels.AgentGraphRun{}, nil},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			documentIDs := runDocumentIDs(&testCase.run)
+			if len(documentIDs) != len(testCase.expected) {
+				t.Fatalf("runDocumentIDs = %v, want %v", documentIDs, testCase.expected)
+			}
+			for i := range documentIDs {
+				if documentIDs[i] != testCase.expected[i] {
+					t.Fatalf("runDocumentIDs = %v, want %v", documentIDs, testCase.expected)
+				}
+			}
+		})
+	}
+}
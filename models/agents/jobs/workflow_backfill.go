@@ -0,0 +1,154 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/google/uuid"
+	"github.com/inngest/inngestgo"
+	"github.com/inngest/inngestgo/step"
+)
+
+const (
+	backfillPageInterval = time.Minute
+	// backfillPagesPerRun keeps each function run's step history bounded; a
+	// longer backfill continues in a fresh run.
+	backfillPagesPerRun = 200
+)
+
+// BackfillWorkflow pages through the documents in a backfill's scope and
+// enqueues one document upload run per document, one page per minute. It stops
+// as soon as the backfill is paused, cancelled or completed, or when a resume
+// has started a newer generation of it.
+func BackfillWorkflow(ctx context.Context, input inngestgo.Input[inputs.BackfillWorkflowInput]) (any, error) {
+	db, ok := GetDBClient(ctx)
+	if !ok {
+		return nil, inngestgo.NoRetryError(fmt.Errorf("db not found in context"))
+	}
+	backfillID := input.Event.Data.BackfillID.String()
+
+	for pageIndex := 0; pageIndex < backfillPagesPerRun; pageIndex++ {
+		page, err := step.Run(ctx, fmt.Sprintf("claim-page-%d", pageIndex), func(ctx context.Context) (backfillPage, error) {
+			return claimBackfillPage(db, backfillID, input.Event.Data.Generation, time.Now())
+		})
+		if err != nil {
+			return nil, err
+		}
+		if page.Status != "running" {
+			slog.InfoContext(ctx, "workflow backfill stopped",
+				"backfill_id", backfillID,
+				"status", page.Status,
+				"generation", input.Event.Data.Generation,
+			)
+			return map[string]any{"backfill_id": backfillID, "status": page.Status}, nil
+		}
+
+		if len(page.DocumentIDs) > 0 {
+			events, err := backfillUploadEvents(backfillID, page)
+			if err != nil {
+				return nil, inngestgo.NoRetryError(err)
+			}
+			if _, err := step.SendMany(ctx, fmt.Sprintf("enqueue-page-%d", pageIndex), events); err != nil {
+				return nil, fmt.Errorf("failed to enqueue backfill page: %w", err)
+			}
+		}
+
+		step.Sleep(ctx, fmt.Sprintf("wait-%d", pageIndex), backfillPageInterval)
+	}
+
+	if _, err := step.Send(ctx, "continue-backfill", inngestgo.GenericEvent[inputs.BackfillWorkflowInput]{
+		Name: "workflow/backfill",
+		Data: input.Event.Data,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to continue backfill: %w", err)
+	}
+	return map[string]any{"backfill_id": backfillID, "status": "running"}, nil
+}
+
+// backfillUploadEvents builds one upload event per claimed document. Event IDs
+// are derived from the backfill and document so a replayed step never runs a
+// document twice.
+func backfillUploadEvents(backfillID string, page backfillPage) ([]inngestgo.GenericEvent[inputs.ProcessDocumentUploadInput], error) {
+	parsedBackfillID, err := uuid.Parse(backfillID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backfill id %q: %w", backfillID, err)
+	}
+	agentGraphID, err := uuid.Parse(page.AgentGraphID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backfill agent graph id %q: %w", page.AgentGraphID, err)
+	}
+
+	events := make([]inngestgo.GenericEvent[inputs.ProcessDocumentUploadInput], 0, len(page.DocumentIDs))
+	for _, rawDocumentID := range page.DocumentIDs {
+		documentID, err := uuid.Parse(rawDocumentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backfill document id %q: %w", rawDocumentID, err)
+		}
+		events = append(events, inngestgo.GenericEvent[inputs.ProcessDocumentUploadInput]{
+			ID:   inngestgo.StrPtr(fmt.Sprintf("backfill-%s-%s", backfillID, rawDocumentID)),
+			Name: "document/process.upload",
+			Data: inputs.ProcessDocumentUploadInput{
+				DocumentID:     documentID,
+				AgentGraphID:   &agentGraphID,
+				BackfillID:     &parsedBackfillID,
+				OrganizationID: page.OrganizationID,
+				APIKeyID:       page.APIKeyID,
+				Source:         inputs.JobSourceBackfill,
+			},
+		})
+	}
+	return events, nil
+}
+
+// CancelBackfill marks a backfill cancelled and cancels the runs it already
+// started. Runs still queued are dropped by the job runner through the cancel event.
+func CancelBackfill(ctx context.Context, input inngestgo.Input[inputs.CancelBackfillInput]) (any, error) {
+	db, ok := GetDBClient(ctx)
+	if !ok {
+		return nil, inngestgo.NoRetryError(fmt.Errorf("db not found in context"))
+	}
+	backfillID := input.Event.Data.BackfillID.String()
+	organizationID := input.Event.Data.OrganizationID.String()
+
+	cancelled, err := setBackfillStatus(db, backfillID, organizationID, "cancelled", input.Event.Data.Reason, "running", "paused")
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		slog.InfoContext(ctx, "workflow backfill cancel_ignored",
+			"backfill_id", backfillID,
+			"reason", "not_active",
+		)
+	}
+
+	var runIDs []string
+	if err := db.Model(&dbmodels.AgentGraphRun{}).
+		Where("status = ?", "running").
+		Where("backfill_id IN (?)", db.Model(&dbmodels.WorkflowBackfill{}).
+			Select("id").
+			Where("id = ? AND organization_id = ?", backfillID, organizationID)).
+		Pluck("id", &runIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list backfill runs: %w", err)
+	}
+	cancelledRuns := 0
+	for _, runID := range runIDs {
+		runCancelled, err := graphs.CancelRun(db, runID, organizationID, input.Event.Data.Reason)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel backfill run %s: %w", runID, err)
+		}
+		if runCancelled {
+			cancelledRuns++
+		}
+	}
+
+	return map[string]any{
+		"backfill_id":    backfillID,
+		"cancelled":      cancelled,
+		"cancelled_runs": cancelledRuns,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/inngest/inngestgo"
+)
+
+// CancelWorkflow marks a running execution cancelled. The worker executing its
+// graph notices the status change and aborts in-flight model and MCP calls.
+func CancelWorkflow(ctx context.Context, input inngestgo.Input[inputs.CancelWorkflowInput]) (any, error) {
+	db, ok := GetDBClient(ctx)
+	if !ok {
+		return nil, inngestgo.NoRetryError(fmt.Errorf("db not found in context"))
+	}
+	executionID := input.Event.Data.ExecutionID.String()
+
+	cancelled, err := graphs.CancelRun(
+		db,
+		executionID,
+		input.Event.Data.OrganizationID.String(),
+		input.Event.Data.Reason,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel workflow run %s: %w", executionID, err)
+	}
+	if !cancelled {
+		slog.InfoContext(ctx, "workflow run cancel_ignored", "run_id", executionID, "reason", "not_running")
+	}
+
+	return map[string]any{
+		"execution_id": executionID,
+		"cancelled":    cancelled,
+	}, nil
+}
@@ -2,24 +2,18 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"time"
+	"log/slog"
 
-	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/load"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
 	"github.com/inngest/inngestgo"
 	"github.com/inngest/inngestgo/step"
-	"github.com/smallnest/langgraphgo/graph"
 )
 
-type preparedWorkflowState struct {
-	DocumentIDs []string         `json:"document_ids"`
-	Documents   []map[string]any `json:"documents"`
-}
-
 func ExecuteWorkflow(ctx context.Context, input inngestgo.Input[inputs.ExecuteWorkflowInput]) (result any, runErr error) {
 	db, ok := GetDBClient(ctx)
 	if !ok {
@@ -27,14 +21,27 @@ func ExecuteWorkflow(ctx context.Context, input inngestgo.Input[inputs.ExecuteWo
 	}
 	executionID := input.Event.Data.ExecutionID.String()
 	organizationID := input.Event.Data.OrganizationID.String()
+	ctx = logging.WithOrganizationID(logging.WithRunID(ctx, executionID), organizationID)
+	attempt := input.InputCtx.Attempt
 	finalizeFailure := func(runErr error) {
 		_, err := graphs.FinalizeRun(db, executionID, organizationID, "failed", nil, runErr)
 		if err != nil {
-			log.Printf("workflow run finalization failed run_id=%s err=%v", executionID, err)
+			slog.ErrorContext(ctx, "workflow run finalization failed", "run_id", executionID, "err", err)
 		}
 	}
+	failStep := func(step string, err error) error {
+		if graphs.IsRunInterrupted(ctx) {
+			return err
+		}
+		stepErr, retrying := recordStepFailure(db, executionID, step, attempt, err)
+		if !retrying {
+			finalizeFailure(err)
+		}
+		return stepErr
+	}
 	defer func() {
-		if runErr != nil {
+		// Interrupted runs stay running for the job's next attempt.
+		if runErr != nil && !graphs.IsRunInterrupted(ctx) {
 			finalizeFailure(runErr)
 		}
 	}()
@@ -55,9 +62,10 @@ func ExecuteWorkflow(ctx context.Context, input inngestgo.Input[inputs.ExecuteWo
 			input.Event.Data.ExecutionID,
 		)
 		if err != nil {
-			err = fmt.Errorf("failed to load workflow execution payload: %w", err)
-			finalizeFailure(err)
-			return nil, inngestgo.NoRetryError(err)
+			return nil, failStep(
+				"load-documents-and-agent-graph",
+				fmt.Errorf("failed to load workflow execution payload: %w", err),
+			)
 		}
 		return payload, nil
 	})
@@ -76,46 +84,11 @@ func ExecuteWorkflow(ctx context.Context, input inngestgo.Input[inputs.ExecuteWo
 	}
 
 	preparedState, err := step.Run(ctx, "prepare-runtime-state", func(ctx context.Context) (*preparedWorkflowState, error) {
-		documentIDs := make([]string, 0, len(payload.Documents))
-		documents := make([]map[string]any, 0, len(payload.Documents))
-
-		for _, document := range payload.Documents {
-			tempURL, err := s3Client.PresignDownload(
-				ctx,
-				document.Bucket,
-				document.ObjectKey,
-				15*time.Minute,
-			)
-			if err != nil {
-				err = fmt.Errorf(
-					"failed to produce temp url for document %s: %w",
-					document.ID,
-					err,
-				)
-				finalizeFailure(err)
-				return nil, inngestgo.NoRetryError(err)
-			}
-
-			documentIDs = append(documentIDs, document.ID)
-			documents = append(documents, map[string]any{
-				"id":              document.ID,
-				"bucket":          document.Bucket,
-				"object_key":      document.ObjectKey,
-				"content_type":    document.ContentType,
-				"size_bytes":      document.SizeBytes,
-				"visibility":      document.Visibility,
-				"organization_id": document.OrganizationID,
-				"project_id":      document.ProjectID,
-				"api_key_id":      document.APIKeyID,
-				"created_at":      document.CreatedAt.Format(time.RFC3339),
-				"temp_url":        tempURL,
-			})
+		preparedState, err := prepareWorkflowDocuments(ctx, s3Client, payload.Documents)
+		if err != nil {
+			return nil, failStep("prepare-runtime-state", err)
 		}
-
-		return &preparedWorkflowState{
-			DocumentIDs: documentIDs,
-			Documents:   documents,
-		}, nil
+		return preparedState, nil
 	})
 	if err != nil {
 		return nil, err
@@ -124,26 +97,43 @@ func ExecuteWorkflow(ctx context.Context, input inngestgo.Input[inputs.ExecuteWo
 		return nil, inngestgo.NoRetryError(fmt.Errorf("prepared workflow state was nil"))
 	}
 
+	run := workflowRun{
+		RunID:                  executionID,
+		Snapshot:               payload.GraphSnapshot,
+		OrganizationID:         organizationID,
+		ProjectID:              payload.Documents[0].ProjectID,
+		State:                  preparedState,
+		RetryTransientFailures: canRetryRun(attempt),
+	}
 	graphResult, err := step.Run(ctx, "run-graph", func(ctx context.Context) (graphState map[string]any, graphErr error) {
 		defer func() {
-			status := "failed"
-			if graphErr == nil {
-				status = "completed"
+			if errors.Is(graphErr, graphs.ErrRunCancelled) {
+				slog.InfoContext(ctx, "workflow run stopped", "run_id", executionID, "status", "cancelled")
+				graphState, graphErr = nil, nil
+				return
 			}
-			_, finalizeErr := graphs.FinalizeRun(
-				db,
-				executionID,
-				organizationID,
-				status,
-				graphState,
-				graphErr,
-			)
+			if errors.Is(graphErr, graphs.ErrRunInterrupted) {
+				slog.WarnContext(ctx, "workflow run interrupted", "run_id", executionID)
+				graphState = nil
+				return
+			}
+			if graphErr != nil {
+				stepErr, retrying := recordStepFailure(db, executionID, "run-graph", attempt, graphErr)
+				if retrying {
+					graphState, graphErr = nil, stepErr
+					return
+				}
+			}
+			_, finalizeErr := finishWorkflowRun(ctx, db, run, graphState, graphErr)
 			if finalizeErr != nil {
 				if graphErr == nil {
 					graphState = nil
 					graphErr = fmt.Errorf("failed to finalize workflow run: %w", finalizeErr)
 				} else {
-					log.Printf("workflow run finalization failed run_id=%s err=%v", executionID, finalizeErr)
+					slog.ErrorContext(ctx, "workflow run finalization failed",
+						"run_id", executionID,
+						"err", finalizeErr,
+					)
 				}
 			}
 			if graphErr != nil {
@@ -151,57 +141,11 @@ func ExecuteWorkflow(ctx context.Context, input inngestgo.Input[inputs.ExecuteWo
 			}
 		}()
 
-		initialState := make(map[string]any, len(input.Event.Data.InitialState)+4)
-		for key, value := range input.Event.Data.InitialState {
-			initialState[key] = value
-		}
-
-		initialState["document_ids"] = preparedState.DocumentIDs
-		initialState["documents"] = preparedState.Documents
-		if input.Event.Data.Scope != nil {
-			initialState["scope"] = input.Event.Data.Scope
-		}
-		if len(preparedState.Documents) == 1 {
-			initialState["document_id"] = preparedState.DocumentIDs[0]
-			initialState["temp_url"] = preparedState.Documents[0]["temp_url"]
-		}
-
-		projectID := payload.Documents[0].ProjectID
-		for _, document := range payload.Documents[1:] {
-			if document.ProjectID != projectID {
-				return nil, fmt.Errorf(
-					"workflow execution %s spans multiple projects",
-					input.Event.Data.ExecutionID.String(),
-				)
-			}
-		}
-
-		tracker, err := graphs.NewRunTrackerWithOptions(
-			db,
-			payload.GraphSnapshot.AgentGraph.ID,
-			payload.GraphSnapshot.AgentGraph.OrganizationID,
-			initialState,
-			graphs.RunTrackerOptions{
-				RunID:     input.Event.Data.ExecutionID.String(),
-				ProjectID: projectID,
-			},
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create run tracker: %w", err)
+		initialState := workflowInitialState(input.Event.Data.InitialState, input.Event.Data.Scope, preparedState)
+		if _, err := workflowProjectID(executionID, payload.Documents); err != nil {
+			return nil, err
 		}
-
-		builtGraph, err := graphs.BuildGraph(payload.GraphSnapshot, mcpClient)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build graph: %w", err)
-		}
-		if builtGraph == nil {
-			return nil, fmt.Errorf("built graph is nil")
-		}
-
-		tracer := graph.NewTracer()
-		tracer.AddHook(tracker)
-		builtGraph.SetTracer(tracer)
-		return builtGraph.Invoke(clients.ContextWithExecutionID(ctx, tracker.RunID()), initialState)
+		return runWorkflowGraph(ctx, db, mcpClient, run, initialState)
 	})
 	if err != nil {
 		return nil, err
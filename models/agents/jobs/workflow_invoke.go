@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/load"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxInvokeDocuments matches the document cap of API-started executions.
+const maxInvokeDocuments = 500
+
+// ErrInvalidInvocation is returned when an invocation request cannot run.
+var ErrInvalidInvocation = errors.New("invalid workflow invocation")
+
+// InvokeWorkflowRequest is the body of a synchronous workflow invocation.
+// Field names follow the service API's execution request.
+type InvokeWorkflowRequest struct {
+	DocumentIDs  []uuid.UUID    `json:"documentIds"`
+	InitialState map[string]any `json:"initialState"`
+}
+
+// InvokeWorkflowResult is a run that finished while the caller waited.
+type InvokeWorkflowResult struct {
+	RunID      string          `json:"runId"`
+	Status     string          `json:"status"`
+	FinalState json.RawMessage `json:"finalState"`
+	Error      *string         `json:"error"`
+	ErrorCode  *string         `json:"errorCode"`
+	DurationMS int64           `json:"durationMs"`
+}
+
+// InvokeWorkflow runs a workflow's current graph inline and waits for it to
+// finish. The run is recorded, pinned, and finalized like a queued execution,
+// but transient failures are not retried. ctx bounds how long the graph runs.
+// sender starts the workflows chained to the run when it finishes.
+func InvokeWorkflow(
+	ctx context.Context,
+	db *gorm.DB,
+	s3Client *clients.S3Client,
+	mcpClient *clients.MCPClient,
+	sender EventSender,
+	workflowID uuid.UUID,
+	request InvokeWorkflowRequest,
+) (*InvokeWorkflowResult, error) {
+	if len(request.DocumentIDs) > maxInvokeDocuments {
+		return nil, fmt.Errorf("%w: at most %d documents can be invoked at once", ErrInvalidInvocation, maxInvokeDocuments)
+	}
+	startedAt := time.Now()
+
+	snapshot, err := load.LoadAgentGraphSnapshot(ctx, db, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow %s: %w", workflowID, err)
+	}
+	organizationID := snapshot.AgentGraph.OrganizationID
+	runID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate run id: %w", err)
+	}
+
+	var documents []*dbmodels.Document
+	if len(request.DocumentIDs) > 0 {
+		documents, err = load.LoadWorkflowDocuments(ctx, db, request.DocumentIDs, organizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load documents: %w", err)
+		}
+	}
+	projectID, err := workflowProjectID(runID.String(), documents)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInvocation, err)
+	}
+	preparedState, err := prepareWorkflowDocuments(ctx, s3Client, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := make(map[string]any, len(request.InitialState)+2)
+	for key, value := range request.InitialState {
+		seed[key] = value
+	}
+	var executionScope map[string]any
+	if len(documents) > 0 {
+		executionScope = map[string]any{"documentIds": preparedState.DocumentIDs}
+		seed["project_id"] = projectID
+		seed["scope"] = executionScope
+	}
+	stateJSON, err := json.Marshal(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode initial state: %w", err)
+	}
+	stateStr := string(stateJSON)
+	graphSnapshot, graphSnapshotHash, err := pinGraphSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &dbmodels.AgentGraphRun{
+		ID:                runID.String(),
+		AgentGraphID:      snapshot.AgentGraph.ID,
+		ProjectID:         toOptionalString(projectID),
+		Status:            "running",
+		InitialState:      &stateStr,
+		GraphSnapshot:     &graphSnapshot,
+		GraphSnapshotHash: &graphSnapshotHash,
+	}
+	if err := db.WithContext(ctx).Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invoked run: %w", runerrors.Database(err))
+	}
+
+	ctx = logging.WithOrganizationID(logging.WithRunID(withEventSender(ctx, sender), record.ID), organizationID)
+	run := workflowRun{
+		RunID:          record.ID,
+		Snapshot:       snapshot,
+		OrganizationID: organizationID,
+		ProjectID:      projectID,
+		State:          preparedState,
+	}
+	graphState, graphErr := runWorkflowGraph(ctx, db, mcpClient, run, workflowInitialState(seed, executionScope, preparedState))
+	// The invocation timeout has often expired by now, but the run must still
+	// be finalized and its triggers fired.
+	finishCtx := context.WithoutCancel(ctx)
+	if errors.Is(graphErr, graphs.ErrRunCancelled) {
+		slog.InfoContext(ctx, "workflow run stopped", "run_id", run.RunID, "status", "cancelled")
+	} else if _, err := finishWorkflowRun(finishCtx, db, run, graphState, graphErr); err != nil {
+		if graphErr != nil {
+			slog.ErrorContext(finishCtx, "workflow run finalization failed", "run_id", run.RunID, "err", err)
+		}
+		return nil, fmt.Errorf("failed to finalize workflow run: %w", err)
+	}
+
+	// Read the outcome back so failures finalized by the tracker report the
+	// error that was recorded. ctx is not used because it may have expired.
+	var finished dbmodels.AgentGraphRun
+	if err := db.Select("id, status, final_state, error, error_code").
+		Where("id = ?", run.RunID).
+		Take(&finished).Error; err != nil {
+		return nil, fmt.Errorf("failed to load invoked run: %w", runerrors.Database(err))
+	}
+	finalState := json.RawMessage("null")
+	if finished.FinalState != nil {
+		finalState = json.RawMessage(*finished.FinalState)
+	}
+	return &InvokeWorkflowResult{
+		RunID:      finished.ID,
+		Status:     finished.Status,
+		FinalState: finalState,
+		Error:      finished.Error,
+		ErrorCode:  finished.ErrorCode,
+		DurationMS: time.Since(startedAt).Milliseconds(),
+	}, nil
+}
@@ -0,0 +1,189 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/smallnest/langgraphgo/graph"
+	"gorm.io/gorm"
+)
+
+// The helpers below are shared by ExecuteWorkflow and InvokeWorkflow so a
+// workflow behaves the same whether it is queued or invoked inline.
+
+type preparedWorkflowState struct {
+	DocumentIDs []string         `json:"document_ids"`
+	Documents   []map[string]any `json:"documents"`
+}
+
+// workflowRun is one run of a workflow graph over prepared documents.
+type workflowRun struct {
+	RunID          string
+	Snapshot       *graphs.Snapshot
+	OrganizationID string
+	ProjectID      string
+	State          *preparedWorkflowState
+	// RetryTransientFailures leaves the run running after a transient node
+	// failure so a queued job can retry it.
+	RetryTransientFailures bool
+}
+
+// prepareWorkflowDocuments presigns a download URL for every document and
+// returns the documents as the graph sees them.
+func prepareWorkflowDocuments(ctx context.Context, s3Client *clients.S3Client, documents []*dbmodels.Document) (*preparedWorkflowState, error) {
+	documentIDs := make([]string, 0, len(documents))
+	prepared := make([]map[string]any, 0, len(documents))
+
+	for _, document := range documents {
+		tempURL, err := s3Client.PresignDownload(
+			ctx,
+			document.Bucket,
+			document.ObjectKey,
+			15*time.Minute,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to produce temp url for document %s: %w",
+				document.ID,
+				runerrors.Transient(err),
+			)
+		}
+
+		documentIDs = append(documentIDs, document.ID)
+		prepared = append(prepared, map[string]any{
+			"id":              document.ID,
+			"bucket":          document.Bucket,
+			"object_key":      document.ObjectKey,
+			"content_type":    document.ContentType,
+			"size_bytes":      document.SizeBytes,
+			"visibility":      document.Visibility,
+			"organization_id": document.OrganizationID,
+			"project_id":      document.ProjectID,
+			"api_key_id":      document.APIKeyID,
+			"created_at":      document.CreatedAt.Format(time.RFC3339),
+			"temp_url":        tempURL,
+		})
+	}
+
+	return &preparedWorkflowState{
+		DocumentIDs: documentIDs,
+		Documents:   prepared,
+	}, nil
+}
+
+// workflowInitialState seeds a graph's state with the caller's initial state,
+// the execution scope, and the prepared documents. A single document is also
+// exposed as document_id and temp_url for graphs written for uploads.
+func workflowInitialState(seed map[string]any, scope map[string]any, prepared *preparedWorkflowState) map[string]any {
+	initialState := make(map[string]any, len(seed)+4)
+	for key, value := range seed {
+		initialState[key] = value
+	}
+
+	initialState["document_ids"] = prepared.DocumentIDs
+	initialState["documents"] = prepared.Documents
+	if scope != nil {
+		initialState["scope"] = scope
+	}
+	if len(prepared.Documents) == 1 {
+		initialState["document_id"] = prepared.DocumentIDs[0]
+		initialState["temp_url"] = prepared.Documents[0]["temp_url"]
+	}
+	return initialState
+}
+
+// workflowProjectID returns the project shared by every document, or an
+// error when they span several projects.
+func workflowProjectID(runID string, documents []*dbmodels.Document) (string, error) {
+	if len(documents) == 0 {
+		return "", nil
+	}
+	projectID := documents[0].ProjectID
+	for _, document := range documents[1:] {
+		if document.ProjectID != projectID {
+			return "", fmt.Errorf("workflow execution %s spans multiple projects", runID)
+		}
+	}
+	return projectID, nil
+}
+
+// runWorkflowGraph builds the run's graph and invokes it with initialState,
+// recording steps on the run. It returns graphs.ErrRunCancelled when the run
+// was cancelled while the graph ran, and graphs.ErrRunInterrupted when the
+// worker stopped it during shutdown.
+func runWorkflowGraph(ctx context.Context, db *gorm.DB, mcpClient *clients.MCPClient, run workflowRun, initialState map[string]any) (map[string]any, error) {
+	tracker, err := graphs.NewRunTrackerWithOptions(
+		db,
+		run.Snapshot.AgentGraph.ID,
+		run.Snapshot.AgentGraph.OrganizationID,
+		initialState,
+		graphs.RunTrackerOptions{
+			RunID:                  run.RunID,
+			ProjectID:              run.ProjectID,
+			RetryTransientFailures: run.RetryTransientFailures,
+			NodeTypes:              graphs.NodeTypes(run.Snapshot),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run tracker: %w", err)
+	}
+
+	builtGraph, err := graphs.BuildGraph(run.Snapshot, mcpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graph: %w", errorcodes.Wrap(errorcodes.GraphInvalid, err))
+	}
+	if builtGraph == nil {
+		return nil, fmt.Errorf("built graph is nil")
+	}
+
+	tracer := graph.NewTracer()
+	tracer.AddHook(tracker)
+	builtGraph.SetTracer(tracer)
+	runCtx, stopCancellation := graphs.WithRunCancellation(ctx, db, tracker.RunID())
+	defer stopCancellation()
+	spanCtx, endRunSpan := graphs.StartRunSpan(runCtx, tracker.RunID(), run.Snapshot.AgentGraph.ID)
+	graphState, graphErr := builtGraph.Invoke(clients.ContextWithExecutionID(spanCtx, tracker.RunID()), initialState)
+	endRunSpan(graphErr)
+	if graphErr != nil && graphs.IsRunCancelled(runCtx) {
+		return nil, graphs.ErrRunCancelled
+	}
+	if graphErr != nil && graphs.IsRunInterrupted(runCtx) {
+		return nil, graphs.ErrRunInterrupted
+	}
+	return graphState, graphErr
+}
+
+// finishWorkflowRun finalizes a run whose graph returned and fires the
+// triggers chained to it. It returns the terminal status.
+func finishWorkflowRun(ctx context.Context, db *gorm.DB, run workflowRun, graphState map[string]any, graphErr error) (string, error) {
+	status := "failed"
+	if graphErr == nil {
+		status = "completed"
+	}
+	finalized, err := graphs.FinalizeRun(
+		db,
+		run.RunID,
+		run.OrganizationID,
+		status,
+		graphState,
+		graphErr,
+	)
+	if finalized {
+		fireRunTriggers(ctx, db, finishedRun{
+			RunID:          run.RunID,
+			AgentGraphID:   run.Snapshot.AgentGraph.ID,
+			OrganizationID: run.OrganizationID,
+			ProjectID:      run.ProjectID,
+			Status:         status,
+			FinalState:     graphState,
+			DocumentIDs:    run.State.DocumentIDs,
+		})
+	}
+	return status, err
+}
@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"testing"
+
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func TestWorkflowInitialStateExposesSingleDocument(t *testing.T) {
+	prepared := &preparedWorkflowState{
+		DocumentIDs: []string{"document-1"},
+		Documents:   []map[string]any{{"id": "document-1", "temp_url": "https://example.test/document-1"}},
+	}
+	seed := map[string]any{"label": "nightly", "document_id": "stale"}
+
+	state := workflowInitialState(seed, map[string]any{"documentIds": []string{"document-1"}}, prepared)
+
+	if state["label"] != "nightly" || state["scope"] == nil {
+		t.Fatalf("expected the seed and scope to be kept, got %v", state)
+	}
+	if state["document_id"] != "document-1" || state["temp_url"] != "https://example.test/document-1" {
+		t.Fatalf("expected the single document to be exposed, got %v", state)
+	}
+	if seed["document_id"] != "stale" {
+		t.Fatal("expected the seed to be left untouched")
+	}
+}
+
+func TestWorkflowInitialStateWithoutDocuments(t *testing.T) {
+	state := workflowInitialState(nil, nil, &preparedWorkflowState{})
+
+	if _, ok := state["document_id"]; ok {
+		t.Fatalf("expected no single document keys, got %v", state)
+	}
+	if _, ok := state["scope"]; ok {
+		t.Fatalf("expected no scope, got %v", state)
+	}
+}
+
+func TestWorkflowProjectIDRejectsMixedProjects(t *testing.T) {
+	projectID, err := workflowProjectID("run-1", []*dbmodels.Document{{ProjectID: "project-1"}, {ProjectID: "project-1"}})
+	if err != nil || projectID != "project-1" {
+		t.Fatalf("expected project-1, got %q, %v", projectID, err)
+	}
+	if _, err := workflowProjectID("run-1", []*dbmodels.Document{{ProjectID: "project-1"}, {ProjectID: "project-2"}}); err == nil {
+		t.Fatal("expected documents from different projects to be rejected")
+	}
+	if projectID, err := workflowProjectID("run-1", nil); err != nil || projectID != "" {
+		t.Fatalf("expected no project without documents, got %q, %v", projectID, err)
+	}
+}
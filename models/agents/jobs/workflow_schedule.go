@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	"github.com/google/uuid"
+	"github.com/inngest/inngestgo"
+	"github.com/inngest/inngestgo/step"
+)
+
+// workflowScheduleTick is how often due schedules are checked. Schedules are
+// stored in the database, so one cron function fires all of them.
+const workflowScheduleTick = "* * * * *"
+
+// TickWorkflowSchedules claims every schedule that has come due and sends one
+// fire event per schedule.
+func TickWorkflowSchedules(ctx context.Context, input inngestgo.Input[map[string]any]) (any, error) {
+	db, ok := GetDBClient(ctx)
+	if !ok {
+		return nil, inngestgo.NoRetryError(fmt.Errorf("db not found in context"))
+	}
+
+	firings, err := step.Run(ctx, "claim-due-schedules", func(ctx context.Context) ([]scheduleFiring, error) {
+		return claimDueSchedules(db, time.Now().UTC())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(firings) == 0 {
+		return map[string]any{"fired": 0}, nil
+	}
+
+	events, err := scheduleFireEvents(firings)
+	if err != nil {
+		return nil, inngestgo.NoRetryError(err)
+	}
+	if _, err := step.SendMany(ctx, "fire-schedules", events); err != nil {
+		return nil, fmt.Errorf("failed to fire schedules: %w", err)
+	}
+	return map[string]any{"fired": len(firings)}, nil
+}
+
+// scheduleFireEvents builds one fire event per firing. Event IDs are derived
+// from the schedule and firing time so a replayed tick never fires twice.
+func scheduleFireEvents(firings []scheduleFiring) ([]inngestgo.GenericEvent[inputs.FireScheduleInput], error) {
+	events := make([]inngestgo.GenericEvent[inputs.FireScheduleInput], 0, len(firings))
+	for _, firing := range firings {
+		scheduleID, err := uuid.Parse(firing.ScheduleID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule id %q: %w", firing.ScheduleID, err)
+		}
+		organizationID, err := uuid.Parse(firing.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule organization id %q: %w", firing.OrganizationID, err)
+		}
+		events = append(events, inngestgo.GenericEvent[inputs.FireScheduleInput]{
+			ID:   inngestgo.StrPtr(fmt.Sprintf("schedule-%s-%d", firing.ScheduleID, firing.FiredAt.Unix())),
+			Name: "workflow/schedule.fire",
+			Data: inputs.FireScheduleInput{
+				ScheduleID:     scheduleID,
+				OrganizationID: organizationID,
+				FiredAt:        firing.FiredAt,
+			},
+		})
+	}
+	return events, nil
+}
+
+// FireWorkflowSchedule creates the run for one schedule firing and queues it
+// as a normal workflow execution.
+func FireWorkflowSchedule(ctx context.Context, input inngestgo.Input[inputs.FireScheduleInput]) (any, error) {
+	db, ok := GetDBClient(ctx)
+	if !ok {
+		return nil, inngestgo.NoRetryError(fmt.Errorf("db not found in context"))
+	}
+	scheduleID := input.Event.Data.ScheduleID.String()
+
+	execution, err := step.Run(ctx, "create-scheduled-run", func(ctx context.Context) (*inputs.ExecuteWorkflowInput, error) {
+		execution, err := createScheduledRun(ctx, db, input.Event.Data)
+		if recordErr := recordScheduleError(db, scheduleID, err); recordErr != nil {
+			slog.WarnContext(ctx, "workflow schedule outcome_not_recorded",
+				"schedule_id", scheduleID,
+				"err", recordErr,
+			)
+		}
+		return execution, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if execution == nil {
+		slog.InfoContext(ctx, "workflow schedule skipped",
+			"schedule_id", scheduleID,
+			"reason", "no_documents_or_disabled",
+		)
+		return map[string]any{"schedule_id": scheduleID, "execution_id": nil}, nil
+	}
+
+	if _, err := step.Send(ctx, "execute-workflow", inngestgo.GenericEvent[inputs.ExecuteWorkflowInput]{
+		ID:   inngestgo.StrPtr(execution.ExecutionID.String()),
+		Name: "workflow/execute",
+		Data: *execution,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to enqueue scheduled run: %w", err)
+	}
+	slog.InfoContext(ctx, "workflow schedule fired",
+		"schedule_id", scheduleID,
+		"run_id", execution.ExecutionID,
+		"documents", len(execution.DocumentIDs),
+	)
+
+	return map[string]any{
+		"schedule_id":  scheduleID,
+		"execution_id": execution.ExecutionID.String(),
+	}, nil
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/inputs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/inngest/inngestgo"
+	"gorm.io/gorm"
+)
+
+// fireRunTriggers starts the workflows chained to a run that has just been
+// finalized. Failures are logged rather than returned so a trigger can never
+// change the outcome of the run that fired it.
+func fireRunTriggers(ctx context.Context, db *gorm.DB, finished finishedRun) {
+	if finished.Status != "completed" && finished.Status != "failed" {
+		return
+	}
+	triggers, err := loadRunTriggers(db, finished)
+	if err != nil {
+		slog.ErrorContext(ctx, "workflow trigger lookup_failed", "run_id", finished.RunID, "err", err)
+		return
+	}
+	if len(triggers) == 0 {
+		return
+	}
+	sender, ok := GetEventSender(ctx)
+	if !ok {
+		slog.InfoContext(ctx, "workflow trigger skipped",
+			"run_id", finished.RunID,
+			"reason", "no_event_sender",
+		)
+		return
+	}
+	chain, err := runChain(db, finished.RunID)
+	if err != nil {
+		slog.ErrorContext(ctx, "workflow trigger lookup_failed", "run_id", finished.RunID, "err", err)
+		return
+	}
+
+	for i := range triggers {
+		trigger := &triggers[i]
+		if err := fireRunTrigger(ctx, db, sender, finished, trigger, chain); err != nil {
+			slog.ErrorContext(ctx, "workflow trigger failed",
+				"run_id", finished.RunID,
+				"trigger_id", trigger.ID,
+				"err", err,
+			)
+		}
+	}
+}
+
+func fireRunTrigger(
+	ctx context.Context,
+	db *gorm.DB,
+	sender EventSender,
+	finished finishedRun,
+	trigger *dbmodels.WorkflowTrigger,
+	chain []string,
+) error {
+	if trigger.Condition != nil {
+		var condition triggerCondition
+		if err := json.Unmarshal([]byte(*trigger.Condition), &condition); err != nil {
+			return fmt.Errorf("invalid trigger condition: %w", err)
+		}
+		matched, err := condition.matches(finished.FinalState)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return nil
+		}
+	}
+	if reason := triggerSkipReason(trigger, finished, chain); reason != "" {
+		slog.InfoContext(ctx, "workflow trigger skipped",
+			"run_id", finished.RunID,
+			"trigger_id", trigger.ID,
+			"reason", reason,
+		)
+		return nil
+	}
+
+	execution, err := createTriggeredRun(ctx, db, finished, trigger, len(chain))
+	if err != nil {
+		return err
+	}
+	if _, err := sender.Send(ctx, inngestgo.GenericEvent[inputs.ExecuteWorkflowInput]{
+		ID:   inngestgo.StrPtr(execution.ExecutionID.String()),
+		Name: "workflow/execute",
+		Data: *execution,
+	}); err != nil {
+		// Nothing would ever pick the child run up, so fail it instead of
+		// leaving it running.
+		sendErr := fmt.Errorf("failed to enqueue triggered run: %w", err)
+		if _, finalizeErr := graphs.FinalizeRun(db, execution.ExecutionID.String(), finished.OrganizationID, "failed", nil, sendErr); finalizeErr != nil {
+			slog.ErrorContext(ctx, "graph run finalization failed", "run_id", execution.ExecutionID, "err", finalizeErr)
+		}
+		return sendErr
+	}
+	slog.InfoContext(ctx, "workflow trigger fired",
+		"run_id", finished.RunID,
+		"trigger_id", trigger.ID,
+		"child_run_id", execution.ExecutionID,
+	)
+	return nil
+}
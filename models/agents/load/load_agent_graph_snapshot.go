@@ -6,8 +6,10 @@ import (
 	"fmt"
 
 	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/utils"
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -24,10 +26,10 @@ func LoadAgentGraphSnapshot(ctx context.Context, db *gorm.DB, agentGraphID uuid.
 		Raw(loadAgentGraphSnapshotQuery(), agentGraphID).
 		Scan(&row)
 	if tx.Error != nil {
-		return nil, tx.Error
+		return nil, runerrors.Database(tx.Error)
 	}
 	if tx.RowsAffected == 0 {
-		return nil, fmt.Errorf("workflow %s not found", agentGraphID)
+		return nil, errorcodes.Wrap(errorcodes.NotFound, fmt.Errorf("workflow %s not found", agentGraphID))
 	}
 
 	agentGraph, err := decodeOptionalAgentGraphPayload(row.AgentGraphJSON)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -29,13 +31,13 @@ func queryDocumentAndAgentGraphRow(
 		Raw(loadDocumentAndAgentGraphQuery(querySpec.graphJoin), querySpec.args...).
 		Scan(&row)
 	if tx.Error != nil {
-		return row, tx.Error
+		return row, runerrors.Database(tx.Error)
 	}
 	if tx.RowsAffected == 0 {
-		return row, fmt.Errorf(
+		return row, errorcodes.Wrap(errorcodes.NotFound, fmt.Errorf(
 			"document %s not found or has no related workflow key",
 			documentID,
-		)
+		))
 	}
 
 	return row, nil
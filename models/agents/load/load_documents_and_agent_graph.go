@@ -6,7 +6,9 @@ import (
 	"fmt"
 
 	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -28,7 +30,7 @@ func loadPinnedWorkflowSnapshot(ctx context.Context, db *gorm.DB, executionID uu
 		Select("graph_snapshot, graph_snapshot_hash").
 		Where("id = ?", executionID).
 		Take(&row).Error; err != nil {
-		return nil, fmt.Errorf("load execution %s snapshot: %w", executionID, err)
+		return nil, fmt.Errorf("load execution %s snapshot: %w", executionID, runerrors.Database(err))
 	}
 	return decodePinnedWorkflowSnapshotRow(executionID, row)
 }
@@ -67,7 +69,7 @@ func orderWorkflowDocuments(documentIDs []uuid.UUID, rows []*dbmodels.Document,
 	for _, documentID := range documentIDs {
 		document := byID[documentID.String()]
 		if document == nil {
-			return nil, fmt.Errorf("document %s not found", documentID)
+			return nil, errorcodes.Wrap(errorcodes.NotFound, fmt.Errorf("document %s not found", documentID))
 		}
 		ordered = append(ordered, document)
 	}
@@ -85,25 +87,31 @@ func LoadWorkflowExecutionPayload(ctx context.Context, db *gorm.DB, documentIDs
 		return nil, fmt.Errorf("workflow execution requires at least one document")
 	}
 
-	var rows []*dbmodels.Document
-	if err := db.WithContext(ctx).
-		Where("id IN ?", documentIDs).
-		Find(&rows).Error; err != nil {
-		return nil, err
-	}
-
 	organizationID := ""
 	if graphSnapshot.AgentGraph != nil {
 		organizationID = graphSnapshot.AgentGraph.OrganizationID
 	}
 
-	ordered, err := orderWorkflowDocuments(documentIDs, rows, organizationID)
+	documents, err := LoadWorkflowDocuments(ctx, db, documentIDs, organizationID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &WorkflowExecutionPayload{
-		Documents:     ordered,
+		Documents:     documents,
 		GraphSnapshot: graphSnapshot,
 	}, nil
 }
+
+// LoadWorkflowDocuments loads documents in the requested order. Every document
+// must exist and, when organizationID is set, belong to that organization.
+func LoadWorkflowDocuments(ctx context.Context, db *gorm.DB, documentIDs []uuid.UUID, organizationID string) ([]*dbmodels.Document, error) {
+	var rows []*dbmodels.Document
+	if err := db.WithContext(ctx).
+		Where("id IN ?", documentIDs).
+		Find(&rows).Error; err != nil {
+		return nil, runerrors.Database(err)
+	}
+
+	return orderWorkflowDocuments(documentIDs, rows, organizationID)
+}
@@ -0,0 +1,73 @@
+// Package runerrors classifies workflow run failures as transient, worth
+// retrying later, or permanent.
+package runerrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"gorm.io/gorm"
+)
+
+// TransientError marks a failure that is expected to clear on its own, such
+// as a provider outage, a refused connection, or an unavailable database.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Transient always reports true; see IsTransient.
+func (e *TransientError) Transient() bool {
+	return true
+}
+
+// Transient marks err as transient. It returns nil for a nil error.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// Database marks a database error transient unless it reports a missing row,
+// which retrying will not fix.
+func Database(err error) error {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return Transient(err)
+}
+
+// IsTransient reports whether a run that failed with err should be retried.
+// Errors can decide for themselves by implementing Transient() bool; the
+// outermost such error in the chain wins. Otherwise timeouts and network
+// failures are transient, and everything else, including cancellation, is
+// permanent.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var classified interface{ Transient() bool }
+	if errors.As(err, &classified) {
+		return classified.Transient()
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
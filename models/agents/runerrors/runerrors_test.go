@@ -0,0 +1,37 @@
+package runerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestIsTransient(t *testing.T) {
+	connectionRefused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	for _, test := range []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{name: "nil", err: nil, transient: false},
+		{name: "marked", err: fmt.Errorf("presign: %w", Transient(errors.New("s3 unavailable"))), transient: true},
+		{name: "connection refused", err: fmt.Errorf("failed to connect to MCP server: %w", connectionRefused), transient: true},
+		{name: "deadline", err: fmt.Errorf("tool node: %w", context.DeadlineExceeded), transient: true},
+		{name: "cancelled", err: fmt.Errorf("tool node: %w", context.Canceled), transient: false},
+		{name: "invalid graph", err: errors.New(`failed to build graph: node "review" has no model`), transient: false},
+		{name: "database", err: Database(errors.New("connection reset by peer")), transient: true},
+		{name: "missing row", err: Database(gorm.ErrRecordNotFound), transient: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsTransient(test.err); got != test.transient {
+				t.Fatalf("expected transient=%t, got %t for %v", test.transient, got, test.err)
+			}
+		})
+	}
+}
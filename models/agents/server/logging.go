@@ -0,0 +1,28 @@
+package server
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// logRequests logs one record per HTTP request. Health and metrics probes
+// are skipped because they only add noise.
+func logRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startedAt := time.Now()
+		c.Next()
+
+		path := c.Request.URL.Path
+		if path == "/health" || path == "/metrics" {
+			return
+		}
+		slog.InfoContext(c.Request.Context(), "http request",
+			"method", c.Request.Method,
+			"path", path,
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(startedAt).Milliseconds(),
+		)
+	}
+}
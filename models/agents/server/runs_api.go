@@ -0,0 +1,403 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/jobs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRunPageSize = 20
+	maxRunPageSize     = 100
+)
+
+var runStatuses = []string{"running", "completed", "failed", "cancelled"}
+
+// runFilter narrows the run list. Empty fields do not filter.
+type runFilter struct {
+	WorkflowID     string
+	OrganizationID string
+	ProjectID      string
+	Status         string
+	StartedAfter   *time.Time
+	StartedBefore  *time.Time
+	// Cursor is the ID of the last run of the previous page.
+	Cursor string
+	Limit  int
+}
+
+// runRow is a run joined with its workflow, as listed by the API.
+type runRow struct {
+	ID                string     `gorm:"column:id"`
+	AgentGraphID      string     `gorm:"column:agent_graph_id"`
+	WorkflowName      string     `gorm:"column:workflow_name"`
+	OrganizationID    string     `gorm:"column:organization_id"`
+	ProjectID         *string    `gorm:"column:project_id"`
+	APIKeyID          *string    `gorm:"column:api_key_id"`
+	Status            string     `gorm:"column:status"`
+	Error             *string    `gorm:"column:error"`
+	ErrorCode         *string    `gorm:"column:error_code"`
+	StartedAt         time.Time  `gorm:"column:started_at"`
+	PickedUpAt        *time.Time `gorm:"column:picked_up_at"`
+	FinishedAt        *time.Time `gorm:"column:finished_at"`
+	GraphSnapshotHash *string    `gorm:"column:graph_snapshot_hash"`
+	ParentRunID       *string    `gorm:"column:parent_run_id"`
+	BackfillID        *string    `gorm:"column:backfill_id"`
+	ScheduleID        *string    `gorm:"column:schedule_id"`
+	TriggerID         *string    `gorm:"column:trigger_id"`
+}
+
+// Field names follow the service API.
+type runSummary struct {
+	ID                string     `json:"id"`
+	WorkflowID        string     `json:"workflowId"`
+	WorkflowName      string     `json:"workflowName"`
+	OrganizationID    string     `json:"organizationId"`
+	ProjectID         *string    `json:"projectId"`
+	APIKeyID          *string    `json:"apiKeyId"`
+	Status            string     `json:"status"`
+	Error             *string    `json:"error"`
+	ErrorCode         *string    `json:"errorCode"`
+	StartedAt         time.Time  `json:"startedAt"`
+	PickedUpAt        *time.Time `json:"pickedUpAt"`
+	FinishedAt        *time.Time `json:"finishedAt"`
+	GraphSnapshotHash *string    `json:"graphSnapshotHash"`
+	ParentRunID       *string    `json:"parentRunId"`
+	BackfillID        *string    `json:"backfillId"`
+	ScheduleID        *string    `json:"scheduleId"`
+	TriggerID         *string    `json:"triggerId"`
+}
+
+type runDetail struct {
+	runSummary
+	InitialState json.RawMessage `json:"initialState"`
+	FinalState   json.RawMessage `json:"finalState"`
+	Attempts     json.RawMessage `json:"attempts"`
+	Steps        []runStep       `json:"steps"`
+}
+
+type runStep struct {
+	ID         string          `json:"id"`
+	NodeKey    string          `json:"nodeKey"`
+	StepOrder  int32           `json:"stepOrder"`
+	StateDelta json.RawMessage `json:"stateDelta"`
+	ErrorCode  *string         `json:"errorCode"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt"`
+	DurationMS *int64          `json:"durationMs"`
+}
+
+type runsAPI struct {
+	db     *gorm.DB
+	sender jobs.EventSender
+}
+
+// registerRunRoutes serves run inspection and control under /api/runs.
+func registerRunRoutes(router gin.IRouter, db *gorm.DB, sender jobs.EventSender) {
+	api := &runsAPI{db: db, sender: sender}
+	runs := router.Group("/runs")
+	runs.GET("", api.list)
+	runs.GET("/:id", api.get)
+	runs.POST("/:id/retry", api.requeue(jobs.RequeueRetry))
+	runs.POST("/:id/rerun", api.requeue(jobs.RequeueRerun))
+	runs.POST("/:id/cancel", api.cancel)
+}
+
+func (api *runsAPI) list(c *gin.Context) {
+	filter, err := parseRunFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	var rows []runRow
+	if err := runListQuery(api.db.WithContext(c.Request.Context()), filter).Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list runs"})
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > filter.Limit {
+		rows = rows[:filter.Limit]
+		nextCursor = &rows[len(rows)-1].ID
+	}
+	runs := make([]runSummary, 0, len(rows))
+	for i := range rows {
+		runs = append(runs, summarizeRun(&rows[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs, "nextCursor": nextCursor})
+}
+
+func (api *runsAPI) get(c *gin.Context) {
+	runID, ok := runIDParam(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	var rows []runRow
+	if err := runListQuery(api.db.WithContext(ctx), runFilter{}).
+		Where("r.id = ?", runID).
+		Limit(1).
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load run"})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Run not found"})
+		return
+	}
+
+	var run dbmodels.AgentGraphRun
+	if err := api.db.WithContext(ctx).
+		Select("id, initial_state, final_state, attempts").
+		Where("id = ?", runID).
+		Take(&run).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load run"})
+		return
+	}
+	var steps []dbmodels.AgentGraphRunStep
+	if err := api.db.WithContext(ctx).
+		Where("run_id = ?", runID).
+		Order("step_order").
+		Find(&steps).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load run steps"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildRunDetail(&rows[0], &run, steps))
+}
+
+func (api *runsAPI) requeue(mode jobs.RequeueMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runID, ok := runIDParam(c)
+		if !ok {
+			return
+		}
+		execution, err := jobs.RequeueRun(c.Request.Context(), api.db, api.sender, runID, mode)
+		if err != nil {
+			respondRunControlError(c, "requeue run", err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"runId":       execution.ExecutionID,
+			"sourceRunId": runID,
+			"mode":        mode,
+			"documentIds": execution.DocumentIDs,
+		})
+	}
+}
+
+func (api *runsAPI) cancel(c *gin.Context) {
+	runID, ok := runIDParam(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid request body"})
+			return
+		}
+	}
+
+	cancelled, err := jobs.CancelRun(c.Request.Context(), api.db, api.sender, runID, body.Reason)
+	if err != nil {
+		respondRunControlError(c, "cancel run", err)
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusConflict, gin.H{"message": "Run is not running"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"runId": runID, "cancelled": true})
+}
+
+// runListQuery selects runs with their workflow, newest first, applying
+// filter. It fetches one extra row so callers can tell whether another page
+// follows.
+func runListQuery(db *gorm.DB, filter runFilter) *gorm.DB {
+	query := db.Table("agent_graph_runs AS r").
+		Select("r.id, r.agent_graph_id, g.name AS workflow_name, g.organization_id, r.project_id, r.api_key_id, " +
+			"r.status, r.error, r.error_code, r.started_at, r.picked_up_at, r.finished_at, r.graph_snapshot_hash, " +
+			"r.parent_run_id, r.backfill_id, r.schedule_id, r.trigger_id").
+		Joins("JOIN agent_graphs AS g ON g.id = r.agent_graph_id")
+	if filter.WorkflowID != "" {
+		query = query.Where("r.agent_graph_id = ?", filter.WorkflowID)
+	}
+	if filter.OrganizationID != "" {
+		query = query.Where("g.organization_id = ?", filter.OrganizationID)
+	}
+	if filter.ProjectID != "" {
+		query = query.Where("r.project_id = ?", filter.ProjectID)
+	}
+	if filter.Status != "" {
+		query = query.Where("r.status = ?", filter.Status)
+	}
+	if filter.StartedAfter != nil {
+		query = query.Where("r.started_at >= ?", *filter.StartedAfter)
+	}
+	if filter.StartedBefore != nil {
+		query = query.Where("r.started_at < ?", *filter.StartedBefore)
+	}
+	if filter.Cursor != "" {
+		query = query.Where("(r.started_at, r.id) < (SELECT started_at, id FROM agent_graph_runs WHERE id = ?)", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit + 1)
+	}
+	return query.Order("r.started_at DESC, r.id DESC")
+}
+
+func parseRunFilter(c *gin.Context) (runFilter, error) {
+	filter := runFilter{
+		Status: c.Query("status"),
+		Limit:  defaultRunPageSize,
+	}
+	for _, param := range []struct {
+		name   string
+		target *string
+	}{
+		{"workflow_id", &filter.WorkflowID},
+		{"organization_id", &filter.OrganizationID},
+		{"project_id", &filter.ProjectID},
+		{"cursor", &filter.Cursor},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			return runFilter{}, fmt.Errorf("invalid %s %q", param.name, value)
+		}
+		*param.target = value
+	}
+	if filter.Status != "" && !slices.Contains(runStatuses, filter.Status) {
+		return runFilter{}, fmt.Errorf("invalid status %q", filter.Status)
+	}
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"started_after", &filter.StartedAfter},
+		{"started_before", &filter.StartedBefore},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return runFilter{}, fmt.Errorf("invalid %s %q: expected an RFC 3339 time", param.name, value)
+		}
+		// started_at is stored without a time zone, in UTC.
+		utc := parsed.UTC()
+		*param.target = &utc
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxRunPageSize {
+			return runFilter{}, fmt.Errorf("invalid limit %q: must be between 1 and %d", raw, maxRunPageSize)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+func runIDParam(c *gin.Context) (string, bool) {
+	runID := c.Param("id")
+	if _, err := uuid.Parse(runID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid run id %q", runID)})
+		return "", false
+	}
+	return runID, true
+}
+
+// respondRunControlError maps a requeue or cancel error to a response. Errors
+// without a client-facing meaning are logged and reported as "failed to
+// <action>" so database details do not leak.
+func respondRunControlError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrRunNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Run not found"})
+	case errors.Is(err, jobs.ErrRunStatusConflict):
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	default:
+		slog.ErrorContext(c.Request.Context(), "run control failed", "action", action, "run_id", c.Param("id"), "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to " + action})
+	}
+}
+
+func summarizeRun(row *runRow) runSummary {
+	return runSummary{
+		ID:                row.ID,
+		WorkflowID:        row.AgentGraphID,
+		WorkflowName:      row.WorkflowName,
+		OrganizationID:    row.OrganizationID,
+		ProjectID:         row.ProjectID,
+		APIKeyID:          row.APIKeyID,
+		Status:            row.Status,
+		Error:             row.Error,
+		ErrorCode:         row.ErrorCode,
+		StartedAt:         row.StartedAt,
+		PickedUpAt:        row.PickedUpAt,
+		FinishedAt:        row.FinishedAt,
+		GraphSnapshotHash: row.GraphSnapshotHash,
+		ParentRunID:       row.ParentRunID,
+		BackfillID:        row.BackfillID,
+		ScheduleID:        row.ScheduleID,
+		TriggerID:         row.TriggerID,
+	}
+}
+
+// buildRunDetail decodes a run's stored JSON so states and step deltas are
+// returned as objects rather than strings.
+func buildRunDetail(row *runRow, run *dbmodels.AgentGraphRun, steps []dbmodels.AgentGraphRunStep) runDetail {
+	detail := runDetail{
+		runSummary:   summarizeRun(row),
+		InitialState: rawJSON(run.InitialState),
+		FinalState:   rawJSON(run.FinalState),
+		Attempts:     rawJSON(run.Attempts),
+		Steps:        make([]runStep, 0, len(steps)),
+	}
+	for _, step := range steps {
+		var durationMS *int64
+		if step.FinishedAt != nil {
+			duration := step.FinishedAt.Sub(step.StartedAt).Milliseconds()
+			durationMS = &duration
+		}
+		detail.Steps = append(detail.Steps, runStep{
+			ID:         step.ID,
+			NodeKey:    step.NodeKey,
+			StepOrder:  step.StepOrder,
+			StateDelta: rawJSON(step.StateDelta),
+			ErrorCode:  step.ErrorCode,
+			StartedAt:  step.StartedAt,
+			FinishedAt: step.FinishedAt,
+			DurationMS: durationMS,
+		})
+	}
+	return detail
+}
+
+// rawJSON returns a stored JSON column as-is, or null when it is empty or
+// not valid JSON.
+func rawJSON(value *string) json.RawMessage {
+	if value == nil || !json.Valid([]byte(*value)) {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(*value)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+func TestRunListQueryAppliesFilters(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+
+	startedAfter := time.Now().Add(-time.Hour)
+	var rows []runRow
+	tx := runListQuery(db, runFilter{
+		WorkflowID:     "workflow-1",
+		OrganizationID: "org-1",
+		ProjectID:      "project-1",
+		Status:         "failed",
+		StartedAfter:   &startedAfter,
+		Cursor:         "run-1",
+		Limit:          10,
+	}).Scan(&rows)
+	query := tx.Statement.SQL.String()
+
+	for _, fragment := range []string{
+		"JOIN agent_graphs AS g ON g.id = r.agent_graph_id",
+		"r.agent_graph_id = ?",
+		"g.organization_id = ?",
+		"r.project_id = ?",
+		"r.status = ?",
+		"r.started_at >= ?",
+		"(r.started_at, r.id) < (SELECT started_at, id FROM agent_graph_runs WHERE id = ?)",
+		"ORDER BY r.started_at DESC, r.id DESC LIMIT ?",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in run query: %s", fragment, query)
+		}
+	}
+	if limit := tx.Statement.Vars[len(tx.Statement.Vars)-1]; limit != 11 {
+		t.Fatalf("expected one extra row to be fetched, got limit %v", limit)
+	}
+	if strings.Contains(query, "r.started_at < ?") {
+		t.Fatalf("expected unset filters to be skipped: %s", query)
+	}
+}
+
+func TestParseRunFilterValidatesParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		query string
+		valid bool
+	}{
+		{"", true},
+		{"status=failed&limit=50&started_after=2026-03-01T00:00:00Z", true},
+		{"status=paused", false},
+		{"workflow_id=not-a-uuid", false},
+		{"limit=1000", false},
+		{"started_before=yesterday", false},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/runs?"+tc.query, nil)
+
+		filter, err := parseRunFilter(c)
+		if (err == nil) != tc.valid {
+			t.Fatalf("query %q: expected valid=%t, got %v", tc.query, tc.valid, err)
+		}
+		if err == nil && filter.Limit == 0 {
+			t.Fatalf("query %q: expected a default page size", tc.query)
+		}
+	}
+}
+
+func TestRequireAPIToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/runs", requireAPIToken("secret"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for header, status := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer secret": http.StatusNoContent,
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/api/runs", nil)
+		if header != "" {
+			request.Header.Set("Authorization", header)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != status {
+			t.Fatalf("Authorization %q: expected %d, got %d", header, status, recorder.Code)
+		}
+	}
+}
@@ -2,35 +2,62 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
 	"github.com/arcnem-ai/arcnem-vision/models/agents/jobs"
 	"github.com/arcnem-ai/arcnem-vision/models/db/client"
 	"github.com/arcnem-ai/arcnem-vision/models/shared/env"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
 	"github.com/gin-gonic/gin"
 )
 
+// shutdownTimeout bounds how long StartServer waits for in-flight requests
+// and jobs after SIGINT or SIGTERM. Jobs still running after it are
+// interrupted and released back to the queue.
+const shutdownTimeout = 30 * time.Second
+
 func StartServer() error {
 	if err := env.LoadEnv(); err != nil {
 		return fmt.Errorf("load env: %w", err)
 	}
+	if err := logging.Setup("arcnem-vision-agents"); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := telemetry.Setup(ctx, "arcnem-vision-agents")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("trace shutdown failed", "err", err)
+		}
+	}()
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery(), logRequests())
 
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 		})
 	})
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-	ctx := context.Background()
-
-	inngestClient, err := clients.NewInngestClient()
-	if err != nil {
-		return err
-	}
 	dbClient, err := client.NewPGClient()
 	if err != nil {
 		return err
@@ -43,12 +70,80 @@ func StartServer() error {
 	if err != nil {
 		return err
 	}
+	jobLimits, err := jobs.LoadJobLimits()
+	if err != nil {
+		return err
+	}
 
-	jobs.RegisterJobs(inngestClient, dbClient, s3Client, mcpClient)
+	jobBackend, err := jobs.LoadJobBackend()
+	if err != nil {
+		return err
+	}
+
+	var runner jobs.JobRunner
+	var postgresRunner *jobs.PostgresRunner
+	switch jobBackend {
+	case jobs.JobBackendPostgres:
+		options, err := jobs.LoadPostgresRunnerOptions()
+		if err != nil {
+			return err
+		}
+		postgresRunner = jobs.NewPostgresRunner(dbClient, jobLimits, options)
+		jobs.RegisterJobs(postgresRunner, dbClient, s3Client, mcpClient)
+		postgresRunner.Start(ctx)
+		runner = postgresRunner
+	default:
+		inngestClient, err := clients.NewInngestClient()
+		if err != nil {
+			return err
+		}
+		inngestRunner := jobs.NewInngestRunner(inngestClient, jobLimits)
+		jobs.RegisterJobs(inngestRunner, dbClient, s3Client, mcpClient)
+
+		router.POST("/api/inngest", gin.WrapH(inngestClient.Serve()))
+		router.GET("/api/inngest", gin.WrapH(inngestClient.Serve()))
+		router.PUT("/api/inngest", gin.WrapH(inngestClient.Serve()))
+		runner = inngestRunner
+	}
+
+	if token := strings.TrimSpace(os.Getenv("AGENTS_API_TOKEN")); token != "" {
+		api := router.Group("/api", requireAPIToken(token))
+		registerRunRoutes(api, dbClient, runner)
+		registerWorkflowRoutes(api, dbClient, s3Client, mcpClient, runner)
+	} else {
+		slog.Warn("internal api disabled", "reason", "AGENTS_API_TOKEN is not set")
+	}
 
-	router.POST("/api/inngest", gin.WrapH(inngestClient.Serve()))
-	router.GET("/api/inngest", gin.WrapH(inngestClient.Serve()))
-	router.PUT("/api/inngest", gin.WrapH(inngestClient.Serve()))
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("agents server listening", "addr", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
 
-	return router.Run()
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	// A second signal stops the process without waiting.
+	stop()
+	slog.Info("agents server shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	var shutdownErr error
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		shutdownErr = fmt.Errorf("http server shutdown: %w", err)
+	}
+	if postgresRunner != nil {
+		if err := postgresRunner.Wait(shutdownCtx); err != nil {
+			shutdownErr = errors.Join(shutdownErr, fmt.Errorf("job runner shutdown: %w", err))
+		}
+	}
+	return shutdownErr
 }
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/clients"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/jobs"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInvokeTimeout = 2 * time.Minute
+	maxInvokeTimeout     = 10 * time.Minute
+)
+
+type invokeWorkflowBody struct {
+	jobs.InvokeWorkflowRequest
+	// TimeoutSeconds bounds how long the graph may run before the run fails
+	// with a timeout.
+	TimeoutSeconds int `json:"timeoutSeconds"`
+}
+
+type workflowsAPI struct {
+	db        *gorm.DB
+	s3Client  *clients.S3Client
+	mcpClient *clients.MCPClient
+	sender    jobs.EventSender
+}
+
+// registerWorkflowRoutes serves synchronous workflow invocation under
+// /api/workflows.
+func registerWorkflowRoutes(router gin.IRouter, db *gorm.DB, s3Client *clients.S3Client, mcpClient *clients.MCPClient, sender jobs.EventSender) {
+	api := &workflowsAPI{db: db, s3Client: s3Client, mcpClient: mcpClient, sender: sender}
+	router.POST("/workflows/:id/invoke", api.invoke)
+}
+
+func (api *workflowsAPI) invoke(c *gin.Context) {
+	workflowID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid workflow id %q", c.Param("id"))})
+		return
+	}
+	var body invokeWorkflowBody
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid request body"})
+			return
+		}
+	}
+	timeout, err := invokeTimeout(body.TimeoutSeconds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	result, err := jobs.InvokeWorkflow(ctx, api.db, api.s3Client, api.mcpClient, api.sender, workflowID, body.InvokeWorkflowRequest)
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrInvalidInvocation):
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		case errorcodes.Of(err) == errorcodes.NotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		default:
+			slog.ErrorContext(c.Request.Context(), "workflow invocation failed", "workflow_id", workflowID, "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to invoke workflow"})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if result.ErrorCode != nil && *result.ErrorCode == string(errorcodes.Timeout) {
+		status = http.StatusGatewayTimeout
+	}
+	c.JSON(status, result)
+}
+
+func invokeTimeout(seconds int) (time.Duration, error) {
+	if seconds == 0 {
+		return defaultInvokeTimeout, nil
+	}
+	timeout := time.Duration(seconds) * time.Second
+	if seconds < 0 || timeout > maxInvokeTimeout {
+		return 0, fmt.Errorf("invalid timeoutSeconds %d: must be between 1 and %d", seconds, int(maxInvokeTimeout.Seconds()))
+	}
+	return timeout, nil
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvokeTimeout(t *testing.T) {
+	for seconds, expected := range map[int]time.Duration{
+		0:   defaultInvokeTimeout,
+		30:  30 * time.Second,
+		600: maxInvokeTimeout,
+	} {
+		timeout, err := invokeTimeout(seconds)
+		if err != nil || timeout != expected {
+			t.Fatalf("timeoutSeconds %d: expected %s, got %s, %v", seconds, expected, timeout, err)
+		}
+	}
+	for _, seconds := range []int{-1, 601} {
+		if _, err := invokeTimeout(seconds); err == nil {
+			t.Fatalf("timeoutSeconds %d: expected an error", seconds)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package workflowfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/load"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// uiPositionKey is the node config key where the dashboard stores a node's
+// position. Files carry it as Node.Position instead.
+const uiPositionKey = "uiPosition"
+
+// ExportWorkflow exports a workflow's current graph.
+func ExportWorkflow(ctx context.Context, db *gorm.DB, workflowID uuid.UUID) (*File, error) {
+	snapshot, err := load.LoadAgentGraphSnapshot(ctx, db, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow %s: %w", workflowID, err)
+	}
+	return FromSnapshot(snapshot)
+}
+
+// FromSnapshot converts a loaded graph snapshot to a canonical file.
+func FromSnapshot(snapshot *graphs.Snapshot) (*File, error) {
+	if snapshot == nil || snapshot.AgentGraph == nil {
+		return nil, fmt.Errorf("graph snapshot is missing its agent graph")
+	}
+	stateSchema, err := decodeJSONObject(snapshot.AgentGraph.StateSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state_schema: %w", err)
+	}
+	file := &File{
+		Version:     FormatVersion,
+		Name:        snapshot.AgentGraph.Name,
+		Description: derefString(snapshot.AgentGraph.Description),
+		EntryNode:   snapshot.AgentGraph.EntryNode,
+		StateSchema: stateSchema,
+		Nodes:       make([]Node, 0, len(snapshot.Nodes)),
+		Edges:       make([]Edge, 0, len(snapshot.Edges)),
+	}
+
+	for _, snapshotNode := range snapshot.Nodes {
+		if snapshotNode == nil || snapshotNode.Node == nil {
+			continue
+		}
+		node, err := nodeFromConfig(snapshotNode.Node.NodeKey, snapshotNode.Node.Config)
+		if err != nil {
+			return nil, err
+		}
+		node.Type = snapshotNode.Node.NodeType
+		node.InputKey = derefString(snapshotNode.Node.InputKey)
+		node.OutputKey = derefString(snapshotNode.Node.OutputKey)
+		if snapshotNode.Model != nil {
+			node.Model = &ModelRef{
+				Provider: snapshotNode.Model.Provider,
+				Name:     snapshotNode.Model.Name,
+				Version:  snapshotNode.Model.Version,
+			}
+		}
+		for _, tool := range snapshotNode.Tools {
+			if tool != nil {
+				node.Tools = append(node.Tools, tool.Name)
+			}
+		}
+		file.Nodes = append(file.Nodes, node)
+	}
+	for _, edge := range snapshot.Edges {
+		if edge != nil {
+			file.Edges = append(file.Edges, Edge{From: edge.FromNode, To: edge.ToNode})
+		}
+	}
+
+	file.Canonicalize()
+	return file, nil
+}
+
+// nodeFromConfig splits a node's stored config into its config and position.
+func nodeFromConfig(nodeKey string, rawConfig string) (Node, error) {
+	node := Node{Key: nodeKey}
+	config, err := decodeJSONObject(&rawConfig)
+	if err != nil {
+		return Node{}, fmt.Errorf("invalid config on node %q: %w", nodeKey, err)
+	}
+	if rawPosition, ok := config[uiPositionKey].(map[string]any); ok {
+		x, xOK := rawPosition["x"].(float64)
+		y, yOK := rawPosition["y"].(float64)
+		if xOK && yOK {
+			node.Position = &Position{X: x, Y: y}
+		}
+	}
+	delete(config, uiPositionKey)
+	node.Config = config
+	return node, nil
+}
+
+// templateSnapshot is the snapshot stored on agent_graph_template_versions by
+// the dashboard.
+type templateSnapshot struct {
+	Name        string         `json:"name"`
+	Description *string        `json:"description"`
+	EntryNode   string         `json:"entryNode"`
+	StateSchema map[string]any `json:"stateSchema"`
+	Nodes       []struct {
+		NodeKey   string         `json:"nodeKey"`
+		NodeType  string         `json:"nodeType"`
+		X         float64        `json:"x"`
+		Y         float64        `json:"y"`
+		InputKey  *string        `json:"inputKey"`
+		OutputKey *string        `json:"outputKey"`
+		ModelID   *string        `json:"modelId"`
+		ToolIDs   []string       `json:"toolIds"`
+		Config    map[string]any `json:"config"`
+	} `json:"nodes"`
+	Edges []struct {
+		FromNode string `json:"fromNode"`
+		ToNode   string `json:"toNode"`
+	} `json:"edges"`
+}
+
+// ExportTemplateVersion exports one version of a workflow template. A version
+// of 0 exports the template's current version.
+func ExportTemplateVersion(ctx context.Context, db *gorm.DB, templateID uuid.UUID, version int) (*File, error) {
+	var templateVersion dbmodels.AgentGraphTemplateVersion
+	query := db.WithContext(ctx).Model(&dbmodels.AgentGraphTemplateVersion{})
+	if version > 0 {
+		query = query.Where("agent_graph_template_id = ? AND version = ?", templateID, version)
+	} else {
+		query = query.Where("id = (SELECT current_version_id FROM agent_graph_templates WHERE id = ?)", templateID)
+	}
+	if err := query.Take(&templateVersion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcodes.Wrap(errorcodes.NotFound, fmt.Errorf("template %s has no version %s", templateID, describeVersion(version)))
+		}
+		return nil, fmt.Errorf("failed to load template %s: %w", templateID, runerrors.Database(err))
+	}
+
+	var snapshot templateSnapshot
+	if err := json.Unmarshal([]byte(templateVersion.Snapshot), &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot on template %s version %d: %w", templateID, templateVersion.Version, err)
+	}
+
+	var modelIDs, toolIDs []string
+	for _, node := range snapshot.Nodes {
+		if node.ModelID != nil {
+			modelIDs = append(modelIDs, *node.ModelID)
+		}
+		toolIDs = append(toolIDs, node.ToolIDs...)
+	}
+	modelsByID := map[string]*dbmodels.Model{}
+	if len(modelIDs) > 0 {
+		var models []*dbmodels.Model
+		if err := db.WithContext(ctx).Where("id IN ?", modelIDs).Find(&models).Error; err != nil {
+			return nil, fmt.Errorf("failed to load template models: %w", runerrors.Database(err))
+		}
+		for _, model := range models {
+			modelsByID[model.ID] = model
+		}
+	}
+	toolsByID := map[string]*dbmodels.Tool{}
+	if len(toolIDs) > 0 {
+		var tools []*dbmodels.Tool
+		if err := db.WithContext(ctx).Select("id, name").Where("id IN ?", toolIDs).Find(&tools).Error; err != nil {
+			return nil, fmt.Errorf("failed to load template tools: %w", runerrors.Database(err))
+		}
+		for _, tool := range tools {
+			toolsByID[tool.ID] = tool
+		}
+	}
+
+	file := &File{
+		Version:     FormatVersion,
+		Name:        snapshot.Name,
+		Description: derefString(snapshot.Description),
+		EntryNode:   snapshot.EntryNode,
+		StateSchema: snapshot.StateSchema,
+		Nodes:       make([]Node, 0, len(snapshot.Nodes)),
+		Edges:       make([]Edge, 0, len(snapshot.Edges)),
+	}
+	for _, templateNode := range snapshot.Nodes {
+		node := Node{
+			Key:       templateNode.NodeKey,
+			Type:      templateNode.NodeType,
+			InputKey:  derefString(templateNode.InputKey),
+			OutputKey: derefString(templateNode.OutputKey),
+			Config:    templateNode.Config,
+			Position:  &Position{X: templateNode.X, Y: templateNode.Y},
+		}
+		delete(node.Config, uiPositionKey)
+		if templateNode.ModelID != nil {
+			model, ok := modelsByID[*templateNode.ModelID]
+			if !ok {
+				return nil, fmt.Errorf("node %q references missing model %s", node.Key, *templateNode.ModelID)
+			}
+			node.Model = &ModelRef{Provider: model.Provider, Name: model.Name, Version: model.Version}
+		}
+		for _, toolID := range templateNode.ToolIDs {
+			tool, ok := toolsByID[toolID]
+			if !ok {
+				return nil, fmt.Errorf("node %q references missing tool %s", node.Key, toolID)
+			}
+			node.Tools = append(node.Tools, tool.Name)
+		}
+		file.Nodes = append(file.Nodes, node)
+	}
+	for _, edge := range snapshot.Edges {
+		file.Edges = append(file.Edges, Edge{From: edge.FromNode, To: edge.ToNode})
+	}
+
+	file.Canonicalize()
+	return file, nil
+}
+
+func describeVersion(version int) string {
+	if version > 0 {
+		return fmt.Sprint(version)
+	}
+	return "marked current"
+}
+
+func decodeJSONObject(raw *string) (map[string]any, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(*raw), &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
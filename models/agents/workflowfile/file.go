@@ -0,0 +1,188 @@
+// Package workflowfile exports workflows to canonical YAML or JSON files and
+// imports them back. Files reference models by provider, name, and version and
+// tools by name, so they can be reviewed and versioned outside the database.
+package workflowfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is the version of the file format written by Encode.
+const FormatVersion = 1
+
+// Format is the encoding of a workflow file.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// FormatForPath picks the format from a file extension, defaulting to YAML.
+func FormatForPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// File is a workflow graph with its references resolved to names.
+type File struct {
+	Version     int            `json:"version" yaml:"version"`
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	EntryNode   string         `json:"entry_node" yaml:"entry_node"`
+	StateSchema map[string]any `json:"state_schema,omitempty" yaml:"state_schema,omitempty"`
+	Nodes       []Node         `json:"nodes" yaml:"nodes"`
+	Edges       []Edge         `json:"edges" yaml:"edges"`
+}
+
+type Node struct {
+	Key       string    `json:"key" yaml:"key"`
+	Type      string    `json:"type" yaml:"type"`
+	InputKey  string    `json:"input_key,omitempty" yaml:"input_key,omitempty"`
+	OutputKey string    `json:"output_key,omitempty" yaml:"output_key,omitempty"`
+	Model     *ModelRef `json:"model,omitempty" yaml:"model,omitempty"`
+	// Tools are tool names.
+	Tools  []string       `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Config map[string]any `json:"config,omitempty" yaml:"config,omitempty"`
+	// Position is where the dashboard editor draws the node.
+	Position *Position `json:"position,omitempty" yaml:"position,omitempty"`
+}
+
+// ModelRef matches a row in models by its unique provider, name, and version.
+type ModelRef struct {
+	Provider string `json:"provider" yaml:"provider"`
+	Name     string `json:"name" yaml:"name"`
+	Version  string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+func (m ModelRef) String() string {
+	if m.Version == "" {
+		return m.Provider + "/" + m.Name
+	}
+	return m.Provider + "/" + m.Name + "@" + m.Version
+}
+
+type Position struct {
+	X float64 `json:"x" yaml:"x"`
+	Y float64 `json:"y" yaml:"y"`
+}
+
+type Edge struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// Canonicalize sorts nodes by key, tools by name, and edges by their
+// endpoints, and drops duplicate tools, so equal graphs encode identically.
+func (f *File) Canonicalize() {
+	slices.SortStableFunc(f.Nodes, func(a, b Node) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	for i := range f.Nodes {
+		slices.Sort(f.Nodes[i].Tools)
+		f.Nodes[i].Tools = slices.Compact(f.Nodes[i].Tools)
+		if len(f.Nodes[i].Tools) == 0 {
+			f.Nodes[i].Tools = nil
+		}
+		if len(f.Nodes[i].Config) == 0 {
+			f.Nodes[i].Config = nil
+		}
+	}
+	slices.SortStableFunc(f.Edges, func(a, b Edge) int {
+		if a.From != b.From {
+			return strings.Compare(a.From, b.From)
+		}
+		return strings.Compare(a.To, b.To)
+	})
+	if len(f.StateSchema) == 0 {
+		f.StateSchema = nil
+	}
+}
+
+// Equal reports whether two files describe the same workflow.
+func (f *File) Equal(other *File) bool {
+	a, errA := canonicalJSON(f)
+	b, errB := canonicalJSON(other)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+func canonicalJSON(file *File) ([]byte, error) {
+	// Decoded YAML and JSON hold numbers as different Go types, so files are
+	// compared by their JSON encoding.
+	copied := file.clone()
+	copied.Canonicalize()
+	return json.Marshal(copied)
+}
+
+func (f *File) clone() *File {
+	copied := *f
+	copied.Nodes = make([]Node, len(f.Nodes))
+	for i, node := range f.Nodes {
+		node.Tools = slices.Clone(node.Tools)
+		copied.Nodes[i] = node
+	}
+	copied.Edges = slices.Clone(f.Edges)
+	return &copied
+}
+
+// Encode writes file in canonical order.
+func Encode(file *File, format Format) ([]byte, error) {
+	copied := file.clone()
+	copied.Canonicalize()
+	switch format {
+	case FormatJSON:
+		encoded, err := json.MarshalIndent(copied, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode workflow file: %w", err)
+		}
+		return append(encoded, '\n'), nil
+	case FormatYAML:
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(copied); err != nil {
+			return nil, fmt.Errorf("failed to encode workflow file: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode workflow file: %w", err)
+		}
+		return buffer.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown workflow file format %q", format)
+	}
+}
+
+// Decode reads a workflow file. Unknown fields are rejected so typos do not
+// silently change a workflow.
+func Decode(data []byte, format Format) (*File, error) {
+	var file File
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("failed to decode workflow file: %w", err)
+		}
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("failed to decode workflow file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown workflow file format %q", format)
+	}
+	if file.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported workflow file version %d: expected %d", file.Version, FormatVersion)
+	}
+	return &file, nil
+}
@@ -0,0 +1,156 @@
+package workflowfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func testSnapshot() *graphs.Snapshot {
+	return &graphs.Snapshot{
+		AgentGraph: &dbmodels.AgentGraph{
+			ID:          "graph-1",
+			Name:        "Describe",
+			Description: stringPtr("Describes receipts."),
+			EntryNode:   "describe",
+			StateSchema: stringPtr(`{"type":"object"}`),
+		},
+		Nodes: []*graphs.SnapshotNode{
+			{
+				Node: &dbmodels.AgentGraphNode{
+					ID:       "node-2",
+					NodeKey:  "save",
+					NodeType: "tool",
+					Config:   `{"uiPosition":{"x":320,"y":80}}`,
+				},
+				Tools: []*dbmodels.Tool{
+					{ID: "tool-2", Name: "save_description"},
+					{ID: "tool-1", Name: "create_embedding"},
+				},
+			},
+			{
+				Node: &dbmodels.AgentGraphNode{
+					ID:        "node-1",
+					NodeKey:   "describe",
+					NodeType:  "worker",
+					InputKey:  stringPtr("temp_url"),
+					OutputKey: stringPtr("description"),
+					Config:    `{"system_message":"Describe the image.","uiPosition":{"x":40,"y":80}}`,
+					ModelID:   stringPtr("model-1"),
+				},
+				Model: &dbmodels.Model{ID: "model-1", Provider: "OPENAI", Name: "gpt-4.1-mini"},
+			},
+		},
+		Edges: []*dbmodels.AgentGraphEdge{
+			{ID: "edge-2", FromNode: "save", ToNode: "END"},
+			{ID: "edge-1", FromNode: "describe", ToNode: "save"},
+		},
+	}
+}
+
+func TestFromSnapshotReferencesByNameInCanonicalOrder(t *testing.T) {
+	file, err := FromSnapshot(testSnapshot())
+	if err != nil {
+		t.Fatalf("FromSnapshot returned error: %v", err)
+	}
+
+	if file.Version != FormatVersion || file.Description != "Describes receipts." || file.StateSchema["type"] != "object" {
+		t.Fatalf("unexpected workflow fields: %#v", file)
+	}
+	if len(file.Nodes) != 2 || file.Nodes[0].Key != "describe" || file.Nodes[1].Key != "save" {
+		t.Fatalf("expected nodes sorted by key, got %#v", file.Nodes)
+	}
+	describe, save := file.Nodes[0], file.Nodes[1]
+	if describe.Model == nil || describe.Model.String() != "OPENAI/gpt-4.1-mini" {
+		t.Fatalf("unexpected model reference: %#v", describe.Model)
+	}
+	if describe.Position == nil || describe.Position.X != 40 || describe.Config["uiPosition"] != nil {
+		t.Fatalf("expected uiPosition to move to position, got %#v and %#v", describe.Position, describe.Config)
+	}
+	if save.Config != nil {
+		t.Fatalf("expected a config holding only uiPosition to be dropped, got %#v", save.Config)
+	}
+	if strings.Join(save.Tools, ",") != "create_embedding,save_description" {
+		t.Fatalf("expected tools sorted by name, got %v", save.Tools)
+	}
+	if len(file.Edges) != 2 || file.Edges[0] != (Edge{From: "describe", To: "save"}) {
+		t.Fatalf("expected edges sorted by endpoints, got %#v", file.Edges)
+	}
+}
+
+func TestEncodeDecodeRoundTrips(t *testing.T) {
+	file, err := FromSnapshot(testSnapshot())
+	if err != nil {
+		t.Fatalf("FromSnapshot returned error: %v", err)
+	}
+
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		encoded, err := Encode(file, format)
+		if err != nil {
+			t.Fatalf("encode %s: %v", format, err)
+		}
+		decoded, err := Decode(encoded, format)
+		if err != nil {
+			t.Fatalf("decode %s: %v\n%s", format, err, encoded)
+		}
+		if !decoded.Equal(file) {
+			t.Fatalf("%s round trip changed the workflow:\n%s", format, encoded)
+		}
+		reencoded, err := Encode(decoded, format)
+		if err != nil {
+			t.Fatalf("re-encode %s: %v", format, err)
+		}
+		if string(reencoded) != string(encoded) {
+			t.Fatalf("%s encoding is not stable:\n%s\n---\n%s", format, encoded, reencoded)
+		}
+	}
+}
+
+func TestEqualIgnoresOrder(t *testing.T) {
+	a := &File{
+		Version: FormatVersion,
+		Nodes:   []Node{{Key: "a", Tools: []string{"y", "x"}}, {Key: "b"}},
+		Edges:   []Edge{{From: "b", To: "END"}, {From: "a", To: "b"}},
+	}
+	b := &File{
+		Version: FormatVersion,
+		Nodes:   []Node{{Key: "b"}, {Key: "a", Tools: []string{"x", "y", "x"}}},
+		Edges:   []Edge{{From: "a", To: "b"}, {From: "b", To: "END"}},
+	}
+	if !a.Equal(b) {
+		t.Fatal("expected files that differ only in order to be equal")
+	}
+	b.Nodes[0].Type = "tool"
+	if a.Equal(b) {
+		t.Fatal("expected files with different node types to differ")
+	}
+}
+
+func TestDecodeRejectsUnknownFieldsAndVersions(t *testing.T) {
+	cases := map[string]struct {
+		data   string
+		format Format
+	}{
+		"unknown YAML field": {"version: 1\nname: a\nentry_nodes: a\n", FormatYAML},
+		"unknown JSON field": {`{"version": 1, "name": "a", "nodez": []}`, FormatJSON},
+		"missing version":    {"name: a\n", FormatYAML},
+		"future version":     {`{"version": 2, "name": "a"}`, FormatJSON},
+	}
+	for name, tc := range cases {
+		if _, err := Decode([]byte(tc.data), tc.format); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	if FormatForPath("workflow.JSON") != FormatJSON || FormatForPath("workflow.yml") != FormatYAML || FormatForPath("workflow") != FormatYAML {
+		t.Fatal("unexpected formats for paths")
+	}
+}
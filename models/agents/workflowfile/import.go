@@ -0,0 +1,406 @@
+package workflowfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	"github.com/arcnem-ai/arcnem-vision/models/agents/runerrors"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportOptions struct {
+	OrganizationID uuid.UUID
+	// WorkflowID is the workflow to overwrite. When it is zero, the file
+	// updates the organization's unarchived workflow with the same name, or
+	// creates one if there is none.
+	WorkflowID uuid.UUID
+}
+
+type ImportResult struct {
+	WorkflowID string
+	Created    bool
+	// Changed is false when the workflow already matched the file.
+	Changed bool
+}
+
+// references are the models and tools a file names, keyed as the file writes
+// them.
+type references struct {
+	models map[ModelRef]*dbmodels.Model
+	tools  map[string]*dbmodels.Tool
+}
+
+// model returns the row ref names. Without resolved references, it returns a
+// row holding only the reference.
+func (r *references) model(ref ModelRef) *dbmodels.Model {
+	if r == nil {
+		return &dbmodels.Model{Provider: ref.Provider, Name: ref.Name, Version: ref.Version}
+	}
+	return r.models[ref]
+}
+
+func (r *references) tool(name string) *dbmodels.Tool {
+	if r == nil {
+		return &dbmodels.Tool{Name: name}
+	}
+	return r.tools[name]
+}
+
+// Snapshot builds the graph file describes without a database. Models and
+// tools carry their names but no IDs, which is enough to validate or render
+// the graph but not to run it.
+func (f *File) Snapshot() (*graphs.Snapshot, error) {
+	file := f.clone()
+	file.Canonicalize()
+	return toSnapshot(file, nil)
+}
+
+// ImportWorkflow validates file and writes it to a workflow. Importing the same
+// file twice leaves the workflow untouched the second time.
+func ImportWorkflow(ctx context.Context, db *gorm.DB, file *File, options ImportOptions) (*ImportResult, error) {
+	if file == nil {
+		return nil, errors.New("workflow file is nil")
+	}
+	if file.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported workflow file version %d: expected %d", file.Version, FormatVersion)
+	}
+	if options.OrganizationID == uuid.Nil {
+		return nil, errors.New("organization ID is required")
+	}
+	file = file.clone()
+	file.Canonicalize()
+
+	refs, err := resolveReferences(ctx, db, file)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := toSnapshot(file, refs)
+	if err != nil {
+		return nil, err
+	}
+	if err := graphs.ValidateSnapshot(snapshot); err != nil {
+		return nil, errorcodes.Wrap(errorcodes.GraphInvalid, fmt.Errorf("invalid workflow file: %w", err))
+	}
+
+	result := &ImportResult{}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		target, err := findImportTarget(tx, file.Name, options)
+		if err != nil {
+			return err
+		}
+		if target != nil {
+			result.WorkflowID = target.ID
+			current, err := ExportWorkflow(ctx, tx, uuid.MustParse(target.ID))
+			if err != nil {
+				return err
+			}
+			if current.Equal(file) {
+				return nil
+			}
+		} else {
+			id, err := uuid.NewV7()
+			if err != nil {
+				return fmt.Errorf("failed to generate workflow id: %w", err)
+			}
+			result.WorkflowID = id.String()
+			result.Created = true
+		}
+		result.Changed = true
+		snapshot.AgentGraph.ID = result.WorkflowID
+		snapshot.AgentGraph.OrganizationID = options.OrganizationID.String()
+		return writeSnapshot(tx, snapshot, result.Created)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolveReferences loads the models and tools file names, failing with every
+// reference that does not exist.
+func resolveReferences(ctx context.Context, db *gorm.DB, file *File) (*references, error) {
+	refs := &references{
+		models: map[ModelRef]*dbmodels.Model{},
+		tools:  map[string]*dbmodels.Tool{},
+	}
+	var toolNames []string
+	var modelKeys [][]any
+	for _, node := range file.Nodes {
+		toolNames = append(toolNames, node.Tools...)
+		if node.Model != nil {
+			modelKeys = append(modelKeys, []any{node.Model.Provider, node.Model.Name, node.Model.Version})
+		}
+	}
+
+	if len(modelKeys) > 0 {
+		var models []*dbmodels.Model
+		if err := db.WithContext(ctx).Where("(provider, name, version) IN ?", modelKeys).Find(&models).Error; err != nil {
+			return nil, fmt.Errorf("failed to load models: %w", runerrors.Database(err))
+		}
+		for _, model := range models {
+			refs.models[ModelRef{Provider: model.Provider, Name: model.Name, Version: model.Version}] = model
+		}
+	}
+	if len(toolNames) > 0 {
+		var tools []*dbmodels.Tool
+		if err := db.WithContext(ctx).Where("name IN ?", toolNames).Find(&tools).Error; err != nil {
+			return nil, fmt.Errorf("failed to load tools: %w", runerrors.Database(err))
+		}
+		for _, tool := range tools {
+			refs.tools[tool.Name] = tool
+		}
+	}
+
+	var missingModels, missingTools []string
+	for _, node := range file.Nodes {
+		if node.Model != nil && refs.models[*node.Model] == nil {
+			missingModels = append(missingModels, node.Model.String())
+		}
+		for _, name := range node.Tools {
+			if refs.tools[name] == nil {
+				missingTools = append(missingTools, name)
+			}
+		}
+	}
+	slices.Sort(missingModels)
+	slices.Sort(missingTools)
+	if len(missingModels) > 0 {
+		return nil, errorcodes.Wrap(errorcodes.ModelNotFound, fmt.Errorf("unknown models: %s", strings.Join(slices.Compact(missingModels), ", ")))
+	}
+	if len(missingTools) > 0 {
+		return nil, errorcodes.Wrap(errorcodes.NotFound, fmt.Errorf("unknown tools: %s", strings.Join(slices.Compact(missingTools), ", ")))
+	}
+	return refs, nil
+}
+
+// toSnapshot builds the rows file describes, with each node's position folded
+// back into its config for the dashboard. Model and tool IDs come from refs
+// when it is set.
+func toSnapshot(file *File, refs *references) (*graphs.Snapshot, error) {
+	stateSchema, err := encodeJSONObject(file.StateSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state_schema: %w", err)
+	}
+	snapshot := &graphs.Snapshot{
+		AgentGraph: &dbmodels.AgentGraph{
+			Name:        file.Name,
+			Description: &file.Description,
+			EntryNode:   file.EntryNode,
+			StateSchema: stateSchema,
+		},
+		Nodes: make([]*graphs.SnapshotNode, 0, len(file.Nodes)),
+		Edges: make([]*dbmodels.AgentGraphEdge, 0, len(file.Edges)),
+	}
+
+	for _, node := range file.Nodes {
+		config := make(map[string]any, len(node.Config)+1)
+		for key, value := range node.Config {
+			config[key] = value
+		}
+		if node.Position != nil {
+			config[uiPositionKey] = map[string]any{"x": node.Position.X, "y": node.Position.Y}
+		}
+		encodedConfig, err := json.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config on node %q: %w", node.Key, err)
+		}
+
+		snapshotNode := &graphs.SnapshotNode{
+			Node: &dbmodels.AgentGraphNode{
+				NodeKey:   node.Key,
+				NodeType:  node.Type,
+				InputKey:  optionalString(node.InputKey),
+				OutputKey: optionalString(node.OutputKey),
+				Config:    string(encodedConfig),
+			},
+		}
+		if node.Model != nil {
+			snapshotNode.Model = refs.model(*node.Model)
+			if snapshotNode.Model.ID != "" {
+				snapshotNode.Node.ModelID = &snapshotNode.Model.ID
+			}
+		}
+		for _, name := range node.Tools {
+			snapshotNode.Tools = append(snapshotNode.Tools, refs.tool(name))
+		}
+		snapshot.Nodes = append(snapshot.Nodes, snapshotNode)
+	}
+	for _, edge := range file.Edges {
+		snapshot.Edges = append(snapshot.Edges, &dbmodels.AgentGraphEdge{FromNode: edge.From, ToNode: edge.To})
+	}
+	return snapshot, nil
+}
+
+func findImportTarget(tx *gorm.DB, name string, options ImportOptions) (*dbmodels.AgentGraph, error) {
+	if options.WorkflowID != uuid.Nil {
+		var workflow dbmodels.AgentGraph
+		err := tx.Where("id = ? AND organization_id = ?", options.WorkflowID, options.OrganizationID).Take(&workflow).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcodes.Wrap(errorcodes.NotFound, fmt.Errorf("workflow %s not found", options.WorkflowID))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workflow %s: %w", options.WorkflowID, runerrors.Database(err))
+		}
+		return &workflow, nil
+	}
+
+	var workflows []dbmodels.AgentGraph
+	if err := tx.Where("organization_id = ? AND name = ? AND archived_at IS NULL", options.OrganizationID, name).
+		Limit(2).
+		Find(&workflows).Error; err != nil {
+		return nil, fmt.Errorf("failed to find workflow %q: %w", name, runerrors.Database(err))
+	}
+	switch len(workflows) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &workflows[0], nil
+	default:
+		return nil, fmt.Errorf("more than one workflow is named %q: pass the workflow ID to import into", name)
+	}
+}
+
+// writeSnapshot creates or overwrites the workflow in snapshot. Nodes keep
+// their IDs when their key survives, like a dashboard save, and tools and
+// edges are replaced.
+func writeSnapshot(tx *gorm.DB, snapshot *graphs.Snapshot, create bool) error {
+	workflow := snapshot.AgentGraph
+	if create {
+		if err := tx.Create(workflow).Error; err != nil {
+			return fmt.Errorf("failed to create workflow: %w", runerrors.Database(err))
+		}
+	} else if err := tx.Model(&dbmodels.AgentGraph{}).
+		Where("id = ?", workflow.ID).
+		Updates(map[string]any{
+			"name":         workflow.Name,
+			"description":  workflow.Description,
+			"entry_node":   workflow.EntryNode,
+			"state_schema": workflow.StateSchema,
+			"updated_at":   time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update workflow %s: %w", workflow.ID, runerrors.Database(err))
+	}
+
+	var existing []dbmodels.AgentGraphNode
+	if err := tx.Select("id, node_key").Where("agent_graph_id = ?", workflow.ID).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to load workflow nodes: %w", runerrors.Database(err))
+	}
+	existingIDs := make(map[string]string, len(existing))
+	for _, node := range existing {
+		existingIDs[node.NodeKey] = node.ID
+	}
+
+	nodeIDs := make([]string, 0, len(snapshot.Nodes))
+	for _, snapshotNode := range snapshot.Nodes {
+		node := snapshotNode.Node
+		node.AgentGraphID = workflow.ID
+		if id, ok := existingIDs[node.NodeKey]; ok {
+			node.ID = id
+			delete(existingIDs, node.NodeKey)
+			if err := tx.Model(&dbmodels.AgentGraphNode{}).
+				Where("id = ?", id).
+				Updates(map[string]any{
+					"node_type":  node.NodeType,
+					"input_key":  node.InputKey,
+					"output_key": node.OutputKey,
+					"config":     node.Config,
+					"model_id":   node.ModelID,
+					"updated_at": time.Now(),
+				}).Error; err != nil {
+				return fmt.Errorf("failed to update node %q: %w", node.NodeKey, runerrors.Database(err))
+			}
+		} else {
+			id, err := uuid.NewV7()
+			if err != nil {
+				return fmt.Errorf("failed to generate node id: %w", err)
+			}
+			node.ID = id.String()
+			if err := tx.Create(node).Error; err != nil {
+				return fmt.Errorf("failed to create node %q: %w", node.NodeKey, runerrors.Database(err))
+			}
+		}
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+
+	// Removed nodes take their tool links with them.
+	if len(existingIDs) > 0 {
+		removed := make([]string, 0, len(existingIDs))
+		for _, id := range existingIDs {
+			removed = append(removed, id)
+		}
+		if err := tx.Where("id IN ?", removed).Delete(&dbmodels.AgentGraphNode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete removed nodes: %w", runerrors.Database(err))
+		}
+	}
+
+	if len(nodeIDs) > 0 {
+		if err := tx.Where("agent_graph_node_id IN ?", nodeIDs).Delete(&dbmodels.AgentGraphNodeTool{}).Error; err != nil {
+			return fmt.Errorf("failed to clear node tools: %w", runerrors.Database(err))
+		}
+	}
+	var nodeTools []dbmodels.AgentGraphNodeTool
+	for _, snapshotNode := range snapshot.Nodes {
+		for _, tool := range snapshotNode.Tools {
+			id, err := uuid.NewV7()
+			if err != nil {
+				return fmt.Errorf("failed to generate node tool id: %w", err)
+			}
+			nodeTools = append(nodeTools, dbmodels.AgentGraphNodeTool{
+				ID:               id.String(),
+				AgentGraphNodeID: snapshotNode.Node.ID,
+				ToolID:           tool.ID,
+			})
+		}
+	}
+	if len(nodeTools) > 0 {
+		if err := tx.Create(&nodeTools).Error; err != nil {
+			return fmt.Errorf("failed to create node tools: %w", runerrors.Database(err))
+		}
+	}
+
+	if err := tx.Where("agent_graph_id = ?", workflow.ID).Delete(&dbmodels.AgentGraphEdge{}).Error; err != nil {
+		return fmt.Errorf("failed to clear edges: %w", runerrors.Database(err))
+	}
+	for _, edge := range snapshot.Edges {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("failed to generate edge id: %w", err)
+		}
+		edge.ID = id.String()
+		edge.AgentGraphID = workflow.ID
+	}
+	if len(snapshot.Edges) > 0 {
+		if err := tx.Create(&snapshot.Edges).Error; err != nil {
+			return fmt.Errorf("failed to create edges: %w", runerrors.Database(err))
+		}
+	}
+	return nil
+}
+
+func encodeJSONObject(object map[string]any) (*string, error) {
+	if len(object) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	text := string(encoded)
+	return &text, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
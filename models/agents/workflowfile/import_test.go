@@ -0,0 +1,91 @@
+package workflowfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/agents/graphs"
+	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+)
+
+func testReferences() *references {
+	return &references{
+		models: map[ModelRef]*dbmodels.Model{
+			{Provider: "OPENAI", Name: "gpt-4.1-mini"}: {ID: "model-1", Provider: "OPENAI", Name: "gpt-4.1-mini"},
+		},
+		tools: map[string]*dbmodels.Tool{
+			"create_embedding": {ID: "tool-1", Name: "create_embedding"},
+			"save_description": {ID: "tool-2", Name: "save_description"},
+		},
+	}
+}
+
+func TestToSnapshotRoundTripsThroughFromSnapshot(t *testing.T) {
+	file, err := FromSnapshot(testSnapshot())
+	if err != nil {
+		t.Fatalf("FromSnapshot returned error: %v", err)
+	}
+
+	snapshot, err := toSnapshot(file, testReferences())
+	if err != nil {
+		t.Fatalf("toSnapshot returned error: %v", err)
+	}
+	if err := graphs.ValidateSnapshot(snapshot); err != nil {
+		t.Fatalf("expected a valid snapshot, got %v", err)
+	}
+	describe := snapshot.Nodes[0].Node
+	if describe.ModelID == nil || *describe.ModelID != "model-1" {
+		t.Fatalf("expected model reference to resolve to its ID, got %v", describe.ModelID)
+	}
+	if !strings.Contains(describe.Config, `"uiPosition":{"x":40,"y":80}`) {
+		t.Fatalf("expected position to be stored in config, got %s", describe.Config)
+	}
+	if tools := snapshot.Nodes[1].Tools; len(tools) != 2 || tools[0].ID != "tool-1" {
+		t.Fatalf("unexpected resolved tools: %#v", tools)
+	}
+
+	exported, err := FromSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("FromSnapshot returned error: %v", err)
+	}
+	if !exported.Equal(file) {
+		t.Fatalf("expected export of imported rows to match the file:\n%#v\n%#v", exported, file)
+	}
+}
+
+func TestToSnapshotFailsValidationForDanglingGraphs(t *testing.T) {
+	file := &File{
+		Version:   FormatVersion,
+		Name:      "Broken",
+		EntryNode: "describe",
+		Nodes:     []Node{{Key: "describe", Type: "worker", Model: &ModelRef{Provider: "OPENAI", Name: "gpt-4.1-mini"}}},
+		Edges:     []Edge{{From: "describe", To: "missing"}},
+	}
+
+	snapshot, err := toSnapshot(file, testReferences())
+	if err != nil {
+		t.Fatalf("toSnapshot returned error: %v", err)
+	}
+	if err := graphs.ValidateSnapshot(snapshot); err == nil {
+		t.Fatal("expected an edge to an unknown node to fail validation")
+	}
+}
+
+func TestFileSnapshotNeedsNoDatabase(t *testing.T) {
+	file, err := FromSnapshot(testSnapshot())
+	if err != nil {
+		t.Fatalf("FromSnapshot returned error: %v", err)
+	}
+
+	snapshot, err := file.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+	describe := snapshot.Nodes[0]
+	if describe.Model == nil || describe.Model.Name != "gpt-4.1-mini" || describe.Node.ModelID != nil {
+		t.Fatalf("expected an unresolved model reference, got %#v and %v", describe.Model, describe.Node.ModelID)
+	}
+	if tools := snapshot.Nodes[1].Tools; len(tools) != 2 || tools[1].Name != "save_description" || tools[1].ID != "" {
+		t.Fatalf("unexpected tools: %#v", tools)
+	}
+}
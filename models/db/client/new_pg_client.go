@@ -45,6 +45,10 @@ func NewPGClient() (*gorm.DB, error) {
 		})
 		if pgClientErr != nil {
 			pgClientErr = fmt.Errorf("failed to connect database: %w", pgClientErr)
+			return
+		}
+		if err := registerTracing(pgClient); err != nil {
+			pgClientErr = fmt.Errorf("failed to register database tracing: %w", err)
 		}
 	})
 
@@ -19,6 +19,7 @@ type AgentGraphRunStep struct {
 	StateDelta *string    `gorm:"column:state_delta;type:jsonb" json:"state_delta"`
 	StartedAt  time.Time  `gorm:"column:started_at;type:timestamp without time zone;not null;default:now()" json:"started_at"`
 	FinishedAt *time.Time `gorm:"column:finished_at;type:timestamp without time zone" json:"finished_at"`
+	ErrorCode  *string    `gorm:"column:error_code;type:text" json:"error_code"`
 }
 
 // TableName AgentGraphRunStep's table name
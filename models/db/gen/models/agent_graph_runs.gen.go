@@ -27,6 +27,14 @@ type AgentGraphRun struct {
 	IdempotencyResponse    *string    `gorm:"column:idempotency_response;type:jsonb" json:"idempotency_response"`
 	GraphSnapshot          *string    `gorm:"column:graph_snapshot;type:jsonb" json:"graph_snapshot"`
 	GraphSnapshotHash      *string    `gorm:"column:graph_snapshot_hash;type:text" json:"graph_snapshot_hash"`
+	Attempts               *string    `gorm:"column:attempts;type:jsonb" json:"attempts"`
+	ErrorCode              *string    `gorm:"column:error_code;type:text" json:"error_code"`
+	PickedUpAt             *time.Time `gorm:"column:picked_up_at;type:timestamp without time zone" json:"picked_up_at"`
+	BackfillID             *string    `gorm:"column:backfill_id;type:uuid" json:"backfill_id"`
+	ScheduleID             *string    `gorm:"column:schedule_id;type:uuid" json:"schedule_id"`
+	ParentRunID            *string    `gorm:"column:parent_run_id;type:uuid" json:"parent_run_id"`
+	TriggerID              *string    `gorm:"column:trigger_id;type:uuid" json:"trigger_id"`
+	ChainDepth             int32      `gorm:"column:chain_depth;type:integer;not null;default:0" json:"chain_depth"`
 }
 
 // TableName AgentGraphRun's table name
@@ -0,0 +1,28 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+const TableNameJobDeadLetter = "job_dead_letters"
+
+// JobDeadLetter mapped from table <job_dead_letters>
+type JobDeadLetter struct {
+	ID         string    `gorm:"column:id;type:uuid;primaryKey" json:"id"`
+	Event      string    `gorm:"column:event;type:text;not null" json:"event"`
+	EventID    *string   `gorm:"column:event_id;type:text" json:"event_id"`
+	Data       string    `gorm:"column:data;type:jsonb;not null" json:"data"`
+	Attempts   int32     `gorm:"column:attempts;type:integer;not null" json:"attempts"`
+	Error      string    `gorm:"column:error;type:text;not null" json:"error"`
+	EnqueuedAt time.Time `gorm:"column:enqueued_at;type:timestamp without time zone;not null" json:"enqueued_at"`
+	FailedAt   time.Time `gorm:"column:failed_at;type:timestamp without time zone;not null;default:now()" json:"failed_at"`
+}
+
+// TableName JobDeadLetter's table name
+func (*JobDeadLetter) TableName() string {
+	return TableNameJobDeadLetter
+}
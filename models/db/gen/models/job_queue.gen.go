@@ -0,0 +1,30 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+const TableNameJobQueue = "job_queue"
+
+// JobQueue mapped from table <job_queue>
+type JobQueue struct {
+	ID          string     `gorm:"column:id;type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	Event       string     `gorm:"column:event;type:text;not null" json:"event"`
+	EventID     *string    `gorm:"column:event_id;type:text" json:"event_id"`
+	Data        string     `gorm:"column:data;type:jsonb;not null" json:"data"`
+	Attempts    int32      `gorm:"column:attempts;type:integer;not null;default:0" json:"attempts"`
+	RunAt       time.Time  `gorm:"column:run_at;type:timestamp without time zone;not null;default:now()" json:"run_at"`
+	LockedUntil *time.Time `gorm:"column:locked_until;type:timestamp without time zone" json:"locked_until"`
+	LastError   *string    `gorm:"column:last_error;type:text" json:"last_error"`
+	CreatedAt   time.Time  `gorm:"column:created_at;type:timestamp without time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;type:timestamp without time zone;not null;default:now()" json:"updated_at"`
+}
+
+// TableName JobQueue's table name
+func (*JobQueue) TableName() string {
+	return TableNameJobQueue
+}
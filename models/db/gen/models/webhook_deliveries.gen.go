@@ -0,0 +1,31 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+const TableNameWebhookDelivery = "webhook_deliveries"
+
+// WebhookDelivery mapped from table <webhook_deliveries>
+type WebhookDelivery struct {
+	ID             string     `gorm:"column:id;type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	SubscriptionID string     `gorm:"column:subscription_id;type:uuid;not null" json:"subscription_id"`
+	RunID          string     `gorm:"column:run_id;type:uuid;not null" json:"run_id"`
+	Status         string     `gorm:"column:status;type:text;not null;default:pending" json:"status"`
+	Payload        string     `gorm:"column:payload;type:jsonb;not null" json:"payload"`
+	Attempts       int32      `gorm:"column:attempts;type:integer;not null;default:0" json:"attempts"`
+	LastStatusCode *int32     `gorm:"column:last_status_code;type:integer" json:"last_status_code"`
+	LastError      *string    `gorm:"column:last_error;type:text" json:"last_error"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at;type:timestamp without time zone" json:"delivered_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp without time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;type:timestamp without time zone;not null;default:now()" json:"updated_at"`
+}
+
+// TableName WebhookDelivery's table name
+func (*WebhookDelivery) TableName() string {
+	return TableNameWebhookDelivery
+}
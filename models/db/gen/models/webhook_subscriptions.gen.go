@@ -0,0 +1,31 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+const TableNameWebhookSubscription = "webhook_subscriptions"
+
+// WebhookSubscription mapped from table <webhook_subscriptions>
+type WebhookSubscription struct {
+	ID             string    `gorm:"column:id;type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	OrganizationID string    `gorm:"column:organization_id;type:uuid;not null" json:"organization_id"`
+	ProjectID      string    `gorm:"column:project_id;type:uuid;not null" json:"project_id"`
+	APIKeyID       *string   `gorm:"column:api_key_id;type:uuid" json:"api_key_id"`
+	URL            string    `gorm:"column:url;type:text;not null" json:"url"`
+	Secret         string    `gorm:"column:secret;type:text;not null" json:"secret"`
+	Statuses       string    `gorm:"column:statuses;type:jsonb;not null;default:["completed","failed","cancelled"]" json:"statuses"`
+	StateKeys      string    `gorm:"column:state_keys;type:jsonb;not null;default:[]" json:"state_keys"`
+	Enabled        bool      `gorm:"column:enabled;type:boolean;not null;default:true" json:"enabled"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp without time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamp without time zone;not null;default:now()" json:"updated_at"`
+}
+
+// TableName WebhookSubscription's table name
+func (*WebhookSubscription) TableName() string {
+	return TableNameWebhookSubscription
+}
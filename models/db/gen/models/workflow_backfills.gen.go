@@ -0,0 +1,37 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+const TableNameWorkflowBackfill = "workflow_backfills"
+
+// WorkflowBackfill mapped from table <workflow_backfills>
+type WorkflowBackfill struct {
+	ID             string     `gorm:"column:id;type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	OrganizationID string     `gorm:"column:organization_id;type:uuid;not null" json:"organization_id"`
+	AgentGraphID   string     `gorm:"column:agent_graph_id;type:uuid;not null" json:"agent_graph_id"`
+	APIKeyID       *string    `gorm:"column:api_key_id;type:uuid" json:"api_key_id"`
+	Scope          string     `gorm:"column:scope;type:jsonb;not null" json:"scope"`
+	Options        string     `gorm:"column:options;type:jsonb;not null" json:"options"`
+	Status         string     `gorm:"column:status;type:text;not null;default:running" json:"status"`
+	Total          *int32     `gorm:"column:total;type:integer" json:"total"`
+	Enqueued       int32      `gorm:"column:enqueued;type:integer;not null;default:0" json:"enqueued"`
+	Completed      int32      `gorm:"column:completed;type:integer;not null;default:0" json:"completed"`
+	Failed         int32      `gorm:"column:failed;type:integer;not null;default:0" json:"failed"`
+	Cursor         *string    `gorm:"column:cursor;type:uuid" json:"cursor"`
+	Error          *string    `gorm:"column:error;type:text" json:"error"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp without time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;type:timestamp without time zone;not null;default:now()" json:"updated_at"`
+	FinishedAt     *time.Time `gorm:"column:finished_at;type:timestamp without time zone" json:"finished_at"`
+	Generation     int32      `gorm:"column:generation;type:integer;not null;default:0" json:"generation"`
+}
+
+// TableName WorkflowBackfill's table name
+func (*WorkflowBackfill) TableName() string {
+	return TableNameWorkflowBackfill
+}
@@ -0,0 +1,36 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+const TableNameWorkflowSchedule = "workflow_schedules"
+
+// WorkflowSchedule mapped from table <workflow_schedules>
+type WorkflowSchedule struct {
+	ID             string     `gorm:"column:id;type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	OrganizationID string     `gorm:"column:organization_id;type:uuid;not null" json:"organization_id"`
+	ProjectID      string     `gorm:"column:project_id;type:uuid;not null" json:"project_id"`
+	AgentGraphID   string     `gorm:"column:agent_graph_id;type:uuid;not null" json:"agent_graph_id"`
+	APIKeyID       *string    `gorm:"column:api_key_id;type:uuid" json:"api_key_id"`
+	Name           string     `gorm:"column:name;type:text;not null" json:"name"`
+	CronExpression string     `gorm:"column:cron_expression;type:text;not null" json:"cron_expression"`
+	Timezone       string     `gorm:"column:timezone;type:text;not null;default:UTC" json:"timezone"`
+	Scope          string     `gorm:"column:scope;type:jsonb;not null" json:"scope"`
+	InitialState   *string    `gorm:"column:initial_state;type:jsonb" json:"initial_state"`
+	Enabled        bool       `gorm:"column:enabled;type:boolean;not null;default:true" json:"enabled"`
+	NextFireAt     *time.Time `gorm:"column:next_fire_at;type:timestamp without time zone" json:"next_fire_at"`
+	LastFiredAt    *time.Time `gorm:"column:last_fired_at;type:timestamp without time zone" json:"last_fired_at"`
+	LastError      *string    `gorm:"column:last_error;type:text" json:"last_error"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp without time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;type:timestamp without time zone;not null;default:now()" json:"updated_at"`
+}
+
+// TableName WorkflowSchedule's table name
+func (*WorkflowSchedule) TableName() string {
+	return TableNameWorkflowSchedule
+}
@@ -0,0 +1,31 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+const TableNameWorkflowTrigger = "workflow_triggers"
+
+// WorkflowTrigger mapped from table <workflow_triggers>
+type WorkflowTrigger struct {
+	ID                 string    `gorm:"column:id;type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	OrganizationID     string    `gorm:"column:organization_id;type:uuid;not null" json:"organization_id"`
+	AgentGraphID       string    `gorm:"column:agent_graph_id;type:uuid;not null" json:"agent_graph_id"`
+	TargetAgentGraphID string    `gorm:"column:target_agent_graph_id;type:uuid;not null" json:"target_agent_graph_id"`
+	Name               string    `gorm:"column:name;type:text;not null" json:"name"`
+	OnStatus           string    `gorm:"column:on_status;type:text;not null;default:completed" json:"on_status"`
+	Condition          *string   `gorm:"column:condition;type:jsonb" json:"condition"`
+	PassKeys           string    `gorm:"column:pass_keys;type:jsonb;not null;default:[]" json:"pass_keys"`
+	Enabled            bool      `gorm:"column:enabled;type:boolean;not null;default:true" json:"enabled"`
+	CreatedAt          time.Time `gorm:"column:created_at;type:timestamp without time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;type:timestamp without time zone;not null;default:now()" json:"updated_at"`
+}
+
+// TableName WorkflowTrigger's table name
+func (*WorkflowTrigger) TableName() string {
+	return TableNameWorkflowTrigger
+}
@@ -34,6 +34,7 @@ func newAgentGraphRunStep(db *gorm.DB, opts ...gen.DOOption) agentGraphRunStep {
 	_agentGraphRunStep.StateDelta = field.NewString(tableName, "state_delta")
 	_agentGraphRunStep.StartedAt = field.NewTime(tableName, "started_at")
 	_agentGraphRunStep.FinishedAt = field.NewTime(tableName, "finished_at")
+	_agentGraphRunStep.ErrorCode = field.NewString(tableName, "error_code")
 
 	_agentGraphRunStep.fillFieldMap()
 
@@ -51,6 +52,7 @@ type agentGraphRunStep struct {
 	StateDelta field.String
 	StartedAt  field.Time
 	FinishedAt field.Time
+	ErrorCode  field.String
 
 	fieldMap map[string]field.Expr
 }
@@ -74,6 +76,7 @@ func (a *agentGraphRunStep) updateTableName(table string) *agentGraphRunStep {
 	a.StateDelta = field.NewString(table, "state_delta")
 	a.StartedAt = field.NewTime(table, "started_at")
 	a.FinishedAt = field.NewTime(table, "finished_at")
+	a.ErrorCode = field.NewString(table, "error_code")
 
 	a.fillFieldMap()
 
@@ -102,7 +105,7 @@ func (a *agentGraphRunStep) GetFieldByName(fieldName string) (field.OrderExpr, b
 }
 
 func (a *agentGraphRunStep) fillFieldMap() {
-	a.fieldMap = make(map[string]field.Expr, 7)
+	a.fieldMap = make(map[string]field.Expr, 8)
 	a.fieldMap["id"] = a.ID
 	a.fieldMap["run_id"] = a.RunID
 	a.fieldMap["node_key"] = a.NodeKey
@@ -110,6 +113,7 @@ func (a *agentGraphRunStep) fillFieldMap() {
 	a.fieldMap["state_delta"] = a.StateDelta
 	a.fieldMap["started_at"] = a.StartedAt
 	a.fieldMap["finished_at"] = a.FinishedAt
+	a.fieldMap["error_code"] = a.ErrorCode
 }
 
 func (a agentGraphRunStep) clone(db *gorm.DB) agentGraphRunStep {
@@ -42,6 +42,14 @@ func newAgentGraphRun(db *gorm.DB, opts ...gen.DOOption) agentGraphRun {
 	_agentGraphRun.IdempotencyResponse = field.NewString(tableName, "idempotency_response")
 	_agentGraphRun.GraphSnapshot = field.NewString(tableName, "graph_snapshot")
 	_agentGraphRun.GraphSnapshotHash = field.NewString(tableName, "graph_snapshot_hash")
+	_agentGraphRun.Attempts = field.NewString(tableName, "attempts")
+	_agentGraphRun.ErrorCode = field.NewString(tableName, "error_code")
+	_agentGraphRun.PickedUpAt = field.NewTime(tableName, "picked_up_at")
+	_agentGraphRun.BackfillID = field.NewString(tableName, "backfill_id")
+	_agentGraphRun.ScheduleID = field.NewString(tableName, "schedule_id")
+	_agentGraphRun.ParentRunID = field.NewString(tableName, "parent_run_id")
+	_agentGraphRun.TriggerID = field.NewString(tableName, "trigger_id")
+	_agentGraphRun.ChainDepth = field.NewInt32(tableName, "chain_depth")
 
 	_agentGraphRun.fillFieldMap()
 
@@ -67,6 +75,14 @@ type agentGraphRun struct {
 	IdempotencyResponse    field.String
 	GraphSnapshot          field.String
 	GraphSnapshotHash      field.String
+	Attempts               field.String
+	ErrorCode              field.String
+	PickedUpAt             field.Time
+	BackfillID             field.String
+	ScheduleID             field.String
+	ParentRunID            field.String
+	TriggerID              field.String
+	ChainDepth             field.Int32
 
 	fieldMap map[string]field.Expr
 }
@@ -98,6 +114,14 @@ func (a *agentGraphRun) updateTableName(table string) *agentGraphRun {
 	a.IdempotencyResponse = field.NewString(table, "idempotency_response")
 	a.GraphSnapshot = field.NewString(table, "graph_snapshot")
 	a.GraphSnapshotHash = field.NewString(table, "graph_snapshot_hash")
+	a.Attempts = field.NewString(table, "attempts")
+	a.ErrorCode = field.NewString(table, "error_code")
+	a.PickedUpAt = field.NewTime(table, "picked_up_at")
+	a.BackfillID = field.NewString(table, "backfill_id")
+	a.ScheduleID = field.NewString(table, "schedule_id")
+	a.ParentRunID = field.NewString(table, "parent_run_id")
+	a.TriggerID = field.NewString(table, "trigger_id")
+	a.ChainDepth = field.NewInt32(table, "chain_depth")
 
 	a.fillFieldMap()
 
@@ -126,7 +150,7 @@ func (a *agentGraphRun) GetFieldByName(fieldName string) (field.OrderExpr, bool)
 }
 
 func (a *agentGraphRun) fillFieldMap() {
-	a.fieldMap = make(map[string]field.Expr, 15)
+	a.fieldMap = make(map[string]field.Expr, 23)
 	a.fieldMap["id"] = a.ID
 	a.fieldMap["agent_graph_id"] = a.AgentGraphID
 	a.fieldMap["status"] = a.Status
@@ -142,6 +166,14 @@ func (a *agentGraphRun) fillFieldMap() {
 	a.fieldMap["idempotency_response"] = a.IdempotencyResponse
 	a.fieldMap["graph_snapshot"] = a.GraphSnapshot
 	a.fieldMap["graph_snapshot_hash"] = a.GraphSnapshotHash
+	a.fieldMap["attempts"] = a.Attempts
+	a.fieldMap["error_code"] = a.ErrorCode
+	a.fieldMap["picked_up_at"] = a.PickedUpAt
+	a.fieldMap["backfill_id"] = a.BackfillID
+	a.fieldMap["schedule_id"] = a.ScheduleID
+	a.fieldMap["parent_run_id"] = a.ParentRunID
+	a.fieldMap["trigger_id"] = a.TriggerID
+	a.fieldMap["chain_depth"] = a.ChainDepth
 }
 
 func (a agentGraphRun) clone(db *gorm.DB) agentGraphRun {
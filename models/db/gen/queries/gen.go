@@ -35,6 +35,8 @@ func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 		DocumentOcrResult:            newDocumentOcrResult(db, opts...),
 		DocumentSegmentation:         newDocumentSegmentation(db, opts...),
 		Invitation:                   newInvitation(db, opts...),
+		JobDeadLetter:                newJobDeadLetter(db, opts...),
+		JobQueue:                     newJobQueue(db, opts...),
 		Member:                       newMember(db, opts...),
 		Model:                        newModel(db, opts...),
 		Organization:                 newOrganization(db, opts...),
@@ -44,6 +46,11 @@ func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 		Tool:                         newTool(db, opts...),
 		User:                         newUser(db, opts...),
 		Verification:                 newVerification(db, opts...),
+		WebhookDelivery:              newWebhookDelivery(db, opts...),
+		WebhookSubscription:          newWebhookSubscription(db, opts...),
+		WorkflowBackfill:             newWorkflowBackfill(db, opts...),
+		WorkflowSchedule:             newWorkflowSchedule(db, opts...),
+		WorkflowTrigger:              newWorkflowTrigger(db, opts...),
 	}
 }
 
@@ -67,6 +74,8 @@ type Query struct {
 	DocumentOcrResult            documentOcrResult
 	DocumentSegmentation         documentSegmentation
 	Invitation                   invitation
+	JobDeadLetter                jobDeadLetter
+	JobQueue                     jobQueue
 	Member                       member
 	Model                        model
 	Organization                 organization
@@ -76,6 +85,11 @@ type Query struct {
 	Tool                         tool
 	User                         user
 	Verification                 verification
+	WebhookDelivery              webhookDelivery
+	WebhookSubscription          webhookSubscription
+	WorkflowBackfill             workflowBackfill
+	WorkflowSchedule             workflowSchedule
+	WorkflowTrigger              workflowTrigger
 }
 
 func (q *Query) Available() bool { return q.db != nil }
@@ -100,6 +114,8 @@ func (q *Query) clone(db *gorm.DB) *Query {
 		DocumentOcrResult:            q.DocumentOcrResult.clone(db),
 		DocumentSegmentation:         q.DocumentSegmentation.clone(db),
 		Invitation:                   q.Invitation.clone(db),
+		JobDeadLetter:                q.JobDeadLetter.clone(db),
+		JobQueue:                     q.JobQueue.clone(db),
 		Member:                       q.Member.clone(db),
 		Model:                        q.Model.clone(db),
 		Organization:                 q.Organization.clone(db),
@@ -109,6 +125,11 @@ func (q *Query) clone(db *gorm.DB) *Query {
 		Tool:                         q.Tool.clone(db),
 		User:                         q.User.clone(db),
 		Verification:                 q.Verification.clone(db),
+		WebhookDelivery:              q.WebhookDelivery.clone(db),
+		WebhookSubscription:          q.WebhookSubscription.clone(db),
+		WorkflowBackfill:             q.WorkflowBackfill.clone(db),
+		WorkflowSchedule:             q.WorkflowSchedule.clone(db),
+		WorkflowTrigger:              q.WorkflowTrigger.clone(db),
 	}
 }
 
@@ -140,6 +161,8 @@ func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 		DocumentOcrResult:            q.DocumentOcrResult.replaceDB(db),
 		DocumentSegmentation:         q.DocumentSegmentation.replaceDB(db),
 		Invitation:                   q.Invitation.replaceDB(db),
+		JobDeadLetter:                q.JobDeadLetter.replaceDB(db),
+		JobQueue:                     q.JobQueue.replaceDB(db),
 		Member:                       q.Member.replaceDB(db),
 		Model:                        q.Model.replaceDB(db),
 		Organization:                 q.Organization.replaceDB(db),
@@ -149,6 +172,11 @@ func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 		Tool:                         q.Tool.replaceDB(db),
 		User:                         q.User.replaceDB(db),
 		Verification:                 q.Verification.replaceDB(db),
+		WebhookDelivery:              q.WebhookDelivery.replaceDB(db),
+		WebhookSubscription:          q.WebhookSubscription.replaceDB(db),
+		WorkflowBackfill:             q.WorkflowBackfill.replaceDB(db),
+		WorkflowSchedule:             q.WorkflowSchedule.replaceDB(db),
+		WorkflowTrigger:              q.WorkflowTrigger.replaceDB(db),
 	}
 }
 
@@ -170,6 +198,8 @@ type queryCtx struct {
 	DocumentOcrResult            *documentOcrResultDo
 	DocumentSegmentation         *documentSegmentationDo
 	Invitation                   *invitationDo
+	JobDeadLetter                *jobDeadLetterDo
+	JobQueue                     *jobQueueDo
 	Member                       *memberDo
 	Model                        *modelDo
 	Organization                 *organizationDo
@@ -179,6 +209,11 @@ type queryCtx struct {
 	Tool                         *toolDo
 	User                         *userDo
 	Verification                 *verificationDo
+	WebhookDelivery              *webhookDeliveryDo
+	WebhookSubscription          *webhookSubscriptionDo
+	WorkflowBackfill             *workflowBackfillDo
+	WorkflowSchedule             *workflowScheduleDo
+	WorkflowTrigger              *workflowTriggerDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
@@ -200,6 +235,8 @@ func (q *Query) WithContext(ctx context.Context) *queryCtx {
 		DocumentOcrResult:            q.DocumentOcrResult.WithContext(ctx),
 		DocumentSegmentation:         q.DocumentSegmentation.WithContext(ctx),
 		Invitation:                   q.Invitation.WithContext(ctx),
+		JobDeadLetter:                q.JobDeadLetter.WithContext(ctx),
+		JobQueue:                     q.JobQueue.WithContext(ctx),
 		Member:                       q.Member.WithContext(ctx),
 		Model:                        q.Model.WithContext(ctx),
 		Organization:                 q.Organization.WithContext(ctx),
@@ -209,6 +246,11 @@ func (q *Query) WithContext(ctx context.Context) *queryCtx {
 		Tool:                         q.Tool.WithContext(ctx),
 		User:                         q.User.WithContext(ctx),
 		Verification:                 q.Verification.WithContext(ctx),
+		WebhookDelivery:              q.WebhookDelivery.WithContext(ctx),
+		WebhookSubscription:          q.WebhookSubscription.WithContext(ctx),
+		WorkflowBackfill:             q.WorkflowBackfill.WithContext(ctx),
+		WorkflowSchedule:             q.WorkflowSchedule.WithContext(ctx),
+		WorkflowTrigger:              q.WorkflowTrigger.WithContext(ctx),
 	}
 }
 
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
@@ -66,12 +66,11 @@ func (t *replicateDiagnosticTransport) RoundTrip(req *http.Request) (*http.Respo
 		readErr = closeErr
 	}
 	if readErr != nil {
-		log.Printf(
-			"replicate response inspect failed method=%s path=%s status=%d err=%v",
-			req.Method,
-			req.URL.Path,
-			resp.StatusCode,
-			readErr,
+		slog.ErrorContext(req.Context(), "replicate response inspect failed",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", resp.StatusCode,
+			"err", readErr,
 		)
 		resp.Body = io.NopCloser(bytes.NewReader(nil))
 		resp.ContentLength = 0
@@ -85,13 +84,12 @@ func (t *replicateDiagnosticTransport) RoundTrip(req *http.Request) (*http.Respo
 
 	preview := responsePreview(body)
 	if resp.StatusCode >= 400 {
-		log.Printf(
-			"replicate error response method=%s path=%s status=%d content_type=%q body_preview=%q",
-			req.Method,
-			req.URL.Path,
-			resp.StatusCode,
-			contentType,
-			preview,
+		slog.ErrorContext(req.Context(), "replicate error response",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", resp.StatusCode,
+			"content_type", contentType,
+			"body_preview", preview,
 		)
 
 		if !looksLikeJSONBody(body) {
@@ -108,13 +106,12 @@ func (t *replicateDiagnosticTransport) RoundTrip(req *http.Request) (*http.Respo
 			}
 		}
 	} else if !strings.Contains(contentType, "application/json") {
-		log.Printf(
-			"replicate non-json success response method=%s path=%s status=%d content_type=%q body_preview=%q",
-			req.Method,
-			req.URL.Path,
-			resp.StatusCode,
-			contentType,
-			preview,
+		slog.WarnContext(req.Context(), "replicate non-json success response",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", resp.StatusCode,
+			"content_type", contentType,
+			"body_preview", preview,
 		)
 	}
 
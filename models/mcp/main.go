@@ -1,13 +1,15 @@
 package main
 
 import (
-	"log"
+	"log/slog"
+	"os"
 
 	"github.com/arcnem-ai/arcnem-vision/models/mcp/server"
 )
 
 func main() {
 	if err := server.StartServer(); err != nil {
-		log.Fatal(err)
+		slog.Error("server stopped", "err", err)
+		os.Exit(1)
 	}
 }
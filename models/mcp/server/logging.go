@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// logToolCalls restores the caller's correlation fields from the request
+// _meta, so tool logs carry the run ID of the agent run that made the call,
+// and logs one record per tool call.
+func logToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		callRequest, ok := req.(*mcp.CallToolRequest)
+		if !ok || callRequest.Params == nil {
+			return next(ctx, method, req)
+		}
+		ctx = logging.ContextFromMeta(ctx, callRequest.Params.Meta)
+
+		startedAt := time.Now()
+		result, err := next(ctx, method, req)
+		attrs := []any{
+			"tool", callRequest.Params.Name,
+			"duration_ms", time.Since(startedAt).Milliseconds(),
+		}
+		switch toolResult, _ := result.(*mcp.CallToolResult); {
+		case err != nil:
+			slog.WarnContext(ctx, "mcp tool call failed", append(attrs, "err", err)...)
+		case toolResult != nil && toolResult.IsError:
+			slog.WarnContext(ctx, "mcp tool call returned error", attrs...)
+		default:
+			slog.InfoContext(ctx, "mcp tool call", attrs...)
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolCallsCarryTheCallersRunID(t *testing.T) {
+	var runID string
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	server.AddReceivingMiddleware(logToolCalls)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, _ *mcp.CallToolRequest, input echoInput) (*mcp.CallToolResult, echoOutput, error) {
+		runID = logging.RunID(ctx)
+		return nil, echoOutput(input), nil
+	})
+	httpServer := httptest.NewServer(newStreamableHTTPHandler(server))
+	t.Cleanup(httpServer.Close)
+
+	ctx := logging.WithRunID(context.Background(), "run-1")
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      logging.Meta(ctx),
+		Name:      "echo",
+		Arguments: map[string]any{"text": "hi"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if runID != "run-1" {
+		t.Fatalf("expected the tool to see run_id run-1, got %q", runID)
+	}
+}
@@ -1,13 +1,17 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 
 	"github.com/arcnem-ai/arcnem-vision/models/mcp/tools"
 	"github.com/arcnem-ai/arcnem-vision/models/shared/env"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/logging"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -59,8 +63,22 @@ func StartServer() error {
 	if serverVersion == "" {
 		return fmt.Errorf("MCP_SERVER_VERSION not set")
 	}
+	if err := logging.Setup(serverName); err != nil {
+		return err
+	}
+
+	shutdownTracing, err := telemetry.Setup(context.Background(), serverName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("trace shutdown failed", "err", err)
+		}
+	}()
 
 	server := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: serverVersion}, nil)
+	server.AddReceivingMiddleware(traceToolCalls, logToolCalls)
 	if err := registerTools(server); err != nil {
 		return err
 	}
@@ -71,6 +89,7 @@ func StartServer() error {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
+	mux.Handle("/metrics", metrics.Handler())
 	mux.Handle("/", newStreamableHTTPHandler(server))
 
 	port := os.Getenv("PORT")
@@ -78,7 +97,7 @@ func StartServer() error {
 		port = "3021"
 	}
 	addr := ":" + port
-	log.Printf("MCP streamable HTTP server listening on %s", addr)
+	slog.Info("MCP streamable HTTP server listening", "addr", addr)
 
 	return http.ListenAndServe(addr, mux)
 }
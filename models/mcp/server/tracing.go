@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = telemetry.Tracer("github.com/arcnem-ai/arcnem-vision/models/mcp/server")
+
+// traceToolCalls records a server span for every tool call. The span joins
+// the trace carried by the HTTP request, so it nests under the agent's
+// mcp.call_tool span.
+func traceToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
+		callRequest, ok := req.(*mcp.CallToolRequest)
+		if !ok || callRequest.Params == nil {
+			return next(ctx, method, req)
+		}
+		if extra := req.GetExtra(); extra != nil {
+			ctx = telemetry.ContextFromHeader(ctx, extra.Header)
+		}
+
+		ctx, span := tracer.Start(ctx, "mcp.tool "+callRequest.Params.Name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("mcp.tool.name", callRequest.Params.Name),
+		))
+		defer func() {
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && err == nil && toolResult.IsError {
+				span.SetAttributes(attribute.Bool("mcp.tool.is_error", true))
+			}
+			telemetry.EndSpan(span, err)
+		}()
+
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/telemetry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+type echoOutput struct {
+	Text string `json:"text"`
+}
+
+func TestToolCallSpansJoinTheCallersTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var toolSpan trace.SpanContext
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	server.AddReceivingMiddleware(traceToolCalls)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, _ *mcp.CallToolRequest, input echoInput) (*mcp.CallToolResult, echoOutput, error) {
+		toolSpan = trace.SpanContextFromContext(ctx)
+		return nil, echoOutput(input), nil
+	})
+	httpServer := httptest.NewServer(newStreamableHTTPHandler(server))
+	t.Cleanup(httpServer.Close)
+
+	ctx, callerSpan := otel.Tracer("test").Start(context.Background(), "mcp.call_tool echo")
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: &http.Client{Transport: telemetry.NewTransport(nil)},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"text": "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+	callerSpan.End()
+
+	var serverSpan *tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "mcp.tool echo" {
+			serverSpan = &span
+		}
+	}
+	if serverSpan == nil {
+		t.Fatalf("expected an mcp.tool span, got %d spans", len(exporter.GetSpans()))
+	}
+	if serverSpan.Parent.SpanID() != callerSpan.SpanContext().SpanID() || serverSpan.SpanContext.TraceID() != callerSpan.SpanContext().TraceID() {
+		t.Fatal("expected the tool span to be a child of the caller's span")
+	}
+	if toolSpan.SpanID() != serverSpan.SpanContext.SpanID() {
+		t.Fatal("expected the tool handler to run inside the tool span")
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	dbclient "github.com/arcnem-ai/arcnem-vision/models/db/client"
@@ -19,7 +19,7 @@ func RegisterCreateDescriptionEmbedding(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "create_description_embedding",
 		Description: "Generate a CLIP text embedding for a document description and save it to the database.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input CreateDescriptionEmbeddingInput) (*mcp.CallToolResult, CreateDescriptionEmbeddingOutput, error) {
@@ -56,18 +56,16 @@ func RegisterCreateDescriptionEmbedding(server *mcp.Server) error {
 			)
 		}
 		if usedText != input.Text {
-			log.Printf(
-				"replicate clip text normalized document_description_id=%s original_len=%d used_len=%d",
-				input.DocumentDescriptionID,
-				len(input.Text),
-				len(usedText),
+			slog.InfoContext(ctx, "replicate clip text normalized",
+				"document_description_id", input.DocumentDescriptionID,
+				"original_len", len(input.Text),
+				"used_len", len(usedText),
 			)
 		}
-		log.Printf(
-			"replicate clip text success document_description_id=%s run_duration_ms=%d total_duration_ms=%d",
-			input.DocumentDescriptionID,
-			runDuration.Milliseconds(),
-			time.Since(startedAt).Milliseconds(),
+		slog.InfoContext(ctx, "replicate clip text success",
+			"document_description_id", input.DocumentDescriptionID,
+			"run_duration_ms", runDuration.Milliseconds(),
+			"total_duration_ms", time.Since(startedAt).Milliseconds(),
 		)
 
 		embedding, err := parseEmbedding(output)
@@ -17,7 +17,7 @@ func RegisterCreateDocumentDescription(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "create_document_description",
 		Description: "Save an LLM-generated text description for a document.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input CreateDocumentDescriptionInput) (*mcp.CallToolResult, CreateDocumentDescriptionOutput, error) {
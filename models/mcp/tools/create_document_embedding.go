@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	dbclient "github.com/arcnem-ai/arcnem-vision/models/db/client"
@@ -31,7 +31,7 @@ func RegisterCreateDocumentEmbedding(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "create_document_embedding",
 		Description: "Generate a CLIP embedding for a document image and save it to the database.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input CreateDocumentEmbeddingInput) (*mcp.CallToolResult, CreateDocumentEmbeddingOutput, error) {
@@ -59,18 +59,17 @@ func RegisterCreateDocumentEmbedding(server *mcp.Server) error {
 				err,
 			)
 		}
-		log.Printf(
-			"replicate clip image optimized document_id=%s original_bytes=%d final_bytes=%d original_size=%dx%d final_size=%dx%d reencoded=%t original_content_type=%q prepare_duration_ms=%d",
-			input.DocumentID,
-			preparedImage.OriginalBytes,
-			preparedImage.FinalBytes,
-			preparedImage.OriginalWidth,
-			preparedImage.OriginalHeight,
-			preparedImage.FinalWidth,
-			preparedImage.FinalHeight,
-			preparedImage.Reencoded,
-			preparedImage.OriginalContentType,
-			prepareDuration.Milliseconds(),
+		slog.InfoContext(ctx, "replicate clip image optimized",
+			"document_id", input.DocumentID,
+			"original_bytes", preparedImage.OriginalBytes,
+			"final_bytes", preparedImage.FinalBytes,
+			"original_width", preparedImage.OriginalWidth,
+			"original_height", preparedImage.OriginalHeight,
+			"final_width", preparedImage.FinalWidth,
+			"final_height", preparedImage.FinalHeight,
+			"reencoded", preparedImage.Reencoded,
+			"original_content_type", preparedImage.OriginalContentType,
+			"prepare_duration_ms", prepareDuration.Milliseconds(),
 		)
 
 		uploadStartedAt := time.Now()
@@ -95,18 +94,21 @@ func RegisterCreateDocumentEmbedding(server *mcp.Server) error {
 				input.DocumentID,
 			)
 		}
-		log.Printf(
-			"replicate clip image upload document_id=%s file_id=%s upload_duration_ms=%d",
-			input.DocumentID,
-			replicateFile.ID,
-			uploadDuration.Milliseconds(),
+		slog.InfoContext(ctx, "replicate clip image upload",
+			"document_id", input.DocumentID,
+			"file_id", replicateFile.ID,
+			"upload_duration_ms", uploadDuration.Milliseconds(),
 		)
 		if replicateFile.ID != "" {
 			defer func(fileID string) {
 				cleanupCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 				defer cancel()
 				if err := replicateClient.DeleteFile(cleanupCtx, fileID); err != nil {
-					log.Printf("replicate clip image cleanup failed document_id=%s file_id=%s err=%v", input.DocumentID, fileID, err)
+					slog.ErrorContext(ctx, "replicate clip image cleanup failed",
+						"document_id", input.DocumentID,
+						"file_id", fileID,
+						"err", err,
+					)
 				}
 			}(replicateFile.ID)
 		}
@@ -127,11 +129,10 @@ func RegisterCreateDocumentEmbedding(server *mcp.Server) error {
 				err,
 			)
 		}
-		log.Printf(
-			"replicate clip image success document_id=%s run_duration_ms=%d total_duration_ms=%d",
-			input.DocumentID,
-			runDuration.Milliseconds(),
-			time.Since(startedAt).Milliseconds(),
+		slog.InfoContext(ctx, "replicate clip image success",
+			"document_id", input.DocumentID,
+			"run_duration_ms", runDuration.Milliseconds(),
+			"total_duration_ms", time.Since(startedAt).Milliseconds(),
 		)
 
 		embedding, err := parseEmbedding(output)
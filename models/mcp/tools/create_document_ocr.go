@@ -30,7 +30,7 @@ func RegisterCreateDocumentOCR(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "create_document_ocr",
 		Description: "Generate OCR text with a versioned model, normalize the result, and persist it for a document.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input CreateDocumentOCRInput) (*mcp.CallToolResult, CreateDocumentOCROutput, error) {
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -36,7 +36,7 @@ func RegisterCreateDocumentSegmentation(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "create_document_segmentation",
 		Description: "Generate a document segmentation with a versioned model and persist both the result payload and any derived segmented image.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input CreateDocumentSegmentationInput) (*mcp.CallToolResult, CreateDocumentSegmentationOutput, error) {
@@ -114,7 +114,11 @@ func RegisterCreateDocumentSegmentation(server *mcp.Server) error {
 				cleanupCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 				defer cancel()
 				if err := replicateClient.DeleteFile(cleanupCtx, fileID); err != nil {
-					log.Printf("replicate segmentation cleanup failed document_id=%s file_id=%s err=%v", input.DocumentID, fileID, err)
+					slog.ErrorContext(ctx, "replicate segmentation cleanup failed",
+						"document_id", input.DocumentID,
+						"file_id", fileID,
+						"err", err,
+					)
 				}
 			}(replicateFile.ID)
 		}
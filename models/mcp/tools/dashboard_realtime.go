@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
 	"github.com/arcnem-ai/arcnem-vision/models/shared/realtime"
@@ -18,19 +18,17 @@ func publishDashboardDescriptionEvent(
 ) {
 	event, err := buildDashboardDescriptionEvent(db, documentID)
 	if err != nil {
-		log.Printf(
-			"dashboard realtime description_event_build_failed document_id=%s err=%v",
-			documentID,
-			err,
+		slog.ErrorContext(ctx, "dashboard realtime description_event_build_failed",
+			"document_id", documentID,
+			"err", err,
 		)
 		return
 	}
 
 	if err := realtime.PublishDashboardEvent(ctx, event); err != nil {
-		log.Printf(
-			"dashboard realtime description_event_publish_failed document_id=%s err=%v",
-			documentID,
-			err,
+		slog.ErrorContext(ctx, "dashboard realtime description_event_publish_failed",
+			"document_id", documentID,
+			"err", err,
 		)
 	}
 }
@@ -48,11 +46,10 @@ func publishDashboardSegmentationEvent(
 	event.SegmentedDocumentID = segmentedDocumentID
 
 	if err := realtime.PublishDashboardEvent(ctx, event); err != nil {
-		log.Printf(
-			"dashboard realtime segmentation_event_publish_failed source_document_id=%s segmented_document_id=%s err=%v",
-			sourceDocument.ID,
-			segmentedDocumentID,
-			err,
+		slog.ErrorContext(ctx, "dashboard realtime segmentation_event_publish_failed",
+			"source_document_id", sourceDocument.ID,
+			"segmented_document_id", segmentedDocumentID,
+			"err", err,
 		)
 	}
 }
@@ -68,10 +65,9 @@ func publishDashboardOCREvent(
 	event.DocumentID = document.ID
 
 	if err := realtime.PublishDashboardEvent(ctx, event); err != nil {
-		log.Printf(
-			"dashboard realtime ocr_event_publish_failed document_id=%s err=%v",
-			document.ID,
-			err,
+		slog.ErrorContext(ctx, "dashboard realtime ocr_event_publish_failed",
+			"document_id", document.ID,
+			"err", err,
 		)
 	}
 }
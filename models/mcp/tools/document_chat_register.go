@@ -14,7 +14,7 @@ func RegisterSearchDocumentsInScope(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "search_documents_in_scope",
 		Description: "Search top-level documents within an authenticated scope using OCR text, descriptions, and metadata.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input SearchDocumentsInScopeInput) (*mcp.CallToolResult, SearchDocumentsInScopeOutput, error) {
@@ -35,7 +35,7 @@ func RegisterBrowseDocumentsInScope(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "browse_documents_in_scope",
 		Description: "Browse recent top-level documents within an authenticated scope using metadata, OCR text, and descriptions.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input BrowseDocumentsInScopeInput) (*mcp.CallToolResult, SearchDocumentsInScopeOutput, error) {
@@ -56,7 +56,7 @@ func RegisterReadDocumentContext(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "read_document_context",
 		Description: "Read normalized context for top-level documents in an authenticated scope, including metadata, OCR excerpts, and related segmentation excerpts.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ReadDocumentContextInput) (*mcp.CallToolResult, ReadDocumentContextOutput, error) {
@@ -14,7 +14,7 @@ func RegisterFindSimilarDescriptions(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "find_similar_descriptions",
 		Description: "Find document descriptions with similar CLIP embeddings using cosine distance.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input FindSimilarDescriptionsInput) (*mcp.CallToolResult, FindSimilarOutput, error) {
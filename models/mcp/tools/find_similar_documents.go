@@ -14,7 +14,7 @@ func RegisterFindSimilarDocuments(server *mcp.Server) error {
 		return err
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "find_similar_documents",
 		Description: "Find documents with similar CLIP embeddings using cosine distance.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input FindSimilarDocumentsInput) (*mcp.CallToolResult, FindSimilarOutput, error) {
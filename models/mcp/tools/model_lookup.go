@@ -1,10 +1,12 @@
 package tools
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	dbmodels "github.com/arcnem-ai/arcnem-vision/models/db/gen/models"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -25,13 +27,17 @@ func findModelByIdentity(db *gorm.DB, provider string, name string, version stri
 		normalizedName,
 		normalizedVersion,
 	).First(&model).Error; err != nil {
-		return nil, fmt.Errorf(
+		err = fmt.Errorf(
 			"failed to find model %s/%s version=%q in db: %w",
 			normalizedProvider,
 			normalizedName,
 			normalizedVersion,
 			err,
 		)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcodes.Wrap(errorcodes.ModelNotFound, err)
+		}
+		return nil, err
 	}
 
 	return &model, nil
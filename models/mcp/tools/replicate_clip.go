@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -14,6 +13,7 @@ import (
 )
 
 const clipRequestTimeout = 90 * time.Second
+const clipModelName = "openai/clip"
 const clipPredictionURL = "https://api.replicate.com/v1/models/openai/clip/predictions"
 const clipPreferWaitSeconds = "60"
 const clipResponsePreviewLimit = 800
@@ -39,18 +39,7 @@ func runCLIPPrediction(ctx context.Context, input map[string]any) (map[string]an
 		return nil, fmt.Errorf("failed to marshal replicate request: %w", err)
 	}
 
-	payload, err := doReplicateJSONRequest(
-		runCtx,
-		token,
-		http.MethodPost,
-		clipPredictionURL,
-		body,
-		clipPreferWaitSeconds,
-	)
-	if err != nil {
-		return nil, err
-	}
-	payload, err = waitForReplicatePrediction(runCtx, token, payload)
+	payload, err := runReplicatePrediction(runCtx, token, clipModelName, clipPredictionURL, body, clipPreferWaitSeconds)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
 	"github.com/replicate/replicate-go"
 )
 
@@ -53,18 +55,7 @@ func runReplicateVersionedPrediction(ctx context.Context, modelName string, vers
 		url.PathEscape(modelSlug),
 		url.PathEscape(strings.TrimSpace(version)),
 	)
-	payload, err := doReplicateJSONRequest(
-		runCtx,
-		token,
-		http.MethodPost,
-		predictionURL,
-		body,
-		replicatePreferWaitSeconds,
-	)
-	if err != nil {
-		return nil, err
-	}
-	payload, err = waitForReplicatePrediction(runCtx, token, payload)
+	payload, err := runReplicatePrediction(runCtx, token, modelName, predictionURL, body, replicatePreferWaitSeconds)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +68,38 @@ func runReplicateVersionedPrediction(ctx context.Context, modelName string, vers
 	return outputRaw, nil
 }
 
+// runReplicatePrediction creates a prediction and waits for its final status,
+// recording how long that took for modelName.
+func runReplicatePrediction(
+	ctx context.Context,
+	token string,
+	modelName string,
+	predictionURL string,
+	body []byte,
+	preferWaitSeconds string,
+) (map[string]any, error) {
+	startedAt := time.Now()
+	payload, err := doReplicateJSONRequest(ctx, token, http.MethodPost, predictionURL, body, preferWaitSeconds)
+	if err == nil {
+		payload, err = waitForReplicatePrediction(ctx, token, payload)
+	}
+	metrics.ObserveReplicatePrediction(modelName, replicatePredictionStatus(err), time.Since(startedAt))
+	return payload, err
+}
+
+func replicatePredictionStatus(err error) string {
+	switch {
+	case err == nil:
+		return "succeeded"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "failed"
+	}
+}
+
 func waitForReplicatePrediction(ctx context.Context, token string, payload map[string]any) (map[string]any, error) {
 	for {
 		if errVal, hasErr := payload["error"]; hasErr && errVal != nil {
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// addTool registers a typed tool whose failures are returned as error results
+// carrying an errorcodes.Code in _meta, so callers can tell failure types
+// apart without parsing messages. Each call is also recorded in the tool
+// metrics.
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	mcp.AddTool(server, tool, withToolMetrics(tool.Name, withErrorCode(handler)))
+}
+
+func withErrorCode[In, Out any](handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		result, output, err := handler(ctx, req, input)
+		if err == nil {
+			return result, output, nil
+		}
+
+		var zero Out
+		return toolErrorResult(err), zero, nil
+	}
+}
+
+func toolErrorResult(err error) *mcp.CallToolResult {
+	code := errorcodes.Of(err)
+	if code == errorcodes.Unknown {
+		code = errorcodes.ToolFailed
+	}
+
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+		Meta:    mcp.Meta{errorcodes.MetaKey: string(code)},
+	}
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolErrorResultCarriesErrorCode(t *testing.T) {
+	result := toolErrorResult(fmt.Errorf(
+		"failed to optimize document image: %w",
+		errorcodes.Wrap(errorcodes.ImageDecodeFailed, errors.New("unknown format")),
+	))
+	if !result.IsError {
+		t.Fatal("expected an error result")
+	}
+	if got := result.Meta[errorcodes.MetaKey]; got != string(errorcodes.ImageDecodeFailed) {
+		t.Fatalf("expected image_decode_failed, got %v", got)
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || text.Text != "failed to optimize document image: unknown format" {
+		t.Fatalf("unexpected content: %#v", result.Content)
+	}
+
+	uncoded := toolErrorResult(errors.New("replicate prediction failed"))
+	if got := uncoded.Meta[errorcodes.MetaKey]; got != string(errorcodes.ToolFailed) {
+		t.Fatalf("expected tool_failed for uncoded errors, got %v", got)
+	}
+}
+
+func TestToolCallErrorCodeReadsErrorResults(t *testing.T) {
+	if got := toolCallErrorCode(&mcp.CallToolResult{}, nil); got != "" {
+		t.Fatalf("expected no code for a successful call, got %q", got)
+	}
+	if got := toolCallErrorCode(toolErrorResult(errorcodes.Wrap(errorcodes.ToolInputInvalid, errors.New("bad input"))), nil); got != string(errorcodes.ToolInputInvalid) {
+		t.Fatalf("expected the code from _meta, got %q", got)
+	}
+	if got := toolCallErrorCode(&mcp.CallToolResult{IsError: true}, nil); got != string(errorcodes.ToolFailed) {
+		t.Fatalf("expected tool_failed for uncoded error results, got %q", got)
+	}
+	if got := toolCallErrorCode(nil, errorcodes.Wrap(errorcodes.Timeout, errors.New("deadline"))); got != string(errorcodes.Timeout) {
+		t.Fatalf("expected the code of a returned error, got %q", got)
+	}
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// withToolMetrics records the latency and error code of every call to the
+// named tool. Only registered tool names reach it, so the label stays bounded.
+func withToolMetrics[In, Out any](name string, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		startedAt := time.Now()
+		result, output, err := handler(ctx, req, input)
+		metrics.ObserveToolCall(name, toolCallErrorCode(result, err), time.Since(startedAt))
+		return result, output, err
+	}
+}
+
+func toolCallErrorCode(result *mcp.CallToolResult, err error) string {
+	if err != nil {
+		return string(errorcodes.Of(err))
+	}
+	if result == nil || !result.IsError {
+		return ""
+	}
+	if code, ok := result.Meta[errorcodes.MetaKey].(string); ok && code != "" {
+		return code
+	}
+	return string(errorcodes.ToolFailed)
+}
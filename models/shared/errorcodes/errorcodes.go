@@ -0,0 +1,110 @@
+// Package errorcodes is the error taxonomy shared by the agents service and
+// the MCP tools. Codes are persisted on runs and steps so workflows and
+// dashboards can branch on and aggregate failure types.
+package errorcodes
+
+import (
+	"context"
+	"errors"
+)
+
+// Code is a stable, machine-readable failure type.
+type Code string
+
+const (
+	ProviderRateLimited Code = "provider_rate_limited"
+	ProviderUnavailable Code = "provider_unavailable"
+	ProviderFailed      Code = "provider_failed"
+	ToolInputInvalid    Code = "tool_input_invalid"
+	ToolUnavailable     Code = "tool_unavailable"
+	ToolFailed          Code = "tool_failed"
+	ImageDecodeFailed   Code = "image_decode_failed"
+	ModelNotFound       Code = "model_not_found"
+	NotFound            Code = "not_found"
+	SchemaViolation     Code = "schema_violation"
+	GraphInvalid        Code = "graph_invalid"
+	Timeout             Code = "timeout"
+	Cancelled           Code = "cancelled"
+	Unknown             Code = "unknown"
+)
+
+// MetaKey is the CallToolResult _meta key MCP tools use to report the code of
+// a failed call.
+const MetaKey = "arcnem/error_code"
+
+var known = map[Code]bool{
+	ProviderRateLimited: true,
+	ProviderUnavailable: true,
+	ProviderFailed:      true,
+	ToolInputInvalid:    true,
+	ToolUnavailable:     true,
+	ToolFailed:          true,
+	ImageDecodeFailed:   true,
+	ModelNotFound:       true,
+	NotFound:            true,
+	SchemaViolation:     true,
+	GraphInvalid:        true,
+	Timeout:             true,
+	Cancelled:           true,
+	Unknown:             true,
+}
+
+// Parse returns the code named by value, or Unknown if it is not part of the
+// taxonomy.
+func Parse(value string) Code {
+	if code := Code(value); known[code] {
+		return code
+	}
+	return Unknown
+}
+
+// Error attaches a code to an error without changing its message.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the attached code.
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+// Wrap attaches code to err. It returns nil for a nil error.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Of returns the code of err: the outermost error in the chain implementing
+// ErrorCode() Code decides, then timeouts and cancellation are recognized, and
+// any other error is Unknown. Of returns "" for a nil error.
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	var coded interface{ ErrorCode() Code }
+	if errors.As(err, &coded) {
+		if code := coded.ErrorCode(); code != "" {
+			return code
+		}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, context.Canceled):
+		return Cancelled
+	default:
+		return Unknown
+	}
+}
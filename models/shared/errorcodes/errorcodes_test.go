@@ -0,0 +1,45 @@
+package errorcodes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "wrapped", err: fmt.Errorf("tool node: %w", Wrap(ImageDecodeFailed, errors.New("bad png"))), want: ImageDecodeFailed},
+		{name: "outermost wins", err: Wrap(ToolFailed, Wrap(Timeout, errors.New("slow"))), want: ToolFailed},
+		{name: "deadline", err: fmt.Errorf("call: %w", context.DeadlineExceeded), want: Timeout},
+		{name: "cancelled", err: context.Canceled, want: Cancelled},
+		{name: "plain", err: errors.New("boom"), want: Unknown},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Of(test.err); got != test.want {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestWrapKeepsMessage(t *testing.T) {
+	err := Wrap(ModelNotFound, errors.New("model openai/clip not found"))
+	if err.Error() != "model openai/clip not found" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if Wrap(ModelNotFound, nil) != nil {
+		t.Fatal("expected nil error to stay nil")
+	}
+}
+
+func TestParse(t *testing.T) {
+	if Parse("schema_violation") != SchemaViolation || Parse("made_up") != Unknown {
+		t.Fatal("unexpected parse result")
+	}
+}
@@ -15,6 +15,9 @@ import (
 
 	_ "image/gif"
 	_ "image/png"
+
+	"github.com/arcnem-ai/arcnem-vision/models/shared/errorcodes"
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
 )
 
 const (
@@ -86,9 +89,17 @@ func PrepareImageForService(ctx context.Context, imageURL string, options Prepar
 }
 
 func PrepareImageBytes(data []byte, contentType string, options PrepareOptions) (*PreparedImage, error) {
-	opts := withDefaults(options)
+	prepared, err := prepareImageBytes(data, contentType, withDefaults(options))
+	if err != nil {
+		return nil, err
+	}
+	metrics.ObserveImagePreparation(prepared.OriginalBytes, prepared.FinalBytes)
+	return prepared, nil
+}
+
+func prepareImageBytes(data []byte, contentType string, opts PrepareOptions) (*PreparedImage, error) {
 	if len(data) == 0 {
-		return nil, fmt.Errorf("image data is empty")
+		return nil, errorcodes.Wrap(errorcodes.ImageDecodeFailed, fmt.Errorf("image data is empty"))
 	}
 
 	detectedContentType := http.DetectContentType(data)
@@ -112,18 +123,18 @@ func PrepareImageBytes(data []byte, contentType string, options PrepareOptions)
 
 	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
-		return nil, fmt.Errorf(
+		return nil, errorcodes.Wrap(errorcodes.ImageDecodeFailed, fmt.Errorf(
 			"image exceeds max_bytes=%d (got %d bytes) and decode failed: %w",
 			opts.MaxBytes,
 			len(data),
 			err,
-		)
+		))
 	}
 
 	bounds := img.Bounds()
 	srcW, srcH := bounds.Dx(), bounds.Dy()
 	if srcW == 0 || srcH == 0 {
-		return nil, fmt.Errorf("image has invalid dimensions %dx%d", srcW, srcH)
+		return nil, errorcodes.Wrap(errorcodes.ImageDecodeFailed, fmt.Errorf("image has invalid dimensions %dx%d", srcW, srcH))
 	}
 
 	targetW, targetH := scaleToMaxDimension(srcW, srcH, opts.MaxDimension)
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Correlation fields carried on a context and added to every record logged
+// with it.
+const (
+	RunIDKey          = "run_id"
+	NodeKeyKey        = "node_key"
+	DocumentIDKey     = "document_id"
+	OrganizationIDKey = "organization_id"
+)
+
+// metaPrefix namespaces correlation fields in MCP request _meta.
+const metaPrefix = "arcnem/"
+
+var fieldKeys = []string{RunIDKey, NodeKeyKey, DocumentIDKey, OrganizationIDKey}
+
+type fieldsContextKey struct{}
+
+// Setup installs a JSON logger as the slog and log default. The level comes
+// from LOG_LEVEL (debug, info, warn or error) and defaults to info.
+func Setup(serviceName string) error {
+	var level slog.Level
+	if raw := strings.TrimSpace(os.Getenv("LOG_LEVEL")); raw != "" {
+		if err := level.UnmarshalText([]byte(raw)); err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL %q: %w", raw, err)
+		}
+	}
+
+	handler := NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+	slog.SetDefault(slog.New(handler).With("service", serviceName))
+	return nil
+}
+
+// NewHandler wraps next so records logged with a context carry its
+// correlation fields. Attributes set explicitly on a record win.
+func NewHandler(next slog.Handler) slog.Handler {
+	return &contextHandler{next: next}
+}
+
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := fieldsFrom(ctx)
+	if len(fields) == 0 {
+		return h.next.Handle(ctx, record)
+	}
+
+	present := make(map[string]bool, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		present[attr.Key] = true
+		return true
+	})
+	for _, key := range fieldKeys {
+		if value := fields[key]; value != "" && !present[key] {
+			record.AddAttrs(slog.String(key, value))
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
+
+// WithRunID returns a context whose log records carry runID.
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return withField(ctx, RunIDKey, runID)
+}
+
+// WithNodeKey returns a context whose log records carry nodeKey.
+func WithNodeKey(ctx context.Context, nodeKey string) context.Context {
+	return withField(ctx, NodeKeyKey, nodeKey)
+}
+
+// WithDocumentID returns a context whose log records carry documentID.
+func WithDocumentID(ctx context.Context, documentID string) context.Context {
+	return withField(ctx, DocumentIDKey, documentID)
+}
+
+// WithOrganizationID returns a context whose log records carry organizationID.
+func WithOrganizationID(ctx context.Context, organizationID string) context.Context {
+	return withField(ctx, OrganizationIDKey, organizationID)
+}
+
+// RunID returns the run ID stored on ctx, if any.
+func RunID(ctx context.Context) string {
+	return fieldsFrom(ctx)[RunIDKey]
+}
+
+// Meta returns the correlation fields of ctx as MCP request _meta entries, so
+// the server handling the request can log under the same run.
+func Meta(ctx context.Context) map[string]any {
+	fields := fieldsFrom(ctx)
+	if len(fields) == 0 {
+		return nil
+	}
+	meta := make(map[string]any, len(fields))
+	for key, value := range fields {
+		meta[metaPrefix+key] = value
+	}
+	return meta
+}
+
+// ContextFromMeta restores the correlation fields sent with Meta.
+func ContextFromMeta(ctx context.Context, meta map[string]any) context.Context {
+	for _, key := range fieldKeys {
+		if value, ok := meta[metaPrefix+key].(string); ok {
+			ctx = withField(ctx, key, value)
+		}
+	}
+	return ctx
+}
+
+func withField(ctx context.Context, key string, value string) context.Context {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ctx
+	}
+	current := fieldsFrom(ctx)
+	if current[key] == value {
+		return ctx
+	}
+	fields := make(map[string]string, len(current)+1)
+	for k, v := range current {
+		fields[k] = v
+	}
+	fields[key] = value
+	return context.WithValue(ctx, fieldsContextKey{}, fields)
+}
+
+func fieldsFrom(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey{}).(map[string]string)
+	return fields
+}
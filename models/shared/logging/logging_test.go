@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestHandlerAddsContextFields(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&output, nil)))
+
+	ctx := WithRunID(context.Background(), "run-1")
+	ctx = WithNodeKey(ctx, "describe")
+	ctx = WithDocumentID(ctx, "document-1")
+	logger.InfoContext(ctx, "graph worker start", "node_key", "override")
+
+	var record map[string]any
+	if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record[RunIDKey] != "run-1" || record[DocumentIDKey] != "document-1" {
+		t.Fatalf("expected run and document IDs from the context, got %v", record)
+	}
+	if record[NodeKeyKey] != "override" {
+		t.Fatalf("expected the explicit node_key to win, got %v", record[NodeKeyKey])
+	}
+	if _, ok := record[OrganizationIDKey]; ok {
+		t.Fatalf("expected unset fields to be omitted, got %v", record)
+	}
+}
+
+func TestMetaRoundTrip(t *testing.T) {
+	ctx := WithOrganizationID(WithRunID(context.Background(), "run-1"), "org-1")
+	meta := Meta(ctx)
+	if meta["arcnem/run_id"] != "run-1" || meta["arcnem/organization_id"] != "org-1" {
+		t.Fatalf("unexpected meta %v", meta)
+	}
+
+	restored := ContextFromMeta(context.Background(), meta)
+	if RunID(restored) != "run-1" || fieldsFrom(restored)[OrganizationIDKey] != "org-1" {
+		t.Fatalf("expected fields to survive the round trip, got %v", fieldsFrom(restored))
+	}
+	if Meta(context.Background()) != nil {
+		t.Fatal("expected no meta without correlation fields")
+	}
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "arcnem"
+
+// Labels only carry values from small fixed sets (statuses, node types,
+// providers, tool names) or configured IDs such as workflow IDs. Document,
+// run and organization IDs never become labels.
+var (
+	// durationBuckets span 50ms to roughly 7 minutes, which covers quick
+	// tool calls as well as slow model and Replicate predictions.
+	durationBuckets = prometheus.ExponentialBuckets(0.05, 2, 14)
+	// byteBuckets span 16KiB to 128MiB, the largest image download allowed.
+	byteBuckets = prometheus.ExponentialBuckets(16*1024, 2, 14)
+
+	runsFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_finished_total",
+		Help:      "Workflow runs that reached a terminal status.",
+	}, []string{"workflow", "status"})
+
+	nodeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "node_duration_seconds",
+		Help:      "Time spent executing graph nodes.",
+		Buckets:   durationBuckets,
+	}, []string{"node_type", "status"})
+
+	providerCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_call_duration_seconds",
+		Help:      "Latency of each model provider call attempt.",
+		Buckets:   durationBuckets,
+	}, []string{"provider", "model", "status"})
+
+	providerCallRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_call_retries_total",
+		Help:      "Model provider call attempts that were retried.",
+	}, []string{"provider", "model"})
+
+	toolCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mcp_tool_call_duration_seconds",
+		Help:      "Latency of MCP tool calls.",
+		Buckets:   durationBuckets,
+	}, []string{"tool", "status"})
+
+	toolCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mcp_tool_call_errors_total",
+		Help:      "MCP tool calls that returned an error result.",
+	}, []string{"tool", "error_code"})
+
+	replicatePredictionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "replicate_prediction_duration_seconds",
+		Help:      "Time from creating a Replicate prediction to its final status.",
+		Buckets:   durationBuckets,
+	}, []string{"model", "status"})
+
+	imagePreparationBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_preparation_bytes",
+		Help:      "Image sizes before (in) and after (out) preparation for a model.",
+		Buckets:   byteBuckets,
+	}, []string{"direction"})
+
+	realtimePublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "realtime_publish_failures_total",
+		Help:      "Dashboard realtime events that could not be published.",
+	}, []string{"reason"})
+)
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveRunFinished counts a run that reached status under its workflow.
+func ObserveRunFinished(workflowID string, status string) {
+	runsFinished.WithLabelValues(labelValue(workflowID), status).Inc()
+}
+
+// ObserveNode records how long a node of nodeType ran and whether it failed.
+func ObserveNode(nodeType string, failed bool, duration time.Duration) {
+	nodeDuration.WithLabelValues(labelValue(nodeType), outcome(failed)).Observe(duration.Seconds())
+}
+
+// ObserveProviderCall records one provider call attempt. status is the
+// provider HTTP status or a failure class such as "timeout".
+func ObserveProviderCall(provider string, model string, status string, duration time.Duration) {
+	providerCallDuration.WithLabelValues(labelValue(provider), labelValue(model), status).Observe(duration.Seconds())
+}
+
+// CountProviderRetry counts a provider call attempt that is about to be retried.
+func CountProviderRetry(provider string, model string) {
+	providerCallRetries.WithLabelValues(labelValue(provider), labelValue(model)).Inc()
+}
+
+// ObserveToolCall records one MCP tool call. errorCode is empty when the call
+// succeeded.
+func ObserveToolCall(tool string, errorCode string, duration time.Duration) {
+	toolCallDuration.WithLabelValues(tool, outcome(errorCode != "")).Observe(duration.Seconds())
+	if errorCode != "" {
+		toolCallErrors.WithLabelValues(tool, errorCode).Inc()
+	}
+}
+
+// ObserveReplicatePrediction records how long a Replicate prediction took to
+// finish with status.
+func ObserveReplicatePrediction(model string, status string, duration time.Duration) {
+	replicatePredictionDuration.WithLabelValues(labelValue(model), status).Observe(duration.Seconds())
+}
+
+// ObserveImagePreparation records the size of an image before and after it
+// was prepared.
+func ObserveImagePreparation(bytesIn int, bytesOut int) {
+	imagePreparationBytes.WithLabelValues("in").Observe(float64(bytesIn))
+	imagePreparationBytes.WithLabelValues("out").Observe(float64(bytesOut))
+}
+
+// CountRealtimePublishFailure counts a dashboard event that was not published.
+func CountRealtimePublishFailure(reason string) {
+	realtimePublishFailures.WithLabelValues(labelValue(reason)).Inc()
+}
+
+func outcome(failed bool) string {
+	if failed {
+		return "error"
+	}
+	return "ok"
+}
+
+func labelValue(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerServesRecordedMetrics(t *testing.T) {
+	ObserveRunFinished("graph-1", "completed")
+	ObserveNode("", true, 2*time.Second)
+	ObserveToolCall("create_document_ocr", "tool_failed", time.Second)
+	ObserveImagePreparation(4096, 1024)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`arcnem_runs_finished_total{status="completed",workflow="graph-1"} 1`,
+		`arcnem_node_duration_seconds_count{node_type="unknown",status="error"} 1`,
+		`arcnem_mcp_tool_call_duration_seconds_count{status="error",tool="create_document_ocr"} 1`,
+		`arcnem_mcp_tool_call_errors_total{error_code="tool_failed",tool="create_document_ocr"} 1`,
+		`arcnem_image_preparation_bytes_sum{direction="in"} 4096`,
+		`arcnem_image_preparation_bytes_sum{direction="out"} 1024`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected metrics output to contain %s", want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/arcnem-ai/arcnem-vision/models/shared/metrics"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -62,6 +63,14 @@ func NewDashboardEvent(reason string, organizationID string) DashboardEvent {
 }
 
 func PublishDashboardEvent(ctx context.Context, event DashboardEvent) error {
+	if err := publishDashboardEvent(ctx, event); err != nil {
+		metrics.CountRealtimePublishFailure(event.Reason)
+		return err
+	}
+	return nil
+}
+
+func publishDashboardEvent(ctx context.Context, event DashboardEvent) error {
 	client, err := getRedisClient()
 	if err != nil {
 		return err
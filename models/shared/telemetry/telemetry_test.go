@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var spanExporter = installSpanExporter()
+
+func installSpanExporter() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return exporter
+}
+
+func TestTransportPropagatesTraceContext(t *testing.T) {
+	spanExporter.Reset()
+	var received trace.SpanContext
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = trace.SpanContextFromContext(ContextFromHeader(context.Background(), r.Header))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctx, span := Tracer("test").Start(context.Background(), "caller")
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := (&http.Client{Transport: NewTransport(nil)}).Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	span.End()
+
+	if request.Header.Get("traceparent") != "" {
+		t.Fatal("expected the caller's request headers to be left untouched")
+	}
+	if !received.IsRemote() || received.TraceID() != span.SpanContext().TraceID() || received.SpanID() != span.SpanContext().SpanID() {
+		t.Fatalf("server saw span context %v, want %v", received, span.SpanContext())
+	}
+}
+
+func TestEndSpanRecordsErrors(t *testing.T) {
+	spanExporter.Reset()
+	_, failed := Tracer("test").Start(context.Background(), "failed")
+	EndSpan(failed, errors.New("provider unavailable"))
+	_, succeeded := Tracer("test").Start(context.Background(), "succeeded")
+	EndSpan(succeeded, nil)
+
+	spans := spanExporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || spans[0].Status.Description != "provider unavailable" || len(spans[0].Events) != 1 {
+		t.Fatalf("expected failed span to record the error, got %#v", spans[0].Status)
+	}
+	if spans[1].Status.Code != codes.Unset {
+		t.Fatalf("expected succeeded span to keep an unset status, got %#v", spans[1].Status)
+	}
+}
@@ -0,0 +1,60 @@
+import { describe, expect, test } from "bun:test";
+import type { PGDB } from "@arcnem-vision/db/server";
+import { createPostgresJobClient } from "./jobs";
+
+function buildFakeDB() {
+	const inserted: Array<Record<string, unknown>> = [];
+
+	const db = {
+		insert: () => ({
+			values: (rows: Array<Record<string, unknown>>) => {
+				inserted.push(...rows);
+				return {
+					onConflictDoNothing: async () => undefined,
+				};
+			},
+		}),
+	} as unknown as PGDB;
+
+	return { db, inserted };
+}
+
+describe("createPostgresJobClient", () => {
+	test("queues events as job rows", async () => {
+		const { db, inserted } = buildFakeDB();
+
+		await createPostgresJobClient(db).send([
+			{
+				id: "execution-1",
+				name: "workflow/execute",
+				data: { execution_id: "execution-1" },
+			},
+			{ name: "document/process.upload", data: { document_id: "doc-1" } },
+		]);
+
+		expect(inserted).toEqual([
+			{
+				event: "workflow/execute",
+				eventId: "execution-1",
+				data: { execution_id: "execution-1" },
+			},
+			{
+				event: "document/process.upload",
+				eventId: null,
+				data: { document_id: "doc-1" },
+			},
+		]);
+	});
+
+	test("rejects events the postgres backend does not run", async () => {
+		const { db, inserted } = buildFakeDB();
+
+		await expect(
+			createPostgresJobClient(db).send({
+				name: "workflow/backfill",
+				data: { backfill_id: "backfill-1" },
+			}),
+		).rejects.toThrow("does not run workflow/backfill events");
+		expect(inserted).toEqual([]);
+	});
+});
@@ -0,0 +1,69 @@
+import { schema } from "@arcnem-vision/db";
+import { getDB, type PGDB } from "@arcnem-vision/db/server";
+import { getInngestClient } from "./inngest";
+
+const { jobQueue } = schema;
+
+export type JobEvent = {
+	id?: string;
+	name: string;
+	data: Record<string, unknown>;
+};
+
+// JobClient queues events for the agents service's jobs on whichever backend
+// JOB_BACKEND selects.
+export type JobClient = {
+	send: (events: JobEvent | JobEvent[]) => Promise<unknown>;
+};
+
+// Events the agents service runs on the Postgres backend. Backfills and
+// schedules sleep between steps, so they still need Inngest.
+export const POSTGRES_JOB_EVENTS: readonly string[] = [
+	"document/process.upload",
+	"workflow/execute",
+	"workflow/cancel",
+	"workflow/backfill.cancel",
+	"webhook/deliver",
+];
+
+export const isPostgresJobBackend = (): boolean => {
+	return process.env.JOB_BACKEND === "postgres";
+};
+
+export const createPostgresJobClient = (db: PGDB): JobClient => ({
+	send: async (events) => {
+		const queued = Array.isArray(events) ? events : [events];
+		for (const event of queued) {
+			if (!POSTGRES_JOB_EVENTS.includes(event.name)) {
+				throw new Error(
+					`The postgres job backend does not run ${event.name} events`,
+				);
+			}
+		}
+		if (queued.length === 0) return;
+
+		await db
+			.insert(jobQueue)
+			.values(
+				queued.map((event) => ({
+					event: event.name,
+					eventId: event.id ?? null,
+					data: event.data,
+				})),
+			)
+			// A repeated event ID is already queued.
+			.onConflictDoNothing({ target: jobQueue.eventId });
+	},
+});
+
+let jobClient: JobClient | null = null;
+
+export const getJobClient = (): JobClient => {
+	if (!jobClient) {
+		jobClient = isPostgresJobBackend()
+			? createPostgresJobClient(getDB())
+			: { send: (events) => getInngestClient().send(events) };
+	}
+
+	return jobClient;
+};
@@ -15,4 +15,5 @@ export const API_ENV_VAR = {
 	MCP_SERVER_URL: "MCP_SERVER_URL",
 	OPENAI_API_KEY: "OPENAI_API_KEY",
 	OPENAI_MODEL: "OPENAI_MODEL",
+	WEBHOOK_ALLOW_PRIVATE_TARGETS: "WEBHOOK_ALLOW_PRIVATE_TARGETS",
 } as const;
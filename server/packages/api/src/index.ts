@@ -10,6 +10,7 @@ import { isTrustedOrigin } from "@/lib/auth-origins";
 import { ackUploadRouter } from "@/routes/ackUpload";
 import { authRouter } from "@/routes/auth";
 import { getInngestClient } from "./clients/inngest";
+import { getJobClient, isPostgresJobBackend } from "./clients/jobs";
 import { getS3Client } from "./clients/s3";
 import { isAPIDebugModeEnabled } from "./env/isAPIDebugModeEnabled";
 import { dashboardRouter } from "./routes/dashboard";
@@ -75,9 +76,9 @@ app.use("*", async (c, next) => {
 });
 
 app.use("*", async (c, next) => {
-	const inngestClient = getInngestClient();
+	const jobClient = getJobClient();
 
-	c.set("inngestClient", inngestClient);
+	c.set("jobClient", jobClient);
 
 	await next();
 });
@@ -90,17 +91,17 @@ app.use("*", async (c, next) => {
 	await next();
 });
 
-app.on(["GET", "PUT", "POST"], "/api/inngest", (c) => {
-	const inngestClient = c.get("inngestClient");
+if (!isPostgresJobBackend()) {
+	app.on(["GET", "PUT", "POST"], "/api/inngest", (c) => {
+		const handler = serve({
+			client: getInngestClient(),
+			functions: [],
+			serveOrigin: process.env.JOB_SERVER_URL,
+		});
 
-	const handler = serve({
-		client: inngestClient,
-		functions: [],
-		serveOrigin: process.env.JOB_SERVER_URL,
+		return handler(c);
 	});
-
-	return handler(c);
-});
+}
 
 const routes = [
 	authRouter,
@@ -8,6 +8,10 @@ import type { S3Client } from "bun";
 import { and, eq } from "drizzle-orm";
 import { ALLOWED_IMAGE_MIME_TYPES } from "@/constants/uploads";
 import { publishDashboardRealtimeEvent } from "@/lib/dashboard-realtime";
+import {
+	buildWorkflowJobRouting,
+	WORKFLOW_JOB_SOURCE,
+} from "@/lib/workflow-job-routing";
 import type {
 	AcknowledgedUpload,
 	AcknowledgedUploadWithProcessing,
@@ -144,11 +148,11 @@ const publishDocumentCreated = async ({
 const enqueueDocumentProcessing = async ({
 	options,
 	documentId,
-	objectKey,
+	upload,
 }: {
 	options: QueueProcessingOptions;
 	documentId: string;
-	objectKey: string;
+	upload: PendingUpload;
 }): Promise<WorkflowUploadProcessing | null> => {
 	if (!options.enabled) {
 		return options.code
@@ -160,13 +164,20 @@ const enqueueDocumentProcessing = async ({
 	}
 
 	try {
-		await options.inngestClient.send({
+		await options.jobClient.send({
 			name: "document/process.upload",
 			data: {
 				document_id: documentId,
 				...(options.agentGraphId
 					? { agent_graph_id: options.agentGraphId }
 					: {}),
+				...buildWorkflowJobRouting({
+					organizationId: upload.organizationId,
+					apiKeyId: upload.apiKeyId,
+					source: upload.apiKeyId
+						? WORKFLOW_JOB_SOURCE.apiKey
+						: WORKFLOW_JOB_SOURCE.dashboard,
+				}),
 			},
 		});
 
@@ -176,7 +187,7 @@ const enqueueDocumentProcessing = async ({
 	} catch (error) {
 		console.error("Failed to enqueue document processing", {
 			documentId,
-			objectKey,
+			objectKey: upload.objectKey,
 			error,
 		});
 
@@ -228,7 +239,7 @@ export async function acknowledgePresignedUpload({
 	const processing = await enqueueDocumentProcessing({
 		options: queueProcessing,
 		documentId: acknowledgedUpload.documentId,
-		objectKey: upload.objectKey,
+		upload,
 	});
 
 	return {
@@ -1,4 +1,4 @@
-import type { Inngest } from "inngest";
+import type { JobClient } from "@/clients/jobs";
 
 export const DOCUMENT_VISIBILITIES = ["private", "org", "public"] as const;
 
@@ -60,7 +60,7 @@ export type QueueProcessingWithResult =
 	  }
 	| {
 			enabled: true;
-			inngestClient: Inngest;
+			jobClient: JobClient;
 			agentGraphId?: string;
 	  };
 
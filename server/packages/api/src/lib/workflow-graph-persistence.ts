@@ -1,6 +1,7 @@
 import { schema } from "@arcnem-vision/db";
 import type { PGDB } from "@arcnem-vision/db/server";
 import type { WorkflowTemplateSnapshot } from "@arcnem-vision/shared";
+import { inArray } from "drizzle-orm";
 
 type DatabaseTransaction = Parameters<Parameters<PGDB["transaction"]>[0]>[0];
 
@@ -25,6 +26,74 @@ export function buildNodeConfig(node: {
 	};
 }
 
+function toolOutputFieldNames(outputSchema: unknown): string[] {
+	if (!outputSchema || typeof outputSchema !== "object") {
+		return [];
+	}
+	const properties = (outputSchema as { properties?: unknown }).properties;
+	return properties && typeof properties === "object"
+		? Object.keys(properties)
+		: [];
+}
+
+// The agents service skips output_mapping paths whose first field is not an
+// output field of the tool, so graphs saved before a tool's schema changed
+// keep running. Saving a graph rejects them instead.
+export async function validateToolOutputMappings(
+	db: PGDB | DatabaseTransaction,
+	nodes: Array<{
+		nodeKey: string;
+		nodeType: string;
+		toolIds: string[];
+		config?: Record<string, unknown>;
+	}>,
+) {
+	const toolNodes = nodes.filter(
+		(node) =>
+			node.nodeType === "tool" &&
+			node.toolIds.length > 0 &&
+			node.config?.output_mapping &&
+			typeof node.config.output_mapping === "object",
+	);
+	if (toolNodes.length === 0) {
+		return;
+	}
+
+	const toolIds = Array.from(
+		new Set(toolNodes.flatMap((node) => node.toolIds.slice(0, 1))),
+	);
+	const toolRows = await db
+		.select({
+			id: schema.tools.id,
+			name: schema.tools.name,
+			outputSchema: schema.tools.outputSchema,
+		})
+		.from(schema.tools)
+		.where(inArray(schema.tools.id, toolIds));
+	const toolById = new Map(toolRows.map((tool) => [tool.id, tool]));
+
+	for (const node of toolNodes) {
+		const [toolId] = node.toolIds;
+		const tool = toolId ? toolById.get(toolId) : undefined;
+		if (!tool) {
+			continue;
+		}
+		const outputFields = toolOutputFieldNames(tool.outputSchema);
+		const availableFields = [...outputFields].sort().join(", ") || "none";
+		for (const path of Object.keys(node.config?.output_mapping ?? {})) {
+			const [field] = path
+				.trim()
+				.replace(/^\$?\.?/, "")
+				.split(/[.[]/);
+			if (field && !outputFields.includes(field)) {
+				throw new Error(
+					`Tool node "${node.nodeKey}" output_mapping "${path}": "${field}" is not an output field of tool "${tool.name}" (available fields: ${availableFields}).`,
+				);
+			}
+		}
+	}
+}
+
 export async function insertWorkflowGraphFromSnapshot(
 	tx: DatabaseTransaction,
 	input: {
@@ -0,0 +1,34 @@
+import { describe, expect, test } from "bun:test";
+import {
+	buildWorkflowJobRouting,
+	WORKFLOW_JOB_SOURCE,
+} from "./workflow-job-routing";
+
+describe("buildWorkflowJobRouting", () => {
+	test("keys API-key runs by organization and key", () => {
+		expect(
+			buildWorkflowJobRouting({
+				organizationId: "org-1",
+				apiKeyId: "key-1",
+				source: WORKFLOW_JOB_SOURCE.apiKey,
+			}),
+		).toEqual({
+			organization_id: "org-1",
+			api_key_id: "key-1",
+			source: "api_key",
+		});
+	});
+
+	test("sends an empty API key for dashboard runs", () => {
+		expect(
+			buildWorkflowJobRouting({
+				organizationId: "org-1",
+				source: WORKFLOW_JOB_SOURCE.dashboard,
+			}),
+		).toEqual({
+			organization_id: "org-1",
+			api_key_id: "",
+			source: "dashboard",
+		});
+	});
+});
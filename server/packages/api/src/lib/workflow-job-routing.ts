@@ -0,0 +1,21 @@
+export const WORKFLOW_JOB_SOURCE = {
+	dashboard: "dashboard",
+	apiKey: "api_key",
+} as const;
+
+export type WorkflowJobSource =
+	(typeof WORKFLOW_JOB_SOURCE)[keyof typeof WORKFLOW_JOB_SOURCE];
+
+// The agents worker keys its per-organization and per-API-key concurrency
+// limits on these fields and starts dashboard runs ahead of API-key runs.
+export function buildWorkflowJobRouting(input: {
+	organizationId: string;
+	apiKeyId?: string | null;
+	source: WorkflowJobSource;
+}) {
+	return {
+		organization_id: input.organizationId,
+		api_key_id: input.apiKeyId ?? "",
+		source: input.source,
+	};
+}
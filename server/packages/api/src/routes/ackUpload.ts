@@ -35,7 +35,7 @@ ackUploadRouter.post(
 
 			const dbClient = c.get("dbClient");
 			const s3Client = c.get("s3Client");
-			const inngestClient = c.get("inngestClient");
+			const jobClient = c.get("jobClient");
 			const body = await readJSONBody(c.req);
 			const { objectKey } = parseAckRequestBody(body);
 			const [uploadForKey] = await dbClient
@@ -87,7 +87,7 @@ ackUploadRouter.post(
 			const queueProcessing: QueueProcessingWithResult = activeWorkflow
 				? {
 						enabled: true,
-						inngestClient,
+						jobClient,
 						agentGraphId: activeWorkflow.id,
 					}
 				: {
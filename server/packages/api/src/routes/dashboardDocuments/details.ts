@@ -9,6 +9,10 @@ import {
 	toOCRResultItem,
 	toSegmentedResultItem,
 } from "@/lib/dashboard-documents";
+import {
+	buildWorkflowJobRouting,
+	WORKFLOW_JOB_SOURCE,
+} from "@/lib/workflow-job-routing";
 import { findActiveWorkflowById } from "@/lib/workflow-run-availability";
 import { requireSession } from "@/middleware/requireSession";
 import type { HonoServerContext } from "@/types/serverContext";
@@ -69,7 +73,7 @@ export function registerDashboardDocumentDetailRoutes(
 		}
 
 		const dbClient = c.get("dbClient");
-		const inngestClient = c.get("inngestClient");
+		const jobClient = c.get("jobClient");
 		const documentAccess = await resolveAccessibleDashboardDocument(
 			c,
 			documentId,
@@ -89,11 +93,15 @@ export function registerDashboardDocumentDetailRoutes(
 		}
 
 		try {
-			await inngestClient.send({
+			await jobClient.send({
 				name: "document/process.upload",
 				data: {
 					document_id: targetDocument.id,
 					agent_graph_id: workflow.id,
+					...buildWorkflowJobRouting({
+						organizationId: targetDocument.organizationId,
+						source: WORKFLOW_JOB_SOURCE.dashboard,
+					}),
 				},
 			});
 		} catch {
@@ -43,7 +43,9 @@ dashboardRunsRouter.get("/dashboard/runs", async (c) => {
 			agentGraphId: agentGraphRuns.agentGraphId,
 			status: agentGraphRuns.status,
 			error: agentGraphRuns.error,
+			errorCode: agentGraphRuns.errorCode,
 			startedAt: agentGraphRuns.startedAt,
+			pickedUpAt: agentGraphRuns.pickedUpAt,
 			finishedAt: agentGraphRuns.finishedAt,
 			workflowName: agentGraphs.name,
 		})
@@ -63,7 +65,11 @@ dashboardRunsRouter.get("/dashboard/runs", async (c) => {
 			workflowName: row.workflowName,
 			status: row.status,
 			error: row.error,
+			errorCode: row.errorCode,
 			startedAt: new Date(row.startedAt).toISOString(),
+			pickedUpAt: row.pickedUpAt
+				? new Date(row.pickedUpAt).toISOString()
+				: null,
 			finishedAt: row.finishedAt
 				? new Date(row.finishedAt).toISOString()
 				: null,
@@ -85,7 +91,9 @@ dashboardRunsRouter.get("/dashboard/runs/:id", async (c) => {
 			agentGraphId: agentGraphRuns.agentGraphId,
 			status: agentGraphRuns.status,
 			error: agentGraphRuns.error,
+			errorCode: agentGraphRuns.errorCode,
 			startedAt: agentGraphRuns.startedAt,
+			pickedUpAt: agentGraphRuns.pickedUpAt,
 			finishedAt: agentGraphRuns.finishedAt,
 			workflowName: agentGraphs.name,
 		})
@@ -109,7 +117,9 @@ dashboardRunsRouter.get("/dashboard/runs/:id", async (c) => {
 		workflowName: row.workflowName,
 		status: row.status,
 		error: row.error,
+		errorCode: row.errorCode,
 		startedAt: new Date(row.startedAt).toISOString(),
+		pickedUpAt: row.pickedUpAt ? new Date(row.pickedUpAt).toISOString() : null,
 		finishedAt: row.finishedAt ? new Date(row.finishedAt).toISOString() : null,
 	});
 });
@@ -126,6 +136,7 @@ dashboardRunsRouter.get("/dashboard/runs/:id/steps", async (c) => {
 			initialState: agentGraphRuns.initialState,
 			finalState: agentGraphRuns.finalState,
 			error: agentGraphRuns.error,
+			errorCode: agentGraphRuns.errorCode,
 		})
 		.from(agentGraphRuns)
 		.innerJoin(agentGraphs, eq(agentGraphRuns.agentGraphId, agentGraphs.id))
@@ -143,6 +154,7 @@ dashboardRunsRouter.get("/dashboard/runs/:id/steps", async (c) => {
 			initialState: null,
 			finalState: null,
 			error: null,
+			errorCode: null,
 		});
 	}
 
@@ -157,6 +169,7 @@ dashboardRunsRouter.get("/dashboard/runs/:id/steps", async (c) => {
 			nodeKey: step.nodeKey,
 			stepOrder: step.stepOrder,
 			stateDelta: step.stateDelta,
+			errorCode: step.errorCode,
 			startedAt: new Date(step.startedAt).toISOString(),
 			finishedAt: step.finishedAt
 				? new Date(step.finishedAt).toISOString()
@@ -165,5 +178,6 @@ dashboardRunsRouter.get("/dashboard/runs/:id/steps", async (c) => {
 		initialState: run.initialState,
 		finalState: run.finalState,
 		error: run.error,
+		errorCode: run.errorCode,
 	});
 });
@@ -20,6 +20,7 @@ import { generateWorkflowDraftFromDescription } from "@/lib/workflow-draft-gener
 import {
 	buildNodeConfig,
 	insertWorkflowGraphFromSnapshot,
+	validateToolOutputMappings,
 } from "@/lib/workflow-graph-persistence";
 import { buildWorkflowTemplateAccessCondition } from "@/lib/workflow-template-access";
 import type { HonoServerContext } from "@/types/serverContext";
@@ -123,6 +124,8 @@ dashboardWorkflowRecordsRouter.post("/dashboard/workflows", async (c) => {
 	if (!parsed.ok) return parsed.response;
 
 	const fields = normalizeWorkflowFields(parsed.data);
+	const snapshot = createWorkflowTemplateSnapshot(parsed.data);
+	await validateToolOutputMappings(c.get("dbClient"), snapshot.nodes);
 	const workflowId = await c.get("dbClient").transaction(async (tx) => {
 		const [createdWorkflow] = await tx
 			.insert(schema.agentGraphs)
@@ -139,7 +142,7 @@ dashboardWorkflowRecordsRouter.post("/dashboard/workflows", async (c) => {
 
 		await insertWorkflowGraphFromSnapshot(tx, {
 			workflowId: createdWorkflow.id,
-			snapshot: createWorkflowTemplateSnapshot(parsed.data),
+			snapshot,
 		});
 
 		return createdWorkflow.id;
@@ -159,6 +162,7 @@ dashboardWorkflowRecordsRouter.post(
 		const db = c.get("dbClient");
 		const fields = normalizeWorkflowFields(parsed.data);
 		const graph = normalizeGraphData(parsed.data);
+		await validateToolOutputMappings(db, graph.nodes);
 
 		await db.transaction(async (tx) => {
 			const workflow = await tx.query.agentGraphs.findFirst({
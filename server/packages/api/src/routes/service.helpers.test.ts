@@ -3,14 +3,24 @@ import {
 	buildExecutionScope,
 	buildSeededInitialState,
 	buildServiceDocumentSearchScope,
+	buildWorkflowBackfillOptions,
+	buildWorkflowBackfillScope,
 	buildWorkflowExecutionEventData,
 	buildWorkflowExecutionSnapshot,
+	buildWorkflowScheduleScope,
 	createServiceIdempotencyRequestHash,
+	createWebhookSecret,
 	createWorkflowExecutionSnapshotHash,
+	isPrivateWebhookAddress,
+	isPrivateWebhookTarget,
+	isWorkflowBackfillInProject,
 	mergeRequestedDocumentIds,
 	parseBoolean,
 	parseCSVList,
 	parseServiceDocumentListQuery,
+	toServiceWebhookDeliveryItem,
+	toServiceWebhookItem,
+	toServiceWorkflowTriggerItem,
 } from "./service.helpers";
 
 describe("service route helpers", () => {
@@ -178,6 +188,7 @@ describe("service route helpers", () => {
 				"execution-1",
 				"workflow-1",
 				"org-1",
+				"key-1",
 				["doc-1"],
 				executionScope,
 				seededState,
@@ -186,6 +197,8 @@ describe("service route helpers", () => {
 			execution_id: "execution-1",
 			workflow_id: "workflow-1",
 			organization_id: "org-1",
+			api_key_id: "key-1",
+			source: "api_key",
 			document_ids: ["doc-1"],
 			scope: {
 				apiKeyBound: false,
@@ -277,4 +290,213 @@ describe("service route helpers", () => {
 			),
 		).toBe(createWorkflowExecutionSnapshotHash(snapshot));
 	});
+
+	test("buildWorkflowBackfillScope pins the backfill to the key's project", () => {
+		expect(buildWorkflowBackfillScope("project-1", undefined)).toEqual({
+			project_ids: ["project-1"],
+		});
+		expect(
+			buildWorkflowBackfillScope("project-1", {
+				apiKeyIds: ["key-1"],
+				apiKeyBound: true,
+			}),
+		).toEqual({
+			project_ids: ["project-1"],
+			api_key_ids: ["key-1"],
+			api_key_uploads_only: true,
+		});
+		expect(
+			buildWorkflowBackfillScope("project-1", { apiKeyBound: false }),
+		).toEqual({
+			project_ids: ["project-1"],
+			dashboard_uploads_only: true,
+		});
+	});
+
+	test("buildWorkflowBackfillOptions only forwards enabled options", () => {
+		expect(buildWorkflowBackfillOptions(undefined)).toEqual({});
+		expect(
+			buildWorkflowBackfillOptions({
+				withoutOcr: true,
+				withoutDescription: false,
+				ratePerMinute: 120,
+			}),
+		).toEqual({ without_ocr: true, rate_per_minute: 120 });
+	});
+
+	test("isWorkflowBackfillInProject matches the stored project scope", () => {
+		expect(
+			isWorkflowBackfillInProject({ project_ids: ["project-1"] }, "project-1"),
+		).toBe(true);
+		expect(
+			isWorkflowBackfillInProject({ project_ids: ["project-2"] }, "project-1"),
+		).toBe(false);
+		expect(isWorkflowBackfillInProject(null, "project-1")).toBe(false);
+	});
+
+	test("buildWorkflowScheduleScope adds the upload lookback window", () => {
+		expect(
+			buildWorkflowScheduleScope("project-1", { apiKeyBound: true }, 24),
+		).toEqual({
+			project_ids: ["project-1"],
+			api_key_uploads_only: true,
+			created_within_hours: 24,
+		});
+		expect(
+			buildWorkflowScheduleScope("project-1", undefined, undefined),
+		).toEqual({ project_ids: ["project-1"] });
+	});
+
+	test("toServiceWorkflowTriggerItem keeps only string pass keys", () => {
+		const timestamp = new Date("2026-03-15T02:00:00.000Z");
+		expect(
+			toServiceWorkflowTriggerItem({
+				id: "trigger-1",
+				agentGraphId: "workflow-ocr",
+				targetAgentGraphId: "workflow-ocr-review",
+				name: "Review low-confidence OCR",
+				onStatus: "completed",
+				condition: { key: "avg_confidence", operator: "lt", value: 60 },
+				passKeys: ["avg_confidence", 7],
+				enabled: true,
+				createdAt: timestamp,
+				updatedAt: timestamp,
+			}),
+		).toEqual({
+			triggerId: "trigger-1",
+			workflowId: "workflow-ocr",
+			targetWorkflowId: "workflow-ocr-review",
+			name: "Review low-confidence OCR",
+			on: "completed",
+			condition: { key: "avg_confidence", operator: "lt", value: 60 },
+			passKeys: ["avg_confidence"],
+			enabled: true,
+			createdAt: "2026-03-15T02:00:00.000Z",
+			updatedAt: "2026-03-15T02:00:00.000Z",
+		});
+	});
+
+	test("createWebhookSecret returns distinct prefixed secrets", () => {
+		const secret = createWebhookSecret();
+
+		expect(secret).toMatch(/^whsec_[0-9a-f]{64}$/);
+		expect(createWebhookSecret()).not.toBe(secret);
+	});
+
+	test("isPrivateWebhookAddress flags internal addresses", () => {
+		for (const address of [
+			"127.0.0.1",
+			"10.1.2.3",
+			"172.16.0.1",
+			"192.168.1.1",
+			"169.254.169.254",
+			"100.64.0.1",
+			"0.0.0.0",
+			"::1",
+			"fd00::1",
+			"fe80::1",
+			"::ffff:127.0.0.1",
+			"[::ffff:7f00:1]",
+		]) {
+			expect(isPrivateWebhookAddress(address)).toBe(true);
+		}
+		for (const address of [
+			"93.184.216.34",
+			"172.32.0.1",
+			"2606:4700::1111",
+		]) {
+			expect(isPrivateWebhookAddress(address)).toBe(false);
+		}
+	});
+
+	test("isPrivateWebhookTarget checks literal and resolved hosts", async () => {
+		const resolveHost = async (hostname: string) =>
+			hostname === "internal.example.com"
+				? ["10.0.0.5"]
+				: ["93.184.216.34"];
+
+		expect(
+			await isPrivateWebhookTarget("http://localhost:8080/hook", resolveHost),
+		).toBe(true);
+		expect(
+			await isPrivateWebhookTarget(
+				"http://169.254.169.254/latest/meta-data",
+				resolveHost,
+			),
+		).toBe(true);
+		expect(await isPrivateWebhookTarget("http://[::1]/hook", resolveHost)).toBe(
+			true,
+		);
+		expect(
+			await isPrivateWebhookTarget(
+				"https://internal.example.com/hook",
+				resolveHost,
+			),
+		).toBe(true);
+		expect(
+			await isPrivateWebhookTarget(
+				"https://hooks.example.com/hook",
+				resolveHost,
+			),
+		).toBe(false);
+	});
+
+	test("toServiceWebhookItem only includes the secret when given", () => {
+		const timestamp = new Date("2026-03-15T02:00:00.000Z");
+		const row = {
+			id: "webhook-1",
+			projectId: "project-1",
+			apiKeyId: null,
+			url: "https://hooks.example.com/arcnem",
+			statuses: ["completed", "failed"],
+			stateKeys: ["summary", 3],
+			enabled: true,
+			createdAt: timestamp,
+			updatedAt: timestamp,
+		};
+
+		expect(toServiceWebhookItem(row)).toEqual({
+			webhookId: "webhook-1",
+			projectId: "project-1",
+			apiKeyId: null,
+			url: "https://hooks.example.com/arcnem",
+			statuses: ["completed", "failed"],
+			stateKeys: ["summary"],
+			enabled: true,
+			createdAt: "2026-03-15T02:00:00.000Z",
+			updatedAt: "2026-03-15T02:00:00.000Z",
+		});
+		expect(toServiceWebhookItem(row, "whsec_test").secret).toBe("whsec_test");
+	});
+
+	test("toServiceWebhookDeliveryItem maps delivery log rows", () => {
+		const timestamp = new Date("2026-03-15T02:00:00.000Z");
+		expect(
+			toServiceWebhookDeliveryItem({
+				id: "delivery-1",
+				subscriptionId: "webhook-1",
+				runId: "run-1",
+				status: "failed",
+				attempts: 8,
+				lastStatusCode: 502,
+				lastError: "webhook endpoint returned status 502",
+				payload: { version: 1 },
+				deliveredAt: null,
+				createdAt: timestamp,
+				updatedAt: timestamp,
+			}),
+		).toEqual({
+			deliveryId: "delivery-1",
+			webhookId: "webhook-1",
+			executionId: "run-1",
+			status: "failed",
+			attempts: 8,
+			lastStatusCode: 502,
+			lastError: "webhook endpoint returned status 502",
+			payload: { version: 1 },
+			deliveredAt: null,
+			createdAt: "2026-03-15T02:00:00.000Z",
+			updatedAt: "2026-03-15T02:00:00.000Z",
+		});
+	});
 });
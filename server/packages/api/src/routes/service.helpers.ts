@@ -1,9 +1,22 @@
-import { createHash } from "node:crypto";
+import { createHash, randomBytes } from "node:crypto";
+import { lookup } from "node:dns/promises";
+import { isIP } from "node:net";
 import {
 	type ServiceDocumentListQuery,
 	type ServiceDocumentScope,
+	type ServiceWebhookDeliveryItem,
+	type ServiceWebhookItem,
+	type ServiceWorkflowBackfillItem,
+	type ServiceWorkflowBackfillRequest,
+	type ServiceWorkflowScheduleItem,
+	type ServiceWorkflowTriggerCondition,
+	type ServiceWorkflowTriggerItem,
 	serviceDocumentListQuerySchema,
 } from "@arcnem-vision/shared";
+import {
+	buildWorkflowJobRouting,
+	WORKFLOW_JOB_SOURCE,
+} from "@/lib/workflow-job-routing";
 
 type ScopedDocumentSelection = {
 	documentIds?: string[];
@@ -290,6 +303,7 @@ export function buildWorkflowExecutionEventData<
 	executionId: string,
 	workflowId: string,
 	organizationId: string,
+	apiKeyId: string,
 	documentIds: string[],
 	executionScope: ReturnType<typeof buildExecutionScope>,
 	initialState: T,
@@ -297,9 +311,302 @@ export function buildWorkflowExecutionEventData<
 	return {
 		execution_id: executionId,
 		workflow_id: workflowId,
-		organization_id: organizationId,
+		...buildWorkflowJobRouting({
+			organizationId,
+			apiKeyId,
+			source: WORKFLOW_JOB_SOURCE.apiKey,
+		}),
 		document_ids: documentIds,
 		scope: executionScope,
 		initial_state: initialState,
 	};
 }
+
+// Backfill scope and options are stored in the shape the agents worker reads.
+// Service keys can only backfill documents in their own project.
+export function buildWorkflowBackfillScope(
+	projectId: string,
+	scope: ServiceDocumentScope | undefined,
+) {
+	return {
+		project_ids: [projectId],
+		...(scope?.apiKeyIds?.length ? { api_key_ids: scope.apiKeyIds } : {}),
+		...(scope?.documentIds?.length ? { document_ids: scope.documentIds } : {}),
+		...(scope?.apiKeyBound === true ? { api_key_uploads_only: true } : {}),
+		...(scope?.apiKeyBound === false ? { dashboard_uploads_only: true } : {}),
+	};
+}
+
+export function buildWorkflowBackfillOptions(
+	options: ServiceWorkflowBackfillRequest["options"],
+) {
+	return {
+		...(options?.withoutOcr ? { without_ocr: true } : {}),
+		...(options?.withoutDescription ? { without_description: true } : {}),
+		...(options?.ratePerMinute
+			? { rate_per_minute: options.ratePerMinute }
+			: {}),
+	};
+}
+
+export function isWorkflowBackfillInProject(
+	scope: unknown,
+	projectId: string,
+) {
+	if (!scope || typeof scope !== "object" || Array.isArray(scope)) {
+		return false;
+	}
+	const projectIds = (scope as { project_ids?: unknown }).project_ids;
+	return (
+		Array.isArray(projectIds) &&
+		projectIds.length === 1 &&
+		projectIds[0] === projectId
+	);
+}
+
+export function toServiceWorkflowBackfillItem(row: {
+	id: string;
+	agentGraphId: string;
+	status: string;
+	total: number | null;
+	enqueued: number;
+	completed: number;
+	failed: number;
+	error: string | null;
+	createdAt: Date;
+	updatedAt: Date;
+	finishedAt: Date | null;
+}): ServiceWorkflowBackfillItem {
+	return {
+		backfillId: row.id,
+		workflowId: row.agentGraphId,
+		status: row.status as ServiceWorkflowBackfillItem["status"],
+		total: row.total,
+		enqueued: row.enqueued,
+		completed: row.completed,
+		failed: row.failed,
+		error: row.error,
+		createdAt: row.createdAt.toISOString(),
+		updatedAt: row.updatedAt.toISOString(),
+		finishedAt: row.finishedAt ? row.finishedAt.toISOString() : null,
+	};
+}
+
+export function buildWorkflowScheduleScope(
+	projectId: string,
+	scope: ServiceDocumentScope | undefined,
+	createdWithinHours: number | undefined,
+) {
+	return {
+		...buildWorkflowBackfillScope(projectId, scope),
+		...(createdWithinHours ? { created_within_hours: createdWithinHours } : {}),
+	};
+}
+
+export function toServiceWorkflowScheduleItem(row: {
+	id: string;
+	agentGraphId: string;
+	name: string;
+	cronExpression: string;
+	timezone: string;
+	enabled: boolean;
+	nextFireAt: Date | null;
+	lastFiredAt: Date | null;
+	lastError: string | null;
+	createdAt: Date;
+	updatedAt: Date;
+}): ServiceWorkflowScheduleItem {
+	return {
+		scheduleId: row.id,
+		workflowId: row.agentGraphId,
+		name: row.name,
+		cron: row.cronExpression,
+		timezone: row.timezone,
+		enabled: row.enabled,
+		nextFireAt: row.nextFireAt ? row.nextFireAt.toISOString() : null,
+		lastFiredAt: row.lastFiredAt ? row.lastFiredAt.toISOString() : null,
+		lastError: row.lastError,
+		createdAt: row.createdAt.toISOString(),
+		updatedAt: row.updatedAt.toISOString(),
+	};
+}
+
+export function toServiceWorkflowTriggerItem(row: {
+	id: string;
+	agentGraphId: string;
+	targetAgentGraphId: string;
+	name: string;
+	onStatus: string;
+	condition: unknown;
+	passKeys: unknown;
+	enabled: boolean;
+	createdAt: Date;
+	updatedAt: Date;
+}): ServiceWorkflowTriggerItem {
+	return {
+		triggerId: row.id,
+		workflowId: row.agentGraphId,
+		targetWorkflowId: row.targetAgentGraphId,
+		name: row.name,
+		on: row.onStatus as ServiceWorkflowTriggerItem["on"],
+		condition:
+			(row.condition as ServiceWorkflowTriggerCondition | null) ?? null,
+		passKeys: Array.isArray(row.passKeys)
+			? row.passKeys.filter((key): key is string => typeof key === "string")
+			: [],
+		enabled: row.enabled,
+		createdAt: row.createdAt.toISOString(),
+		updatedAt: row.updatedAt.toISOString(),
+	};
+}
+
+export function createWebhookSecret(): string {
+	return `whsec_${randomBytes(32).toString("hex")}`;
+}
+
+function isPrivateIPv4Address(address: string): boolean {
+	const octets = address.split(".").map(Number);
+	const [a = 0, b = 0] = octets;
+	return (
+		a === 0 ||
+		a === 10 ||
+		a === 127 ||
+		a >= 224 ||
+		(a === 100 && b >= 64 && b < 128) ||
+		(a === 169 && b === 254) ||
+		(a === 172 && b >= 16 && b < 32) ||
+		(a === 192 && b === 168)
+	);
+}
+
+// Webhook targets must be publicly routable, so loopback, private,
+// link-local, and carrier-grade NAT addresses are refused.
+export function isPrivateWebhookAddress(address: string): boolean {
+	const normalized = address.toLowerCase().replace(/^\[|\]$/g, "");
+	const version = isIP(normalized);
+	if (version === 4) {
+		return isPrivateIPv4Address(normalized);
+	}
+	if (version !== 6) {
+		return true;
+	}
+
+	const mapped = normalized.match(/^::ffff:(\d+\.\d+\.\d+\.\d+)$/);
+	if (mapped?.[1]) {
+		return isPrivateIPv4Address(mapped[1]);
+	}
+	const mappedHex = normalized.match(
+		/^::ffff:([0-9a-f]{1,4}):([0-9a-f]{1,4})$/,
+	);
+	if (mappedHex?.[1] && mappedHex[2]) {
+		const high = Number.parseInt(mappedHex[1], 16);
+		const low = Number.parseInt(mappedHex[2], 16);
+		return isPrivateIPv4Address(
+			`${high >> 8}.${high & 0xff}.${low >> 8}.${low & 0xff}`,
+		);
+	}
+	if (normalized === "::" || normalized === "::1") {
+		return true;
+	}
+
+	const firstHextet = normalized.startsWith("::")
+		? 0
+		: Number.parseInt(normalized.split(":")[0] ?? "0", 16);
+	return (
+		(firstHextet & 0xfe00) === 0xfc00 ||
+		(firstHextet & 0xffc0) === 0xfe80 ||
+		(firstHextet & 0xff00) === 0xff00
+	);
+}
+
+type WebhookHostLookup = (hostname: string) => Promise<string[]>;
+
+async function lookupWebhookHost(hostname: string): Promise<string[]> {
+	const addresses = await lookup(hostname, { all: true, verbatim: true });
+	return addresses.map((entry) => entry.address);
+}
+
+// Hostnames that do not resolve yet are accepted. The agents service checks
+// the dialed address again on every delivery.
+export async function isPrivateWebhookTarget(
+	url: string,
+	resolveHost: WebhookHostLookup = lookupWebhookHost,
+): Promise<boolean> {
+	const hostname = new URL(url).hostname.toLowerCase().replace(/\.$/, "");
+	if (hostname === "localhost" || hostname.endsWith(".localhost")) {
+		return true;
+	}
+	if (isIP(hostname.replace(/^\[|\]$/g, "")) !== 0) {
+		return isPrivateWebhookAddress(hostname);
+	}
+
+	let addresses: string[];
+	try {
+		addresses = await resolveHost(hostname);
+	} catch {
+		return false;
+	}
+	return addresses.some(isPrivateWebhookAddress);
+}
+
+function stringArray(value: unknown): string[] {
+	return Array.isArray(value)
+		? value.filter((item): item is string => typeof item === "string")
+		: [];
+}
+
+export function toServiceWebhookItem(
+	row: {
+		id: string;
+		projectId: string;
+		apiKeyId: string | null;
+		url: string;
+		statuses: unknown;
+		stateKeys: unknown;
+		enabled: boolean;
+		createdAt: Date;
+		updatedAt: Date;
+	},
+	secret?: string,
+): ServiceWebhookItem {
+	return {
+		webhookId: row.id,
+		projectId: row.projectId,
+		apiKeyId: row.apiKeyId,
+		url: row.url,
+		statuses: stringArray(row.statuses) as ServiceWebhookItem["statuses"],
+		stateKeys: stringArray(row.stateKeys),
+		enabled: row.enabled,
+		...(secret ? { secret } : {}),
+		createdAt: row.createdAt.toISOString(),
+		updatedAt: row.updatedAt.toISOString(),
+	};
+}
+
+export function toServiceWebhookDeliveryItem(row: {
+	id: string;
+	subscriptionId: string;
+	runId: string;
+	status: string;
+	attempts: number;
+	lastStatusCode: number | null;
+	lastError: string | null;
+	payload: unknown;
+	deliveredAt: Date | null;
+	createdAt: Date;
+	updatedAt: Date;
+}): ServiceWebhookDeliveryItem {
+	return {
+		deliveryId: row.id,
+		webhookId: row.subscriptionId,
+		executionId: row.runId,
+		status: row.status as ServiceWebhookDeliveryItem["status"],
+		attempts: row.attempts,
+		lastStatusCode: row.lastStatusCode,
+		lastError: row.lastError,
+		payload: (row.payload as ServiceWebhookDeliveryItem["payload"]) ?? null,
+		deliveredAt: row.deliveredAt?.toISOString() ?? null,
+		createdAt: row.createdAt.toISOString(),
+		updatedAt: row.updatedAt.toISOString(),
+	};
+}
@@ -15,10 +15,24 @@ import {
 	serviceUploadAcknowledgeResponseSchema,
 	serviceUploadPresignRequestSchema,
 	serviceUploadPresignResponseSchema,
+	serviceWebhookDeliveriesResponseSchema,
+	serviceWebhookDeliveryItemSchema,
+	serviceWebhookItemSchema,
+	serviceWebhookRequestSchema,
+	serviceWebhooksResponseSchema,
+	serviceWorkflowBackfillItemSchema,
+	serviceWorkflowBackfillRequestSchema,
 	serviceWorkflowExecutionAcceptedSchema,
 	serviceWorkflowExecutionItemSchema,
 	serviceWorkflowExecutionRequestSchema,
+	serviceWorkflowScheduleItemSchema,
+	serviceWorkflowScheduleRequestSchema,
+	serviceWorkflowSchedulesResponseSchema,
+	serviceWorkflowScheduleUpdateSchema,
 	serviceWorkflowsResponseSchema,
+	serviceWorkflowTriggerItemSchema,
+	serviceWorkflowTriggerRequestSchema,
+	serviceWorkflowTriggersResponseSchema,
 } from "@arcnem-vision/shared";
 import {
 	and,
@@ -30,11 +44,13 @@ import {
 	isNull,
 	lt,
 	type SQL,
+	sql,
 } from "drizzle-orm";
 import { Hono, type Context as HonoContext } from "hono";
 import { describeRoute, resolver, validator } from "hono-openapi";
-import type { Inngest } from "inngest";
 import { getApiMcpClient } from "@/clients/apiMcpClient";
+import { isPostgresJobBackend, type JobClient } from "@/clients/jobs";
+import { API_ENV_VAR } from "@/env/apiEnvVar";
 import { toAPIDocumentItem } from "@/lib/document-api";
 import {
 	acknowledgePresignedUpload,
@@ -54,12 +70,23 @@ import {
 	buildExecutionScope,
 	buildSeededInitialState,
 	buildServiceDocumentSearchScope,
+	buildWorkflowBackfillOptions,
+	buildWorkflowBackfillScope,
 	buildWorkflowExecutionEventData,
 	buildWorkflowExecutionSnapshot,
+	buildWorkflowScheduleScope,
 	createServiceIdempotencyRequestHash,
+	createWebhookSecret,
 	createWorkflowExecutionSnapshotHash,
+	isPrivateWebhookTarget,
+	isWorkflowBackfillInProject,
 	mergeRequestedDocumentIds,
 	parseServiceDocumentListQuery,
+	toServiceWebhookDeliveryItem,
+	toServiceWebhookItem,
+	toServiceWorkflowBackfillItem,
+	toServiceWorkflowScheduleItem,
+	toServiceWorkflowTriggerItem,
 } from "./service.helpers";
 
 const {
@@ -71,11 +98,23 @@ const {
 	organizations,
 	presignedUploads,
 	projects,
+	webhookDeliveries,
+	webhookSubscriptions,
+	workflowBackfills,
+	workflowSchedules,
+	workflowTriggers,
 } = schema;
 
 const DEFAULT_PAGE_SIZE = 20;
 const MAX_SCOPED_DOCUMENTS = 500;
 const WORKFLOW_ENQUEUE_ERROR = "Failed to enqueue workflow execution";
+const WORKFLOW_BACKFILL_ENQUEUE_ERROR = "Failed to enqueue workflow backfill";
+// Backfills and schedules sleep between steps, so the postgres job backend
+// cannot run them.
+const WORKFLOW_BACKFILL_BACKEND_ERROR =
+	"Workflow backfills need the Inngest job backend and are disabled when JOB_BACKEND=postgres";
+const WORKFLOW_SCHEDULE_BACKEND_ERROR =
+	"Workflow schedules need the Inngest job backend and are disabled when JOB_BACKEND=postgres";
 const jsonErrorSchema = resolver(serviceErrorResponseSchema);
 const jsonSelectionErrorSchema = resolver(serviceDocumentSelectionErrorSchema);
 
@@ -175,14 +214,15 @@ async function findAcknowledgedServiceUpload(
 }
 
 async function dispatchServiceWorkflowExecution(input: {
-	inngestClient: Inngest;
+	jobClient: JobClient;
 	organizationId: string;
+	apiKeyId: string;
 	projectId: string;
 	request: ServiceWorkflowExecutionRequest;
 	response: ServiceWorkflowExecutionAccepted;
 	run: { status: string };
 }) {
-	const { inngestClient, projectId, request, response, run } = input;
+	const { jobClient, projectId, request, response, run } = input;
 
 	if (run.status !== "running") {
 		return true;
@@ -199,7 +239,7 @@ async function dispatchServiceWorkflowExecution(input: {
 	);
 
 	try {
-		await inngestClient.send({
+		await jobClient.send({
 			// A stable event ID keeps concurrent retries from starting another run.
 			id: response.executionId,
 			name: "workflow/execute",
@@ -207,6 +247,7 @@ async function dispatchServiceWorkflowExecution(input: {
 				response.executionId,
 				response.workflowId,
 				input.organizationId,
+				input.apiKeyId,
 				response.documentIds,
 				executionScope,
 				seededState,
@@ -645,7 +686,7 @@ serviceRouter.post(
 
 		const body = c.req.valid("json");
 		const dbClient = c.get("dbClient");
-		const inngestClient = c.get("inngestClient");
+		const jobClient = c.get("jobClient");
 		const { idempotencyKey, ...requestInput } = body;
 		const requestHash = createServiceIdempotencyRequestHash(requestInput);
 
@@ -669,8 +710,9 @@ serviceRouter.post(
 			}
 
 			const enqueued = await dispatchServiceWorkflowExecution({
-				inngestClient,
+				jobClient,
 				organizationId: apiKey.organizationId,
+				apiKeyId: apiKey.id,
 				projectId: apiKey.projectId,
 				request: body,
 				response: replay.data,
@@ -838,8 +880,9 @@ serviceRouter.post(
 		}
 
 		const enqueued = await dispatchServiceWorkflowExecution({
-			inngestClient,
+			jobClient,
 			organizationId: apiKey.organizationId,
+			apiKeyId: apiKey.id,
 			projectId: apiKey.projectId,
 			request: body,
 			response,
@@ -974,10 +1017,15 @@ serviceRouter.get(
 				projectId: agentGraphRuns.projectId,
 				status: agentGraphRuns.status,
 				error: agentGraphRuns.error,
+				errorCode: agentGraphRuns.errorCode,
 				finalState: agentGraphRuns.finalState,
 				graphSnapshotHash: agentGraphRuns.graphSnapshotHash,
 				startedAt: agentGraphRuns.startedAt,
+				pickedUpAt: agentGraphRuns.pickedUpAt,
 				finishedAt: agentGraphRuns.finishedAt,
+				scheduleId: agentGraphRuns.scheduleId,
+				parentRunId: agentGraphRuns.parentRunId,
+				triggerId: agentGraphRuns.triggerId,
 				organizationId: agentGraphs.organizationId,
 			})
 			.from(agentGraphRuns)
@@ -999,10 +1047,17 @@ serviceRouter.get(
 			snapshotHash: row.graphSnapshotHash,
 			status: row.status,
 			startedAt: row.startedAt ? new Date(row.startedAt).toISOString() : null,
+			pickedUpAt: row.pickedUpAt
+				? new Date(row.pickedUpAt).toISOString()
+				: null,
 			finishedAt: row.finishedAt
 				? new Date(row.finishedAt).toISOString()
 				: null,
 			error: row.error,
+			errorCode: row.errorCode,
+			scheduleId: row.scheduleId,
+			parentExecutionId: row.parentRunId,
+			triggerId: row.triggerId,
 			finalState: row.finalState ?? null,
 		});
 	},
@@ -1406,3 +1461,1078 @@ serviceRouter.post(
 		});
 	},
 );
+
+async function findServiceWorkflowBackfill(
+	dbClient: PGDB,
+	apiKey: ServiceKeyScope,
+	backfillId: string,
+) {
+	const backfill = await dbClient.query.workflowBackfills.findFirst({
+		where: (row, { and, eq }) =>
+			and(
+				eq(row.id, backfillId),
+				eq(row.organizationId, apiKey.organizationId),
+			),
+	});
+	if (
+		!backfill ||
+		!isWorkflowBackfillInProject(backfill.scope, apiKey.projectId)
+	) {
+		return null;
+	}
+	return backfill;
+}
+
+const idPathParameters = [
+	{
+		name: "id",
+		in: "path" as const,
+		required: true,
+		schema: { type: "string" as const },
+	},
+];
+
+const workflowBackfillResponses = {
+	200: {
+		description: "Backfill state",
+		content: {
+			"application/json": {
+				schema: resolver(serviceWorkflowBackfillItemSchema),
+			},
+		},
+	},
+	401: {
+		description: "Unauthorized",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+	403: {
+		description: "Forbidden",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+	404: {
+		description: "Backfill not found",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+};
+
+serviceRouter.post(
+	"/service/workflow-backfills",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Start a workflow backfill",
+		description:
+			"Runs a workflow over the existing documents in the API key's project, enqueuing executions at a controlled rate.",
+		responses: {
+			202: {
+				description: "Backfill accepted",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWorkflowBackfillItemSchema),
+					},
+				},
+			},
+			400: {
+				description: "Invalid request",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			401: {
+				description: "Unauthorized",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			403: {
+				description: "Forbidden",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			404: {
+				description: "Workflow not found",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			409: {
+				description: "Backfills are disabled on the postgres job backend",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			502: {
+				description: "Failed to enqueue backfill",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	validator(
+		"json",
+		serviceWorkflowBackfillRequestSchema,
+		serviceJSONBodyValidation,
+	),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		if (isPostgresJobBackend()) {
+			return c.json({ message: WORKFLOW_BACKFILL_BACKEND_ERROR }, 409);
+		}
+
+		const body = c.req.valid("json");
+		const dbClient = c.get("dbClient");
+		const jobClient = c.get("jobClient");
+
+		const workflow = await dbClient.query.agentGraphs.findFirst({
+			where: (row, { and, eq, isNull }) =>
+				and(
+					eq(row.id, body.workflowId),
+					eq(row.organizationId, apiKey.organizationId),
+					isNull(row.archivedAt),
+				),
+			columns: { id: true },
+		});
+		if (!workflow) {
+			return c.json({ message: "Workflow not found" }, 404);
+		}
+
+		const [backfill] = await dbClient
+			.insert(workflowBackfills)
+			.values({
+				organizationId: apiKey.organizationId,
+				agentGraphId: workflow.id,
+				apiKeyId: apiKey.id,
+				scope: buildWorkflowBackfillScope(apiKey.projectId, body.scope),
+				options: buildWorkflowBackfillOptions(body.options),
+			})
+			.returning();
+		if (!backfill) {
+			throw new Error("Failed to create workflow backfill");
+		}
+
+		try {
+			await jobClient.send({
+				id: `backfill-start-${backfill.id}`,
+				name: "workflow/backfill",
+				data: {
+					backfill_id: backfill.id,
+					organization_id: apiKey.organizationId,
+					generation: backfill.generation,
+				},
+			});
+		} catch {
+			await dbClient
+				.update(workflowBackfills)
+				.set({
+					status: "failed",
+					error: WORKFLOW_BACKFILL_ENQUEUE_ERROR,
+					finishedAt: new Date(),
+				})
+				.where(eq(workflowBackfills.id, backfill.id));
+			return c.json({ message: WORKFLOW_BACKFILL_ENQUEUE_ERROR }, 502);
+		}
+
+		return c.json(toServiceWorkflowBackfillItem(backfill), 202);
+	},
+);
+
+serviceRouter.get(
+	"/service/workflow-backfills/:id",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Read a workflow backfill",
+		parameters: idPathParameters,
+		responses: workflowBackfillResponses,
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "read"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const backfill = await findServiceWorkflowBackfill(
+			c.get("dbClient"),
+			apiKey,
+			c.req.param("id"),
+		);
+		if (!backfill) {
+			return c.json({ message: "Backfill not found" }, 404);
+		}
+
+		return c.json(toServiceWorkflowBackfillItem(backfill));
+	},
+);
+
+// Pausing only flips the status: the backfill job stops before claiming its
+// next page, and runs it already enqueued finish normally. Resuming starts a
+// new generation so a job still sleeping from before the pause stops instead
+// of paging alongside the new one.
+const workflowBackfillTransitions = {
+	pause: {
+		from: ["running"],
+		to: "paused",
+		summary: "Pause a workflow backfill",
+	},
+	resume: {
+		from: ["paused"],
+		to: "running",
+		summary: "Resume a workflow backfill",
+	},
+	cancel: {
+		from: ["running", "paused"],
+		to: "cancelled",
+		summary: "Cancel a workflow backfill",
+	},
+} as const;
+
+for (const [action, transition] of Object.entries(
+	workflowBackfillTransitions,
+)) {
+	serviceRouter.post(
+		`/service/workflow-backfills/:id/${action}`,
+		describeRoute({
+			tags: ["Service"],
+			summary: transition.summary,
+			parameters: idPathParameters,
+			responses: {
+				...workflowBackfillResponses,
+				409: {
+					description: "Backfill cannot make this transition",
+					content: { "application/json": { schema: jsonErrorSchema } },
+				},
+				502: {
+					description: "Failed to enqueue backfill",
+					content: { "application/json": { schema: jsonErrorSchema } },
+				},
+			},
+		}),
+		requireAPIKey,
+		requireServiceAPIKey,
+		requireAPIKeyPermission("workflows", "execute"),
+		async (c) => {
+			const apiKey = c.get("apiKey");
+			if (!apiKey) {
+				return c.json({ message: "Unauthorized" }, 401);
+			}
+
+			const dbClient = c.get("dbClient");
+			const jobClient = c.get("jobClient");
+			const backfill = await findServiceWorkflowBackfill(
+				dbClient,
+				apiKey,
+				c.req.param("id"),
+			);
+			if (!backfill) {
+				return c.json({ message: "Backfill not found" }, 404);
+			}
+
+			const [updated] = await dbClient
+				.update(workflowBackfills)
+				.set({
+					status: transition.to,
+					...(transition.to === "cancelled"
+						? { finishedAt: new Date() }
+						: {}),
+					...(transition.to === "running"
+						? { generation: sql`${workflowBackfills.generation} + 1` }
+						: {}),
+				})
+				.where(
+					and(
+						eq(workflowBackfills.id, backfill.id),
+						inArray(workflowBackfills.status, [...transition.from]),
+					),
+				)
+				.returning();
+			if (!updated) {
+				return c.json(
+					{ message: `Backfill is ${backfill.status} and cannot ${action}` },
+					409,
+				);
+			}
+
+			const eventData = {
+				backfill_id: updated.id,
+				organization_id: updated.organizationId,
+			};
+			try {
+				if (transition.to === "running") {
+					await jobClient.send({
+						id: `backfill-resume-${updated.id}-${updated.generation}`,
+						name: "workflow/backfill",
+						data: { ...eventData, generation: updated.generation },
+					});
+				}
+				if (transition.to === "cancelled") {
+					await jobClient.send({
+						name: "workflow/backfill.cancel",
+						data: { ...eventData, reason: "cancelled by API key" },
+					});
+				}
+			} catch {
+				if (transition.to === "running") {
+					await dbClient
+						.update(workflowBackfills)
+						.set({ status: "paused" })
+						.where(eq(workflowBackfills.id, updated.id));
+				}
+				return c.json({ message: WORKFLOW_BACKFILL_ENQUEUE_ERROR }, 502);
+			}
+
+			return c.json(toServiceWorkflowBackfillItem(updated));
+		},
+	);
+}
+
+const workflowScheduleResponses = {
+	401: {
+		description: "Unauthorized",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+	403: {
+		description: "Forbidden",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+	404: {
+		description: "Schedule not found",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+};
+
+function workflowScheduleScopeFilter(apiKey: ServiceKeyScope, id: string) {
+	return and(
+		eq(workflowSchedules.id, id),
+		eq(workflowSchedules.organizationId, apiKey.organizationId),
+		eq(workflowSchedules.projectId, apiKey.projectId),
+	);
+}
+
+serviceRouter.post(
+	"/service/workflow-schedules",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Create a workflow schedule",
+		description:
+			"Runs a workflow on a cron schedule over the documents in the API key's project that match the scope.",
+		responses: {
+			...workflowScheduleResponses,
+			201: {
+				description: "Schedule created",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWorkflowScheduleItemSchema),
+					},
+				},
+			},
+			400: {
+				description: "Invalid request",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			404: {
+				description: "Workflow not found",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			409: {
+				description: "Schedules are disabled on the postgres job backend",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	validator(
+		"json",
+		serviceWorkflowScheduleRequestSchema,
+		serviceJSONBodyValidation,
+	),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		if (isPostgresJobBackend()) {
+			return c.json({ message: WORKFLOW_SCHEDULE_BACKEND_ERROR }, 409);
+		}
+
+		const body = c.req.valid("json");
+		const dbClient = c.get("dbClient");
+		const workflow = await dbClient.query.agentGraphs.findFirst({
+			where: (row, { and, eq, isNull }) =>
+				and(
+					eq(row.id, body.workflowId),
+					eq(row.organizationId, apiKey.organizationId),
+					isNull(row.archivedAt),
+				),
+			columns: { id: true },
+		});
+		if (!workflow) {
+			return c.json({ message: "Workflow not found" }, 404);
+		}
+
+		const [schedule] = await dbClient
+			.insert(workflowSchedules)
+			.values({
+				organizationId: apiKey.organizationId,
+				projectId: apiKey.projectId,
+				agentGraphId: workflow.id,
+				apiKeyId: apiKey.id,
+				name: body.name,
+				cronExpression: body.cron,
+				timezone: body.timezone ?? "UTC",
+				scope: buildWorkflowScheduleScope(
+					apiKey.projectId,
+					body.scope,
+					body.createdWithinHours,
+				),
+				initialState: body.initialState ?? null,
+				enabled: body.enabled ?? true,
+			})
+			.returning();
+		if (!schedule) {
+			throw new Error("Failed to create workflow schedule");
+		}
+
+		return c.json(toServiceWorkflowScheduleItem(schedule), 201);
+	},
+);
+
+serviceRouter.get(
+	"/service/workflow-schedules",
+	describeRoute({
+		tags: ["Service"],
+		summary: "List workflow schedules",
+		responses: {
+			...workflowScheduleResponses,
+			200: {
+				description: "Workflow schedules in the API key's project",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWorkflowSchedulesResponseSchema),
+					},
+				},
+			},
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "read"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const schedules = await c
+			.get("dbClient")
+			.select()
+			.from(workflowSchedules)
+			.where(
+				and(
+					eq(workflowSchedules.organizationId, apiKey.organizationId),
+					eq(workflowSchedules.projectId, apiKey.projectId),
+				),
+			)
+			.orderBy(asc(workflowSchedules.name), asc(workflowSchedules.id));
+
+		return c.json({
+			schedules: schedules.map(toServiceWorkflowScheduleItem),
+		});
+	},
+);
+
+serviceRouter.patch(
+	"/service/workflow-schedules/:id",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Update a workflow schedule",
+		description:
+			"Renames, reschedules, or enables and disables a schedule. Changing the cron expression, time zone, or enabled flag recomputes the next firing.",
+		parameters: idPathParameters,
+		responses: {
+			...workflowScheduleResponses,
+			200: {
+				description: "Schedule updated",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWorkflowScheduleItemSchema),
+					},
+				},
+			},
+			400: {
+				description: "Invalid request",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	validator(
+		"json",
+		serviceWorkflowScheduleUpdateSchema,
+		serviceJSONBodyValidation,
+	),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const body = c.req.valid("json");
+		const reschedule =
+			body.cron !== undefined ||
+			body.timezone !== undefined ||
+			body.enabled !== undefined;
+		const [schedule] = await c
+			.get("dbClient")
+			.update(workflowSchedules)
+			.set({
+				...(body.name !== undefined ? { name: body.name } : {}),
+				...(body.cron !== undefined ? { cronExpression: body.cron } : {}),
+				...(body.timezone !== undefined ? { timezone: body.timezone } : {}),
+				...(body.enabled !== undefined ? { enabled: body.enabled } : {}),
+				// The agents service works out the next firing from updatedAt.
+				...(reschedule ? { nextFireAt: null, lastError: null } : {}),
+			})
+			.where(workflowScheduleScopeFilter(apiKey, c.req.param("id")))
+			.returning();
+		if (!schedule) {
+			return c.json({ message: "Schedule not found" }, 404);
+		}
+
+		return c.json(toServiceWorkflowScheduleItem(schedule));
+	},
+);
+
+serviceRouter.delete(
+	"/service/workflow-schedules/:id",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Delete a workflow schedule",
+		description:
+			"Deletes a schedule. Runs it already started keep running and keep their history.",
+		parameters: idPathParameters,
+		responses: {
+			...workflowScheduleResponses,
+			204: { description: "Schedule deleted" },
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const [deleted] = await c
+			.get("dbClient")
+			.delete(workflowSchedules)
+			.where(workflowScheduleScopeFilter(apiKey, c.req.param("id")))
+			.returning({ id: workflowSchedules.id });
+		if (!deleted) {
+			return c.json({ message: "Schedule not found" }, 404);
+		}
+
+		return c.body(null, 204);
+	},
+);
+
+serviceRouter.post(
+	"/service/workflow-triggers",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Create a workflow trigger",
+		description:
+			"Starts the target workflow on the same documents when a run of the source workflow finishes and the condition on its final state holds.",
+		responses: {
+			201: {
+				description: "Trigger created",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWorkflowTriggerItemSchema),
+					},
+				},
+			},
+			400: {
+				description: "Invalid request",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			401: {
+				description: "Unauthorized",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			403: {
+				description: "Forbidden",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			404: {
+				description: "Workflow not found",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	validator(
+		"json",
+		serviceWorkflowTriggerRequestSchema,
+		serviceJSONBodyValidation,
+	),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const body = c.req.valid("json");
+		const dbClient = c.get("dbClient");
+		const workflows = await dbClient.query.agentGraphs.findMany({
+			where: (row, { and, eq, inArray, isNull }) =>
+				and(
+					inArray(row.id, [body.workflowId, body.targetWorkflowId]),
+					eq(row.organizationId, apiKey.organizationId),
+					isNull(row.archivedAt),
+				),
+			columns: { id: true },
+		});
+		if (workflows.length !== 2) {
+			return c.json({ message: "Workflow not found" }, 404);
+		}
+
+		const [trigger] = await dbClient
+			.insert(workflowTriggers)
+			.values({
+				organizationId: apiKey.organizationId,
+				agentGraphId: body.workflowId,
+				targetAgentGraphId: body.targetWorkflowId,
+				name: body.name,
+				onStatus: body.on ?? "completed",
+				condition: body.condition ?? null,
+				passKeys: body.passKeys ?? [],
+				enabled: body.enabled ?? true,
+			})
+			.returning();
+		if (!trigger) {
+			throw new Error("Failed to create workflow trigger");
+		}
+
+		return c.json(toServiceWorkflowTriggerItem(trigger), 201);
+	},
+);
+
+serviceRouter.get(
+	"/service/workflow-triggers",
+	describeRoute({
+		tags: ["Service"],
+		summary: "List workflow triggers",
+		description:
+			"Lists the organization's workflow triggers, optionally only those of one source workflow.",
+		parameters: [
+			{
+				name: "workflowId",
+				in: "query",
+				required: false,
+				schema: { type: "string" },
+			},
+		],
+		responses: {
+			200: {
+				description: "Workflow triggers",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWorkflowTriggersResponseSchema),
+					},
+				},
+			},
+			401: {
+				description: "Unauthorized",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			403: {
+				description: "Forbidden",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "read"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const workflowId = c.req.query("workflowId");
+		const triggers = await c
+			.get("dbClient")
+			.select()
+			.from(workflowTriggers)
+			.where(
+				and(
+					eq(workflowTriggers.organizationId, apiKey.organizationId),
+					workflowId
+						? eq(workflowTriggers.agentGraphId, workflowId)
+						: undefined,
+				),
+			)
+			.orderBy(asc(workflowTriggers.name), asc(workflowTriggers.id));
+
+		return c.json({
+			triggers: triggers.map(toServiceWorkflowTriggerItem),
+		});
+	},
+);
+
+serviceRouter.delete(
+	"/service/workflow-triggers/:id",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Delete a workflow trigger",
+		description:
+			"Deletes a trigger. Runs it already started keep running and keep their link to the run that started them.",
+		parameters: idPathParameters,
+		responses: {
+			204: { description: "Trigger deleted" },
+			401: {
+				description: "Unauthorized",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			403: {
+				description: "Forbidden",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			404: {
+				description: "Trigger not found",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const [deleted] = await c
+			.get("dbClient")
+			.delete(workflowTriggers)
+			.where(
+				and(
+					eq(workflowTriggers.id, c.req.param("id")),
+					eq(workflowTriggers.organizationId, apiKey.organizationId),
+				),
+			)
+			.returning({ id: workflowTriggers.id });
+		if (!deleted) {
+			return c.json({ message: "Trigger not found" }, 404);
+		}
+
+		return c.body(null, 204);
+	},
+);
+
+const WEBHOOK_PRIVATE_TARGET_ERROR =
+	"Webhook URLs cannot point at loopback, private, or link-local addresses";
+
+const webhookResponses = {
+	401: {
+		description: "Unauthorized",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+	403: {
+		description: "Forbidden",
+		content: { "application/json": { schema: jsonErrorSchema } },
+	},
+};
+
+serviceRouter.post(
+	"/service/webhooks",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Create a webhook",
+		description:
+			"Subscribes a URL to finished executions in the API key's project. The signing secret is only returned here.",
+		responses: {
+			201: {
+				description: "Webhook created",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWebhookItemSchema),
+					},
+				},
+			},
+			400: {
+				description: "Invalid request",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			...webhookResponses,
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	validator("json", serviceWebhookRequestSchema, serviceJSONBodyValidation),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const body = c.req.valid("json");
+		if (
+			process.env[API_ENV_VAR.WEBHOOK_ALLOW_PRIVATE_TARGETS] !== "true" &&
+			(await isPrivateWebhookTarget(body.url))
+		) {
+			return c.json({ message: WEBHOOK_PRIVATE_TARGET_ERROR }, 400);
+		}
+		const secret = createWebhookSecret();
+		const [webhook] = await c
+			.get("dbClient")
+			.insert(webhookSubscriptions)
+			.values({
+				organizationId: apiKey.organizationId,
+				projectId: apiKey.projectId,
+				apiKeyId: body.apiKeyOnly ? apiKey.id : null,
+				url: body.url,
+				secret,
+				...(body.statuses ? { statuses: body.statuses } : {}),
+				stateKeys: body.stateKeys ?? [],
+				enabled: body.enabled ?? true,
+			})
+			.returning();
+		if (!webhook) {
+			throw new Error("Failed to create webhook");
+		}
+
+		return c.json(toServiceWebhookItem(webhook, secret), 201);
+	},
+);
+
+serviceRouter.get(
+	"/service/webhooks",
+	describeRoute({
+		tags: ["Service"],
+		summary: "List webhooks",
+		description: "Lists the webhooks of the API key's project.",
+		responses: {
+			200: {
+				description: "Webhooks",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWebhooksResponseSchema),
+					},
+				},
+			},
+			...webhookResponses,
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "read"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const webhooks = await c
+			.get("dbClient")
+			.select()
+			.from(webhookSubscriptions)
+			.where(eq(webhookSubscriptions.projectId, apiKey.projectId))
+			.orderBy(
+				asc(webhookSubscriptions.createdAt),
+				asc(webhookSubscriptions.id),
+			);
+
+		return c.json({
+			webhooks: webhooks.map((webhook) => toServiceWebhookItem(webhook)),
+		});
+	},
+);
+
+serviceRouter.delete(
+	"/service/webhooks/:id",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Delete a webhook",
+		description: "Deletes a webhook and its delivery log.",
+		parameters: idPathParameters,
+		responses: {
+			204: { description: "Webhook deleted" },
+			404: {
+				description: "Webhook not found",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			...webhookResponses,
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const [deleted] = await c
+			.get("dbClient")
+			.delete(webhookSubscriptions)
+			.where(
+				and(
+					eq(webhookSubscriptions.id, c.req.param("id")),
+					eq(webhookSubscriptions.projectId, apiKey.projectId),
+				),
+			)
+			.returning({ id: webhookSubscriptions.id });
+		if (!deleted) {
+			return c.json({ message: "Webhook not found" }, 404);
+		}
+
+		return c.body(null, 204);
+	},
+);
+
+serviceRouter.get(
+	"/service/webhooks/:id/deliveries",
+	describeRoute({
+		tags: ["Service"],
+		summary: "List webhook deliveries",
+		description:
+			"Lists a webhook's most recent deliveries with their attempts and last response.",
+		parameters: idPathParameters,
+		responses: {
+			200: {
+				description: "Webhook deliveries",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWebhookDeliveriesResponseSchema),
+					},
+				},
+			},
+			404: {
+				description: "Webhook not found",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			...webhookResponses,
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "read"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const dbClient = c.get("dbClient");
+		const webhook = await dbClient.query.webhookSubscriptions.findFirst({
+			where: (row, { and, eq }) =>
+				and(eq(row.id, c.req.param("id")), eq(row.projectId, apiKey.projectId)),
+			columns: { id: true },
+		});
+		if (!webhook) {
+			return c.json({ message: "Webhook not found" }, 404);
+		}
+
+		const deliveries = await dbClient
+			.select()
+			.from(webhookDeliveries)
+			.where(eq(webhookDeliveries.subscriptionId, webhook.id))
+			.orderBy(desc(webhookDeliveries.createdAt), desc(webhookDeliveries.id))
+			.limit(DEFAULT_PAGE_SIZE);
+
+		return c.json({
+			deliveries: deliveries.map(toServiceWebhookDeliveryItem),
+		});
+	},
+);
+
+serviceRouter.post(
+	"/service/webhook-deliveries/:id/redeliver",
+	describeRoute({
+		tags: ["Service"],
+		summary: "Redeliver a webhook",
+		description:
+			"Sends a delivery's original payload again with a fresh set of retries.",
+		parameters: idPathParameters,
+		responses: {
+			202: {
+				description: "Redelivery queued",
+				content: {
+					"application/json": {
+						schema: resolver(serviceWebhookDeliveryItemSchema),
+					},
+				},
+			},
+			404: {
+				description: "Delivery not found",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			502: {
+				description: "Redelivery could not be queued",
+				content: { "application/json": { schema: jsonErrorSchema } },
+			},
+			...webhookResponses,
+		},
+	}),
+	requireAPIKey,
+	requireServiceAPIKey,
+	requireAPIKeyPermission("workflows", "execute"),
+	async (c) => {
+		const apiKey = c.get("apiKey");
+		if (!apiKey) {
+			return c.json({ message: "Unauthorized" }, 401);
+		}
+
+		const dbClient = c.get("dbClient");
+		const [delivery] = await dbClient
+			.select({ id: webhookDeliveries.id })
+			.from(webhookDeliveries)
+			.innerJoin(
+				webhookSubscriptions,
+				eq(webhookSubscriptions.id, webhookDeliveries.subscriptionId),
+			)
+			.where(
+				and(
+					eq(webhookDeliveries.id, c.req.param("id")),
+					eq(webhookSubscriptions.projectId, apiKey.projectId),
+				),
+			)
+			.limit(1);
+		if (!delivery) {
+			return c.json({ message: "Delivery not found" }, 404);
+		}
+
+		const [updated] = await dbClient
+			.update(webhookDeliveries)
+			.set({ status: "pending", deliveredAt: null })
+			.where(eq(webhookDeliveries.id, delivery.id))
+			.returning();
+		if (!updated) {
+			return c.json({ message: "Delivery not found" }, 404);
+		}
+
+		try {
+			await c.get("jobClient").send({
+				id: `${updated.id}-redeliver-${Date.now()}`,
+				name: "webhook/deliver",
+				data: { delivery_id: updated.id },
+			});
+		} catch {
+			return c.json({ message: "Failed to enqueue webhook delivery" }, 502);
+		}
+
+		return c.json(toServiceWebhookDeliveryItem(updated), 202);
+	},
+);
@@ -1,12 +1,12 @@
 import type { PGDB } from "@arcnem-vision/db/server";
 import type { S3Client } from "bun";
-import type { Inngest } from "inngest";
+import type { JobClient } from "@/clients/jobs";
 import type { VerifiedAPIKey } from "@/lib/api-keys";
 import type { AuthType } from "./auth";
 
 export type ServerContext = AuthType & {
 	s3Client: S3Client;
-	inngestClient: Inngest;
+	jobClient: JobClient;
 	dbClient: PGDB;
 	apiKey: VerifiedAPIKey | null;
 };
@@ -133,7 +133,12 @@ export function RunStepsDetail({
 				<>
 					<Separator />
 					<div>
-						<p className="mb-1 text-xs font-medium text-rose-500">Error</p>
+						<p className="mb-1 text-xs font-medium text-rose-500">
+							Error
+							{data.errorCode ? (
+								<span className="ml-1.5 font-mono">{data.errorCode}</span>
+							) : null}
+						</p>
 						<p className="text-sm text-rose-600">{data.error}</p>
 					</div>
 				</>
@@ -63,6 +63,11 @@ function statusIcon(status: string) {
 	}
 }
 
+// Runs accepted by the API stay queued until the agents worker picks them up.
+function runStatus(run: RunItem): string {
+	return run.status === "running" && !run.pickedUpAt ? "queued" : run.status;
+}
+
 function mergeRuns(primary: RunItem[], secondary: RunItem[]): RunItem[] {
 	const runsById = new Map<string, RunItem>();
 	for (const run of secondary) {
@@ -87,6 +92,8 @@ function RunRow({
 	onToggle: () => void;
 	refreshToken: number;
 }) {
+	const status = runStatus(run);
+
 	return (
 		<Card
 			className={cn(
@@ -104,14 +111,14 @@ function RunRow({
 						<div
 							className={cn(
 								"flex size-8 shrink-0 items-center justify-center rounded-lg",
-								run.status === "completed" && "bg-emerald-50",
-								run.status === "running" && "bg-amber-50",
-								run.status === "failed" && "bg-rose-50",
-								!["completed", "running", "failed"].includes(run.status) &&
+								status === "completed" && "bg-emerald-50",
+								status === "running" && "bg-amber-50",
+								status === "failed" && "bg-rose-50",
+								!["completed", "running", "failed"].includes(status) &&
 									"bg-slate-100",
 							)}
 						>
-							{statusIcon(run.status)}
+							{statusIcon(status)}
 						</div>
 						<div className="min-w-0 flex-1">
 							<CardTitle className="truncate text-sm font-medium">
@@ -120,7 +127,9 @@ function RunRow({
 							<CardDescription className="text-xs">
 								{formatTimestamp(run.startedAt)}
 								<span className="ml-2 tabular-nums text-slate-400">
-									{formatDuration(run.startedAt, run.finishedAt)}
+									{status === "queued"
+										? "queued"
+										: formatDuration(run.startedAt, run.finishedAt)}
 								</span>
 							</CardDescription>
 						</div>
@@ -128,10 +137,10 @@ function RunRow({
 							variant="outline"
 							className={cn(
 								"hidden shrink-0 rounded-full text-[11px] sm:inline-flex",
-								statusBadgeVariant(run.status),
+								statusBadgeVariant(status),
 							)}
 						>
-							{run.status}
+							{status}
 						</Badge>
 						<ChevronDown
 							className={cn(
@@ -142,6 +151,9 @@ function RunRow({
 					</div>
 					{run.error ? (
 						<p className="mt-1 truncate pl-10 text-xs text-rose-500">
+							{run.errorCode ? (
+								<span className="mr-1.5 font-mono">[{run.errorCode}]</span>
+							) : null}
 							{run.error.length > 100
 								? `${run.error.slice(0, 100)}...`
 								: run.error}
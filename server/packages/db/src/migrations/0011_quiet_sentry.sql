@@ -0,0 +1,2 @@
+ALTER TABLE "agent_graph_runs" DROP CONSTRAINT "agent_graph_runs_status_known";--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD CONSTRAINT "agent_graph_runs_status_known" CHECK ("agent_graph_runs"."status" in ('running', 'completed', 'failed', 'cancelled'));
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE "agent_graph_runs" ADD COLUMN "attempts" jsonb;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "agent_graph_run_steps" ADD COLUMN "error_code" text;--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD COLUMN "error_code" text;--> statement-breakpoint
+CREATE INDEX "agent_graph_runs_error_code_idx" ON "agent_graph_runs" USING btree ("error_code");
\ No newline at end of file
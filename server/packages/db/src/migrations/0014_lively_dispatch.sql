@@ -0,0 +1,2 @@
+ALTER TABLE "agent_graph_runs" ADD COLUMN "picked_up_at" timestamp;--> statement-breakpoint
+UPDATE "agent_graph_runs" SET "picked_up_at" = "started_at";
\ No newline at end of file
@@ -0,0 +1,28 @@
+CREATE TABLE "workflow_backfills" (
+	"id" uuid PRIMARY KEY DEFAULT uuidv7() NOT NULL,
+	"organization_id" uuid NOT NULL,
+	"agent_graph_id" uuid NOT NULL,
+	"api_key_id" uuid,
+	"scope" jsonb NOT NULL,
+	"options" jsonb NOT NULL,
+	"status" text DEFAULT 'running' NOT NULL,
+	"total" integer,
+	"enqueued" integer DEFAULT 0 NOT NULL,
+	"completed" integer DEFAULT 0 NOT NULL,
+	"failed" integer DEFAULT 0 NOT NULL,
+	"cursor" uuid,
+	"error" text,
+	"created_at" timestamp DEFAULT now() NOT NULL,
+	"updated_at" timestamp DEFAULT now() NOT NULL,
+	"finished_at" timestamp,
+	CONSTRAINT "workflow_backfills_status_known" CHECK ("workflow_backfills"."status" in ('running', 'paused', 'completed', 'failed', 'cancelled'))
+);
+--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD COLUMN "backfill_id" uuid;--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD CONSTRAINT "agent_graph_runs_backfill_id_workflow_backfills_id_fk" FOREIGN KEY ("backfill_id") REFERENCES "public"."workflow_backfills"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_backfills" ADD CONSTRAINT "workflow_backfills_organization_id_organizations_id_fk" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_backfills" ADD CONSTRAINT "workflow_backfills_agent_graph_id_agent_graphs_id_fk" FOREIGN KEY ("agent_graph_id") REFERENCES "public"."agent_graphs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_backfills" ADD CONSTRAINT "workflow_backfills_api_key_id_apikeys_id_fk" FOREIGN KEY ("api_key_id") REFERENCES "public"."apikeys"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "agent_graph_runs_backfill_id_idx" ON "agent_graph_runs" USING btree ("backfill_id");--> statement-breakpoint
+CREATE INDEX "workflow_backfills_organization_id_idx" ON "workflow_backfills" USING btree ("organization_id");--> statement-breakpoint
+CREATE INDEX "workflow_backfills_status_idx" ON "workflow_backfills" USING btree ("status");
\ No newline at end of file
@@ -0,0 +1,28 @@
+CREATE TABLE "workflow_schedules" (
+	"id" uuid PRIMARY KEY DEFAULT uuidv7() NOT NULL,
+	"organization_id" uuid NOT NULL,
+	"project_id" uuid NOT NULL,
+	"agent_graph_id" uuid NOT NULL,
+	"api_key_id" uuid,
+	"name" text NOT NULL,
+	"cron_expression" text NOT NULL,
+	"timezone" text DEFAULT 'UTC' NOT NULL,
+	"scope" jsonb NOT NULL,
+	"initial_state" jsonb,
+	"enabled" boolean DEFAULT true NOT NULL,
+	"next_fire_at" timestamp,
+	"last_fired_at" timestamp,
+	"last_error" text,
+	"created_at" timestamp DEFAULT now() NOT NULL,
+	"updated_at" timestamp DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD COLUMN "schedule_id" uuid;--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD CONSTRAINT "agent_graph_runs_schedule_id_workflow_schedules_id_fk" FOREIGN KEY ("schedule_id") REFERENCES "public"."workflow_schedules"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_schedules" ADD CONSTRAINT "workflow_schedules_organization_id_organizations_id_fk" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_schedules" ADD CONSTRAINT "workflow_schedules_project_id_projects_id_fk" FOREIGN KEY ("project_id") REFERENCES "public"."projects"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_schedules" ADD CONSTRAINT "workflow_schedules_agent_graph_id_agent_graphs_id_fk" FOREIGN KEY ("agent_graph_id") REFERENCES "public"."agent_graphs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_schedules" ADD CONSTRAINT "workflow_schedules_api_key_id_apikeys_id_fk" FOREIGN KEY ("api_key_id") REFERENCES "public"."apikeys"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "agent_graph_runs_schedule_id_idx" ON "agent_graph_runs" USING btree ("schedule_id");--> statement-breakpoint
+CREATE INDEX "workflow_schedules_organization_id_idx" ON "workflow_schedules" USING btree ("organization_id");--> statement-breakpoint
+CREATE INDEX "workflow_schedules_next_fire_at_idx" ON "workflow_schedules" USING btree ("next_fire_at");
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE "workflow_triggers" (
+	"id" uuid PRIMARY KEY DEFAULT uuidv7() NOT NULL,
+	"organization_id" uuid NOT NULL,
+	"agent_graph_id" uuid NOT NULL,
+	"target_agent_graph_id" uuid NOT NULL,
+	"name" text NOT NULL,
+	"on_status" text DEFAULT 'completed' NOT NULL,
+	"condition" jsonb,
+	"pass_keys" jsonb DEFAULT '[]' NOT NULL,
+	"enabled" boolean DEFAULT true NOT NULL,
+	"created_at" timestamp DEFAULT now() NOT NULL,
+	"updated_at" timestamp DEFAULT now() NOT NULL,
+	CONSTRAINT "workflow_triggers_on_status_known" CHECK ("workflow_triggers"."on_status" in ('completed', 'failed', 'finished')),
+	CONSTRAINT "workflow_triggers_no_self_trigger" CHECK ("workflow_triggers"."agent_graph_id" <> "workflow_triggers"."target_agent_graph_id")
+);
+--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD COLUMN "parent_run_id" uuid;--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD COLUMN "trigger_id" uuid;--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD COLUMN "chain_depth" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD CONSTRAINT "agent_graph_runs_parent_run_id_agent_graph_runs_id_fk" FOREIGN KEY ("parent_run_id") REFERENCES "public"."agent_graph_runs"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "agent_graph_runs" ADD CONSTRAINT "agent_graph_runs_trigger_id_workflow_triggers_id_fk" FOREIGN KEY ("trigger_id") REFERENCES "public"."workflow_triggers"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_triggers" ADD CONSTRAINT "workflow_triggers_organization_id_organizations_id_fk" FOREIGN KEY ("organization_id") REFERENCES "public"."organizations"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_triggers" ADD CONSTRAINT "workflow_triggers_agent_graph_id_agent_graphs_id_fk" FOREIGN KEY ("agent_graph_id") REFERENCES "public"."agent_graphs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "workflow_triggers" ADD CONSTRAINT "workflow_triggers_target_agent_graph_id_agent_graphs_id_fk" FOREIGN KEY ("target_agent_graph_id") REFERENCES "public"."agent_graphs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "agent_graph_runs_parent_run_id_idx" ON "agent_graph_runs" USING btree ("parent_run_id");--> statement-breakpoint
+CREATE INDEX "workflow_triggers_organization_id_idx" ON "workflow_triggers" USING btree ("organization_id");--> statement-breakpoint
+CREATE INDEX "workflow_triggers_agent_graph_id_idx" ON "workflow_triggers" USING btree ("agent_graph_id");
\ No newline at end of file
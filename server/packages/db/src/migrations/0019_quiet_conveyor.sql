@@ -0,0 +1,27 @@
+CREATE TABLE "job_dead_letters" (
+	"id" uuid PRIMARY KEY NOT NULL,
+	"event" text NOT NULL,
+	"event_id" text,
+	"data" jsonb NOT NULL,
+	"attempts" integer NOT NULL,
+	"error" text NOT NULL,
+	"enqueued_at" timestamp NOT NULL,
+	"failed_at" timestamp DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "job_queue" (
+	"id" uuid PRIMARY KEY DEFAULT uuidv7() NOT NULL,
+	"event" text NOT NULL,
+	"event_id" text,
+	"data" jsonb NOT NULL,
+	"attempts" integer DEFAULT 0 NOT NULL,
+	"run_at" timestamp DEFAULT now() NOT NULL,
+	"locked_until" timestamp,
+	"last_error" text,
+	"created_at" timestamp DEFAULT now() NOT NULL,
+	"updated_at" timestamp DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+CREATE INDEX "job_dead_letters_failed_at_idx" ON "job_dead_letters" USING btree ("failed_at");--> statement-breakpoint
+CREATE UNIQUE INDEX "job_queue_event_id_uidx" ON "job_queue" USING btree ("event_id");--> statement-breakpoint
+CREATE INDEX "job_queue_event_run_at_idx" ON "job_queue" USING btree ("event","run_at");
\ No newline at end of file
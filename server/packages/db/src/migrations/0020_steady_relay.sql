@@ -0,0 +1 @@
+ALTER TABLE "workflow_backfills" ADD COLUMN "generation" integer DEFAULT 0 NOT NULL;
\ No newline at end of file
@@ -2,6 +2,7 @@ import { sql } from "drizzle-orm";
 import {
 	type AnyPgColumn,
 	check,
+	boolean,
 	index,
 	integer,
 	jsonb,
@@ -230,13 +231,37 @@ export const agentGraphRuns = pgTable(
 		initialState: jsonb("initial_state"),
 		finalState: jsonb("final_state"),
 		error: text(),
+		errorCode: text("error_code"),
+		attempts: jsonb("attempts"),
+		backfillId: uuid("backfill_id").references(
+			(): AnyPgColumn => workflowBackfills.id,
+			{ onDelete: "set null" },
+		),
+		scheduleId: uuid("schedule_id").references(
+			(): AnyPgColumn => workflowSchedules.id,
+			{ onDelete: "set null" },
+		),
+		parentRunId: uuid("parent_run_id").references(
+			(): AnyPgColumn => agentGraphRuns.id,
+			{ onDelete: "set null" },
+		),
+		triggerId: uuid("trigger_id").references(
+			(): AnyPgColumn => workflowTriggers.id,
+			{ onDelete: "set null" },
+		),
+		chainDepth: integer("chain_depth").notNull().default(0),
 		startedAt: timestamp("started_at").defaultNow().notNull(),
+		pickedUpAt: timestamp("picked_up_at"),
 		finishedAt: timestamp("finished_at"),
 	},
 	(t) => [
 		index("agent_graph_runs_graph_id_idx").on(t.agentGraphId),
 		index("agent_graph_runs_project_id_idx").on(t.projectId),
 		index("agent_graph_runs_status_idx").on(t.status),
+		index("agent_graph_runs_error_code_idx").on(t.errorCode),
+		index("agent_graph_runs_backfill_id_idx").on(t.backfillId),
+		index("agent_graph_runs_schedule_id_idx").on(t.scheduleId),
+		index("agent_graph_runs_parent_run_id_idx").on(t.parentRunId),
 		uniqueIndex("agent_graph_runs_api_key_idempotency_key_uidx")
 			.on(t.apiKeyId, t.idempotencyKey)
 			.where(sql`${t.idempotencyKey} is not null`),
@@ -256,7 +281,7 @@ export const agentGraphRuns = pgTable(
 		),
 		check(
 			"agent_graph_runs_status_known",
-			sql`${t.status} in ('running', 'completed', 'failed')`,
+			sql`${t.status} in ('running', 'completed', 'failed', 'cancelled')`,
 		),
 		check(
 			"agent_graph_runs_finished_after_started",
@@ -275,6 +300,7 @@ export const agentGraphRunSteps = pgTable(
 		nodeKey: text().notNull(),
 		stepOrder: integer("step_order").notNull(),
 		stateDelta: jsonb("state_delta"),
+		errorCode: text("error_code"),
 		startedAt: timestamp("started_at").defaultNow().notNull(),
 		finishedAt: timestamp("finished_at"),
 	},
@@ -292,3 +318,120 @@ export const agentGraphRunSteps = pgTable(
 		),
 	],
 );
+
+export const workflowBackfills = pgTable(
+	"workflow_backfills",
+	{
+		id: uuid("id").primaryKey().default(sql`uuidv7()`),
+		organizationId: uuid("organization_id")
+			.notNull()
+			.references(() => organizations.id, { onDelete: "cascade" }),
+		agentGraphId: uuid("agent_graph_id")
+			.notNull()
+			.references(() => agentGraphs.id, { onDelete: "cascade" }),
+		apiKeyId: uuid("api_key_id").references(() => apikeys.id, {
+			onDelete: "set null",
+		}),
+		scope: jsonb("scope").notNull(),
+		options: jsonb("options").notNull(),
+		status: text().notNull().default("running"),
+		total: integer("total"),
+		enqueued: integer("enqueued").notNull().default(0),
+		completed: integer("completed").notNull().default(0),
+		failed: integer("failed").notNull().default(0),
+		cursor: uuid("cursor"),
+		error: text(),
+		createdAt: timestamp("created_at").defaultNow().notNull(),
+		updatedAt: timestamp("updated_at")
+			.defaultNow()
+			.$onUpdate(() => /* @__PURE__ */ new Date())
+			.notNull(),
+		finishedAt: timestamp("finished_at"),
+		// Bumped on every resume. Backfill jobs carry the generation they were
+		// started with and stop once it is stale.
+		generation: integer("generation").notNull().default(0),
+	},
+	(t) => [
+		index("workflow_backfills_organization_id_idx").on(t.organizationId),
+		index("workflow_backfills_status_idx").on(t.status),
+		check(
+			"workflow_backfills_status_known",
+			sql`${t.status} in ('running', 'paused', 'completed', 'failed', 'cancelled')`,
+		),
+	],
+);
+
+export const workflowSchedules = pgTable(
+	"workflow_schedules",
+	{
+		id: uuid("id").primaryKey().default(sql`uuidv7()`),
+		organizationId: uuid("organization_id")
+			.notNull()
+			.references(() => organizations.id, { onDelete: "cascade" }),
+		projectId: uuid("project_id")
+			.notNull()
+			.references(() => projects.id, { onDelete: "cascade" }),
+		agentGraphId: uuid("agent_graph_id")
+			.notNull()
+			.references(() => agentGraphs.id, { onDelete: "cascade" }),
+		apiKeyId: uuid("api_key_id").references(() => apikeys.id, {
+			onDelete: "set null",
+		}),
+		name: text().notNull(),
+		cronExpression: text("cron_expression").notNull(),
+		timezone: text().notNull().default("UTC"),
+		scope: jsonb("scope").notNull(),
+		initialState: jsonb("initial_state"),
+		enabled: boolean("enabled").notNull().default(true),
+		nextFireAt: timestamp("next_fire_at"),
+		lastFiredAt: timestamp("last_fired_at"),
+		lastError: text("last_error"),
+		createdAt: timestamp("created_at").defaultNow().notNull(),
+		updatedAt: timestamp("updated_at")
+			.defaultNow()
+			.$onUpdate(() => /* @__PURE__ */ new Date())
+			.notNull(),
+	},
+	(t) => [
+		index("workflow_schedules_organization_id_idx").on(t.organizationId),
+		index("workflow_schedules_next_fire_at_idx").on(t.nextFireAt),
+	],
+);
+
+export const workflowTriggers = pgTable(
+	"workflow_triggers",
+	{
+		id: uuid("id").primaryKey().default(sql`uuidv7()`),
+		organizationId: uuid("organization_id")
+			.notNull()
+			.references(() => organizations.id, { onDelete: "cascade" }),
+		agentGraphId: uuid("agent_graph_id")
+			.notNull()
+			.references(() => agentGraphs.id, { onDelete: "cascade" }),
+		targetAgentGraphId: uuid("target_agent_graph_id")
+			.notNull()
+			.references(() => agentGraphs.id, { onDelete: "cascade" }),
+		name: text().notNull(),
+		onStatus: text("on_status").notNull().default("completed"),
+		condition: jsonb("condition"),
+		passKeys: jsonb("pass_keys").notNull().default("[]"),
+		enabled: boolean("enabled").notNull().default(true),
+		createdAt: timestamp("created_at").defaultNow().notNull(),
+		updatedAt: timestamp("updated_at")
+			.defaultNow()
+			.$onUpdate(() => /* @__PURE__ */ new Date())
+			.notNull(),
+	},
+	(t) => [
+		index("workflow_triggers_organization_id_idx").on(t.organizationId),
+		index("workflow_triggers_agent_graph_id_idx").on(t.agentGraphId),
+		check(
+			"workflow_triggers_on_status_known",
+			sql`${t.onStatus} in ('completed', 'failed', 'finished')`,
+		),
+		check(
+			"workflow_triggers_no_self_trigger",
+			sql`${t.agentGraphId} <> ${t.targetAgentGraphId}`,
+		),
+	],
+);
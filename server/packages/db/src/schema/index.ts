@@ -1,4 +1,6 @@
 export * from "./agentGraphSchemas";
 export * from "./authSchema";
+export * from "./jobSchema";
 export * from "./projectSchema";
 export * from "./relationships";
+export * from "./webhookSchema";
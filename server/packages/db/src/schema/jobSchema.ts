@@ -0,0 +1,50 @@
+import { sql } from "drizzle-orm";
+import {
+	index,
+	integer,
+	jsonb,
+	pgTable,
+	text,
+	timestamp,
+	uniqueIndex,
+	uuid,
+} from "drizzle-orm/pg-core";
+
+// Jobs queued for the agents service when JOB_BACKEND=postgres.
+export const jobQueue = pgTable(
+	"job_queue",
+	{
+		id: uuid("id").primaryKey().default(sql`uuidv7()`),
+		event: text().notNull(),
+		eventId: text("event_id"),
+		data: jsonb("data").notNull(),
+		attempts: integer("attempts").notNull().default(0),
+		runAt: timestamp("run_at").defaultNow().notNull(),
+		lockedUntil: timestamp("locked_until"),
+		lastError: text("last_error"),
+		createdAt: timestamp("created_at").defaultNow().notNull(),
+		updatedAt: timestamp("updated_at")
+			.defaultNow()
+			.$onUpdate(() => /* @__PURE__ */ new Date())
+			.notNull(),
+	},
+	(t) => [
+		uniqueIndex("job_queue_event_id_uidx").on(t.eventId),
+		index("job_queue_event_run_at_idx").on(t.event, t.runAt),
+	],
+);
+
+export const jobDeadLetters = pgTable(
+	"job_dead_letters",
+	{
+		id: uuid("id").primaryKey(),
+		event: text().notNull(),
+		eventId: text("event_id"),
+		data: jsonb("data").notNull(),
+		attempts: integer("attempts").notNull(),
+		error: text().notNull(),
+		enqueuedAt: timestamp("enqueued_at").notNull(),
+		failedAt: timestamp("failed_at").defaultNow().notNull(),
+	},
+	(t) => [index("job_dead_letters_failed_at_idx").on(t.failedAt)],
+);
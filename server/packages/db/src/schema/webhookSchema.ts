@@ -0,0 +1,82 @@
+import { sql } from "drizzle-orm";
+import {
+	boolean,
+	check,
+	index,
+	integer,
+	jsonb,
+	pgTable,
+	text,
+	timestamp,
+	uniqueIndex,
+	uuid,
+} from "drizzle-orm/pg-core";
+import { agentGraphRuns } from "./agentGraphSchemas";
+import { apikeys, organizations, projects } from "./authSchema";
+
+export const webhookSubscriptions = pgTable(
+	"webhook_subscriptions",
+	{
+		id: uuid("id").primaryKey().default(sql`uuidv7()`),
+		organizationId: uuid("organization_id")
+			.notNull()
+			.references(() => organizations.id, { onDelete: "cascade" }),
+		projectId: uuid("project_id")
+			.notNull()
+			.references(() => projects.id, { onDelete: "cascade" }),
+		apiKeyId: uuid("api_key_id").references(() => apikeys.id, {
+			onDelete: "cascade",
+		}),
+		url: text().notNull(),
+		secret: text().notNull(),
+		statuses: jsonb("statuses")
+			.notNull()
+			.default('["completed","failed","cancelled"]'),
+		stateKeys: jsonb("state_keys").notNull().default("[]"),
+		enabled: boolean("enabled").notNull().default(true),
+		createdAt: timestamp("created_at").defaultNow().notNull(),
+		updatedAt: timestamp("updated_at")
+			.defaultNow()
+			.$onUpdate(() => /* @__PURE__ */ new Date())
+			.notNull(),
+	},
+	(t) => [
+		index("webhook_subscriptions_project_id_idx").on(t.projectId),
+		index("webhook_subscriptions_api_key_id_idx").on(t.apiKeyId),
+	],
+);
+
+export const webhookDeliveries = pgTable(
+	"webhook_deliveries",
+	{
+		id: uuid("id").primaryKey().default(sql`uuidv7()`),
+		subscriptionId: uuid("subscription_id")
+			.notNull()
+			.references(() => webhookSubscriptions.id, { onDelete: "cascade" }),
+		runId: uuid("run_id")
+			.notNull()
+			.references(() => agentGraphRuns.id, { onDelete: "cascade" }),
+		status: text().notNull().default("pending"),
+		payload: jsonb("payload").notNull(),
+		attempts: integer("attempts").notNull().default(0),
+		lastStatusCode: integer("last_status_code"),
+		lastError: text("last_error"),
+		deliveredAt: timestamp("delivered_at"),
+		createdAt: timestamp("created_at").defaultNow().notNull(),
+		updatedAt: timestamp("updated_at")
+			.defaultNow()
+			.$onUpdate(() => /* @__PURE__ */ new Date())
+			.notNull(),
+	},
+	(t) => [
+		uniqueIndex("webhook_deliveries_subscription_run_uidx").on(
+			t.subscriptionId,
+			t.runId,
+		),
+		index("webhook_deliveries_run_id_idx").on(t.runId),
+		check(
+			"webhook_deliveries_status_known",
+			sql`${t.status} in ('pending', 'succeeded', 'failed')`,
+		),
+	],
+);
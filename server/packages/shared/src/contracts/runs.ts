@@ -7,7 +7,9 @@ export const runItemSchema = z.object({
 	workflowName: z.string().min(1),
 	status: z.string().min(1),
 	error: z.string().nullable(),
+	errorCode: z.string().nullable(),
 	startedAt: z.string().min(1),
+	pickedUpAt: z.string().nullable(),
 	finishedAt: z.string().nullable(),
 });
 
@@ -18,6 +20,7 @@ export const runStepSchema = z.object({
 	nodeKey: z.string().min(1),
 	stepOrder: z.number().int(),
 	stateDelta: jsonValueSchema,
+	errorCode: z.string().nullable(),
 	startedAt: z.string().min(1),
 	finishedAt: z.string().nullable(),
 });
@@ -36,6 +39,7 @@ export const runStepsResponseSchema = z.object({
 	initialState: jsonValueSchema.nullable(),
 	finalState: jsonValueSchema.nullable(),
 	error: z.string().nullable(),
+	errorCode: z.string().nullable(),
 });
 
 export type RunStepsResponse = z.infer<typeof runStepsResponseSchema>;
@@ -7,7 +7,11 @@ import {
 	serviceUploadAcknowledgeRequestSchema,
 	serviceUploadAcknowledgeResponseSchema,
 	serviceUploadPresignRequestSchema,
+	serviceWebhookRequestSchema,
 	serviceWorkflowExecutionRequestSchema,
+	serviceWorkflowScheduleRequestSchema,
+	serviceWorkflowScheduleUpdateSchema,
+	serviceWorkflowTriggerRequestSchema,
 } from "./service-api";
 
 describe("service API schemas", () => {
@@ -164,4 +168,91 @@ describe("service API schemas", () => {
 			limit: 8,
 		});
 	});
+
+	test("accepts workflow schedules with cron fields or macros", () => {
+		for (const cron of ["0 2 * * *", "*/15 9-17 * * mon-fri", "@daily"]) {
+			const parsed = serviceWorkflowScheduleRequestSchema.safeParse({
+				workflowId: "workflow-1",
+				name: "Nightly descriptions",
+				cron,
+				timezone: "Europe/Paris",
+				createdWithinHours: 24,
+			});
+
+			expect(parsed.success).toBe(true);
+		}
+	});
+
+	test("rejects malformed workflow schedules", () => {
+		for (const input of [
+			{ cron: "0 2 * *" },
+			{ cron: "every night" },
+			{ cron: "0 2 * * *", timezone: "Mars/Olympus" },
+		]) {
+			const parsed = serviceWorkflowScheduleRequestSchema.safeParse({
+				workflowId: "workflow-1",
+				name: "Nightly descriptions",
+				...input,
+			});
+
+			expect(parsed.success).toBe(false);
+		}
+	});
+
+	test("rejects empty workflow schedule updates", () => {
+		const parsed = serviceWorkflowScheduleUpdateSchema.safeParse({});
+
+		expect(parsed.success).toBe(false);
+	});
+
+	test("accepts workflow triggers with a final-state condition", () => {
+		const parsed = serviceWorkflowTriggerRequestSchema.safeParse({
+			workflowId: "workflow-ocr",
+			targetWorkflowId: "workflow-ocr-review",
+			name: "Review low-confidence OCR",
+			condition: { key: "avg_confidence", operator: "lt", value: 60 },
+			passKeys: ["avg_confidence", "ocr_text"],
+		});
+
+		expect(parsed.success).toBe(true);
+	});
+
+	test("rejects self-triggers and conditions without a value", () => {
+		for (const input of [
+			{ targetWorkflowId: "workflow-ocr" },
+			{
+				targetWorkflowId: "workflow-ocr-review",
+				condition: { key: "avg_confidence", operator: "lt" },
+			},
+		]) {
+			const parsed = serviceWorkflowTriggerRequestSchema.safeParse({
+				workflowId: "workflow-ocr",
+				name: "Review low-confidence OCR",
+				...input,
+			});
+
+			expect(parsed.success).toBe(false);
+		}
+	});
+
+	test("accepts webhooks with execution statuses and state keys", () => {
+		const parsed = serviceWebhookRequestSchema.safeParse({
+			url: "https://hooks.example.com/arcnem",
+			statuses: ["completed", "failed"],
+			stateKeys: ["summary"],
+			apiKeyOnly: true,
+		});
+
+		expect(parsed.success).toBe(true);
+	});
+
+	test("rejects webhooks with non-http urls or unknown statuses", () => {
+		for (const input of [
+			{ url: "ftp://hooks.example.com/arcnem" },
+			{ url: "https://hooks.example.com/arcnem", statuses: ["running"] },
+			{ url: "https://hooks.example.com/arcnem", statuses: [] },
+		]) {
+			expect(serviceWebhookRequestSchema.safeParse(input).success).toBe(false);
+		}
+	});
 });
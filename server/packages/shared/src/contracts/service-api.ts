@@ -139,10 +139,15 @@ export const serviceWorkflowExecutionItemSchema = z.object({
 	executionId: z.string().min(1),
 	workflowId: z.string().min(1),
 	snapshotHash: z.string().length(64).nullable(),
-	status: z.enum(["running", "completed", "failed"]),
+	status: z.enum(["running", "completed", "failed", "cancelled"]),
 	startedAt: z.string().nullable(),
+	pickedUpAt: z.string().nullable(),
 	finishedAt: z.string().nullable(),
 	error: z.string().nullable(),
+	errorCode: z.string().nullable(),
+	scheduleId: z.string().nullable(),
+	parentExecutionId: z.string().nullable(),
+	triggerId: z.string().nullable(),
 	finalState: jsonValueSchema.nullable(),
 });
 
@@ -150,6 +155,298 @@ export type ServiceWorkflowExecutionItem = z.infer<
 	typeof serviceWorkflowExecutionItemSchema
 >;
 
+export const SERVICE_WORKFLOW_BACKFILL_MAX_RATE_PER_MINUTE = 600;
+
+export const serviceWorkflowBackfillRequestSchema = z.object({
+	workflowId: z.string().min(1),
+	scope: serviceDocumentScopeSchema.optional(),
+	options: z
+		.object({
+			withoutOcr: z.boolean().optional(),
+			withoutDescription: z.boolean().optional(),
+			ratePerMinute: z
+				.number()
+				.int()
+				.positive()
+				.max(SERVICE_WORKFLOW_BACKFILL_MAX_RATE_PER_MINUTE)
+				.optional(),
+		})
+		.optional(),
+});
+
+export type ServiceWorkflowBackfillRequest = z.infer<
+	typeof serviceWorkflowBackfillRequestSchema
+>;
+
+export const serviceWorkflowBackfillItemSchema = z.object({
+	backfillId: z.string().min(1),
+	workflowId: z.string().min(1),
+	status: z.enum(["running", "paused", "completed", "failed", "cancelled"]),
+	total: z.number().int().nonnegative().nullable(),
+	enqueued: z.number().int().nonnegative(),
+	completed: z.number().int().nonnegative(),
+	failed: z.number().int().nonnegative(),
+	error: z.string().nullable(),
+	createdAt: z.string().min(1),
+	updatedAt: z.string().min(1),
+	finishedAt: z.string().nullable(),
+});
+
+export type ServiceWorkflowBackfillItem = z.infer<
+	typeof serviceWorkflowBackfillItemSchema
+>;
+
+const WORKFLOW_CRON_MACROS = [
+	"@yearly",
+	"@annually",
+	"@monthly",
+	"@weekly",
+	"@daily",
+	"@midnight",
+	"@hourly",
+];
+
+// The agents service is the authority on cron syntax; this only rejects
+// obviously malformed expressions before they are stored.
+export const workflowCronExpressionSchema = z
+	.string()
+	.trim()
+	.min(1)
+	.refine(
+		(value) =>
+			WORKFLOW_CRON_MACROS.includes(value.toLowerCase()) ||
+			(value.split(/\s+/).length === 5 &&
+				value.split(/\s+/).every((field) => /^[\w*,/-]+$/.test(field))),
+		{ message: "cron must have five fields or be a macro such as @daily" },
+	);
+
+export const workflowTimezoneSchema = z
+	.string()
+	.trim()
+	.min(1)
+	.refine(
+		(value) => {
+			try {
+				new Intl.DateTimeFormat("en-US", { timeZone: value });
+				return true;
+			} catch {
+				return false;
+			}
+		},
+		{ message: "timezone must be an IANA time zone such as Europe/Paris" },
+	);
+
+export const serviceWorkflowScheduleRequestSchema = z.object({
+	workflowId: z.string().min(1),
+	name: z.string().trim().min(1).max(200),
+	cron: workflowCronExpressionSchema,
+	timezone: workflowTimezoneSchema.optional(),
+	scope: serviceDocumentScopeSchema.optional(),
+	createdWithinHours: z.number().int().positive().max(8760).optional(),
+	initialState: z.record(z.string(), jsonValueSchema).optional(),
+	enabled: z.boolean().optional(),
+});
+
+export type ServiceWorkflowScheduleRequest = z.infer<
+	typeof serviceWorkflowScheduleRequestSchema
+>;
+
+export const serviceWorkflowScheduleUpdateSchema = z
+	.object({
+		name: z.string().trim().min(1).max(200).optional(),
+		cron: workflowCronExpressionSchema.optional(),
+		timezone: workflowTimezoneSchema.optional(),
+		enabled: z.boolean().optional(),
+	})
+	.refine((value) => Object.values(value).some((item) => item !== undefined), {
+		message: "update must change name, cron, timezone, or enabled",
+	});
+
+export type ServiceWorkflowScheduleUpdate = z.infer<
+	typeof serviceWorkflowScheduleUpdateSchema
+>;
+
+export const serviceWorkflowScheduleItemSchema = z.object({
+	scheduleId: z.string().min(1),
+	workflowId: z.string().min(1),
+	name: z.string().min(1),
+	cron: z.string().min(1),
+	timezone: z.string().min(1),
+	enabled: z.boolean(),
+	nextFireAt: z.string().nullable(),
+	lastFiredAt: z.string().nullable(),
+	lastError: z.string().nullable(),
+	createdAt: z.string().min(1),
+	updatedAt: z.string().min(1),
+});
+
+export type ServiceWorkflowScheduleItem = z.infer<
+	typeof serviceWorkflowScheduleItemSchema
+>;
+
+export const serviceWorkflowSchedulesResponseSchema = z.object({
+	schedules: z.array(serviceWorkflowScheduleItemSchema),
+});
+
+export type ServiceWorkflowSchedulesResponse = z.infer<
+	typeof serviceWorkflowSchedulesResponseSchema
+>;
+
+export const WORKFLOW_TRIGGER_STATUSES = [
+	"completed",
+	"failed",
+	"finished",
+] as const;
+
+export const WORKFLOW_TRIGGER_CONDITION_OPERATORS = [
+	"equals",
+	"not_equals",
+	"contains",
+	"lt",
+	"lte",
+	"gt",
+	"gte",
+	"exists",
+] as const;
+
+export const serviceWorkflowTriggerConditionSchema = z
+	.object({
+		key: z.string().trim().min(1).max(200),
+		operator: z.enum(WORKFLOW_TRIGGER_CONDITION_OPERATORS),
+		value: z.union([z.string(), z.number(), z.boolean()]).optional(),
+	})
+	.refine(
+		(value) => value.operator === "exists" || value.value !== undefined,
+		{
+			message: "value is required unless operator is exists",
+			path: ["value"],
+		},
+	);
+
+export type ServiceWorkflowTriggerCondition = z.infer<
+	typeof serviceWorkflowTriggerConditionSchema
+>;
+
+export const serviceWorkflowTriggerRequestSchema = z
+	.object({
+		workflowId: z.string().min(1),
+		targetWorkflowId: z.string().min(1),
+		name: z.string().trim().min(1).max(200),
+		on: z.enum(WORKFLOW_TRIGGER_STATUSES).optional(),
+		condition: serviceWorkflowTriggerConditionSchema.optional(),
+		passKeys: z.array(z.string().trim().min(1)).max(50).optional(),
+		enabled: z.boolean().optional(),
+	})
+	.refine((value) => value.workflowId !== value.targetWorkflowId, {
+		message: "a workflow cannot trigger itself",
+		path: ["targetWorkflowId"],
+	});
+
+export type ServiceWorkflowTriggerRequest = z.infer<
+	typeof serviceWorkflowTriggerRequestSchema
+>;
+
+export const serviceWorkflowTriggerItemSchema = z.object({
+	triggerId: z.string().min(1),
+	workflowId: z.string().min(1),
+	targetWorkflowId: z.string().min(1),
+	name: z.string().min(1),
+	on: z.enum(WORKFLOW_TRIGGER_STATUSES),
+	condition: serviceWorkflowTriggerConditionSchema.nullable(),
+	passKeys: z.array(z.string()),
+	enabled: z.boolean(),
+	createdAt: z.string().min(1),
+	updatedAt: z.string().min(1),
+});
+
+export type ServiceWorkflowTriggerItem = z.infer<
+	typeof serviceWorkflowTriggerItemSchema
+>;
+
+export const serviceWorkflowTriggersResponseSchema = z.object({
+	triggers: z.array(serviceWorkflowTriggerItemSchema),
+});
+
+export type ServiceWorkflowTriggersResponse = z.infer<
+	typeof serviceWorkflowTriggersResponseSchema
+>;
+
+export const WEBHOOK_EXECUTION_STATUSES = [
+	"completed",
+	"failed",
+	"cancelled",
+] as const;
+
+export const WEBHOOK_DELIVERY_STATUSES = [
+	"pending",
+	"succeeded",
+	"failed",
+] as const;
+
+export const serviceWebhookRequestSchema = z.object({
+	url: z
+		.string()
+		.url()
+		.refine((value) => /^https?:\/\//.test(value), {
+			message: "url must use http or https",
+		}),
+	statuses: z.array(z.enum(WEBHOOK_EXECUTION_STATUSES)).min(1).optional(),
+	stateKeys: z.array(z.string().trim().min(1)).max(50).optional(),
+	apiKeyOnly: z.boolean().optional(),
+	enabled: z.boolean().optional(),
+});
+
+export type ServiceWebhookRequest = z.infer<typeof serviceWebhookRequestSchema>;
+
+export const serviceWebhookItemSchema = z.object({
+	webhookId: z.string().min(1),
+	projectId: z.string().min(1),
+	apiKeyId: z.string().nullable(),
+	url: z.string().min(1),
+	statuses: z.array(z.enum(WEBHOOK_EXECUTION_STATUSES)),
+	stateKeys: z.array(z.string()),
+	enabled: z.boolean(),
+	secret: z.string().min(1).optional(),
+	createdAt: z.string().min(1),
+	updatedAt: z.string().min(1),
+});
+
+export type ServiceWebhookItem = z.infer<typeof serviceWebhookItemSchema>;
+
+export const serviceWebhooksResponseSchema = z.object({
+	webhooks: z.array(serviceWebhookItemSchema),
+});
+
+export type ServiceWebhooksResponse = z.infer<
+	typeof serviceWebhooksResponseSchema
+>;
+
+export const serviceWebhookDeliveryItemSchema = z.object({
+	deliveryId: z.string().min(1),
+	webhookId: z.string().min(1),
+	executionId: z.string().min(1),
+	status: z.enum(WEBHOOK_DELIVERY_STATUSES),
+	attempts: z.number().int().nonnegative(),
+	lastStatusCode: z.number().int().nullable(),
+	lastError: z.string().nullable(),
+	payload: jsonValueSchema,
+	deliveredAt: z.string().nullable(),
+	createdAt: z.string().min(1),
+	updatedAt: z.string().min(1),
+});
+
+export type ServiceWebhookDeliveryItem = z.infer<
+	typeof serviceWebhookDeliveryItemSchema
+>;
+
+export const serviceWebhookDeliveriesResponseSchema = z.object({
+	deliveries: z.array(serviceWebhookDeliveryItemSchema),
+});
+
+export type ServiceWebhookDeliveriesResponse = z.infer<
+	typeof serviceWebhookDeliveriesResponseSchema
+>;
+
 export const serviceDocumentVisibilityUpdateSchema = z.object({
 	documentIds: z.array(z.string().min(1)).min(1),
 	visibility: z.enum(["private", "org", "public"]),
@@ -5,6 +5,7 @@ import {
 	normalizeNodeConfig,
 	normalizeOptionalStateKey,
 	normalizeOptionalUuid,
+	validateToolFanOut,
 	validateToolMapping,
 	WORKFLOW_NODE_TYPES,
 	type WorkflowEdgeInput,
@@ -73,6 +74,15 @@ export function normalizeGraphData(input: {
 		if (nodeType === "tool") {
 			validateToolMapping(config.input_mapping, "input_mapping", nodeKey);
 			validateToolMapping(config.output_mapping, "output_mapping", nodeKey);
+			if (
+				config.skip_if_missing != null &&
+				typeof config.skip_if_missing !== "boolean"
+			) {
+				throw new Error(
+					`Tool node "${nodeKey}" skip_if_missing must be true or false.`,
+				);
+			}
+			validateToolFanOut(config.fan_out, nodeKey);
 		}
 
 		if (nodeType === "condition") {
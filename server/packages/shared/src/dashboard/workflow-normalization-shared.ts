@@ -2,6 +2,8 @@ import type { WorkflowNodeConfig } from "../contracts/dashboard";
 
 export const NODE_KEY_PATTERN = /^[a-zA-Z0-9._:-]+$/;
 export const STATE_KEY_PATTERN = /^[a-zA-Z0-9._:-]+$/;
+// State paths may also index into arrays, e.g. "matches[0].id" or "matches[*].id".
+export const STATE_PATH_PATTERN = /^\$?[a-zA-Z0-9._:*[\]-]+$/;
 
 export const WORKFLOW_NODE_TYPES = new Set([
 	"worker",
@@ -113,6 +115,14 @@ export function validateToolMapping(
 		if (mappingName === "input_mapping" && normalized.startsWith("_const:")) {
 			continue;
 		}
+		if (mappingName === "input_mapping") {
+			if (!STATE_PATH_PATTERN.test(normalized)) {
+				throw new Error(
+					`Tool node "${nodeKey}" mapping "${field}" must be a state key or path such as "scope.project_ids" or "matches[0].id".`,
+				);
+			}
+			continue;
+		}
 		if (!STATE_KEY_PATTERN.test(normalized)) {
 			throw new Error(
 				`Tool node "${nodeKey}" mapping "${field}" must use letters, numbers, dots, colons, dashes, and underscores only.`,
@@ -121,6 +131,38 @@ export function validateToolMapping(
 	}
 }
 
+export function validateToolFanOut(fanOut: unknown, nodeKey: string) {
+	if (fanOut == null) return;
+	if (!isRecord(fanOut)) {
+		throw new Error(
+			`Tool node "${nodeKey}" must provide fan_out as an object when set.`,
+		);
+	}
+	if (typeof fanOut.field !== "string" || !fanOut.field.trim()) {
+		throw new Error(`Tool node "${nodeKey}" fan_out must set field.`);
+	}
+	if (
+		fanOut.concurrency != null &&
+		(typeof fanOut.concurrency !== "number" ||
+			!Number.isInteger(fanOut.concurrency) ||
+			fanOut.concurrency < 1 ||
+			fanOut.concurrency > 16)
+	) {
+		throw new Error(
+			`Tool node "${nodeKey}" fan_out.concurrency must be a whole number between 1 and 16.`,
+		);
+	}
+	if (
+		fanOut.on_error != null &&
+		fanOut.on_error !== "fail_fast" &&
+		fanOut.on_error !== "collect"
+	) {
+		throw new Error(
+			`Tool node "${nodeKey}" fan_out.on_error must be fail_fast or collect.`,
+		);
+	}
+}
+
 export function normalizeConditionTarget(
 	value: unknown,
 	nodeKey: string,